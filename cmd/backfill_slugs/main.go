@@ -0,0 +1,37 @@
+package main
+
+import (
+	"context"
+	"edjr-trk/configs/env"
+	"edjr-trk/internal/ioc"
+	"go.uber.org/zap"
+)
+
+// One-shot migration: generates slugs for articles and projects created before slugs existed.
+// Items in the trash are skipped; run it again after restoring them.
+//
+//	go run ./cmd/backfill_slugs
+func main() {
+	env.LoadEnv()
+
+	container := ioc.NewContainer()
+	defer container.Close()
+
+	ctx := context.Background()
+	logger := container.Logger
+
+	articles, err := container.ArticleService.BackfillSlugs(ctx)
+	if err != nil {
+		logger.Fatal("Failed to backfill article slugs", zap.Int("done", articles), zap.Error(err))
+	}
+
+	products, err := container.ProductService.BackfillSlugs(ctx)
+	if err != nil {
+		logger.Fatal("Failed to backfill product slugs", zap.Int("done", products), zap.Error(err))
+	}
+
+	logger.Info("Slug backfill finished",
+		zap.Int("articles", articles),
+		zap.Int("products", products),
+	)
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"edjr-trk/configs/env"
+	"edjr-trk/internal/api/dto"
+	"edjr-trk/internal/ioc"
+	"edjr-trk/internal/service"
+	"errors"
+	"flag"
+	"go.uber.org/zap"
+)
+
+// First-run setup: creates the initial owner account, who then manages other users through the API.
+// Does nothing once an owner exists. The password is read from the environment to keep it out of shell history.
+//
+//	BOOTSTRAP_OWNER_PASSWORD=... go run ./cmd/bootstrap_owner -email owner@example.com -phone 375291234567
+func main() {
+	email := flag.String("email", "", "owner email")
+	phone := flag.String("phone", "", "owner phone")
+	flag.Parse()
+
+	env.LoadEnv()
+
+	container := ioc.NewContainer()
+	defer container.Close()
+
+	logger := container.Logger
+
+	password := env.GetEnv("BOOTSTRAP_OWNER_PASSWORD", "")
+	if *email == "" || len(password) < 8 {
+		logger.Fatal("Owner email and a BOOTSTRAP_OWNER_PASSWORD of at least 8 characters are required")
+	}
+
+	owner, err := container.UserService.BootstrapOwner(context.Background(), &dto.CreateUserRequest{
+		Email:    *email,
+		Phone:    *phone,
+		Password: password,
+	})
+	if errors.Is(err, service.ErrOwnerExists) {
+		logger.Info("Owner already exists, nothing to do")
+		return
+	}
+	if err != nil {
+		logger.Fatal("Failed to create owner", zap.Error(err))
+	}
+
+	logger.Info("Owner created", zap.String("id", owner.ID.Hex()), zap.String("email", owner.Email))
+}
@@ -6,6 +6,8 @@ import (
 	"edjr-trk/internal/api/middlewares"
 	"edjr-trk/internal/api/routes"
 	"edjr-trk/internal/ioc"
+	"edjr-trk/internal/service"
+	"edjr-trk/pkg/utils"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
 	"go.uber.org/zap"
@@ -23,7 +25,14 @@ func main() {
 	container := ioc.NewContainer()
 
 	// Setup Fiber application
-	app := fiber.New()
+	app := fiber.New(fiber.Config{
+		// Images are uploaded as base64 JSON or multipart, so allow bodies larger than the 4MB default
+		BodyLimit: env.GetEnvInt("SERV_BODY_LIMIT", 16<<20),
+	})
+
+	// Middleware: Client IP for per-IP limits. The proxy header is read only on connections
+	// from SERV_TRUSTED_PROXIES, otherwise a client could pick any IP and bypass the limits
+	app.Use(newClientIPResolver(container.Logger).Middleware())
 
 	// Middleware: CORS
 	app.Use(cors.New(cors.Config{
@@ -36,6 +45,9 @@ func main() {
 	// Middleware: Request logging
 	app.Use(middlewares.RequestLoggerMiddleware(container.Logger))
 
+	// Public keys for verifying our tokens, outside of `/api`
+	routes.RegisterWellKnownRoutes(app, container)
+
 	// Create a group for API routes with prefix `/api`
 	api := app.Group("/api")
 
@@ -44,7 +56,19 @@ func main() {
 	routes.RegisterUserRoutes(api, container)
 	routes.RegisterAuthRoutes(api, container)
 	routes.RegisterEmailRoutes(api, container)
+	routes.RegisterLeadRoutes(api, container)
+	routes.RegisterOutboxRoutes(api, container)
 	routes.RegisterProductRoutes(api, container)
+	routes.RegisterMediaRoutes(api, container)
+	routes.RegisterTaxonomyRoutes(api, container)
+
+	// Background purge of soft-deleted documents
+	purgeCtx, stopPurger := context.WithCancel(context.Background())
+	defer stopPurger()
+	go container.TrashPurger.Run(purgeCtx)
+
+	// Background workers sending queued emails
+	container.EmailOutbox.Start()
 
 	// Start the server
 	port := env.GetEnv("SERV_PORT", "3000")
@@ -58,11 +82,32 @@ func main() {
 	}()
 
 	// Call graceful shutdown handler
-	handleGracefulShutdown(app, container.Logger)
+	handleGracefulShutdown(app, container.EmailOutbox, container.Logger)
+}
+
+// newClientIPResolver reads the proxy settings. Behind a proxy without SERV_TRUSTED_PROXIES every client
+// would share the proxy's IP, and one client could lock everybody out, so the server refuses to start.
+func newClientIPResolver(logger *zap.Logger) *utils.ClientIPResolver {
+	header := env.GetEnv("SERV_PROXY_HEADER", "")
+	behindProxy := env.GetEnvBool("SERV_BEHIND_PROXY", false) || header != ""
+	if header == "" {
+		header = fiber.HeaderXForwardedFor
+	}
+
+	resolver, err := utils.NewClientIPResolver(env.GetEnvList("SERV_TRUSTED_PROXIES"), header, behindProxy)
+	if err != nil {
+		logger.Fatal("Invalid proxy configuration, set SERV_TRUSTED_PROXIES", zap.Error(err))
+	}
+	if !resolver.HasTrustedProxies() {
+		logger.Warn("No trusted proxies configured: the client IP is the connection address. " +
+			"Behind a reverse proxy all clients share its IP for rate limits and login lockouts; " +
+			"set SERV_TRUSTED_PROXIES and SERV_BEHIND_PROXY=true")
+	}
+	return resolver
 }
 
 // handleGracefulShutdown handles signal-based graceful shutdown
-func handleGracefulShutdown(app *fiber.App, logger *zap.Logger) {
+func handleGracefulShutdown(app *fiber.App, outbox *service.EmailOutbox, logger *zap.Logger) {
 	// Create a channel to receive OS signals
 	signalChan := make(chan os.Signal, 1)
 	signal.Notify(signalChan, os.Interrupt, syscall.SIGTERM)
@@ -81,4 +126,9 @@ func handleGracefulShutdown(app *fiber.App, logger *zap.Logger) {
 	} else {
 		logger.Info("Server shut down gracefully")
 	}
+
+	// Send queued emails within the remaining time; the rest stay in the outbox until next start
+	if err := outbox.Shutdown(ctx); err != nil {
+		logger.Warn("Email outbox was not fully drained", zap.Error(err))
+	}
 }
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"edjr-trk/configs/env"
+	"edjr-trk/internal/api/dto"
+	"edjr-trk/internal/ioc"
+	"go.uber.org/zap"
+)
+
+// One-shot migration: moves base64 images stored inline in articles and products
+// into the media store and replaces them with media URLs.
+//
+//	go run ./cmd/migrate_media
+func main() {
+	env.LoadEnv()
+
+	container := ioc.NewContainer()
+	defer container.Close()
+
+	ctx := context.Background()
+	logger := container.Logger
+
+	articles, err := container.ArticleRepo.GetArticlesWithInlineImg(ctx)
+	if err != nil {
+		logger.Fatal("Failed to fetch articles with inline images", zap.Error(err))
+	}
+
+	migratedArticles := 0
+	for _, article := range articles {
+		url, err := container.MediaService.StoreInlineImage(ctx, article.Img)
+		if err != nil {
+			logger.Error("Failed to migrate article image", zap.String("id", article.ID.Hex()), zap.Error(err))
+			continue
+		}
+		if _, err := container.ArticleRepo.PatchArticleById(ctx, &dto.PatchArticleRequest{Img: url}, article.ID.Hex()); err != nil {
+			logger.Error("Failed to update article image", zap.String("id", article.ID.Hex()), zap.Error(err))
+			continue
+		}
+		migratedArticles++
+	}
+
+	products, err := container.ProductRepo.GetProductsWithInlineImg(ctx)
+	if err != nil {
+		logger.Fatal("Failed to fetch products with inline images", zap.Error(err))
+	}
+
+	migratedProducts := 0
+	for _, product := range products {
+		url, err := container.MediaService.StoreInlineImage(ctx, product.Img)
+		if err != nil {
+			logger.Error("Failed to migrate product image", zap.String("id", product.ID.Hex()), zap.Error(err))
+			continue
+		}
+		if _, err := container.ProductRepo.PatchProductById(ctx, &dto.PatchProductRequest{Img: url}, product.ID.Hex()); err != nil {
+			logger.Error("Failed to update product image", zap.String("id", product.ID.Hex()), zap.Error(err))
+			continue
+		}
+		migratedProducts++
+	}
+
+	logger.Info("Media migration finished",
+		zap.Int("articles", migratedArticles),
+		zap.Int("articlesTotal", len(articles)),
+		zap.Int("products", migratedProducts),
+		zap.Int("productsTotal", len(products)),
+	)
+}
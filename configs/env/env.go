@@ -4,6 +4,8 @@ import (
 	"github.com/joho/godotenv"
 	"log"
 	"os"
+	"strconv"
+	"strings"
 )
 
 // LoadEnv загружает переменные из файла .env
@@ -22,3 +24,32 @@ func GetEnv(key, defaultValue string) string {
 	}
 	return value
 }
+
+// GetEnvInt получает целочисленное значение переменной окружения
+func GetEnvInt(key string, defaultValue int) int {
+	value, err := strconv.Atoi(GetEnv(key, ""))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+// GetEnvBool получает логическое значение переменной окружения (true/false, 1/0)
+func GetEnvBool(key string, defaultValue bool) bool {
+	value, err := strconv.ParseBool(GetEnv(key, ""))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+// GetEnvList получает список из переменной окружения, значения через запятую
+func GetEnvList(key string) []string {
+	var list []string
+	for _, item := range strings.Split(GetEnv(key, ""), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
@@ -0,0 +1,41 @@
+package locale
+
+import (
+	"edjr-trk/configs/env"
+	"strings"
+)
+
+// Supported - языки контента (CONTENT_LOCALES), через запятую.
+func Supported() []string {
+	return splitLocales(env.GetEnv("CONTENT_LOCALES", "ru,en"))
+}
+
+// Default - язык, на котором написаны документы без явного языка (CONTENT_DEFAULT_LOCALE).
+func Default() string {
+	return strings.ToLower(env.GetEnv("CONTENT_DEFAULT_LOCALE", "ru"))
+}
+
+// Fallback - языки, которые пробуются по порядку, если запрошенного перевода нет (CONTENT_FALLBACK_LOCALES).
+func Fallback() []string {
+	return splitLocales(env.GetEnv("CONTENT_FALLBACK_LOCALES", "en,ru"))
+}
+
+// IsSupported - есть ли язык в CONTENT_LOCALES.
+func IsSupported(locale string) bool {
+	for _, supported := range Supported() {
+		if supported == locale {
+			return true
+		}
+	}
+	return false
+}
+
+func splitLocales(value string) []string {
+	var locales []string
+	for _, locale := range strings.Split(value, ",") {
+		if locale = strings.ToLower(strings.TrimSpace(locale)); locale != "" {
+			locales = append(locales, locale)
+		}
+	}
+	return locales
+}
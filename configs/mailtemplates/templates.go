@@ -0,0 +1,9 @@
+package mailtemplates
+
+import "embed"
+
+// FS - встроенные шаблоны писем: <locale>/<name>.html и <locale>/<name>.txt.
+// Любой файл можно заменить одноимённым в MAIL_TEMPLATES_DIR.
+//
+//go:embed en ru
+var FS embed.FS
@@ -0,0 +1,26 @@
+package media
+
+import (
+	"edjr-trk/configs/env"
+	"edjr-trk/pkg/imaging"
+)
+
+// MaxUploadSize - максимальный размер загружаемого файла в байтах (MEDIA_MAX_UPLOAD_SIZE).
+func MaxUploadSize() int {
+	return env.GetEnvInt("MEDIA_MAX_UPLOAD_SIZE", 5<<20)
+}
+
+// MaxImageWidth - максимальная ширина изображения в пикселях (MEDIA_MAX_IMAGE_WIDTH).
+func MaxImageWidth() int {
+	return env.GetEnvInt("MEDIA_MAX_IMAGE_WIDTH", 6000)
+}
+
+// MaxImageHeight - максимальная высота изображения в пикселях (MEDIA_MAX_IMAGE_HEIGHT).
+func MaxImageHeight() int {
+	return env.GetEnvInt("MEDIA_MAX_IMAGE_HEIGHT", 6000)
+}
+
+// ImageLimits - ограничения загружаемого изображения.
+func ImageLimits() imaging.Limits {
+	return imaging.Limits{MaxSize: MaxUploadSize(), MaxWidth: MaxImageWidth(), MaxHeight: MaxImageHeight()}
+}
@@ -1,6 +1,15 @@
 package mongo
 
 const (
-	UsersCollection   = "users"
-	ArticleCollection = "articles"
+	UsersCollection         = "users"
+	ArticleCollection       = "articles"
+	ProductCollection       = "products"
+	MediaCollection         = "media"
+	MediaBucket             = "media_blobs"
+	RevisionCollection      = "revisions"
+	TaxonomyCollection      = "taxonomy"
+	RefreshTokenCollection  = "refresh_tokens"
+	PasswordResetCollection = "password_resets"
+	LeadCollection          = "leads"
+	OutboxCollection        = "outbox"
 )
@@ -44,6 +44,36 @@ func InitMongoSingleton() {
 
 		// Ensure unique index on email field
 		ensureEmailUniqueIndex(ctx)
+
+		// Ensure full-text indexes for search
+		ensureTextIndexes(ctx)
+
+		// Give articles created before publishing existed a publish date
+		backfillPublishAt(ctx)
+
+		// Ensure (date, _id) and (publishAt, _id) indexes for cursor pagination
+		ensureKeysetIndexes(ctx)
+
+		// Ensure unique (entity, version) index for revision history
+		ensureRevisionIndexes(ctx)
+
+		// Ensure unique slug indexes for SEO-friendly lookup
+		ensureSlugIndexes(ctx)
+
+		// Ensure taxonomy indexes for tag/category filtering
+		ensureTaxonomyIndexes(ctx)
+
+		// Ensure refresh token lookup and expiry indexes
+		ensureRefreshTokenIndexes(ctx)
+
+		// Ensure password reset token lookup and expiry indexes
+		ensurePasswordResetIndexes(ctx)
+
+		// Ensure lead inbox indexes
+		ensureLeadIndexes(ctx)
+
+		// Ensure outbound email queue indexes
+		ensureOutboxIndexes(ctx)
 	})
 }
 
@@ -77,7 +107,7 @@ func ensureEmailUniqueIndex(ctx context.Context) {
 	indexModel := mongo.IndexModel{
 		Keys: bson.D{{Key: "email", Value: 1}}, // Create index on "email" field
 		Options: options.Index().
-			SetUnique(true). // Make the index unique
+			SetUnique(true).               // Make the index unique
 			SetName("unique_email_index"), // Optional: name for the index
 	}
 
@@ -88,3 +118,308 @@ func ensureEmailUniqueIndex(ctx context.Context) {
 		log.Info("Unique index on email field created successfully.")
 	}
 }
+
+// ensureTextIndexes creates weighted text indexes used by the "q" search on articles and projects.
+// A collection has at most one text index, so one created with another name, fields, weights
+// or language (e.g. after MONGO_TEXT_LANGUAGE changed) is dropped and replaced.
+func ensureTextIndexes(ctx context.Context) {
+	db := GetClient().Database(env.GetEnv("MONGO_DB_NAME", "default_db"))
+	language := env.GetEnv("MONGO_TEXT_LANGUAGE", "russian")
+
+	indexes := map[string]mongo.IndexModel{
+		ArticleCollection: {
+			Keys: bson.D{{Key: "title", Value: "text"}, {Key: "text", Value: "text"}},
+			Options: options.Index().
+				SetName("articles_text_index").
+				SetDefaultLanguage(language).
+				SetWeights(bson.D{{Key: "title", Value: 10}, {Key: "text", Value: 1}}),
+		},
+		ProductCollection: {
+			Keys: bson.D{{Key: "title", Value: "text"}, {Key: "shortText", Value: "text"}, {Key: "text", Value: "text"}},
+			Options: options.Index().
+				SetName("products_text_index").
+				SetDefaultLanguage(language).
+				SetWeights(bson.D{{Key: "title", Value: 10}, {Key: "shortText", Value: 5}, {Key: "text", Value: 1}}),
+		},
+	}
+
+	for collection, indexModel := range indexes {
+		indexView := db.Collection(collection).Indexes()
+		_, err := indexView.CreateOne(ctx, indexModel)
+		if err != nil {
+			// Мешает существующий text индекс с другими параметрами - пересоздаём
+			name, findErr := findTextIndex(ctx, indexView)
+			if findErr == nil && name != "" {
+				log.Warn("Replacing text index", zap.String("collection", collection), zap.String("index", name), zap.Error(err))
+				if _, err = indexView.DropOne(ctx, name); err == nil {
+					_, err = indexView.CreateOne(ctx, indexModel)
+				}
+			}
+		}
+
+		if err != nil {
+			log.Fatal("Failed to create text index", zap.String("collection", collection), zap.Error(err))
+		} else {
+			log.Info("Text index created successfully.", zap.String("collection", collection))
+		}
+	}
+}
+
+// findTextIndex returns the name of the collection's text index, or "" if there is none.
+func findTextIndex(ctx context.Context, indexView mongo.IndexView) (string, error) {
+	cursor, err := indexView.List(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer cursor.Close(ctx)
+
+	var indexes []struct {
+		Name string `bson:"name"`
+		Key  bson.M `bson:"key"`
+	}
+	if err := cursor.All(ctx, &indexes); err != nil {
+		return "", err
+	}
+
+	for _, index := range indexes {
+		// Text индекс хранит ключ как {_fts: "text", _ftsx: 1}
+		if index.Key["_fts"] == "text" {
+			return index.Name, nil
+		}
+	}
+	return "", nil
+}
+
+// ensureKeysetIndexes creates the (date, _id) and (publishAt, _id) indexes used by cursor pagination.
+func ensureKeysetIndexes(ctx context.Context) {
+	db := GetClient().Database(env.GetEnv("MONGO_DB_NAME", "default_db"))
+
+	indexModel := mongo.IndexModel{
+		Keys:    bson.D{{Key: "date", Value: -1}, {Key: "_id", Value: -1}},
+		Options: options.Index().SetName("date_id_index"),
+	}
+
+	for _, collection := range []string{ArticleCollection, ProductCollection} {
+		if _, err := db.Collection(collection).Indexes().CreateOne(ctx, indexModel); err != nil {
+			log.Fatal("Failed to create keyset index", zap.String("collection", collection), zap.Error(err))
+		} else {
+			log.Info("Keyset index created successfully.", zap.String("collection", collection))
+		}
+	}
+
+	// The public article list is ordered by publishAt
+	publishedIndex := mongo.IndexModel{
+		Keys:    bson.D{{Key: "publishAt", Value: -1}, {Key: "_id", Value: -1}},
+		Options: options.Index().SetName("publish_at_id_index"),
+	}
+
+	if _, err := db.Collection(ArticleCollection).Indexes().CreateOne(ctx, publishedIndex); err != nil {
+		log.Fatal("Failed to create published keyset index", zap.Error(err))
+	} else {
+		log.Info("Published keyset index created successfully.")
+	}
+}
+
+// backfillPublishAt sets publishAt to the creation date on articles created before publishing existed,
+// so the public list can sort on publishAt alone. Published articles always get publishAt on save.
+func backfillPublishAt(ctx context.Context) {
+	collection := GetClient().Database(env.GetEnv("MONGO_DB_NAME", "default_db")).Collection(ArticleCollection)
+
+	result, err := collection.UpdateMany(ctx,
+		// Drafts keep no publishAt: it is set when they are published
+		bson.M{"status": bson.M{"$exists": false}, "publishAt": bson.M{"$exists": false}},
+		mongo.Pipeline{{{Key: "$set", Value: bson.M{"publishAt": "$date"}}}},
+	)
+	if err != nil {
+		log.Fatal("Failed to backfill article publishAt", zap.Error(err))
+	} else if result.ModifiedCount > 0 {
+		log.Info("Article publishAt backfilled.", zap.Int64("articles", result.ModifiedCount))
+	}
+}
+
+// ensureRevisionIndexes creates the unique (entityType, entityId, version) index of the revision history.
+func ensureRevisionIndexes(ctx context.Context) {
+	collection := GetClient().Database(env.GetEnv("MONGO_DB_NAME", "default_db")).Collection(RevisionCollection)
+
+	indexModel := mongo.IndexModel{
+		Keys: bson.D{{Key: "entityType", Value: 1}, {Key: "entityId", Value: 1}, {Key: "version", Value: -1}},
+		Options: options.Index().
+			SetUnique(true).
+			SetName("entity_version_unique_index"),
+	}
+
+	if _, err := collection.Indexes().CreateOne(ctx, indexModel); err != nil {
+		log.Fatal("Failed to create revision index", zap.Error(err))
+	} else {
+		log.Info("Revision index created successfully.")
+	}
+}
+
+// ensureSlugIndexes creates unique indexes on current and old slugs of articles and projects.
+// Documents created before slugs existed are left out by the partial filter.
+func ensureSlugIndexes(ctx context.Context) {
+	db := GetClient().Database(env.GetEnv("MONGO_DB_NAME", "default_db"))
+
+	indexModels := []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "slug", Value: 1}},
+			Options: options.Index().
+				SetUnique(true).
+				SetName("unique_slug_index").
+				SetPartialFilterExpression(bson.M{"slug": bson.M{"$type": "string"}}),
+		},
+		{
+			Keys: bson.D{{Key: "oldSlugs", Value: 1}},
+			Options: options.Index().
+				SetUnique(true).
+				SetName("unique_old_slugs_index").
+				SetPartialFilterExpression(bson.M{"oldSlugs": bson.M{"$type": "string"}}),
+		},
+	}
+
+	for _, collection := range []string{ArticleCollection, ProductCollection} {
+		if _, err := db.Collection(collection).Indexes().CreateMany(ctx, indexModels); err != nil {
+			log.Fatal("Failed to create slug indexes", zap.String("collection", collection), zap.Error(err))
+		} else {
+			log.Info("Slug indexes created successfully.", zap.String("collection", collection))
+		}
+	}
+}
+
+// ensureTaxonomyIndexes creates the unique (kind, slug) index of terms and the
+// multikey indexes used to filter articles and projects by tags and categories.
+func ensureTaxonomyIndexes(ctx context.Context) {
+	db := GetClient().Database(env.GetEnv("MONGO_DB_NAME", "default_db"))
+
+	termIndex := mongo.IndexModel{
+		Keys: bson.D{{Key: "kind", Value: 1}, {Key: "slug", Value: 1}},
+		Options: options.Index().
+			SetUnique(true).
+			SetName("kind_slug_unique_index"),
+	}
+
+	if _, err := db.Collection(TaxonomyCollection).Indexes().CreateOne(ctx, termIndex); err != nil {
+		log.Fatal("Failed to create taxonomy index", zap.Error(err))
+	} else {
+		log.Info("Taxonomy index created successfully.")
+	}
+
+	referenceIndexes := []mongo.IndexModel{
+		{Keys: bson.D{{Key: "tags", Value: 1}}, Options: options.Index().SetName("tags_index")},
+		{Keys: bson.D{{Key: "categories", Value: 1}}, Options: options.Index().SetName("categories_index")},
+	}
+
+	for _, collection := range []string{ArticleCollection, ProductCollection} {
+		if _, err := db.Collection(collection).Indexes().CreateMany(ctx, referenceIndexes); err != nil {
+			log.Fatal("Failed to create taxonomy reference indexes", zap.String("collection", collection), zap.Error(err))
+		} else {
+			log.Info("Taxonomy reference indexes created successfully.", zap.String("collection", collection))
+		}
+	}
+}
+
+// ensureRefreshTokenIndexes creates the unique token hash index, family and user lookup indexes,
+// and a TTL index that removes refresh tokens once they expire.
+func ensureRefreshTokenIndexes(ctx context.Context) {
+	collection := GetClient().Database(env.GetEnv("MONGO_DB_NAME", "default_db")).Collection(RefreshTokenCollection)
+
+	indexModels := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "tokenHash", Value: 1}},
+			Options: options.Index().SetUnique(true).SetName("token_hash_unique_index"),
+		},
+		{Keys: bson.D{{Key: "familyId", Value: 1}}, Options: options.Index().SetName("family_index")},
+		{Keys: bson.D{{Key: "userId", Value: 1}}, Options: options.Index().SetName("user_index")},
+		{
+			Keys:    bson.D{{Key: "expiresAt", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0).SetName("expires_at_ttl_index"),
+		},
+	}
+
+	if _, err := collection.Indexes().CreateMany(ctx, indexModels); err != nil {
+		log.Fatal("Failed to create refresh token indexes", zap.Error(err))
+	} else {
+		log.Info("Refresh token indexes created successfully.")
+	}
+}
+
+// ensurePasswordResetIndexes creates the unique token hash index, the user lookup index,
+// and a TTL index that removes reset tokens once they expire.
+func ensurePasswordResetIndexes(ctx context.Context) {
+	collection := GetClient().Database(env.GetEnv("MONGO_DB_NAME", "default_db")).Collection(PasswordResetCollection)
+
+	indexModels := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "tokenHash", Value: 1}},
+			Options: options.Index().SetUnique(true).SetName("token_hash_unique_index"),
+		},
+		{Keys: bson.D{{Key: "userId", Value: 1}}, Options: options.Index().SetName("user_index")},
+		{
+			Keys:    bson.D{{Key: "expiresAt", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0).SetName("expires_at_ttl_index"),
+		},
+	}
+
+	if _, err := collection.Indexes().CreateMany(ctx, indexModels); err != nil {
+		log.Fatal("Failed to create password reset indexes", zap.Error(err))
+	} else {
+		log.Info("Password reset indexes created successfully.")
+	}
+}
+
+// ensureLeadIndexes creates indexes for the lead inbox: newest first, optionally by status or assignee,
+// and lookup by the reference number given to the visitor.
+func ensureLeadIndexes(ctx context.Context) {
+	collection := GetClient().Database(env.GetEnv("MONGO_DB_NAME", "default_db")).Collection(LeadCollection)
+
+	indexModels := []mongo.IndexModel{
+		{Keys: bson.D{{Key: "createdAt", Value: -1}}, Options: options.Index().SetName("created_at_index")},
+		{
+			Keys:    bson.D{{Key: "status", Value: 1}, {Key: "createdAt", Value: -1}},
+			Options: options.Index().SetName("status_created_at_index"),
+		},
+		{
+			Keys:    bson.D{{Key: "assigneeId", Value: 1}, {Key: "createdAt", Value: -1}},
+			Options: options.Index().SetName("assignee_created_at_index"),
+		},
+		{
+			// Обращения до появления номеров его не имеют
+			Keys:    bson.D{{Key: "reference", Value: 1}},
+			Options: options.Index().SetName("reference_index").SetUnique(true).SetSparse(true),
+		},
+	}
+
+	if _, err := collection.Indexes().CreateMany(ctx, indexModels); err != nil {
+		log.Fatal("Failed to create lead indexes", zap.Error(err))
+	} else {
+		log.Info("Lead indexes created successfully.")
+	}
+}
+
+// ensureOutboxIndexes creates indexes for picking due messages and listing the queue in the admin API,
+// and a TTL index that removes sent messages once their retention period ends.
+func ensureOutboxIndexes(ctx context.Context) {
+	collection := GetClient().Database(env.GetEnv("MONGO_DB_NAME", "default_db")).Collection(OutboxCollection)
+
+	indexModels := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "status", Value: 1}, {Key: "nextAttemptAt", Value: 1}},
+			Options: options.Index().SetName("status_next_attempt_index"),
+		},
+		{
+			Keys:    bson.D{{Key: "status", Value: 1}, {Key: "createdAt", Value: -1}},
+			Options: options.Index().SetName("status_created_at_index"),
+		},
+		{Keys: bson.D{{Key: "createdAt", Value: -1}}, Options: options.Index().SetName("created_at_index")},
+		{
+			Keys:    bson.D{{Key: "expiresAt", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0).SetName("expires_at_ttl_index"),
+		},
+	}
+
+	if _, err := collection.Indexes().CreateMany(ctx, indexModels); err != nil {
+		log.Fatal("Failed to create outbox indexes", zap.Error(err))
+	} else {
+		log.Info("Outbox indexes created successfully.")
+	}
+}
@@ -1,13 +1,31 @@
 package dto
 
+import "time"
+
 type CreateArticleRequest struct {
-	Title string  `json:"title" validate:"required,min=3"`             // The title of the article, required and must be at least 3 characters long
-	Text  string  `json:"text" validate:"required,min=10"`             // The content of the article, required and must be at least 10 characters long
-	Img   *string `json:"img" validate:"omitempty,img_base64_or_null"` // The image URL, optional, can be null or a valid Base64 string
+	Title      string     `json:"title" form:"title" validate:"required,min=3"`                                       // The title of the article, required and must be at least 3 characters long
+	Text       string     `json:"text" form:"text" validate:"required,min=10"`                                        // The content of the article, required and must be at least 10 characters long
+	Locale     *string    `json:"locale" form:"locale" validate:"omitempty,locale"`                                   // Language of title and text, CONTENT_DEFAULT_LOCALE when omitted
+	Slug       *string    `json:"slug" form:"slug" validate:"omitempty,slug"`                                         // URL slug, generated from the title when omitted
+	Tags       []string   `json:"tags" form:"tags" validate:"omitempty,dive,mongodb"`                                 // Tag IDs
+	Categories []string   `json:"categories" form:"categories" validate:"omitempty,dive,mongodb"`                     // Category IDs
+	Img        *string    `json:"img" form:"img" validate:"omitempty,img"`                                            // The image URL, optional, can be null or a valid Base64 string
+	Status     *string    `json:"status" form:"status" validate:"omitempty,oneof=draft scheduled published archived"` // Publication status, published by default
+	PublishAt  *time.Time `json:"publishAt" form:"-"`                                                                 // Publication time, required for scheduled articles; RFC 3339 string in multipart
 }
 
 type PatchArticleRequest struct {
-	Title *string `json:"title" validate:"omitempty,min=3"`            // Заголовок статьи, опционально, минимум 3 символа
-	Text  *string `json:"text" validate:"omitempty,min=10"`            // Текст статьи, опционально, минимум 10 символов
-	Img   *string `json:"img" validate:"omitempty,img_base64_or_null"` // URL изображения, опционально, null или строка Base64
+	Title      *string    `json:"title" form:"title" validate:"omitempty,min=3"`                                      // Заголовок статьи, опционально, минимум 3 символа
+	Text       *string    `json:"text" form:"text" validate:"omitempty,min=10"`                                       // Текст статьи, опционально, минимум 10 символов
+	Locale     *string    `json:"locale" form:"locale" validate:"omitempty,locale"`                                   // Язык title/text; отличный от языка оригинала пишется в перевод
+	Slug       *string    `json:"slug" form:"slug" validate:"omitempty,slug"`                                         // Новый slug, прежний остаётся редиректом
+	Tags       *[]string  `json:"tags" form:"tags" validate:"omitempty,dive,mongodb"`                                 // ID тегов, заменяют текущие целиком
+	Categories *[]string  `json:"categories" form:"categories" validate:"omitempty,dive,mongodb"`                     // ID категорий, заменяют текущие целиком
+	Img        *string    `json:"img" form:"img" validate:"omitempty,img"`                                            // URL изображения, опционально, null или строка Base64
+	Status     *string    `json:"status" form:"status" validate:"omitempty,oneof=draft scheduled published archived"` // Статус публикации, опционально
+	PublishAt  *time.Time `json:"publishAt" form:"-"`                                                                 // Время публикации, обязательно для scheduled; в multipart - строка RFC 3339
+}
+
+type PublishArticleRequest struct {
+	PublishAt *time.Time `json:"publishAt"` // Время публикации, в будущем - статья будет запланирована
 }
@@ -5,4 +5,6 @@ type SendEmailRequest struct {
 	Name  string `json:"name" validate:"required,min=2"`
 	Phone string `json:"phone" validate:"required,min=5"`
 	Text  string `json:"text" validate:"required,min=5,max=500"`
+	// Language of the auto-reply; Accept-Language when omitted
+	Locale string `json:"locale" validate:"omitempty,locale"`
 }
@@ -0,0 +1,11 @@
+package dto
+
+// PatchLeadRequest - изменяются только переданные поля.
+type PatchLeadRequest struct {
+	Status     *string `json:"status" validate:"omitempty,lead_status"`
+	AssigneeID *string `json:"assigneeId" validate:"omitempty,len=0|mongodb"` // пустая строка снимает назначение
+}
+
+type AddLeadNoteRequest struct {
+	Text string `json:"text" validate:"required,max=2000"`
+}
@@ -4,3 +4,32 @@ type LoginRequest struct {
 	Email    string `json:"email" validate:"required,custom_email"`
 	Password string `json:"password" validate:"required,min=3"`
 }
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refreshToken" validate:"required"`
+}
+
+type LogoutRequest struct {
+	RefreshToken string `json:"refreshToken" validate:"required"`
+	AllSessions  bool   `json:"allSessions"` // выйти на всех устройствах
+}
+
+// TwoFactorCodeRequest - код из приложения-аутентификатора или код восстановления.
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" validate:"required,max=32"`
+}
+
+type LoginTwoFactorRequest struct {
+	ChallengeToken string `json:"challengeToken" validate:"required"`
+	Code           string `json:"code" validate:"required,max=32"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,custom_email"`
+}
+
+// ResetPasswordRequest - токен из письма и новый пароль; bcrypt учитывает только первые 72 байта.
+type ResetPasswordRequest struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required,min=8,max=72"`
+}
@@ -1,15 +1,23 @@
 package dto
 
 type CreateProductRequest struct {
-	Title     string  `json:"title" validate:"required,min=3"`
-	Text      string  `json:"text" validate:"required,min=10,max=20000"`
-	ShortText string  `json:"shortText" validate:"required,min=10,max=10000"`
-	Img       *string `json:"img" validate:"omitempty,img_base64_or_null"`
+	Title      string   `json:"title" form:"title" validate:"required,min=3"`
+	Text       string   `json:"text" form:"text" validate:"required,min=10,max=20000"`
+	ShortText  string   `json:"shortText" form:"shortText" validate:"required,min=10,max=10000"`
+	Locale     *string  `json:"locale" form:"locale" validate:"omitempty,locale"`
+	Slug       *string  `json:"slug" form:"slug" validate:"omitempty,slug"`
+	Tags       []string `json:"tags" form:"tags" validate:"omitempty,dive,mongodb"`
+	Categories []string `json:"categories" form:"categories" validate:"omitempty,dive,mongodb"`
+	Img        *string  `json:"img" form:"img" validate:"omitempty,img"`
 }
 
 type PatchProductRequest struct {
-	Title     *string `json:"title" validate:"omitempty,min=3"`
-	Text      *string `json:"text" validate:"omitempty,min=10,max=20000"`
-	ShortText *string `json:"shortText" validate:"omitempty,min=10,max=10000"`
-	Img       *string `json:"img" validate:"omitempty,img_base64_or_null"`
+	Title      *string   `json:"title" form:"title" validate:"omitempty,min=3"`
+	Text       *string   `json:"text" form:"text" validate:"omitempty,min=10,max=20000"`
+	ShortText  *string   `json:"shortText" form:"shortText" validate:"omitempty,min=10,max=10000"`
+	Locale     *string   `json:"locale" form:"locale" validate:"omitempty,locale"`
+	Slug       *string   `json:"slug" form:"slug" validate:"omitempty,slug"`
+	Tags       *[]string `json:"tags" form:"tags" validate:"omitempty,dive,mongodb"`
+	Categories *[]string `json:"categories" form:"categories" validate:"omitempty,dive,mongodb"`
+	Img        *string   `json:"img" form:"img" validate:"omitempty,img"`
 }
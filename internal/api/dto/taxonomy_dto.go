@@ -0,0 +1,13 @@
+package dto
+
+type CreateTermRequest struct {
+	Name     string  `json:"name" validate:"required,min=1,max=100"`
+	Slug     *string `json:"slug" validate:"omitempty,slug"`        // Генерируется из имени, если не задан
+	ParentID *string `json:"parentId" validate:"omitempty,mongodb"` // Только для категорий
+}
+
+type PatchTermRequest struct {
+	Name     *string `json:"name" validate:"omitempty,min=1,max=100"`
+	Slug     *string `json:"slug" validate:"omitempty,slug"`
+	ParentID *string `json:"parentId" validate:"omitempty,len=0|mongodb"` // Пустая строка переносит категорию в корень
+}
@@ -1,7 +1,23 @@
 package dto
 
 type CreateUserRequest struct {
-	Email    string `json:"email" validate:"required,custom_email"`
-	Phone    string `json:"phone" validate:"required,min=5"`
-	Password string `json:"password" validate:"required,min=3"`
+	Email       string `json:"email" validate:"required,custom_email"`
+	Phone       string `json:"phone" validate:"required,min=5"`
+	Password    string `json:"password" validate:"required,min=3"`
+	Role        string `json:"role" validate:"omitempty,role"` // по умолчанию viewer
+	DisplayName string `json:"displayName" validate:"omitempty,max=100"`
+}
+
+// PatchUserRequest - изменяются только переданные поля; пустые displayName и avatar их удаляют.
+type PatchUserRequest struct {
+	Email       *string `json:"email" validate:"omitempty,custom_email"`
+	Phone       *string `json:"phone" validate:"omitempty,min=5"`
+	Role        *string `json:"role" validate:"omitempty,role"`
+	DisplayName *string `json:"displayName" validate:"omitempty,max=100"`
+	Avatar      *string `json:"avatar" validate:"omitempty,img"`
+}
+
+type ChangePasswordRequest struct {
+	OldPassword string `json:"oldPassword" validate:"required"`
+	NewPassword string `json:"newPassword" validate:"required,min=8,max=72"`
 }
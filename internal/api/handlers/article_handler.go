@@ -1,23 +1,32 @@
 package handlers
 
 import (
+	"context"
 	"edjr-trk/internal/api/dto"
+	"edjr-trk/internal/api/middlewares/auth"
+	"edjr-trk/internal/model"
 	"edjr-trk/internal/service"
 	"edjr-trk/pkg/http_error"
+	"edjr-trk/pkg/utils"
+	"errors"
 	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/mongo"
 	"go.uber.org/zap"
+	"strings"
 )
 
 type ArticleHandler struct {
-	service *service.ArticleService
-	logger  *zap.Logger
+	service  *service.ArticleService
+	taxonomy service.TaxonomyServiceInterface
+	logger   *zap.Logger
 }
 
 // NewArticleHandler creates a new instance of ArticleHandler.
-func NewArticleHandler(service *service.ArticleService, logger *zap.Logger) *ArticleHandler {
+func NewArticleHandler(service *service.ArticleService, taxonomy service.TaxonomyServiceInterface, logger *zap.Logger) *ArticleHandler {
 	return &ArticleHandler{
-		service: service,
-		logger:  logger,
+		service:  service,
+		taxonomy: taxonomy,
+		logger:   logger,
 	}
 }
 
@@ -34,19 +43,41 @@ func (h *ArticleHandler) CreateArticle(c *fiber.Ctx) error {
 	}
 
 	// Create a new article via the service.
-	article, err := h.service.CreateArticle(c.Context(), req)
+	authorID, _ := auth.GetUserId(c)
+	article, err := h.service.CreateArticle(c.Context(), req, authorID)
+	if errors.Is(err, service.ErrInvalidPublishState) {
+		return http_error.NewHTTPError(fiber.StatusBadRequest, err.Error(), nil).Send(c)
+	}
+	if errors.Is(err, service.ErrSlugTaken) {
+		return http_error.NewHTTPError(fiber.StatusConflict, "Slug is already taken", nil).Send(c)
+	}
+	if errors.Is(err, service.ErrUnknownTerm) {
+		return http_error.NewHTTPError(fiber.StatusBadRequest, err.Error(), nil).Send(c)
+	}
 	if err != nil {
 		h.logger.Error("Failed to create article", zap.Error(err))
 		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Failed to create article", nil).Send(c)
 	}
 
+	article.Localize(writtenLocalePreference(c, req.Locale))
 	return c.Status(fiber.StatusCreated).JSON(article)
 }
 
-// GetAllArticles handles fetching all articles with pagination.
+// GetAllArticles handles fetching published articles with pagination.
 func (h *ArticleHandler) GetAllArticles(c *fiber.Ctx) error {
 	h.logger.Info("GetAllArticles")
+	return h.listArticles(c, model.ArticleFilter{PublishedOnly: true})
+}
+
+// GetAdminArticles handles fetching articles in any status, optionally filtered by ?status=.
+func (h *ArticleHandler) GetAdminArticles(c *fiber.Ctx) error {
+	h.logger.Info("GetAdminArticles")
+	status, _ := c.Locals("articleStatus").(string)
+	return h.listArticles(c, model.ArticleFilter{Status: status})
+}
 
+// listArticles - общий обработчик списков статей для публичного и административного API.
+func (h *ArticleHandler) listArticles(c *fiber.Ctx, filter model.ArticleFilter) error {
 	// Retrieve pagination parameters from context.
 	pageNumberInterface := c.Locals("pageNumber")
 	pageSizeInterface := c.Locals("pageSize")
@@ -63,8 +94,41 @@ func (h *ArticleHandler) GetAllArticles(c *fiber.Ctx) error {
 		pageSize = 10
 	}
 
-	// Fetch articles via the service.
-	articles, err := h.service.GetAllArticles(c.Context(), pageNumber, pageSize)
+	// Narrow the listing by ?tag= and ?category= slugs.
+	taxonomy, err := taxonomyFilter(c, h.taxonomy)
+	if err != nil {
+		h.logger.Error("Failed to resolve taxonomy filter", zap.Error(err))
+		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Failed to fetch articles", nil).Send(c)
+	}
+	filter.TaxonomyFilter = taxonomy
+	// Counters for the sidebar are computed only on request (?facets=1).
+	filter.WithFacets = c.QueryBool("facets")
+
+	// Fetch articles via the service, searching when a query is given.
+	query, _ := c.Locals("searchQuery").(string)
+
+	// Cursor mode returns a different envelope.
+	if cursorMode, _ := c.Locals("cursorMode").(bool); cursorMode {
+		if query != "" {
+			return http_error.NewHTTPError(fiber.StatusBadRequest, "Cursor pagination is not supported for search", nil).Send(c)
+		}
+
+		cursor, _ := c.Locals("pageCursor").(*utils.PageCursor)
+		page, err := h.service.GetArticlesByCursor(c.Context(), filter, cursor, pageSize)
+		if err != nil {
+			h.logger.Error("Failed to fetch articles by cursor", zap.Error(err))
+			return http_error.NewHTTPError(fiber.StatusInternalServerError, "Failed to fetch articles", nil).Send(c)
+		}
+		localizeAll(page.Items, localePreference(c))
+		return c.Status(fiber.StatusOK).JSON(page)
+	}
+
+	var articles *model.Paginate[*model.ArticleResponse]
+	if query != "" {
+		articles, err = h.service.SearchArticles(c.Context(), filter, query, pageNumber, pageSize)
+	} else {
+		articles, err = h.service.GetAllArticles(c.Context(), filter, pageNumber, pageSize)
+	}
 	if err != nil {
 		h.logger.Error("Failed to fetch paginated articles", zap.Error(err))
 		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Failed to fetch articles", nil).Send(c)
@@ -76,12 +140,24 @@ func (h *ArticleHandler) GetAllArticles(c *fiber.Ctx) error {
 		zap.Int("fetchedItems", len(articles.Items)),
 	)
 
+	localizeAll(articles.Items, localePreference(c))
+
 	return c.Status(fiber.StatusOK).JSON(articles)
 }
 
-// GetArticleById handles fetching a single article by its ID.
+// GetArticleById handles fetching a single published article by its ID.
 func (h *ArticleHandler) GetArticleById(c *fiber.Ctx) error {
 	h.logger.Info("Received request to fetch an article by ID")
+	return h.getArticle(c, h.service.GetPublishedArticleById)
+}
+
+// GetAdminArticleById handles fetching a single article by its ID regardless of status.
+func (h *ArticleHandler) GetAdminArticleById(c *fiber.Ctx) error {
+	h.logger.Info("Received request to fetch an article by ID (admin)")
+	return h.getArticle(c, h.service.GetArticleById)
+}
+
+func (h *ArticleHandler) getArticle(c *fiber.Ctx, fetch func(ctx context.Context, id string) (*model.ArticleResponse, error)) error {
 
 	// Retrieve article ID from context.
 	articleIDInterface := c.Locals("articleID")
@@ -92,7 +168,10 @@ func (h *ArticleHandler) GetArticleById(c *fiber.Ctx) error {
 	}
 
 	// Fetch the article via the service.
-	article, err := h.service.GetArticleById(c.Context(), articleID)
+	article, err := fetch(c.Context(), articleID)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return http_error.NewHTTPError(fiber.StatusNotFound, "Article not found", nil).Send(c)
+	}
 	if err != nil {
 		h.logger.Error("Failed to fetch article by ID", zap.String("articleID", articleID), zap.Error(err))
 		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Failed to fetch article", nil).Send(c)
@@ -103,6 +182,7 @@ func (h *ArticleHandler) GetArticleById(c *fiber.Ctx) error {
 	}
 
 	h.logger.Info("Article fetched successfully", zap.String("articleID", articleID))
+	article.Localize(localePreference(c))
 	return c.Status(fiber.StatusOK).JSON(article)
 }
 
@@ -120,6 +200,9 @@ func (h *ArticleHandler) RemoveArticleById(c *fiber.Ctx) error {
 
 	// Remove the article via the service.
 	removedArticle, err := h.service.RemoveArticleById(c.Context(), articleID)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return http_error.NewHTTPError(fiber.StatusNotFound, "Article not found", nil).Send(c)
+	}
 	if err != nil {
 		h.logger.Error("Failed to remove article", zap.String("articleID", articleID), zap.Error(err))
 		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Failed to remove article", nil).Send(c)
@@ -150,12 +233,279 @@ func (h *ArticleHandler) PatchArticleById(c *fiber.Ctx) error {
 	}
 
 	// Update the article via the service.
-	updatedArticle, err := h.service.PatchArticleById(c.Context(), req, articleID)
+	authorID, _ := auth.GetUserId(c)
+	updatedArticle, err := h.service.PatchArticleById(c.Context(), req, articleID, authorID)
+	if errors.Is(err, service.ErrInvalidPublishState) {
+		return http_error.NewHTTPError(fiber.StatusBadRequest, err.Error(), nil).Send(c)
+	}
+	if errors.Is(err, service.ErrSlugTaken) {
+		return http_error.NewHTTPError(fiber.StatusConflict, "Slug is already taken", nil).Send(c)
+	}
+	if errors.Is(err, service.ErrUnknownTerm) {
+		return http_error.NewHTTPError(fiber.StatusBadRequest, err.Error(), nil).Send(c)
+	}
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return http_error.NewHTTPError(fiber.StatusNotFound, "Article not found", nil).Send(c)
+	}
 	if err != nil {
 		h.logger.Error("Failed to update article", zap.String("articleID", articleID), zap.Error(err))
 		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Failed to update article", nil).Send(c)
 	}
 
 	h.logger.Info("Article updated successfully", zap.String("articleID", articleID))
+	updatedArticle.Localize(writtenLocalePreference(c, req.Locale))
 	return c.Status(fiber.StatusOK).JSON(updatedArticle)
 }
+
+// PublishArticle handles publishing an article now or scheduling it for publishAt.
+func (h *ArticleHandler) PublishArticle(c *fiber.Ctx) error {
+	h.logger.Info("Received request to publish an article")
+
+	articleID, ok := c.Locals("articleID").(string)
+	if !ok || articleID == "" {
+		h.logger.Error("Article ID is missing in the context")
+		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Article ID is required", nil).Send(c)
+	}
+
+	// Тело необязательно: без publishAt статья публикуется сразу.
+	var req dto.PublishArticleRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			h.logger.Warn("Failed to parse publish request", zap.Error(err))
+			return http_error.NewHTTPError(fiber.StatusBadRequest, "Invalid request body", nil).Send(c)
+		}
+	}
+
+	authorID, _ := auth.GetUserId(c)
+	article, err := h.service.PublishArticle(c.Context(), articleID, req.PublishAt, authorID)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return http_error.NewHTTPError(fiber.StatusNotFound, "Article not found", nil).Send(c)
+	}
+	if err != nil {
+		h.logger.Error("Failed to publish article", zap.String("articleID", articleID), zap.Error(err))
+		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Failed to publish article", nil).Send(c)
+	}
+
+	h.logger.Info("Article published successfully", zap.String("articleID", articleID), zap.String("status", article.Status))
+	article.Localize(localePreference(c))
+	return c.Status(fiber.StatusOK).JSON(article)
+}
+
+// UnpublishArticle handles moving an article back to drafts.
+func (h *ArticleHandler) UnpublishArticle(c *fiber.Ctx) error {
+	h.logger.Info("Received request to unpublish an article")
+
+	articleID, ok := c.Locals("articleID").(string)
+	if !ok || articleID == "" {
+		h.logger.Error("Article ID is missing in the context")
+		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Article ID is required", nil).Send(c)
+	}
+
+	authorID, _ := auth.GetUserId(c)
+	article, err := h.service.UnpublishArticle(c.Context(), articleID, authorID)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return http_error.NewHTTPError(fiber.StatusNotFound, "Article not found", nil).Send(c)
+	}
+	if err != nil {
+		h.logger.Error("Failed to unpublish article", zap.String("articleID", articleID), zap.Error(err))
+		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Failed to unpublish article", nil).Send(c)
+	}
+
+	h.logger.Info("Article unpublished successfully", zap.String("articleID", articleID))
+	article.Localize(localePreference(c))
+	return c.Status(fiber.StatusOK).JSON(article)
+}
+
+// GetArticleRevisions handles listing the revision history of an article.
+func (h *ArticleHandler) GetArticleRevisions(c *fiber.Ctx) error {
+	articleID, ok := c.Locals("articleID").(string)
+	if !ok || articleID == "" {
+		h.logger.Error("Article ID is missing in the context")
+		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Article ID is required", nil).Send(c)
+	}
+
+	pageNumber, ok := c.Locals("pageNumber").(int)
+	if !ok {
+		pageNumber = 1
+	}
+	pageSize, ok := c.Locals("pageSize").(int)
+	if !ok {
+		pageSize = 10
+	}
+
+	revisions, err := h.service.GetArticleRevisions(c.Context(), articleID, pageNumber, pageSize)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return http_error.NewHTTPError(fiber.StatusNotFound, "Article not found", nil).Send(c)
+	}
+	if err != nil {
+		h.logger.Error("Failed to fetch article revisions", zap.String("articleID", articleID), zap.Error(err))
+		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Failed to fetch revisions", nil).Send(c)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(revisions)
+}
+
+// GetArticleRevision handles fetching a single revision with its snapshot.
+func (h *ArticleHandler) GetArticleRevision(c *fiber.Ctx) error {
+	articleID, _ := c.Locals("articleID").(string)
+	revisionID, _ := c.Locals("revisionID").(string)
+
+	revision, err := h.service.GetArticleRevision(c.Context(), articleID, revisionID)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return http_error.NewHTTPError(fiber.StatusNotFound, "Revision not found", nil).Send(c)
+	}
+	if err != nil {
+		h.logger.Error("Failed to fetch article revision", zap.String("revisionID", revisionID), zap.Error(err))
+		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Failed to fetch revision", nil).Send(c)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(revision)
+}
+
+// DiffArticleRevisions handles comparing two revisions given as ?from=&to=.
+func (h *ArticleHandler) DiffArticleRevisions(c *fiber.Ctx) error {
+	articleID, _ := c.Locals("articleID").(string)
+	fromID, _ := c.Locals("revisionFrom").(string)
+	toID, _ := c.Locals("revisionTo").(string)
+
+	diff, err := h.service.DiffArticleRevisions(c.Context(), articleID, fromID, toID)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return http_error.NewHTTPError(fiber.StatusNotFound, "Revision not found", nil).Send(c)
+	}
+	if err != nil {
+		h.logger.Error("Failed to diff article revisions", zap.String("articleID", articleID), zap.Error(err))
+		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Failed to diff revisions", nil).Send(c)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(diff)
+}
+
+// RestoreArticleRevision handles making a revision the current version of an article.
+func (h *ArticleHandler) RestoreArticleRevision(c *fiber.Ctx) error {
+	h.logger.Info("Received request to restore an article revision")
+
+	articleID, _ := c.Locals("articleID").(string)
+	revisionID, _ := c.Locals("revisionID").(string)
+	authorID, _ := auth.GetUserId(c)
+
+	restored, err := h.service.RestoreArticleRevision(c.Context(), articleID, revisionID, authorID)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return http_error.NewHTTPError(fiber.StatusNotFound, "Revision not found", nil).Send(c)
+	}
+	if err != nil {
+		h.logger.Error("Failed to restore article revision", zap.String("revisionID", revisionID), zap.Error(err))
+		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Failed to restore revision", nil).Send(c)
+	}
+
+	h.logger.Info("Article restored successfully", zap.String("articleID", articleID), zap.String("revisionID", revisionID))
+	restored.Localize(localePreference(c))
+	return c.Status(fiber.StatusOK).JSON(restored)
+}
+
+// GetDeletedArticles handles listing articles in the trash.
+func (h *ArticleHandler) GetDeletedArticles(c *fiber.Ctx) error {
+	h.logger.Info("Received request to fetch deleted articles")
+
+	pageNumber, ok := c.Locals("pageNumber").(int)
+	if !ok {
+		pageNumber = 1
+	}
+	pageSize, ok := c.Locals("pageSize").(int)
+	if !ok {
+		pageSize = 10
+	}
+
+	items, err := h.service.GetDeletedArticles(c.Context(), pageNumber, pageSize)
+	if err != nil {
+		h.logger.Error("Failed to fetch deleted articles", zap.Error(err))
+		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Failed to fetch trash", nil).Send(c)
+	}
+
+	localizeAll(items.Items, localePreference(c))
+	return c.Status(fiber.StatusOK).JSON(items)
+}
+
+// RestoreArticleById handles moving a article out of the trash.
+func (h *ArticleHandler) RestoreArticleById(c *fiber.Ctx) error {
+	h.logger.Info("Received request to restore a article")
+
+	articleID, ok := c.Locals("articleID").(string)
+	if !ok || articleID == "" {
+		h.logger.Error("Article ID is missing in the context")
+		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Article ID is required", nil).Send(c)
+	}
+
+	restored, err := h.service.RestoreArticleById(c.Context(), articleID)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return http_error.NewHTTPError(fiber.StatusNotFound, "Article not found in trash", nil).Send(c)
+	}
+	if err != nil {
+		h.logger.Error("Failed to restore article", zap.String("articleID", articleID), zap.Error(err))
+		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Failed to restore article", nil).Send(c)
+	}
+
+	h.logger.Info("Article restored successfully", zap.String("articleID", articleID))
+	restored.Localize(localePreference(c))
+	return c.Status(fiber.StatusOK).JSON(restored)
+}
+
+// GetArticleBySlug handles fetching article by slug; old slugs answer with a 301 to the current one.
+func (h *ArticleHandler) GetArticleBySlug(c *fiber.Ctx) error {
+	slug, ok := c.Locals("slug").(string)
+	if !ok || slug == "" {
+		h.logger.Error("Slug is missing in the context")
+		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Slug is required", nil).Send(c)
+	}
+
+	article, err := h.service.GetPublishedArticleBySlug(c.Context(), slug)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return http_error.NewHTTPError(fiber.StatusNotFound, "Article not found", nil).Send(c)
+	}
+	if err != nil {
+		h.logger.Error("Failed to fetch article by slug", zap.String("slug", slug), zap.Error(err))
+		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Failed to fetch article", nil).Send(c)
+	}
+
+	// Запрошен прежний slug - отправляем на актуальный адрес
+	if article.Slug != slug {
+		c.Location(slugLocation(c, slug, article.Slug))
+		return c.Status(fiber.StatusMovedPermanently).JSON(fiber.Map{"slug": article.Slug})
+	}
+
+	article.Localize(localePreference(c))
+	return c.Status(fiber.StatusOK).JSON(article)
+}
+
+// RequireArticleAuthor ensures an author changes only their own articles.
+// Roles with the articles:edit_any permission may change any article.
+func (h *ArticleHandler) RequireArticleAuthor(c *fiber.Ctx) error {
+	if model.HasPermission(auth.GetUserRole(c), model.PermArticlesEditAny) {
+		return c.Next()
+	}
+
+	articleID, _ := c.Locals("articleID").(string)
+	userID, _ := auth.GetUserId(c)
+	isAuthor, err := h.service.IsArticleAuthor(c.Context(), articleID, userID)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return http_error.NewHTTPError(fiber.StatusNotFound, "Article not found", nil).Send(c)
+	}
+	if err != nil {
+		h.logger.Error("Failed to check article author", zap.String("articleID", articleID), zap.Error(err))
+		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Failed to check article author", nil).Send(c)
+	}
+	if !isAuthor {
+		h.logger.Warn("User is not the author of the article", zap.String("articleID", articleID), zap.String("userId", userID))
+		return http_error.NewHTTPError(fiber.StatusForbidden, "You can only change your own articles", nil).Send(c)
+	}
+
+	return c.Next()
+}
+
+// slugLocation - адрес с актуальным slug; строка запроса (например, ?lang=) сохраняется.
+func slugLocation(c *fiber.Ctx, oldSlug, newSlug string) string {
+	location := strings.TrimSuffix(c.Path(), oldSlug) + newSlug
+	if query := c.Request().URI().QueryString(); len(query) > 0 {
+		location += "?" + string(query)
+	}
+	return location
+}
@@ -2,26 +2,40 @@ package handlers
 
 import (
 	"edjr-trk/internal/api/dto"
+	"edjr-trk/internal/api/middlewares/auth"
 	"edjr-trk/internal/service"
 	"edjr-trk/pkg/http_error"
+	"edjr-trk/pkg/utils"
+	"errors"
 	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/mongo"
 	"go.uber.org/zap"
+	"math"
+	"strconv"
 )
 
 type authHandler struct {
-	service service.AuthServiceInterface
-	logger  *zap.Logger
+	service   service.AuthServiceInterface
+	twoFactor service.TwoFactorServiceInterface
+	logger    *zap.Logger
 }
 
 type AuthHandlerInterface interface {
 	Login(c *fiber.Ctx) error
+	Refresh(c *fiber.Ctx) error
+	Logout(c *fiber.Ctx) error
+	LoginTwoFactor(c *fiber.Ctx) error
+	SetupTwoFactor(c *fiber.Ctx) error
+	EnableTwoFactor(c *fiber.Ctx) error
+	DisableTwoFactor(c *fiber.Ctx) error
 }
 
 // NewAuthHandler creates a new instance of UserHandler.
-func NewAuthHandler(service service.AuthServiceInterface, logger *zap.Logger) AuthHandlerInterface {
+func NewAuthHandler(service service.AuthServiceInterface, twoFactor service.TwoFactorServiceInterface, logger *zap.Logger) AuthHandlerInterface {
 	return &authHandler{
-		service: service,
-		logger:  logger,
+		service:   service,
+		twoFactor: twoFactor,
+		logger:    logger,
 	}
 }
 
@@ -37,11 +51,149 @@ func (h *authHandler) Login(c *fiber.Ctx) error {
 		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Internal Server Error", nil).Send(c)
 	}
 
-	user, err := h.service.Login(c.Context(), body.Email, body.Password)
+	user, err := h.service.Login(c.Context(), body.Email, body.Password, utils.GetClientIP(c))
 	if err != nil {
-		h.logger.Error("Failed to create article", zap.Error(err))
-		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Failed to login user", nil).Send(c)
+		return h.sendLoginError(c, err, "Failed to login user")
 	}
 
 	return c.Status(fiber.StatusCreated).JSON(user)
 }
+
+// Refresh exchanges a refresh token for a new access and refresh token pair.
+func (h *authHandler) Refresh(c *fiber.Ctx) error {
+	h.logger.Info("Received request to refresh tokens")
+
+	body, ok := c.Locals("validatedBody").(dto.RefreshTokenRequest)
+	if !ok {
+		h.logger.Error("Failed to retrieve validated request from context")
+		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Internal Server Error", nil).Send(c)
+	}
+
+	tokens, err := h.service.Refresh(c.Context(), body.RefreshToken)
+	if err != nil {
+		return h.sendRefreshTokenError(c, err, "Failed to refresh tokens")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(tokens)
+}
+
+// Logout revokes the session of the given refresh token, or every session of its user.
+func (h *authHandler) Logout(c *fiber.Ctx) error {
+	h.logger.Info("Received request to logout the user")
+
+	body, ok := c.Locals("validatedBody").(dto.LogoutRequest)
+	if !ok {
+		h.logger.Error("Failed to retrieve validated request from context")
+		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Internal Server Error", nil).Send(c)
+	}
+
+	if err := h.service.Logout(c.Context(), body.RefreshToken, body.AllSessions); err != nil {
+		return h.sendRefreshTokenError(c, err, "Failed to logout user")
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func (h *authHandler) sendRefreshTokenError(c *fiber.Ctx, err error, message string) error {
+	if errors.Is(err, service.ErrInvalidRefreshToken) || errors.Is(err, service.ErrRefreshTokenReused) {
+		return http_error.NewHTTPError(fiber.StatusUnauthorized, err.Error(), nil).Send(c)
+	}
+
+	h.logger.Error(message, zap.Error(err))
+	return http_error.NewHTTPError(fiber.StatusInternalServerError, message, nil).Send(c)
+}
+
+// LoginTwoFactor completes a login started by Login when the user has 2FA enabled.
+func (h *authHandler) LoginTwoFactor(c *fiber.Ctx) error {
+	h.logger.Info("Received request to complete two-factor login")
+
+	body, ok := c.Locals("validatedBody").(dto.LoginTwoFactorRequest)
+	if !ok {
+		h.logger.Error("Failed to retrieve validated request from context")
+		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Internal Server Error", nil).Send(c)
+	}
+
+	tokens, err := h.service.LoginTwoFactor(c.Context(), body.ChallengeToken, body.Code, utils.GetClientIP(c))
+	if err != nil {
+		return h.sendLoginError(c, err, "Failed to login user")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(tokens)
+}
+
+// SetupTwoFactor starts 2FA enrollment for the current user and returns the otpauth URI.
+func (h *authHandler) SetupTwoFactor(c *fiber.Ctx) error {
+	userID, _ := auth.GetUserId(c)
+
+	setup, err := h.twoFactor.Setup(c.Context(), userID)
+	if err != nil {
+		return h.sendTwoFactorError(c, err, "Failed to start two-factor setup")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(setup)
+}
+
+// EnableTwoFactor confirms enrollment with the first code and returns the recovery codes.
+func (h *authHandler) EnableTwoFactor(c *fiber.Ctx) error {
+	userID, _ := auth.GetUserId(c)
+	body, ok := c.Locals("validatedBody").(dto.TwoFactorCodeRequest)
+	if !ok {
+		h.logger.Error("Failed to retrieve validated request from context")
+		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Internal Server Error", nil).Send(c)
+	}
+
+	codes, err := h.twoFactor.Enable(c.Context(), userID, body.Code)
+	if err != nil {
+		return h.sendTwoFactorError(c, err, "Failed to enable two-factor authentication")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(codes)
+}
+
+// DisableTwoFactor turns 2FA off after checking a current or recovery code.
+func (h *authHandler) DisableTwoFactor(c *fiber.Ctx) error {
+	userID, _ := auth.GetUserId(c)
+	body, ok := c.Locals("validatedBody").(dto.TwoFactorCodeRequest)
+	if !ok {
+		h.logger.Error("Failed to retrieve validated request from context")
+		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Internal Server Error", nil).Send(c)
+	}
+
+	if err := h.twoFactor.Disable(c.Context(), userID, body.Code); err != nil {
+		return h.sendTwoFactorError(c, err, "Failed to disable two-factor authentication")
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// sendLoginError - ответ на ошибку входа; при блокировке в Retry-After передаётся, сколько секунд ждать.
+func (h *authHandler) sendLoginError(c *fiber.Ctx, err error, message string) error {
+	var throttled *service.LoginThrottledError
+	switch {
+	case errors.As(err, &throttled):
+		c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(throttled.RetryAfter.Seconds()))))
+		return http_error.NewHTTPError(fiber.StatusTooManyRequests, "Too many failed login attempts, try again later", nil).Send(c)
+	case errors.Is(err, service.ErrInvalidCredentials):
+		return http_error.NewHTTPError(fiber.StatusUnauthorized, "Invalid email or password", nil).Send(c)
+	case errors.Is(err, service.ErrAccountDisabled):
+		return http_error.NewHTTPError(fiber.StatusForbidden, "Account is disabled", nil).Send(c)
+	}
+
+	return h.sendTwoFactorError(c, err, message)
+}
+
+// sendTwoFactorError - переводит ошибки 2FA в HTTP-ответ.
+func (h *authHandler) sendTwoFactorError(c *fiber.Ctx, err error, message string) error {
+	switch {
+	case errors.Is(err, service.ErrInvalidChallengeToken), errors.Is(err, service.ErrInvalidTwoFactorCode):
+		return http_error.NewHTTPError(fiber.StatusUnauthorized, err.Error(), nil).Send(c)
+	case errors.Is(err, service.ErrTwoFactorEnabled), errors.Is(err, service.ErrTwoFactorDisabled),
+		errors.Is(err, service.ErrTwoFactorNotPending):
+		return http_error.NewHTTPError(fiber.StatusConflict, err.Error(), nil).Send(c)
+	case errors.Is(err, mongo.ErrNoDocuments):
+		return http_error.NewHTTPError(fiber.StatusNotFound, "User not found", nil).Send(c)
+	}
+
+	h.logger.Error(message, zap.Error(err))
+	return http_error.NewHTTPError(fiber.StatusInternalServerError, message, nil).Send(c)
+}
@@ -1,9 +1,11 @@
 package handlers
 
 import (
+	"edjr-trk/configs/locale"
 	"edjr-trk/internal/api/dto"
 	"edjr-trk/internal/service"
 	"edjr-trk/pkg/http_error"
+	"edjr-trk/pkg/utils"
 	"github.com/gofiber/fiber/v2"
 	"go.uber.org/zap"
 )
@@ -35,11 +37,22 @@ func (h *emailHandler) SendMsg(c *fiber.Ctx) error {
 		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Internal Server Error", nil).Send(c)
 	}
 
-	err := h.service.SendMessage(&body)
+	// Язык подтверждения посетителю: из формы, иначе из Accept-Language
+	if body.Locale == "" {
+		for _, lang := range utils.ParseAcceptLanguage(c.Get(fiber.HeaderAcceptLanguage)) {
+			if locale.IsSupported(lang) {
+				body.Locale = lang
+				break
+			}
+		}
+	}
+
+	// Ошибка здесь означает, что обращение не сохранилось; сбой самой почты записывается в обращение
+	lead, err := h.service.SendMessage(c.Context(), &body, utils.GetClientIP(c), c.Get(fiber.HeaderUserAgent))
 	if err != nil {
-		h.logger.Error("Failed to send email", zap.Error(err))
+		h.logger.Error("Failed to save message", zap.Error(err))
 		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Failed to send email", nil).Send(c)
 	}
 
-	return c.Status(fiber.StatusCreated).JSON(fiber.Map{"status": true})
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{"status": true, "reference": lead.Reference})
 }
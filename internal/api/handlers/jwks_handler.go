@@ -0,0 +1,24 @@
+package handlers
+
+import (
+	"edjr-trk/internal/service"
+	"github.com/gofiber/fiber/v2"
+)
+
+// jwksMaxAge - сколько секунд клиенты могут кэшировать набор ключей.
+const jwksMaxAge = "300"
+
+type JWKSHandler struct {
+	jwtService service.JWTServiceInterface
+}
+
+// NewJWKSHandler creates a new instance of JWKSHandler.
+func NewJWKSHandler(jwtService service.JWTServiceInterface) *JWKSHandler {
+	return &JWKSHandler{jwtService: jwtService}
+}
+
+// GetJWKS handles publishing the public keys that verify our access tokens.
+func (h *JWKSHandler) GetJWKS(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderCacheControl, "public, max-age="+jwksMaxAge)
+	return c.Status(fiber.StatusOK).JSON(h.jwtService.PublicKeys())
+}
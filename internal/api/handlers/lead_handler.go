@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"edjr-trk/internal/api/dto"
+	"edjr-trk/internal/api/middlewares/auth"
+	"edjr-trk/internal/model"
+	"edjr-trk/internal/service"
+	"edjr-trk/pkg/http_error"
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.uber.org/zap"
+)
+
+type leadHandler struct {
+	service service.LeadServiceInterface
+	logger  *zap.Logger
+}
+
+// LeadHandlerInterface - админский inbox обращений.
+type LeadHandlerInterface interface {
+	GetLeads(c *fiber.Ctx) error
+	GetLeadById(c *fiber.Ctx) error
+	PatchLeadById(c *fiber.Ctx) error
+	AddLeadNote(c *fiber.Ctx) error
+}
+
+// NewLeadHandler creates a new instance of LeadHandler.
+func NewLeadHandler(service service.LeadServiceInterface, logger *zap.Logger) LeadHandlerInterface {
+	return &leadHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// GetLeads handles listing leads, newest first, optionally filtered by status and assignee.
+func (h *leadHandler) GetLeads(c *fiber.Ctx) error {
+	pageNumber, ok := c.Locals("pageNumber").(int)
+	if !ok {
+		pageNumber = 1
+	}
+	pageSize, ok := c.Locals("pageSize").(int)
+	if !ok {
+		pageSize = 10
+	}
+	filter, _ := c.Locals("leadFilter").(model.LeadFilter)
+
+	leads, err := h.service.GetLeads(c.Context(), filter, pageNumber, pageSize)
+	if err != nil {
+		return h.sendLeadError(c, err, "Failed to fetch leads")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(leads)
+}
+
+// GetLeadById handles fetching a single lead with its notes and delivery status.
+func (h *leadHandler) GetLeadById(c *fiber.Ctx) error {
+	leadID, _ := c.Locals("leadID").(string)
+
+	lead, err := h.service.GetLeadById(c.Context(), leadID)
+	if err != nil {
+		return h.sendLeadError(c, err, "Failed to fetch lead")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(lead)
+}
+
+// PatchLeadById handles changing the status and the assignee of a lead.
+func (h *leadHandler) PatchLeadById(c *fiber.Ctx) error {
+	leadID, _ := c.Locals("leadID").(string)
+	body, ok := c.Locals("validatedBody").(dto.PatchLeadRequest)
+	if !ok {
+		h.logger.Error("Failed to retrieve validated request from context")
+		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Internal Server Error", nil).Send(c)
+	}
+
+	lead, err := h.service.PatchLeadById(c.Context(), body, leadID)
+	if err != nil {
+		return h.sendLeadError(c, err, "Failed to update lead")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(lead)
+}
+
+// AddLeadNote handles adding an internal note on behalf of the current user.
+func (h *leadHandler) AddLeadNote(c *fiber.Ctx) error {
+	leadID, _ := c.Locals("leadID").(string)
+	userID, _ := auth.GetUserId(c)
+	body, ok := c.Locals("validatedBody").(dto.AddLeadNoteRequest)
+	if !ok {
+		h.logger.Error("Failed to retrieve validated request from context")
+		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Internal Server Error", nil).Send(c)
+	}
+
+	lead, err := h.service.AddNote(c.Context(), leadID, userID, body.Text)
+	if err != nil {
+		return h.sendLeadError(c, err, "Failed to add note")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(lead)
+}
+
+func (h *leadHandler) sendLeadError(c *fiber.Ctx, err error, message string) error {
+	switch {
+	case errors.Is(err, mongo.ErrNoDocuments):
+		return http_error.NewHTTPError(fiber.StatusNotFound, "Lead not found", nil).Send(c)
+	case errors.Is(err, service.ErrAssigneeNotFound):
+		return http_error.NewHTTPError(fiber.StatusBadRequest, err.Error(), []http_error.ErrorItem{
+			{Field: "assigneeId", Error: "The user does not exist or is disabled"},
+		}).Send(c)
+	}
+
+	h.logger.Error(message, zap.Error(err))
+	return http_error.NewHTTPError(fiber.StatusInternalServerError, message, nil).Send(c)
+}
@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"edjr-trk/configs/locale"
+	"edjr-trk/internal/model"
+	"github.com/gofiber/fiber/v2"
+)
+
+// localizable - ответ, который умеет выбрать язык текстов.
+type localizable interface {
+	Localize(p model.LocalePreference)
+}
+
+// localePreference - порядок языков из ValidateLocaleMiddleware, без него - только запасные языки.
+func localePreference(c *fiber.Ctx) model.LocalePreference {
+	if preference, ok := c.Locals("localePreference").(model.LocalePreference); ok {
+		return preference
+	}
+	return model.NewLocalePreference("", locale.Fallback(), locale.Default())
+}
+
+// writtenLocalePreference - после create/patch отвечаем на языке, который был записан.
+func writtenLocalePreference(c *fiber.Ctx, written *string) model.LocalePreference {
+	if written == nil {
+		return localePreference(c)
+	}
+	return model.NewLocalePreference(*written, locale.Fallback(), locale.Default())
+}
+
+func localizeAll[T localizable](items []T, preference model.LocalePreference) {
+	for _, item := range items {
+		item.Localize(preference)
+	}
+}
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"edjr-trk/internal/repository"
+	"edjr-trk/internal/service"
+	"edjr-trk/pkg/http_error"
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.uber.org/zap"
+	"net/http"
+)
+
+// mediaCacheControl - содержимое по ID никогда не меняется, поэтому кэшируем надолго.
+const mediaCacheControl = "public, max-age=31536000, immutable"
+
+type mediaHandler struct {
+	service service.MediaServiceInterface
+	logger  *zap.Logger
+}
+
+type MediaHandlerInterface interface {
+	GetMediaById(c *fiber.Ctx) error
+}
+
+func NewMediaHandler(service service.MediaServiceInterface, logger *zap.Logger) MediaHandlerInterface {
+	return &mediaHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// GetMediaById streams the stored file (or one of its variants) with its content type and caching headers.
+func (h *mediaHandler) GetMediaById(c *fiber.Ctx) error {
+	mediaID, ok := c.Locals("mediaID").(string)
+	if !ok || mediaID == "" {
+		h.logger.Error("Media ID is missing in the context")
+		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Media ID is required", nil).Send(c)
+	}
+
+	variant, _ := c.Locals("mediaVariant").(string)
+
+	// Сначала убеждаемся, что файл существует: удалённый не должен отвечать 304
+	media, err := h.service.GetMediaById(c.Context(), mediaID)
+	if err != nil {
+		return h.sendFetchError(c, mediaID, err)
+	}
+
+	etag := `"` + mediaID + "-" + variant + `"`
+	if c.Get(fiber.HeaderIfNoneMatch) == etag {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+
+	contentType, data, err := h.service.GetMediaContent(c.Context(), media, variant)
+	if err != nil {
+		return h.sendFetchError(c, mediaID, err)
+	}
+
+	c.Set(fiber.HeaderContentType, contentType)
+	c.Set(fiber.HeaderCacheControl, mediaCacheControl)
+	c.Set(fiber.HeaderETag, etag)
+	c.Set(fiber.HeaderLastModified, media.CreatedAt.UTC().Format(http.TimeFormat))
+	c.Set(fiber.HeaderXContentTypeOptions, "nosniff")
+
+	return c.Status(fiber.StatusOK).Send(data)
+}
+
+func (h *mediaHandler) sendFetchError(c *fiber.Ctx, mediaID string, err error) error {
+	if errors.Is(err, mongo.ErrNoDocuments) || errors.Is(err, repository.ErrBlobNotFound) {
+		return http_error.NewHTTPError(fiber.StatusNotFound, "Media not found", nil).Send(c)
+	}
+	h.logger.Error("Failed to fetch media", zap.String("mediaID", mediaID), zap.Error(err))
+	return http_error.NewHTTPError(fiber.StatusInternalServerError, "Failed to fetch media", nil).Send(c)
+}
@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"edjr-trk/internal/service"
+	"edjr-trk/pkg/http_error"
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.uber.org/zap"
+)
+
+type outboxHandler struct {
+	service service.OutboxServiceInterface
+	logger  *zap.Logger
+}
+
+// OutboxHandlerInterface - просмотр очереди исходящей почты и возврат dead писем.
+type OutboxHandlerInterface interface {
+	GetMessages(c *fiber.Ctx) error
+	GetMessageById(c *fiber.Ctx) error
+	RequeueMessage(c *fiber.Ctx) error
+}
+
+// NewOutboxHandler creates a new instance of OutboxHandler.
+func NewOutboxHandler(service service.OutboxServiceInterface, logger *zap.Logger) OutboxHandlerInterface {
+	return &outboxHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// GetMessages handles listing queued emails, newest first, optionally filtered by status.
+func (h *outboxHandler) GetMessages(c *fiber.Ctx) error {
+	pageNumber, ok := c.Locals("pageNumber").(int)
+	if !ok {
+		pageNumber = 1
+	}
+	pageSize, ok := c.Locals("pageSize").(int)
+	if !ok {
+		pageSize = 10
+	}
+	status, _ := c.Locals("outboxStatus").(string)
+
+	messages, err := h.service.GetMessages(c.Context(), status, pageNumber, pageSize)
+	if err != nil {
+		return h.sendOutboxError(c, err, "Failed to fetch outbox messages")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(messages)
+}
+
+// GetMessageById handles fetching a single queued email with its last error.
+func (h *outboxHandler) GetMessageById(c *fiber.Ctx) error {
+	messageID, _ := c.Locals("messageID").(string)
+
+	message, err := h.service.GetMessageById(c.Context(), messageID)
+	if err != nil {
+		return h.sendOutboxError(c, err, "Failed to fetch outbox message")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(message)
+}
+
+// RequeueMessage handles putting a dead email back into the queue.
+func (h *outboxHandler) RequeueMessage(c *fiber.Ctx) error {
+	messageID, _ := c.Locals("messageID").(string)
+
+	message, err := h.service.Requeue(c.Context(), messageID)
+	if err != nil {
+		return h.sendOutboxError(c, err, "Failed to requeue outbox message")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(message)
+}
+
+func (h *outboxHandler) sendOutboxError(c *fiber.Ctx, err error, message string) error {
+	switch {
+	case errors.Is(err, mongo.ErrNoDocuments):
+		return http_error.NewHTTPError(fiber.StatusNotFound, "Message not found", nil).Send(c)
+	case errors.Is(err, service.ErrOutboxNotDead):
+		return http_error.NewHTTPError(fiber.StatusConflict, err.Error(), nil).Send(c)
+	}
+
+	h.logger.Error(message, zap.Error(err))
+	return http_error.NewHTTPError(fiber.StatusInternalServerError, message, nil).Send(c)
+}
@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"edjr-trk/internal/api/dto"
+	"edjr-trk/internal/api/middlewares/auth"
+	"edjr-trk/internal/service"
+	"edjr-trk/pkg/http_error"
+	"edjr-trk/pkg/utils"
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.uber.org/zap"
+	"math"
+	"strconv"
+)
+
+type passwordHandler struct {
+	service service.PasswordServiceInterface
+	logger  *zap.Logger
+}
+
+type PasswordHandlerInterface interface {
+	ForgotPassword(c *fiber.Ctx) error
+	ResetPassword(c *fiber.Ctx) error
+	ChangePassword(c *fiber.Ctx) error
+}
+
+// NewPasswordHandler creates a new instance of PasswordHandler.
+func NewPasswordHandler(service service.PasswordServiceInterface, logger *zap.Logger) PasswordHandlerInterface {
+	return &passwordHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// ForgotPassword emails a reset token. The response is the same whether the email exists or not.
+func (h *passwordHandler) ForgotPassword(c *fiber.Ctx) error {
+	h.logger.Info("Received request to reset a forgotten password")
+
+	body, ok := c.Locals("validatedBody").(dto.ForgotPasswordRequest)
+	if !ok {
+		h.logger.Error("Failed to retrieve validated request from context")
+		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Internal Server Error", nil).Send(c)
+	}
+
+	if err := h.service.Forgot(c.Context(), body.Email); err != nil {
+		return h.sendPasswordError(c, err, "Failed to request password reset")
+	}
+
+	return c.SendStatus(fiber.StatusAccepted)
+}
+
+// ResetPassword sets a new password using the token from the email.
+func (h *passwordHandler) ResetPassword(c *fiber.Ctx) error {
+	h.logger.Info("Received request to reset password")
+
+	body, ok := c.Locals("validatedBody").(dto.ResetPasswordRequest)
+	if !ok {
+		h.logger.Error("Failed to retrieve validated request from context")
+		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Internal Server Error", nil).Send(c)
+	}
+
+	if err := h.service.Reset(c.Context(), body.Token, body.Password); err != nil {
+		return h.sendPasswordError(c, err, "Failed to reset password")
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// ChangePassword changes the password of the current user and ends all their sessions.
+func (h *passwordHandler) ChangePassword(c *fiber.Ctx) error {
+	h.logger.Info("Received request to change password")
+
+	userID, _ := auth.GetUserId(c)
+	body, ok := c.Locals("validatedBody").(dto.ChangePasswordRequest)
+	if !ok {
+		h.logger.Error("Failed to retrieve validated request from context")
+		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Internal Server Error", nil).Send(c)
+	}
+
+	if err := h.service.Change(c.Context(), userID, body.OldPassword, body.NewPassword, utils.GetClientIP(c)); err != nil {
+		return h.sendPasswordError(c, err, "Failed to change password")
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func (h *passwordHandler) sendPasswordError(c *fiber.Ctx, err error, message string) error {
+	var throttled *service.LoginThrottledError
+	switch {
+	case errors.As(err, &throttled):
+		c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(throttled.RetryAfter.Seconds()))))
+		return http_error.NewHTTPError(fiber.StatusTooManyRequests, "Too many failed password attempts, try again later", nil).Send(c)
+	case errors.Is(err, service.ErrInvalidResetToken), errors.Is(err, service.ErrWrongPassword):
+		return http_error.NewHTTPError(fiber.StatusBadRequest, err.Error(), nil).Send(c)
+	case errors.Is(err, mongo.ErrNoDocuments):
+		return http_error.NewHTTPError(fiber.StatusNotFound, "User not found", nil).Send(c)
+	}
+
+	h.logger.Error(message, zap.Error(err))
+	return http_error.NewHTTPError(fiber.StatusInternalServerError, message, nil).Send(c)
+}
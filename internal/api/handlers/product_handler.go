@@ -2,21 +2,28 @@ package handlers
 
 import (
 	"edjr-trk/internal/api/dto"
+	"edjr-trk/internal/api/middlewares/auth"
+	"edjr-trk/internal/model"
 	"edjr-trk/internal/service"
 	"edjr-trk/pkg/http_error"
+	"edjr-trk/pkg/utils"
+	"errors"
 	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/mongo"
 	"go.uber.org/zap"
 )
 
 type ProductHandler struct {
-	service service.ProductServiceInterface
-	logger  *zap.Logger
+	service  service.ProductServiceInterface
+	taxonomy service.TaxonomyServiceInterface
+	logger   *zap.Logger
 }
 
-func NewProductHandler(service service.ProductServiceInterface, logger *zap.Logger) *ProductHandler {
+func NewProductHandler(service service.ProductServiceInterface, taxonomy service.TaxonomyServiceInterface, logger *zap.Logger) *ProductHandler {
 	return &ProductHandler{
-		service: service,
-		logger:  logger,
+		service:  service,
+		taxonomy: taxonomy,
+		logger:   logger,
 	}
 }
 
@@ -30,12 +37,20 @@ func (h *ProductHandler) CreateProduct(c *fiber.Ctx) error {
 		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Internal Server Error", nil).Send(c)
 	}
 
-	product, err := h.service.CreateProduct(c.Context(), req)
+	authorID, _ := auth.GetUserId(c)
+	product, err := h.service.CreateProduct(c.Context(), req, authorID)
+	if errors.Is(err, service.ErrSlugTaken) {
+		return http_error.NewHTTPError(fiber.StatusConflict, "Slug is already taken", nil).Send(c)
+	}
+	if errors.Is(err, service.ErrUnknownTerm) {
+		return http_error.NewHTTPError(fiber.StatusBadRequest, err.Error(), nil).Send(c)
+	}
 	if err != nil {
 		h.logger.Error("Failed to create product", zap.Error(err))
 		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Failed to create product", nil).Send(c)
 	}
 
+	product.Localize(writtenLocalePreference(c, req.Locale))
 	return c.Status(fiber.StatusCreated).JSON(product)
 }
 
@@ -55,7 +70,36 @@ func (h *ProductHandler) GetAllProducts(c *fiber.Ctx) error {
 		pageSize = 10
 	}
 
-	products, err := h.service.GetAllProducts(c.Context(), pageNumber, pageSize)
+	taxonomy, err := taxonomyFilter(c, h.taxonomy)
+	if err != nil {
+		h.logger.Error("Failed to resolve taxonomy filter", zap.Error(err))
+		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Failed to fetch products", nil).Send(c)
+	}
+	filter := model.ProductFilter{TaxonomyFilter: taxonomy, WithFacets: c.QueryBool("facets")}
+
+	query, _ := c.Locals("searchQuery").(string)
+
+	if cursorMode, _ := c.Locals("cursorMode").(bool); cursorMode {
+		if query != "" {
+			return http_error.NewHTTPError(fiber.StatusBadRequest, "Cursor pagination is not supported for search", nil).Send(c)
+		}
+
+		cursor, _ := c.Locals("pageCursor").(*utils.PageCursor)
+		page, err := h.service.GetProductsByCursor(c.Context(), filter, cursor, pageSize)
+		if err != nil {
+			h.logger.Error("Failed to fetch products by cursor", zap.Error(err))
+			return http_error.NewHTTPError(fiber.StatusInternalServerError, "Failed to fetch products", nil).Send(c)
+		}
+		localizeAll(page.Items, localePreference(c))
+		return c.Status(fiber.StatusOK).JSON(page)
+	}
+
+	var products *model.Paginate[*model.ProductResponse]
+	if query != "" {
+		products, err = h.service.SearchProducts(c.Context(), filter, query, pageNumber, pageSize)
+	} else {
+		products, err = h.service.GetAllProducts(c.Context(), filter, pageNumber, pageSize)
+	}
 	if err != nil {
 		h.logger.Error("Failed to fetch paginated products", zap.Error(err))
 		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Failed to fetch products", nil).Send(c)
@@ -67,6 +111,8 @@ func (h *ProductHandler) GetAllProducts(c *fiber.Ctx) error {
 		zap.Int("fetchedItems", len(products.Items)),
 	)
 
+	localizeAll(products.Items, localePreference(c))
+
 	return c.Status(fiber.StatusOK).JSON(products)
 }
 
@@ -81,6 +127,9 @@ func (h *ProductHandler) GetProductById(c *fiber.Ctx) error {
 	}
 
 	article, err := h.service.GetProductById(c.Context(), productID)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return http_error.NewHTTPError(fiber.StatusNotFound, "Product not found", nil).Send(c)
+	}
 	if err != nil {
 		h.logger.Error("Failed to fetch product by ID", zap.String("productID", productID), zap.Error(err))
 		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Failed to fetch product", nil).Send(c)
@@ -91,6 +140,7 @@ func (h *ProductHandler) GetProductById(c *fiber.Ctx) error {
 	}
 
 	h.logger.Info("Product fetched successfully", zap.String("productID", productID))
+	article.Localize(localePreference(c))
 	return c.Status(fiber.StatusOK).JSON(article)
 }
 
@@ -105,6 +155,9 @@ func (h *ProductHandler) RemoveProductById(c *fiber.Ctx) error {
 	}
 
 	removedProduct, err := h.service.RemoveProductById(c.Context(), productID)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return http_error.NewHTTPError(fiber.StatusNotFound, "Product not found", nil).Send(c)
+	}
 	if err != nil {
 		h.logger.Error("Failed to remove article", zap.String("productID", productID), zap.Error(err))
 		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Failed to remove product", nil).Send(c)
@@ -133,12 +186,183 @@ func (h *ProductHandler) PatchProductById(c *fiber.Ctx) error {
 	}
 
 	// Update the article via the service.
-	updatedArticle, err := h.service.PatchProductById(c.Context(), req, productID)
+	authorID, _ := auth.GetUserId(c)
+	updatedArticle, err := h.service.PatchProductById(c.Context(), req, productID, authorID)
+	if errors.Is(err, service.ErrSlugTaken) {
+		return http_error.NewHTTPError(fiber.StatusConflict, "Slug is already taken", nil).Send(c)
+	}
+	if errors.Is(err, service.ErrUnknownTerm) {
+		return http_error.NewHTTPError(fiber.StatusBadRequest, err.Error(), nil).Send(c)
+	}
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return http_error.NewHTTPError(fiber.StatusNotFound, "Product not found", nil).Send(c)
+	}
 	if err != nil {
 		h.logger.Error("Failed to update article", zap.String("productID", productID), zap.Error(err))
 		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Failed to update product", nil).Send(c)
 	}
 
 	h.logger.Info("Product updated successfully", zap.String("productID", productID))
+	updatedArticle.Localize(writtenLocalePreference(c, req.Locale))
 	return c.Status(fiber.StatusOK).JSON(updatedArticle)
 }
+
+// GetProductRevisions handles listing the revision history of a project.
+func (h *ProductHandler) GetProductRevisions(c *fiber.Ctx) error {
+	productID, ok := c.Locals("productID").(string)
+	if !ok || productID == "" {
+		h.logger.Error("Product ID is missing in the context")
+		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Product ID is required", nil).Send(c)
+	}
+
+	pageNumber, ok := c.Locals("pageNumber").(int)
+	if !ok {
+		pageNumber = 1
+	}
+	pageSize, ok := c.Locals("pageSize").(int)
+	if !ok {
+		pageSize = 10
+	}
+
+	revisions, err := h.service.GetProductRevisions(c.Context(), productID, pageNumber, pageSize)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return http_error.NewHTTPError(fiber.StatusNotFound, "Product not found", nil).Send(c)
+	}
+	if err != nil {
+		h.logger.Error("Failed to fetch product revisions", zap.String("productID", productID), zap.Error(err))
+		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Failed to fetch revisions", nil).Send(c)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(revisions)
+}
+
+// GetProductRevision handles fetching a single revision with its snapshot.
+func (h *ProductHandler) GetProductRevision(c *fiber.Ctx) error {
+	productID, _ := c.Locals("productID").(string)
+	revisionID, _ := c.Locals("revisionID").(string)
+
+	revision, err := h.service.GetProductRevision(c.Context(), productID, revisionID)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return http_error.NewHTTPError(fiber.StatusNotFound, "Revision not found", nil).Send(c)
+	}
+	if err != nil {
+		h.logger.Error("Failed to fetch product revision", zap.String("revisionID", revisionID), zap.Error(err))
+		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Failed to fetch revision", nil).Send(c)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(revision)
+}
+
+// DiffProductRevisions handles comparing two revisions given as ?from=&to=.
+func (h *ProductHandler) DiffProductRevisions(c *fiber.Ctx) error {
+	productID, _ := c.Locals("productID").(string)
+	fromID, _ := c.Locals("revisionFrom").(string)
+	toID, _ := c.Locals("revisionTo").(string)
+
+	diff, err := h.service.DiffProductRevisions(c.Context(), productID, fromID, toID)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return http_error.NewHTTPError(fiber.StatusNotFound, "Revision not found", nil).Send(c)
+	}
+	if err != nil {
+		h.logger.Error("Failed to diff product revisions", zap.String("productID", productID), zap.Error(err))
+		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Failed to diff revisions", nil).Send(c)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(diff)
+}
+
+// RestoreProductRevision handles making a revision the current version of a project.
+func (h *ProductHandler) RestoreProductRevision(c *fiber.Ctx) error {
+	h.logger.Info("Received request to restore a project revision")
+
+	productID, _ := c.Locals("productID").(string)
+	revisionID, _ := c.Locals("revisionID").(string)
+	authorID, _ := auth.GetUserId(c)
+
+	restored, err := h.service.RestoreProductRevision(c.Context(), productID, revisionID, authorID)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return http_error.NewHTTPError(fiber.StatusNotFound, "Revision not found", nil).Send(c)
+	}
+	if err != nil {
+		h.logger.Error("Failed to restore product revision", zap.String("revisionID", revisionID), zap.Error(err))
+		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Failed to restore revision", nil).Send(c)
+	}
+
+	h.logger.Info("Product restored successfully", zap.String("productID", productID), zap.String("revisionID", revisionID))
+	restored.Localize(localePreference(c))
+	return c.Status(fiber.StatusOK).JSON(restored)
+}
+
+// GetDeletedProducts handles listing projects in the trash.
+func (h *ProductHandler) GetDeletedProducts(c *fiber.Ctx) error {
+	h.logger.Info("Received request to fetch deleted projects")
+
+	pageNumber, ok := c.Locals("pageNumber").(int)
+	if !ok {
+		pageNumber = 1
+	}
+	pageSize, ok := c.Locals("pageSize").(int)
+	if !ok {
+		pageSize = 10
+	}
+
+	items, err := h.service.GetDeletedProducts(c.Context(), pageNumber, pageSize)
+	if err != nil {
+		h.logger.Error("Failed to fetch deleted projects", zap.Error(err))
+		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Failed to fetch trash", nil).Send(c)
+	}
+
+	localizeAll(items.Items, localePreference(c))
+	return c.Status(fiber.StatusOK).JSON(items)
+}
+
+// RestoreProductById handles moving a project out of the trash.
+func (h *ProductHandler) RestoreProductById(c *fiber.Ctx) error {
+	h.logger.Info("Received request to restore a project")
+
+	productID, ok := c.Locals("productID").(string)
+	if !ok || productID == "" {
+		h.logger.Error("Product ID is missing in the context")
+		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Product ID is required", nil).Send(c)
+	}
+
+	restored, err := h.service.RestoreProductById(c.Context(), productID)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return http_error.NewHTTPError(fiber.StatusNotFound, "Product not found in trash", nil).Send(c)
+	}
+	if err != nil {
+		h.logger.Error("Failed to restore project", zap.String("productID", productID), zap.Error(err))
+		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Failed to restore project", nil).Send(c)
+	}
+
+	h.logger.Info("Product restored successfully", zap.String("productID", productID))
+	restored.Localize(localePreference(c))
+	return c.Status(fiber.StatusOK).JSON(restored)
+}
+
+// GetProductBySlug handles fetching product by slug; old slugs answer with a 301 to the current one.
+func (h *ProductHandler) GetProductBySlug(c *fiber.Ctx) error {
+	slug, ok := c.Locals("slug").(string)
+	if !ok || slug == "" {
+		h.logger.Error("Slug is missing in the context")
+		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Slug is required", nil).Send(c)
+	}
+
+	product, err := h.service.GetProductBySlug(c.Context(), slug)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return http_error.NewHTTPError(fiber.StatusNotFound, "Product not found", nil).Send(c)
+	}
+	if err != nil {
+		h.logger.Error("Failed to fetch product by slug", zap.String("slug", slug), zap.Error(err))
+		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Failed to fetch product", nil).Send(c)
+	}
+
+	// Запрошен прежний slug - отправляем на актуальный адрес
+	if product.Slug != slug {
+		c.Location(slugLocation(c, slug, product.Slug))
+		return c.Status(fiber.StatusMovedPermanently).JSON(fiber.Map{"slug": product.Slug})
+	}
+
+	product.Localize(localePreference(c))
+	return c.Status(fiber.StatusOK).JSON(product)
+}
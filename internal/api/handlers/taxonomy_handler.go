@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"edjr-trk/internal/api/dto"
+	"edjr-trk/internal/model"
+	"edjr-trk/internal/service"
+	"edjr-trk/pkg/http_error"
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.uber.org/zap"
+)
+
+type TaxonomyHandler struct {
+	service service.TaxonomyServiceInterface
+	logger  *zap.Logger
+}
+
+// NewTaxonomyHandler creates a new instance of TaxonomyHandler.
+func NewTaxonomyHandler(service service.TaxonomyServiceInterface, logger *zap.Logger) *TaxonomyHandler {
+	return &TaxonomyHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// GetTags handles listing all tags.
+func (h *TaxonomyHandler) GetTags(c *fiber.Ctx) error {
+	return h.getTerms(c, model.TermKindTag)
+}
+
+// CreateTag handles creating a tag.
+func (h *TaxonomyHandler) CreateTag(c *fiber.Ctx) error {
+	return h.createTerm(c, model.TermKindTag)
+}
+
+// PatchTag handles renaming a tag or changing its slug.
+func (h *TaxonomyHandler) PatchTag(c *fiber.Ctx) error {
+	return h.patchTerm(c, model.TermKindTag)
+}
+
+// RemoveTag handles deleting a tag and detaching it from articles and projects.
+func (h *TaxonomyHandler) RemoveTag(c *fiber.Ctx) error {
+	return h.removeTerm(c, model.TermKindTag)
+}
+
+// GetCategories handles listing all categories as a flat list with parent IDs.
+func (h *TaxonomyHandler) GetCategories(c *fiber.Ctx) error {
+	return h.getTerms(c, model.TermKindCategory)
+}
+
+// CreateCategory handles creating a category, optionally under a parent.
+func (h *TaxonomyHandler) CreateCategory(c *fiber.Ctx) error {
+	return h.createTerm(c, model.TermKindCategory)
+}
+
+// PatchCategory handles renaming or moving a category.
+func (h *TaxonomyHandler) PatchCategory(c *fiber.Ctx) error {
+	return h.patchTerm(c, model.TermKindCategory)
+}
+
+// RemoveCategory handles deleting a category without subcategories.
+func (h *TaxonomyHandler) RemoveCategory(c *fiber.Ctx) error {
+	return h.removeTerm(c, model.TermKindCategory)
+}
+
+func (h *TaxonomyHandler) getTerms(c *fiber.Ctx, kind string) error {
+	terms, err := h.service.GetTerms(c.Context(), kind)
+	if err != nil {
+		h.logger.Error("Failed to fetch terms", zap.String("kind", kind), zap.Error(err))
+		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Failed to fetch terms", nil).Send(c)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(terms)
+}
+
+func (h *TaxonomyHandler) createTerm(c *fiber.Ctx, kind string) error {
+	h.logger.Info("Received request to create a term", zap.String("kind", kind))
+
+	req, ok := c.Locals("validatedBody").(dto.CreateTermRequest)
+	if !ok {
+		h.logger.Error("Failed to retrieve validated request from context")
+		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Internal Server Error", nil).Send(c)
+	}
+
+	term, err := h.service.CreateTerm(c.Context(), kind, req)
+	if err != nil {
+		return h.sendTermError(c, err, "Failed to create term")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(term)
+}
+
+func (h *TaxonomyHandler) patchTerm(c *fiber.Ctx, kind string) error {
+	h.logger.Info("Received request to update a term", zap.String("kind", kind))
+
+	termID, _ := c.Locals("termID").(string)
+	req, ok := c.Locals("validatedBody").(dto.PatchTermRequest)
+	if !ok {
+		h.logger.Error("Failed to retrieve validated request from context")
+		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Internal Server Error", nil).Send(c)
+	}
+
+	term, err := h.service.PatchTerm(c.Context(), kind, termID, req)
+	if err != nil {
+		return h.sendTermError(c, err, "Failed to update term")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(term)
+}
+
+func (h *TaxonomyHandler) removeTerm(c *fiber.Ctx, kind string) error {
+	h.logger.Info("Received request to remove a term", zap.String("kind", kind))
+
+	termID, _ := c.Locals("termID").(string)
+	if err := h.service.RemoveTerm(c.Context(), kind, termID); err != nil {
+		return h.sendTermError(c, err, "Failed to remove term")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"id": termID})
+}
+
+// sendTermError - переводит ошибки сервиса таксономии в HTTP-ответ.
+func (h *TaxonomyHandler) sendTermError(c *fiber.Ctx, err error, message string) error {
+	switch {
+	case errors.Is(err, mongo.ErrNoDocuments):
+		return http_error.NewHTTPError(fiber.StatusNotFound, "Term not found", nil).Send(c)
+	case errors.Is(err, service.ErrSlugTaken):
+		return http_error.NewHTTPError(fiber.StatusConflict, "Slug is already taken", nil).Send(c)
+	case errors.Is(err, service.ErrTermHasChildren):
+		return http_error.NewHTTPError(fiber.StatusConflict, "Category has subcategories", nil).Send(c)
+	case errors.Is(err, service.ErrInvalidTermParent):
+		return http_error.NewHTTPError(fiber.StatusBadRequest, err.Error(), []http_error.ErrorItem{
+			{Field: "ParentID", Error: "The parent must be an existing category that is not this category or its descendant"},
+		}).Send(c)
+	}
+
+	h.logger.Error(message, zap.Error(err))
+	return http_error.NewHTTPError(fiber.StatusInternalServerError, message, nil).Send(c)
+}
+
+// taxonomyFilter - фильтр по ?tag= и ?category=, разобранным ValidateTaxonomyFilterMiddleware.
+func taxonomyFilter(c *fiber.Ctx, taxonomy service.TaxonomyServiceInterface) (model.TaxonomyFilter, error) {
+	tagSlugs, _ := c.Locals("tagSlugs").([]string)
+	categorySlugs, _ := c.Locals("categorySlugs").([]string)
+	if tagSlugs == nil && categorySlugs == nil {
+		return model.TaxonomyFilter{}, nil
+	}
+	return taxonomy.ResolveFilter(c.Context(), tagSlugs, categorySlugs)
+}
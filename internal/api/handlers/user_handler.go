@@ -2,9 +2,12 @@ package handlers
 
 import (
 	"edjr-trk/internal/api/dto"
+	"edjr-trk/internal/api/middlewares/auth"
 	"edjr-trk/internal/service"
 	"edjr-trk/pkg/http_error"
+	"errors"
 	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/mongo"
 	"go.uber.org/zap"
 )
 
@@ -18,6 +21,12 @@ type UserHandlerInterface interface {
 	CreateUser(c *fiber.Ctx) error
 	GetAllUsers(c *fiber.Ctx) error
 	RemoveUserById(c *fiber.Ctx) error
+	GetDeletedUsers(c *fiber.Ctx) error
+	RestoreUserById(c *fiber.Ctx) error
+	GetMe(c *fiber.Ctx) error
+	PatchUserById(c *fiber.Ctx) error
+	DisableUserById(c *fiber.Ctx) error
+	EnableUserById(c *fiber.Ctx) error
 }
 
 // NewUserHandler creates a new instance of UserHandler.
@@ -47,10 +56,9 @@ func (h *userHandler) CreateUser(c *fiber.Ctx) error {
 	//	return http_error.NewHTTPError(fiber.StatusBadRequest, "Invalid request body", nil).Send(c)
 	//}
 
-	user, err := h.service.CreateNewAdmin(c.Context(), &body)
+	user, err := h.service.CreateUser(c.Context(), &body)
 	if err != nil {
-		h.logger.Error("Failed to create article", zap.Error(err))
-		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Failed to create user", nil).Send(c)
+		return h.sendUserError(c, err, "Failed to create user")
 	}
 
 	return c.Status(fiber.StatusCreated).JSON(user)
@@ -106,6 +114,12 @@ func (h *userHandler) RemoveUserById(c *fiber.Ctx) error {
 
 	// Remove the article via the service.
 	removedUser, err := h.service.RemoveUserById(c.Context(), userId)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return http_error.NewHTTPError(fiber.StatusNotFound, "User not found", nil).Send(c)
+	}
+	if errors.Is(err, service.ErrLastOwner) {
+		return http_error.NewHTTPError(fiber.StatusConflict, err.Error(), nil).Send(c)
+	}
 	if err != nil {
 		h.logger.Error("Failed to remove article", zap.String("userId", userId), zap.Error(err))
 		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Failed to remove user", nil).Send(c)
@@ -114,3 +128,131 @@ func (h *userHandler) RemoveUserById(c *fiber.Ctx) error {
 	h.logger.Info("User removed successfully", zap.String("userId", userId))
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{"id": removedUser})
 }
+
+// GetDeletedUsers handles listing users in the trash.
+func (h *userHandler) GetDeletedUsers(c *fiber.Ctx) error {
+	h.logger.Info("Received request to fetch deleted users")
+
+	pageNumber, ok := c.Locals("pageNumber").(int)
+	if !ok {
+		pageNumber = 1
+	}
+	pageSize, ok := c.Locals("pageSize").(int)
+	if !ok {
+		pageSize = 10
+	}
+
+	items, err := h.service.GetDeletedUsers(c.Context(), pageNumber, pageSize)
+	if err != nil {
+		h.logger.Error("Failed to fetch deleted users", zap.Error(err))
+		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Failed to fetch trash", nil).Send(c)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(items)
+}
+
+// RestoreUserById handles moving a user out of the trash.
+func (h *userHandler) RestoreUserById(c *fiber.Ctx) error {
+	h.logger.Info("Received request to restore a user")
+
+	userId, ok := c.Locals("userId").(string)
+	if !ok || userId == "" {
+		h.logger.Error("User ID is missing in the context")
+		return http_error.NewHTTPError(fiber.StatusInternalServerError, "User ID is required", nil).Send(c)
+	}
+
+	restored, err := h.service.RestoreUserById(c.Context(), userId)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return http_error.NewHTTPError(fiber.StatusNotFound, "User not found in trash", nil).Send(c)
+	}
+	if err != nil {
+		h.logger.Error("Failed to restore user", zap.String("userId", userId), zap.Error(err))
+		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Failed to restore user", nil).Send(c)
+	}
+
+	h.logger.Info("User restored successfully", zap.String("userId", userId))
+	return c.Status(fiber.StatusOK).JSON(restored)
+}
+
+// GetMe returns the profile of the current user.
+func (h *userHandler) GetMe(c *fiber.Ctx) error {
+	userId, _ := auth.GetUserId(c)
+
+	user, err := h.service.GetUserById(c.Context(), userId)
+	if err != nil {
+		return h.sendUserError(c, err, "Failed to fetch user")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(user)
+}
+
+// PatchUserById handles partial updates of a user: email, phone, role, display name and avatar.
+func (h *userHandler) PatchUserById(c *fiber.Ctx) error {
+	h.logger.Info("Received request to patch a user")
+
+	userId, ok := c.Locals("userId").(string)
+	if !ok || userId == "" {
+		h.logger.Error("User ID is missing in the context")
+		return http_error.NewHTTPError(fiber.StatusInternalServerError, "User ID is required", nil).Send(c)
+	}
+	body, ok := c.Locals("validatedBody").(dto.PatchUserRequest)
+	if !ok {
+		h.logger.Error("Failed to retrieve validated request from context")
+		return http_error.NewHTTPError(fiber.StatusInternalServerError, "Internal Server Error", nil).Send(c)
+	}
+
+	user, err := h.service.PatchUserById(c.Context(), body, userId)
+	if err != nil {
+		return h.sendUserError(c, err, "Failed to update user")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(user)
+}
+
+// DisableUserById blocks login for a user without deleting them.
+func (h *userHandler) DisableUserById(c *fiber.Ctx) error {
+	h.logger.Info("Received request to disable a user")
+
+	userId, ok := c.Locals("userId").(string)
+	if !ok || userId == "" {
+		h.logger.Error("User ID is missing in the context")
+		return http_error.NewHTTPError(fiber.StatusInternalServerError, "User ID is required", nil).Send(c)
+	}
+
+	user, err := h.service.DisableUserById(c.Context(), userId)
+	if err != nil {
+		return h.sendUserError(c, err, "Failed to disable user")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(user)
+}
+
+// EnableUserById allows a disabled user to log in again.
+func (h *userHandler) EnableUserById(c *fiber.Ctx) error {
+	h.logger.Info("Received request to enable a user")
+
+	userId, ok := c.Locals("userId").(string)
+	if !ok || userId == "" {
+		h.logger.Error("User ID is missing in the context")
+		return http_error.NewHTTPError(fiber.StatusInternalServerError, "User ID is required", nil).Send(c)
+	}
+
+	user, err := h.service.EnableUserById(c.Context(), userId)
+	if err != nil {
+		return h.sendUserError(c, err, "Failed to enable user")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(user)
+}
+
+func (h *userHandler) sendUserError(c *fiber.Ctx, err error, message string) error {
+	switch {
+	case errors.Is(err, mongo.ErrNoDocuments):
+		return http_error.NewHTTPError(fiber.StatusNotFound, "User not found", nil).Send(c)
+	case errors.Is(err, service.ErrLastOwner), errors.Is(err, service.ErrEmailTaken):
+		return http_error.NewHTTPError(fiber.StatusConflict, err.Error(), nil).Send(c)
+	}
+
+	h.logger.Error(message, zap.Error(err))
+	return http_error.NewHTTPError(fiber.StatusInternalServerError, message, nil).Send(c)
+}
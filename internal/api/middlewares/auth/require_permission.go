@@ -0,0 +1,18 @@
+package auth
+
+import (
+	"edjr-trk/internal/model"
+	"edjr-trk/pkg/http_error"
+	"github.com/gofiber/fiber/v2"
+)
+
+// RequirePermission пропускает запрос, только если у роли из токена есть право permission.
+// Ставится после JwtAuthMiddleware.
+func RequirePermission(permission model.Permission) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if !model.HasPermission(GetUserRole(c), permission) {
+			return http_error.NewHTTPError(fiber.StatusForbidden, "Insufficient permissions", nil).Send(c)
+		}
+		return c.Next()
+	}
+}
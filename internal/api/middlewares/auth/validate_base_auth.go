@@ -1,15 +1,43 @@
 package auth
 
 import (
+	"crypto/sha256"
+	"crypto/subtle"
 	"edjr-trk/configs/env"
+	"edjr-trk/internal/model"
+	"edjr-trk/internal/service"
 	"edjr-trk/pkg/http_error"
 	"encoding/base64"
 	"github.com/gofiber/fiber/v2"
 	"strings"
 )
 
+// BasicAuthEnabled - разрешён ли вход супер-админа из SUPER_ADMIN_LOGIN/SUPER_ADMIN_PASSWORD.
+// Нужен только до создания владельца командой bootstrap_owner, по умолчанию выключен.
+func BasicAuthEnabled() bool {
+	return env.GetEnvBool("SUPER_ADMIN_BASIC_AUTH", false)
+}
+
+// JwtOrBasicAuthMiddleware - JwtAuthMiddleware, а при включённом SUPER_ADMIN_BASIC_AUTH
+// также Basic-авторизация супер-админа с правами владельца.
+func JwtOrBasicAuthMiddleware(jwtService service.JWTServiceInterface, authService service.AuthServiceInterface) fiber.Handler {
+	jwtAuth := JwtAuthMiddleware(jwtService, authService)
+	basicAuth := BasicAuthMiddleware()
+
+	return func(c *fiber.Ctx) error {
+		if BasicAuthEnabled() && strings.HasPrefix(c.Get("Authorization"), "Basic ") {
+			return basicAuth(c)
+		}
+		return jwtAuth(c)
+	}
+}
+
 func BasicAuthMiddleware() fiber.Handler {
 	return func(c *fiber.Ctx) error {
+		if !BasicAuthEnabled() {
+			return http_error.NewHTTPError(fiber.StatusUnauthorized, "Invalid authorization", nil).Send(c)
+		}
+
 		// Получаем заголовок Authorization
 		authHeader := c.Get("Authorization")
 
@@ -40,12 +68,28 @@ func BasicAuthMiddleware() fiber.Handler {
 		validSuperLogin := env.GetEnv("SUPER_ADMIN_LOGIN", "")
 		validSuperPassword := env.GetEnv("SUPER_ADMIN_PASSWORD", "")
 
-		// Проверяем креденшалы
-		if username != validSuperLogin || password != validSuperPassword {
+		// Пустые креденшалы в конфиге не должны пускать с пустым логином и паролем
+		if validSuperLogin == "" || validSuperPassword == "" {
+			return http_error.NewHTTPError(fiber.StatusUnauthorized, "Invalid authorization", nil).Send(c)
+		}
+
+		// Проверяем креденшалы за постоянное время, обе части всегда
+		loginOK := constantTimeEqual(username, validSuperLogin)
+		passwordOK := constantTimeEqual(password, validSuperPassword)
+		if loginOK&passwordOK != 1 {
 			return http_error.NewHTTPError(fiber.StatusUnauthorized, "Invalid authorization", nil).Send(c)
 		}
 
+		// Супер-админ действует с правами владельца
+		c.Locals(userRoleKey, model.RoleOwner)
+
 		// Если всё ок — продолжаем
 		return c.Next()
 	}
 }
+
+// constantTimeEqual - сравнение, время которого не зависит ни от содержимого, ни от длины строк.
+func constantTimeEqual(a, b string) int {
+	hashA, hashB := sha256.Sum256([]byte(a)), sha256.Sum256([]byte(b))
+	return subtle.ConstantTimeCompare(hashA[:], hashB[:])
+}
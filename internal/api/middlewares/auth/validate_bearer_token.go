@@ -7,7 +7,14 @@ import (
 	"strings"
 )
 
-func JwtAuthMiddleware(jwtService service.JWTServiceInterface) fiber.Handler {
+// Ключи Locals с данными пользователя из токена. Отличаются от параметров маршрута
+// (например, userId в /users/:id), чтобы не перезаписываться валидаторами.
+const (
+	userIdKey   = "authUserId"
+	userRoleKey = "authUserRole"
+)
+
+func JwtAuthMiddleware(jwtService service.JWTServiceInterface, authService service.AuthServiceInterface) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		// Получаем заголовок Authorization
 		authHeader := c.Get("Authorization")
@@ -36,8 +43,21 @@ func JwtAuthMiddleware(jwtService service.JWTServiceInterface) fiber.Handler {
 			return http_error.NewHTTPError(fiber.StatusUnauthorized, "Invalid token claims", nil).Send(c)
 		}
 
-		// Добавляем UserId в локальные данные запроса
-		c.Locals("userId", claims.UserId)
+		// Токены, выпущенные до выхода пользователя из системы, больше не действуют
+		if claims.IssuedAt == nil {
+			return http_error.NewHTTPError(fiber.StatusUnauthorized, "Invalid token claims", nil).Send(c)
+		}
+		revoked, err := authService.IsTokenRevoked(c.Context(), claims.UserId, claims.IssuedAt.Time)
+		if err != nil {
+			return http_error.NewHTTPError(fiber.StatusInternalServerError, "Failed to verify token", nil).Send(c)
+		}
+		if revoked {
+			return http_error.NewHTTPError(fiber.StatusUnauthorized, "Token has been revoked", nil).Send(c)
+		}
+
+		// Добавляем UserId и роль в локальные данные запроса
+		c.Locals(userIdKey, claims.UserId)
+		c.Locals(userRoleKey, claims.Role)
 
 		// Продолжаем выполнение запроса
 		return c.Next()
@@ -46,6 +66,12 @@ func JwtAuthMiddleware(jwtService service.JWTServiceInterface) fiber.Handler {
 
 // GetUserId retrieves the UserId from the Fiber context
 func GetUserId(c *fiber.Ctx) (string, bool) {
-	userId, ok := c.Locals("userId").(string)
+	userId, ok := c.Locals(userIdKey).(string)
 	return userId, ok
 }
+
+// GetUserRole retrieves the role from the Fiber context
+func GetUserRole(c *fiber.Ctx) string {
+	role, _ := c.Locals(userRoleKey).(string)
+	return role
+}
@@ -1,21 +1,16 @@
 package dto_validator
 
 import (
-	"encoding/base64"
+	"edjr-trk/configs/media"
+	"edjr-trk/pkg/imaging"
 	"github.com/go-playground/validator/v10"
 )
 
-// imgBase64OrNull checks if a string is either empty, null, or a valid Base64-encoded string.
-func imgBase64OrNull(fl validator.FieldLevel) bool {
-	value := fl.Field().Interface()
-
-	// Allow null values
-	if value == nil {
-		return true
-	}
-
-	// Check if the value is a string
-	str, ok := value.(string)
+// imgDataURI checks that an image field is null, empty, or a Base64 data URI of an allowed image type
+// whose content matches the declared type and fits MEDIA_MAX_UPLOAD_SIZE and the maximum dimensions.
+// All checks share one decode of the payload; the error message names the failed check.
+func imgDataURI(fl validator.FieldLevel) bool {
+	str, ok := fl.Field().Interface().(string)
 	if !ok {
 		return false
 	}
@@ -25,30 +20,5 @@ func imgBase64OrNull(fl validator.FieldLevel) bool {
 		return true
 	}
 
-	// Define allowed prefixes for Base64 data URIs
-	allowedPrefixes := []string{
-		"data:image/jpeg;base64,", // JPEG
-		"data:image/png;base64,",  // PNG
-		"data:image/gif;base64,",  // GIF
-		"data:image/webp;base64,", // WebP
-	}
-
-	// Check if the string starts with any allowed prefix
-	hasValidPrefix := false
-	for _, prefix := range allowedPrefixes {
-		if len(str) > len(prefix) && str[:len(prefix)] == prefix {
-			hasValidPrefix = true
-			str = str[len(prefix):] // Remove the prefix
-			break
-		}
-	}
-
-	// If no valid prefix was found, the string is invalid
-	if !hasValidPrefix {
-		return false
-	}
-
-	// Try to decode the Base64 string
-	_, err := base64.StdEncoding.DecodeString(str)
-	return err == nil
+	return imaging.CheckDataURI(str, media.ImageLimits()) == nil
 }
@@ -0,0 +1,30 @@
+package dto_validator
+
+import (
+	"edjr-trk/internal/model"
+	"edjr-trk/pkg/http_error"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+	"strings"
+)
+
+// ValidateArticleStatusFilterMiddleware - проверяет необязательный фильтр ?status= для административного списка статей.
+func ValidateArticleStatusFilterMiddleware(logger *zap.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		status := strings.TrimSpace(c.Query("status"))
+
+		switch status {
+		case "", model.ArticleStatusDraft, model.ArticleStatusScheduled, model.ArticleStatusPublished, model.ArticleStatusArchived:
+		default:
+			logger.Error("Invalid article status filter", zap.String("status", status))
+			return http_error.NewHTTPError(fiber.StatusBadRequest, "Invalid status filter", []http_error.ErrorItem{
+				{Field: "status", Error: "The field must be one of: draft, scheduled, published, archived"},
+			}).Send(c)
+		}
+
+		// Store the status filter in context; empty means all statuses.
+		c.Locals("articleStatus", status)
+
+		return c.Next()
+	}
+}
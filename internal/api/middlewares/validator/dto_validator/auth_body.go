@@ -0,0 +1,35 @@
+package dto_validator
+
+import (
+	"edjr-trk/internal/api/dto"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+func ValidateRefreshTokenMiddleware(logger *zap.Logger) fiber.Handler {
+	return validateJSONBody[dto.RefreshTokenRequest](logger)
+}
+
+func ValidateLogoutMiddleware(logger *zap.Logger) fiber.Handler {
+	return validateJSONBody[dto.LogoutRequest](logger)
+}
+
+func ValidateTwoFactorCodeMiddleware(logger *zap.Logger) fiber.Handler {
+	return validateJSONBody[dto.TwoFactorCodeRequest](logger)
+}
+
+func ValidateLoginTwoFactorMiddleware(logger *zap.Logger) fiber.Handler {
+	return validateJSONBody[dto.LoginTwoFactorRequest](logger)
+}
+
+func ValidateForgotPasswordMiddleware(logger *zap.Logger) fiber.Handler {
+	return validateJSONBody[dto.ForgotPasswordRequest](logger)
+}
+
+func ValidateResetPasswordMiddleware(logger *zap.Logger) fiber.Handler {
+	return validateJSONBody[dto.ResetPasswordRequest](logger)
+}
+
+func ValidateChangePasswordMiddleware(logger *zap.Logger) fiber.Handler {
+	return validateJSONBody[dto.ChangePasswordRequest](logger)
+}
@@ -40,3 +40,7 @@ func ValidateCreateUserMiddleware(logger *zap.Logger) fiber.Handler {
 		return c.Next()
 	}
 }
+
+func ValidatePatchUserMiddleware(logger *zap.Logger) fiber.Handler {
+	return validateJSONBody[dto.PatchUserRequest](logger)
+}
@@ -0,0 +1,63 @@
+package dto_validator
+
+import (
+	"edjr-trk/internal/api/dto"
+	"edjr-trk/internal/model"
+	"edjr-trk/pkg/http_error"
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+	"strings"
+)
+
+func ValidateLeadIdMiddleware(logger *zap.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		leadID := c.Params("id")
+		if !primitive.IsValidObjectID(leadID) {
+			logger.Error("Invalid lead ID", zap.String("id", leadID))
+			return http_error.NewHTTPError(fiber.StatusBadRequest, "Invalid lead ID", nil).Send(c)
+		}
+
+		c.Locals("leadID", leadID)
+		return c.Next()
+	}
+}
+
+// ValidateLeadFilterMiddleware - разбирает ?reference=, ?status= и ?assignee= для списка обращений.
+func ValidateLeadFilterMiddleware(logger *zap.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		filter := model.LeadFilter{Reference: strings.ToUpper(strings.TrimSpace(c.Query("reference")))}
+
+		if status := c.Query("status"); status != "" {
+			if !model.IsValidLeadStatus(status) {
+				logger.Warn("Invalid lead status filter", zap.String("status", status))
+				return http_error.NewHTTPError(fiber.StatusBadRequest, "Invalid lead filter", []http_error.ErrorItem{
+					{Field: "status", Error: "Unknown lead status"},
+				}).Send(c)
+			}
+			filter.Status = status
+		}
+
+		if assignee := c.Query("assignee"); assignee != "" {
+			assigneeID, err := primitive.ObjectIDFromHex(assignee)
+			if err != nil {
+				logger.Warn("Invalid lead assignee filter", zap.String("assignee", assignee))
+				return http_error.NewHTTPError(fiber.StatusBadRequest, "Invalid lead filter", []http_error.ErrorItem{
+					{Field: "assignee", Error: "The field must be a valid ID"},
+				}).Send(c)
+			}
+			filter.AssigneeID = &assigneeID
+		}
+
+		c.Locals("leadFilter", filter)
+		return c.Next()
+	}
+}
+
+func ValidatePatchLeadMiddleware(logger *zap.Logger) fiber.Handler {
+	return validateJSONBody[dto.PatchLeadRequest](logger)
+}
+
+func ValidateAddLeadNoteMiddleware(logger *zap.Logger) fiber.Handler {
+	return validateJSONBody[dto.AddLeadNoteRequest](logger)
+}
@@ -0,0 +1,41 @@
+package dto_validator
+
+import (
+	"edjr-trk/configs/locale"
+	"edjr-trk/internal/model"
+	"edjr-trk/pkg/http_error"
+	"edjr-trk/pkg/utils"
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+	"strings"
+)
+
+// ValidateLocaleMiddleware - выбирает язык ответа: ?lang= важнее Accept-Language,
+// дальше пробуются CONTENT_FALLBACK_LOCALES. Неподдерживаемый ?lang= - ошибка 400.
+func ValidateLocaleMiddleware(logger *zap.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		requested := strings.ToLower(strings.TrimSpace(c.Query("lang")))
+		if requested != "" && !locale.IsSupported(requested) {
+			logger.Warn("Unsupported locale requested", zap.String("lang", requested))
+			return http_error.NewHTTPError(fiber.StatusBadRequest, "Unsupported locale", []http_error.ErrorItem{
+				{Field: "lang", Error: fmt.Sprintf("The locale must be one of: %s", strings.Join(locale.Supported(), ", "))},
+			}).Send(c)
+		}
+
+		if requested == "" {
+			for _, lang := range utils.ParseAcceptLanguage(c.Get(fiber.HeaderAcceptLanguage)) {
+				if locale.IsSupported(lang) {
+					requested = lang
+					break
+				}
+			}
+		}
+
+		c.Locals("localePreference", model.NewLocalePreference(requested, locale.Fallback(), locale.Default()))
+		// Ответ зависит от Accept-Language - кэши должны это учитывать
+		c.Vary(fiber.HeaderAcceptLanguage)
+
+		return c.Next()
+	}
+}
@@ -0,0 +1,26 @@
+package dto_validator
+
+import (
+	"edjr-trk/pkg/http_error"
+	"edjr-trk/pkg/imaging"
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+)
+
+func ValidateMediaIdMiddleware(logger *zap.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		mediaID := c.Params("id")
+		if !primitive.IsValidObjectID(mediaID) {
+			logger.Error("Invalid media ID in the request", zap.String("id", mediaID))
+			return http_error.NewHTTPError(fiber.StatusBadRequest, "Invalid media ID", nil).Send(c)
+		}
+
+		// Без указания варианта отдаём оригинал
+		variant := c.Params("variant", imaging.VariantFull)
+
+		c.Locals("mediaID", mediaID)
+		c.Locals("mediaVariant", variant)
+		return c.Next()
+	}
+}
@@ -0,0 +1,98 @@
+package dto_validator
+
+import (
+	"edjr-trk/configs/media"
+	"edjr-trk/pkg/http_error"
+	"edjr-trk/pkg/imaging"
+	"encoding/base64"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// parseMultipartParts - for multipart requests reads the parts that BodyParser cannot handle:
+// the image file part "img" into *img and, if publishAt is not nil, the RFC 3339 time "publishAt".
+// Returns the error response to send, or nil when the request may proceed.
+func parseMultipartParts(c *fiber.Ctx, logger *zap.Logger, img **string, publishAt **time.Time) *http_error.HTTPError {
+	if !isMultipartRequest(c) {
+		return nil
+	}
+
+	file, errorDetails, err := parseImagePart(c, "img")
+	if err != nil {
+		logger.Error("Failed to read image file", zap.Error(err))
+		return http_error.NewHTTPError(fiber.StatusBadRequest, "Invalid request body", nil)
+	}
+	if errorDetails != nil {
+		return http_error.NewHTTPError(fiber.StatusBadRequest, "Validation error", errorDetails)
+	}
+	if file != nil {
+		*img = file
+	}
+
+	if value := c.FormValue("publishAt"); publishAt != nil && value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return http_error.NewHTTPError(fiber.StatusBadRequest, "Validation error", []http_error.ErrorItem{
+				{Field: "publishAt", Error: "The field must be an RFC 3339 date-time, e.g. 2025-01-31T09:00:00Z"},
+			})
+		}
+		*publishAt = &parsed
+	}
+
+	return nil
+}
+
+// isMultipartRequest checks whether the request body is multipart/form-data.
+func isMultipartRequest(c *fiber.Ctx) bool {
+	return strings.HasPrefix(strings.ToLower(c.Get(fiber.HeaderContentType)), fiber.MIMEMultipartForm)
+}
+
+// parseImagePart reads an image file part and converts it to a base64 data URI,
+// so the rest of the pipeline handles it exactly like a JSON image.
+// Returns nil when the part is absent; field-level errors are returned as ErrorItem.
+func parseImagePart(c *fiber.Ctx, field string) (*string, []http_error.ErrorItem, error) {
+	form, err := c.MultipartForm()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	files := form.File[field]
+	if len(files) == 0 {
+		return nil, nil, nil
+	}
+	fileHeader := files[0]
+
+	maxSize := int64(media.MaxUploadSize())
+	tooLarge := []http_error.ErrorItem{{Field: field, Error: "The file exceeds the maximum size of " + strconv.FormatInt(maxSize, 10) + " bytes"}}
+	if fileHeader.Size > maxSize {
+		return nil, tooLarge, nil
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxSize+1))
+	if err != nil {
+		return nil, nil, err
+	}
+	if int64(len(data)) > maxSize {
+		return nil, tooLarge, nil
+	}
+
+	// Не доверяем Content-Type из запроса - определяем тип по содержимому
+	contentType := http.DetectContentType(data)
+	if !imaging.IsUploadType(contentType) {
+		return nil, []http_error.ErrorItem{{Field: field, Error: "The file must be a JPEG, PNG, GIF or WebP image"}}, nil
+	}
+
+	dataURI := "data:" + contentType + ";base64," + base64.StdEncoding.EncodeToString(data)
+	return &dataURI, nil, nil
+}
@@ -0,0 +1,38 @@
+package dto_validator
+
+import (
+	"edjr-trk/internal/model"
+	"edjr-trk/pkg/http_error"
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+)
+
+func ValidateOutboxIdMiddleware(logger *zap.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		messageID := c.Params("id")
+		if !primitive.IsValidObjectID(messageID) {
+			logger.Error("Invalid outbox message ID", zap.String("id", messageID))
+			return http_error.NewHTTPError(fiber.StatusBadRequest, "Invalid message ID", nil).Send(c)
+		}
+
+		c.Locals("messageID", messageID)
+		return c.Next()
+	}
+}
+
+// ValidateOutboxFilterMiddleware - проверяет ?status= для списка писем в очереди.
+func ValidateOutboxFilterMiddleware(logger *zap.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		status := c.Query("status")
+		if status != "" && !model.IsValidOutboxStatus(status) {
+			logger.Warn("Invalid outbox status filter", zap.String("status", status))
+			return http_error.NewHTTPError(fiber.StatusBadRequest, "Invalid outbox filter", []http_error.ErrorItem{
+				{Field: "status", Error: "Unknown message status"},
+			}).Send(c)
+		}
+
+		c.Locals("outboxStatus", status)
+		return c.Next()
+	}
+}
@@ -2,6 +2,7 @@ package dto_validator
 
 import (
 	"edjr-trk/pkg/http_error"
+	"edjr-trk/pkg/utils"
 	"github.com/gofiber/fiber/v2"
 	"go.uber.org/zap"
 	"strconv"
@@ -25,6 +26,20 @@ func ValidatePaginationMiddleware(logger *zap.Logger) fiber.Handler {
 			return http_error.NewHTTPError(fiber.StatusBadRequest, "Invalid page size", nil).Send(c)
 		}
 
+		// Opt-in cursor mode: "?cursor=" starts from the first page, a value continues from it
+		if c.Context().QueryArgs().Has("cursor") {
+			var cursor *utils.PageCursor
+			if cursorStr := c.Query("cursor"); cursorStr != "" {
+				cursor, err = utils.DecodePageCursor(cursorStr)
+				if err != nil {
+					logger.Error("Invalid page cursor", zap.String("cursor", cursorStr))
+					return http_error.NewHTTPError(fiber.StatusBadRequest, "Invalid page cursor", nil).Send(c)
+				}
+			}
+			c.Locals("cursorMode", true)
+			c.Locals("pageCursor", cursor)
+		}
+
 		// Store pagination parameters in context
 		c.Locals("pageNumber", pageNumber)
 		c.Locals("pageSize", pageSize)
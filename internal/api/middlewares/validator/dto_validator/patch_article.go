@@ -17,6 +17,11 @@ func ValidatePatchArticleMiddleware(logger *zap.Logger) fiber.Handler {
 			return http_error.NewHTTPError(fiber.StatusBadRequest, "Invalid request body", nil).Send(c)
 		}
 
+		// For multipart requests the image arrives as a file part and publishAt as an RFC 3339 string.
+		if httpErr := parseMultipartParts(c, logger, &req.Img, &req.PublishAt); httpErr != nil {
+			return httpErr.Send(c)
+		}
+
 		// validate the input data.
 		if err := validate.Struct(&req); err != nil {
 			logger.Error("Validation failed for request body", zap.Error(err))
@@ -17,6 +17,11 @@ func ValidatePatchProductMiddleware(logger *zap.Logger) fiber.Handler {
 			return http_error.NewHTTPError(fiber.StatusBadRequest, "Invalid request body", nil).Send(c)
 		}
 
+		// For multipart requests the image arrives as a file part.
+		if httpErr := parseMultipartParts(c, logger, &req.Img, nil); httpErr != nil {
+			return httpErr.Send(c)
+		}
+
 		if err := validate.Struct(&req); err != nil {
 			logger.Error("Validation failed for request body", zap.Error(err))
 
@@ -1,6 +1,9 @@
 package dto_validator
 
 import (
+	"edjr-trk/configs/locale"
+	"edjr-trk/internal/model"
+	"edjr-trk/pkg/utils"
 	"github.com/go-playground/validator/v10"
 	"regexp"
 )
@@ -15,5 +18,17 @@ func init() {
 		re := regexp.MustCompile(emailRegex)
 		return re.MatchString(fl.Field().String())
 	})
-	validate.RegisterValidation("img_base64_or_null", imgBase64OrNull)
+	validate.RegisterValidation("img", imgDataURI)
+	validate.RegisterValidation("slug", func(fl validator.FieldLevel) bool {
+		return utils.IsValidSlug(fl.Field().String())
+	})
+	validate.RegisterValidation("locale", func(fl validator.FieldLevel) bool {
+		return locale.IsSupported(fl.Field().String())
+	})
+	validate.RegisterValidation("role", func(fl validator.FieldLevel) bool {
+		return model.IsValidRole(fl.Field().String())
+	})
+	validate.RegisterValidation("lead_status", func(fl validator.FieldLevel) bool {
+		return model.IsValidLeadStatus(fl.Field().String())
+	})
 }
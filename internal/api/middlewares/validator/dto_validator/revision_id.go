@@ -0,0 +1,47 @@
+package dto_validator
+
+import (
+	"edjr-trk/pkg/http_error"
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+)
+
+func ValidateRevisionIdMiddleware(logger *zap.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		revisionID := c.Params("revisionId")
+		if !primitive.IsValidObjectID(revisionID) {
+			logger.Error("Invalid revision ID", zap.String("revisionId", revisionID))
+			return http_error.NewHTTPError(fiber.StatusBadRequest, "Invalid revision ID", nil).Send(c)
+		}
+
+		// Store the revision ID in context for use in the handler.
+		c.Locals("revisionID", revisionID)
+
+		return c.Next()
+	}
+}
+
+// ValidateRevisionDiffMiddleware - проверяет пару ревизий ?from=&to= для сравнения.
+func ValidateRevisionDiffMiddleware(logger *zap.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		from, to := c.Query("from"), c.Query("to")
+
+		var errorsList []http_error.ErrorItem
+		if !primitive.IsValidObjectID(from) {
+			errorsList = append(errorsList, http_error.ErrorItem{Field: "from", Error: "The field must be a revision ID"})
+		}
+		if !primitive.IsValidObjectID(to) {
+			errorsList = append(errorsList, http_error.ErrorItem{Field: "to", Error: "The field must be a revision ID"})
+		}
+		if len(errorsList) > 0 {
+			logger.Error("Invalid revision diff parameters", zap.String("from", from), zap.String("to", to))
+			return http_error.NewHTTPError(fiber.StatusBadRequest, "Invalid revision IDs", errorsList).Send(c)
+		}
+
+		c.Locals("revisionFrom", from)
+		c.Locals("revisionTo", to)
+
+		return c.Next()
+	}
+}
@@ -0,0 +1,29 @@
+package dto_validator
+
+import (
+	"edjr-trk/pkg/http_error"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxSearchQueryLength - ограничение длины поискового запроса в символах.
+const maxSearchQueryLength = 200
+
+func ValidateSearchQueryMiddleware(logger *zap.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		query := strings.TrimSpace(c.Query("q"))
+		if utf8.RuneCountInString(query) > maxSearchQueryLength {
+			logger.Error("Search query is too long", zap.Int("length", utf8.RuneCountInString(query)))
+			return http_error.NewHTTPError(fiber.StatusBadRequest, "Search query is too long", []http_error.ErrorItem{
+				{Field: "q", Error: "The field must not exceed 200 characters"},
+			}).Send(c)
+		}
+
+		// Store the search query in context; empty means a regular listing.
+		c.Locals("searchQuery", query)
+
+		return c.Next()
+	}
+}
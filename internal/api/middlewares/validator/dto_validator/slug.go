@@ -0,0 +1,23 @@
+package dto_validator
+
+import (
+	"edjr-trk/pkg/http_error"
+	"edjr-trk/pkg/utils"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+func ValidateSlugMiddleware(logger *zap.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		slug := c.Params("slug")
+		if !utils.IsValidSlug(slug) {
+			logger.Error("Invalid slug", zap.String("slug", slug))
+			return http_error.NewHTTPError(fiber.StatusNotFound, "Not found", nil).Send(c)
+		}
+
+		// Store the slug in context for use in the handler.
+		c.Locals("slug", slug)
+
+		return c.Next()
+	}
+}
@@ -0,0 +1,54 @@
+package dto_validator
+
+import (
+	"edjr-trk/pkg/http_error"
+	"edjr-trk/pkg/utils"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+	"strings"
+)
+
+// maxTaxonomyFilterTerms - сколько тегов или категорий можно передать в одном запросе.
+const maxTaxonomyFilterTerms = 20
+
+// ValidateTaxonomyFilterMiddleware - разбирает ?tag= и ?category= (через запятую или повтором параметра).
+// Отсутствующий параметр сохраняется как nil, то есть без фильтра.
+func ValidateTaxonomyFilterMiddleware(logger *zap.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		for _, param := range []struct{ query, local string }{
+			{"tag", "tagSlugs"},
+			{"category", "categorySlugs"},
+		} {
+			slugs, ok := parseSlugList(c, param.query)
+			if !ok {
+				logger.Warn("Invalid taxonomy filter", zap.String("param", param.query))
+				return http_error.NewHTTPError(fiber.StatusBadRequest, "Invalid taxonomy filter", []http_error.ErrorItem{
+					{Field: param.query, Error: "The field must be a comma-separated list of up to 20 slugs"},
+				}).Send(c)
+			}
+			c.Locals(param.local, slugs)
+		}
+
+		return c.Next()
+	}
+}
+
+func parseSlugList(c *fiber.Ctx, param string) ([]string, bool) {
+	values := c.Context().QueryArgs().PeekMulti(param)
+	if len(values) == 0 {
+		return nil, true
+	}
+
+	slugs := make([]string, 0, len(values))
+	for _, value := range values {
+		for _, slug := range strings.Split(string(value), ",") {
+			slug = strings.TrimSpace(slug)
+			if !utils.IsValidSlug(slug) {
+				return nil, false
+			}
+			slugs = append(slugs, slug)
+		}
+	}
+
+	return slugs, len(slugs) <= maxTaxonomyFilterTerms
+}
@@ -0,0 +1,23 @@
+package dto_validator
+
+import (
+	"edjr-trk/pkg/http_error"
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+)
+
+func ValidateTermIdMiddleware(logger *zap.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		termID := c.Params("id")
+		if !primitive.IsValidObjectID(termID) {
+			logger.Error("Invalid term ID", zap.String("id", termID))
+			return http_error.NewHTTPError(fiber.StatusBadRequest, "Invalid term ID", nil).Send(c)
+		}
+
+		// Store the term ID in context for use in the handler.
+		c.Locals("termID", termID)
+
+		return c.Next()
+	}
+}
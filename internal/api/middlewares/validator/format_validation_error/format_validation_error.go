@@ -1,8 +1,16 @@
 package format_validation_error
 
 import (
+	"edjr-trk/configs/locale"
+	"edjr-trk/configs/media"
+	"edjr-trk/internal/model"
 	"edjr-trk/pkg/http_error"
+	"edjr-trk/pkg/imaging"
+	"edjr-trk/pkg/utils"
+	"errors"
+	"fmt"
 	"github.com/go-playground/validator/v10"
+	"strings"
 )
 
 // FormatValidationErrors formats validation errors into an array of ErrorItem.
@@ -11,10 +19,16 @@ func FormatValidationErrors(validationErrors validator.ValidationErrors) []http_
 
 	// Опционально можно заранее определить сообщения по тегам:
 	errorMessages := map[string]string{
-		"required":           "This field is required",
-		"min":                "The field does not meet the minimum length requirement",
-		"img_base64_or_null": "The field must be null or a valid Base64 string",
-		"custom_email":       "Invalid email",
+		"required":      "This field is required",
+		"min":           "The field does not meet the minimum length requirement",
+		"max":           "The field exceeds the maximum length",
+		"custom_email":  "Invalid email",
+		"mongodb":       "The field must be a valid ID",
+		"len=0|mongodb": "The field must be a valid ID or an empty string",
+		"locale":        fmt.Sprintf("The locale must be one of: %s", strings.Join(locale.Supported(), ", ")),
+		"role":          fmt.Sprintf("The role must be one of: %s", strings.Join(model.Roles(), ", ")),
+		"lead_status":   fmt.Sprintf("The status must be one of: %s", strings.Join(model.LeadStatuses(), ", ")),
+		"slug":          fmt.Sprintf("The slug may contain only lowercase latin letters, digits and single dashes, up to %d characters", utils.MaxSlugLength),
 	}
 
 	for _, validationErr := range validationErrors {
@@ -22,6 +36,9 @@ func FormatValidationErrors(validationErrors validator.ValidationErrors) []http_
 		tag := validationErr.Tag()
 
 		errorMsg, ok := errorMessages[tag]
+		if tag == "img" {
+			errorMsg, ok = imageErrorMessage(validationErr.Value()), true
+		}
 		if !ok {
 			errorMsg = "Validation failed on tag: " + tag
 		}
@@ -34,3 +51,23 @@ func FormatValidationErrors(validationErrors validator.ValidationErrors) []http_
 
 	return errorDetails
 }
+
+// imageErrorMessage - сообщение о первой проваленной проверке изображения (тег img).
+// Проверка повторяется только для невалидного значения.
+func imageErrorMessage(value interface{}) string {
+	str, _ := value.(string)
+	if ptr, ok := value.(*string); ok && ptr != nil {
+		str = *ptr
+	}
+
+	switch err := imaging.CheckDataURI(str, media.ImageLimits()); {
+	case errors.Is(err, imaging.ErrContentMismatch):
+		return "The image content does not match the declared MIME type"
+	case errors.Is(err, imaging.ErrTooLarge):
+		return fmt.Sprintf("The image must not exceed %d bytes", media.MaxUploadSize())
+	case errors.Is(err, imaging.ErrUnsupportedImage), errors.Is(err, imaging.ErrTooManyPixels):
+		return fmt.Sprintf("The image must be a decodable image no larger than %dx%d pixels", media.MaxImageWidth(), media.MaxImageHeight())
+	default:
+		return "The field must be null or a valid Base64 JPEG, PNG, GIF or WebP data URI"
+	}
+}
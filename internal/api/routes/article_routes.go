@@ -4,37 +4,135 @@ import (
 	"edjr-trk/internal/api/middlewares/auth"
 	"edjr-trk/internal/api/middlewares/validator/dto_validator"
 	"edjr-trk/internal/ioc"
+	"edjr-trk/internal/model"
 	"github.com/gofiber/fiber/v2"
 )
 
 // RegisterArticleRoutes - регистрирует маршруты для работы со статьями
 func RegisterArticleRoutes(app fiber.Router, container *ioc.Container) {
 	app.Post("/articles",
-		auth.JwtAuthMiddleware(container.JwtService),
+		auth.JwtAuthMiddleware(container.JwtService, container.AuthService),
+		auth.RequirePermission(model.PermArticlesWrite),
 		dto_validator.ValidateCreateArticleMiddleware(container.Logger),
 		container.ArticleHandler.CreateArticle,
 	)
 
 	app.Patch("/articles/:id",
-		auth.JwtAuthMiddleware(container.JwtService),
+		auth.JwtAuthMiddleware(container.JwtService, container.AuthService),
+		auth.RequirePermission(model.PermArticlesWrite),
 		dto_validator.ValidateArticleIdMiddleware(container.Logger),
+		container.ArticleHandler.RequireArticleAuthor,
 		dto_validator.ValidatePatchArticleMiddleware(container.Logger),
 		container.ArticleHandler.PatchArticleById,
 	)
 
 	app.Delete("/articles/:id",
-		auth.JwtAuthMiddleware(container.JwtService),
+		auth.JwtAuthMiddleware(container.JwtService, container.AuthService),
+		auth.RequirePermission(model.PermArticlesWrite),
 		dto_validator.ValidateArticleIdMiddleware(container.Logger),
+		container.ArticleHandler.RequireArticleAuthor,
 		container.ArticleHandler.RemoveArticleById,
 	)
 
+	app.Post("/articles/:id/publish",
+		auth.JwtAuthMiddleware(container.JwtService, container.AuthService),
+		auth.RequirePermission(model.PermArticlesWrite),
+		dto_validator.ValidateArticleIdMiddleware(container.Logger),
+		container.ArticleHandler.RequireArticleAuthor,
+		container.ArticleHandler.PublishArticle,
+	)
+
+	app.Post("/articles/:id/unpublish",
+		auth.JwtAuthMiddleware(container.JwtService, container.AuthService),
+		auth.RequirePermission(model.PermArticlesWrite),
+		dto_validator.ValidateArticleIdMiddleware(container.Logger),
+		container.ArticleHandler.RequireArticleAuthor,
+		container.ArticleHandler.UnpublishArticle,
+	)
+
+	app.Get("/admin/articles",
+		auth.JwtAuthMiddleware(container.JwtService, container.AuthService),
+		auth.RequirePermission(model.PermContentRead),
+		dto_validator.ValidatePaginationMiddleware(container.Logger),
+		dto_validator.ValidateSearchQueryMiddleware(container.Logger),
+		dto_validator.ValidateArticleStatusFilterMiddleware(container.Logger),
+		dto_validator.ValidateTaxonomyFilterMiddleware(container.Logger),
+		dto_validator.ValidateLocaleMiddleware(container.Logger),
+		container.ArticleHandler.GetAdminArticles,
+	)
+
+	app.Get("/admin/articles/:id",
+		auth.JwtAuthMiddleware(container.JwtService, container.AuthService),
+		auth.RequirePermission(model.PermContentRead),
+		dto_validator.ValidateArticleIdMiddleware(container.Logger),
+		dto_validator.ValidateLocaleMiddleware(container.Logger),
+		container.ArticleHandler.GetAdminArticleById,
+	)
+
+	app.Get("/articles/by-slug/:slug",
+		dto_validator.ValidateSlugMiddleware(container.Logger),
+		dto_validator.ValidateLocaleMiddleware(container.Logger),
+		container.ArticleHandler.GetArticleBySlug,
+	)
+
 	app.Get("/articles/:id",
 		dto_validator.ValidateArticleIdMiddleware(container.Logger),
+		dto_validator.ValidateLocaleMiddleware(container.Logger),
 		container.ArticleHandler.GetArticleById,
 	)
 
 	app.Get("/articles",
 		dto_validator.ValidatePaginationMiddleware(container.Logger),
+		dto_validator.ValidateSearchQueryMiddleware(container.Logger),
+		dto_validator.ValidateTaxonomyFilterMiddleware(container.Logger),
+		dto_validator.ValidateLocaleMiddleware(container.Logger),
 		container.ArticleHandler.GetAllArticles,
 	)
+
+	app.Get("/articles/:id/revisions",
+		auth.JwtAuthMiddleware(container.JwtService, container.AuthService),
+		auth.RequirePermission(model.PermContentRead),
+		dto_validator.ValidateArticleIdMiddleware(container.Logger),
+		dto_validator.ValidatePaginationMiddleware(container.Logger),
+		container.ArticleHandler.GetArticleRevisions,
+	)
+
+	app.Get("/articles/:id/revisions/diff",
+		auth.JwtAuthMiddleware(container.JwtService, container.AuthService),
+		auth.RequirePermission(model.PermContentRead),
+		dto_validator.ValidateArticleIdMiddleware(container.Logger),
+		dto_validator.ValidateRevisionDiffMiddleware(container.Logger),
+		container.ArticleHandler.DiffArticleRevisions,
+	)
+
+	app.Get("/articles/:id/revisions/:revisionId",
+		auth.JwtAuthMiddleware(container.JwtService, container.AuthService),
+		auth.RequirePermission(model.PermContentRead),
+		dto_validator.ValidateArticleIdMiddleware(container.Logger),
+		dto_validator.ValidateRevisionIdMiddleware(container.Logger),
+		container.ArticleHandler.GetArticleRevision,
+	)
+
+	app.Post("/articles/:id/revisions/:revisionId/restore",
+		auth.JwtAuthMiddleware(container.JwtService, container.AuthService),
+		auth.RequirePermission(model.PermArticlesWrite),
+		dto_validator.ValidateArticleIdMiddleware(container.Logger),
+		container.ArticleHandler.RequireArticleAuthor,
+		dto_validator.ValidateRevisionIdMiddleware(container.Logger),
+		container.ArticleHandler.RestoreArticleRevision,
+	)
+
+	app.Get("/admin/trash/articles",
+		auth.JwtAuthMiddleware(container.JwtService, container.AuthService),
+		auth.RequirePermission(model.PermTrashManage),
+		dto_validator.ValidatePaginationMiddleware(container.Logger),
+		container.ArticleHandler.GetDeletedArticles,
+	)
+
+	app.Post("/admin/trash/articles/:id/restore",
+		auth.JwtAuthMiddleware(container.JwtService, container.AuthService),
+		auth.RequirePermission(model.PermTrashManage),
+		dto_validator.ValidateArticleIdMiddleware(container.Logger),
+		container.ArticleHandler.RestoreArticleById,
+	)
 }
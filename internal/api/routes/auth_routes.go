@@ -1,6 +1,7 @@
 package routes
 
 import (
+	"edjr-trk/internal/api/middlewares/auth"
 	"edjr-trk/internal/api/middlewares/validator/dto_validator"
 	"edjr-trk/internal/ioc"
 	"github.com/gofiber/fiber/v2"
@@ -11,4 +12,49 @@ func RegisterAuthRoutes(app fiber.Router, container *ioc.Container) {
 		dto_validator.ValidateLoginMiddleware(container.Logger),
 		container.AuthHandler.Login,
 	)
+
+	app.Post("/auth/login/2fa",
+		dto_validator.ValidateLoginTwoFactorMiddleware(container.Logger),
+		container.AuthHandler.LoginTwoFactor,
+	)
+
+	app.Post("/auth/refresh",
+		dto_validator.ValidateRefreshTokenMiddleware(container.Logger),
+		container.AuthHandler.Refresh,
+	)
+
+	app.Post("/auth/logout",
+		dto_validator.ValidateLogoutMiddleware(container.Logger),
+		container.AuthHandler.Logout,
+	)
+
+	// Восстановление пароля по email
+	app.Post("/auth/password/forgot",
+		dto_validator.RateLimiterMiddleware(container.Logger, container.PasswordResetLimiter),
+		dto_validator.ValidateForgotPasswordMiddleware(container.Logger),
+		container.PasswordHandler.ForgotPassword,
+	)
+
+	app.Post("/auth/password/reset",
+		dto_validator.ValidateResetPasswordMiddleware(container.Logger),
+		container.PasswordHandler.ResetPassword,
+	)
+
+	// Подключение 2FA для текущего пользователя
+	app.Post("/auth/2fa/setup",
+		auth.JwtAuthMiddleware(container.JwtService, container.AuthService),
+		container.AuthHandler.SetupTwoFactor,
+	)
+
+	app.Post("/auth/2fa/enable",
+		auth.JwtAuthMiddleware(container.JwtService, container.AuthService),
+		dto_validator.ValidateTwoFactorCodeMiddleware(container.Logger),
+		container.AuthHandler.EnableTwoFactor,
+	)
+
+	app.Post("/auth/2fa/disable",
+		auth.JwtAuthMiddleware(container.JwtService, container.AuthService),
+		dto_validator.ValidateTwoFactorCodeMiddleware(container.Logger),
+		container.AuthHandler.DisableTwoFactor,
+	)
 }
@@ -0,0 +1,43 @@
+package routes
+
+import (
+	"edjr-trk/internal/api/middlewares/auth"
+	"edjr-trk/internal/api/middlewares/validator/dto_validator"
+	"edjr-trk/internal/ioc"
+	"edjr-trk/internal/model"
+	"github.com/gofiber/fiber/v2"
+)
+
+// RegisterLeadRoutes - регистрирует маршруты inbox обращений из формы обратной связи
+func RegisterLeadRoutes(app fiber.Router, container *ioc.Container) {
+	app.Get("/admin/leads",
+		auth.JwtAuthMiddleware(container.JwtService, container.AuthService),
+		auth.RequirePermission(model.PermLeadsManage),
+		dto_validator.ValidatePaginationMiddleware(container.Logger),
+		dto_validator.ValidateLeadFilterMiddleware(container.Logger),
+		container.LeadHandler.GetLeads,
+	)
+
+	app.Get("/admin/leads/:id",
+		auth.JwtAuthMiddleware(container.JwtService, container.AuthService),
+		auth.RequirePermission(model.PermLeadsManage),
+		dto_validator.ValidateLeadIdMiddleware(container.Logger),
+		container.LeadHandler.GetLeadById,
+	)
+
+	app.Patch("/admin/leads/:id",
+		auth.JwtAuthMiddleware(container.JwtService, container.AuthService),
+		auth.RequirePermission(model.PermLeadsManage),
+		dto_validator.ValidateLeadIdMiddleware(container.Logger),
+		dto_validator.ValidatePatchLeadMiddleware(container.Logger),
+		container.LeadHandler.PatchLeadById,
+	)
+
+	app.Post("/admin/leads/:id/notes",
+		auth.JwtAuthMiddleware(container.JwtService, container.AuthService),
+		auth.RequirePermission(model.PermLeadsManage),
+		dto_validator.ValidateLeadIdMiddleware(container.Logger),
+		dto_validator.ValidateAddLeadNoteMiddleware(container.Logger),
+		container.LeadHandler.AddLeadNote,
+	)
+}
@@ -0,0 +1,15 @@
+package routes
+
+import (
+	"edjr-trk/internal/api/middlewares/validator/dto_validator"
+	"edjr-trk/internal/ioc"
+	"github.com/gofiber/fiber/v2"
+)
+
+// RegisterMediaRoutes - регистрирует маршруты для отдачи медиафайлов
+func RegisterMediaRoutes(app fiber.Router, container *ioc.Container) {
+	app.Get("/media/:id/:variant?",
+		dto_validator.ValidateMediaIdMiddleware(container.Logger),
+		container.MediaHandler.GetMediaById,
+	)
+}
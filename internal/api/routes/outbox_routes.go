@@ -0,0 +1,34 @@
+package routes
+
+import (
+	"edjr-trk/internal/api/middlewares/auth"
+	"edjr-trk/internal/api/middlewares/validator/dto_validator"
+	"edjr-trk/internal/ioc"
+	"edjr-trk/internal/model"
+	"github.com/gofiber/fiber/v2"
+)
+
+// RegisterOutboxRoutes - регистрирует маршруты очереди исходящей почты
+func RegisterOutboxRoutes(app fiber.Router, container *ioc.Container) {
+	app.Get("/admin/outbox",
+		auth.JwtAuthMiddleware(container.JwtService, container.AuthService),
+		auth.RequirePermission(model.PermOutboxManage),
+		dto_validator.ValidatePaginationMiddleware(container.Logger),
+		dto_validator.ValidateOutboxFilterMiddleware(container.Logger),
+		container.OutboxHandler.GetMessages,
+	)
+
+	app.Get("/admin/outbox/:id",
+		auth.JwtAuthMiddleware(container.JwtService, container.AuthService),
+		auth.RequirePermission(model.PermOutboxManage),
+		dto_validator.ValidateOutboxIdMiddleware(container.Logger),
+		container.OutboxHandler.GetMessageById,
+	)
+
+	app.Post("/admin/outbox/:id/requeue",
+		auth.JwtAuthMiddleware(container.JwtService, container.AuthService),
+		auth.RequirePermission(model.PermOutboxManage),
+		dto_validator.ValidateOutboxIdMiddleware(container.Logger),
+		container.OutboxHandler.RequeueMessage,
+	)
+}
@@ -4,36 +4,96 @@ import (
 	"edjr-trk/internal/api/middlewares/auth"
 	"edjr-trk/internal/api/middlewares/validator/dto_validator"
 	"edjr-trk/internal/ioc"
+	"edjr-trk/internal/model"
 	"github.com/gofiber/fiber/v2"
 )
 
 func RegisterProductRoutes(app fiber.Router, container *ioc.Container) {
 	app.Post("/projects",
-		auth.JwtAuthMiddleware(container.JwtService),
+		auth.JwtAuthMiddleware(container.JwtService, container.AuthService),
+		auth.RequirePermission(model.PermProjectsWrite),
 		dto_validator.ValidateCreateProductMiddleware(container.Logger),
 		container.ProductHandler.CreateProduct,
 	)
 
 	app.Patch("/projects/:id",
-		auth.JwtAuthMiddleware(container.JwtService),
+		auth.JwtAuthMiddleware(container.JwtService, container.AuthService),
+		auth.RequirePermission(model.PermProjectsWrite),
 		dto_validator.ValidateProductIdMiddleware(container.Logger),
 		dto_validator.ValidatePatchProductMiddleware(container.Logger),
 		container.ProductHandler.PatchProductById,
 	)
 
 	app.Delete("/projects/:id",
-		auth.JwtAuthMiddleware(container.JwtService),
+		auth.JwtAuthMiddleware(container.JwtService, container.AuthService),
+		auth.RequirePermission(model.PermProjectsWrite),
 		dto_validator.ValidateProductIdMiddleware(container.Logger),
 		container.ProductHandler.RemoveProductById,
 	)
 
+	app.Get("/projects/by-slug/:slug",
+		dto_validator.ValidateSlugMiddleware(container.Logger),
+		dto_validator.ValidateLocaleMiddleware(container.Logger),
+		container.ProductHandler.GetProductBySlug,
+	)
+
 	app.Get("/projects/:id",
 		dto_validator.ValidateProductIdMiddleware(container.Logger),
+		dto_validator.ValidateLocaleMiddleware(container.Logger),
 		container.ProductHandler.GetProductById,
 	)
 
 	app.Get("/projects",
 		dto_validator.ValidatePaginationMiddleware(container.Logger),
+		dto_validator.ValidateSearchQueryMiddleware(container.Logger),
+		dto_validator.ValidateTaxonomyFilterMiddleware(container.Logger),
+		dto_validator.ValidateLocaleMiddleware(container.Logger),
 		container.ProductHandler.GetAllProducts,
 	)
+
+	app.Get("/projects/:id/revisions",
+		auth.JwtAuthMiddleware(container.JwtService, container.AuthService),
+		auth.RequirePermission(model.PermContentRead),
+		dto_validator.ValidateProductIdMiddleware(container.Logger),
+		dto_validator.ValidatePaginationMiddleware(container.Logger),
+		container.ProductHandler.GetProductRevisions,
+	)
+
+	app.Get("/projects/:id/revisions/diff",
+		auth.JwtAuthMiddleware(container.JwtService, container.AuthService),
+		auth.RequirePermission(model.PermContentRead),
+		dto_validator.ValidateProductIdMiddleware(container.Logger),
+		dto_validator.ValidateRevisionDiffMiddleware(container.Logger),
+		container.ProductHandler.DiffProductRevisions,
+	)
+
+	app.Get("/projects/:id/revisions/:revisionId",
+		auth.JwtAuthMiddleware(container.JwtService, container.AuthService),
+		auth.RequirePermission(model.PermContentRead),
+		dto_validator.ValidateProductIdMiddleware(container.Logger),
+		dto_validator.ValidateRevisionIdMiddleware(container.Logger),
+		container.ProductHandler.GetProductRevision,
+	)
+
+	app.Post("/projects/:id/revisions/:revisionId/restore",
+		auth.JwtAuthMiddleware(container.JwtService, container.AuthService),
+		auth.RequirePermission(model.PermProjectsWrite),
+		dto_validator.ValidateProductIdMiddleware(container.Logger),
+		dto_validator.ValidateRevisionIdMiddleware(container.Logger),
+		container.ProductHandler.RestoreProductRevision,
+	)
+
+	app.Get("/admin/trash/projects",
+		auth.JwtAuthMiddleware(container.JwtService, container.AuthService),
+		auth.RequirePermission(model.PermTrashManage),
+		dto_validator.ValidatePaginationMiddleware(container.Logger),
+		container.ProductHandler.GetDeletedProducts,
+	)
+
+	app.Post("/admin/trash/projects/:id/restore",
+		auth.JwtAuthMiddleware(container.JwtService, container.AuthService),
+		auth.RequirePermission(model.PermTrashManage),
+		dto_validator.ValidateProductIdMiddleware(container.Logger),
+		container.ProductHandler.RestoreProductById,
+	)
 }
@@ -0,0 +1,60 @@
+package routes
+
+import (
+	"edjr-trk/internal/api/middlewares/auth"
+	"edjr-trk/internal/api/middlewares/validator/dto_validator"
+	"edjr-trk/internal/ioc"
+	"edjr-trk/internal/model"
+	"github.com/gofiber/fiber/v2"
+)
+
+// RegisterTaxonomyRoutes - регистрирует маршруты для тегов и категорий
+func RegisterTaxonomyRoutes(app fiber.Router, container *ioc.Container) {
+	app.Get("/tags", container.TaxonomyHandler.GetTags)
+
+	app.Post("/tags",
+		auth.JwtAuthMiddleware(container.JwtService, container.AuthService),
+		auth.RequirePermission(model.PermTaxonomyWrite),
+		dto_validator.ValidateCreateTermMiddleware(container.Logger),
+		container.TaxonomyHandler.CreateTag,
+	)
+
+	app.Patch("/tags/:id",
+		auth.JwtAuthMiddleware(container.JwtService, container.AuthService),
+		auth.RequirePermission(model.PermTaxonomyWrite),
+		dto_validator.ValidateTermIdMiddleware(container.Logger),
+		dto_validator.ValidatePatchTermMiddleware(container.Logger),
+		container.TaxonomyHandler.PatchTag,
+	)
+
+	app.Delete("/tags/:id",
+		auth.JwtAuthMiddleware(container.JwtService, container.AuthService),
+		auth.RequirePermission(model.PermTaxonomyWrite),
+		dto_validator.ValidateTermIdMiddleware(container.Logger),
+		container.TaxonomyHandler.RemoveTag,
+	)
+
+	app.Get("/categories", container.TaxonomyHandler.GetCategories)
+
+	app.Post("/categories",
+		auth.JwtAuthMiddleware(container.JwtService, container.AuthService),
+		auth.RequirePermission(model.PermTaxonomyWrite),
+		dto_validator.ValidateCreateTermMiddleware(container.Logger),
+		container.TaxonomyHandler.CreateCategory,
+	)
+
+	app.Patch("/categories/:id",
+		auth.JwtAuthMiddleware(container.JwtService, container.AuthService),
+		auth.RequirePermission(model.PermTaxonomyWrite),
+		dto_validator.ValidateTermIdMiddleware(container.Logger),
+		dto_validator.ValidatePatchTermMiddleware(container.Logger),
+		container.TaxonomyHandler.PatchCategory,
+	)
+
+	app.Delete("/categories/:id",
+		auth.JwtAuthMiddleware(container.JwtService, container.AuthService),
+		auth.RequirePermission(model.PermTaxonomyWrite),
+		dto_validator.ValidateTermIdMiddleware(container.Logger),
+		container.TaxonomyHandler.RemoveCategory,
+	)
+}
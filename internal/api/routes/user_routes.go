@@ -4,26 +4,80 @@ import (
 	"edjr-trk/internal/api/middlewares/auth"
 	"edjr-trk/internal/api/middlewares/validator/dto_validator"
 	"edjr-trk/internal/ioc"
+	"edjr-trk/internal/model"
 	"github.com/gofiber/fiber/v2"
 )
 
 // RegisterUserRoutes - регистрирует маршруты для работы с user
 func RegisterUserRoutes(app fiber.Router, container *ioc.Container) {
 	app.Post("/users",
-		auth.BasicAuthMiddleware(),
+		auth.JwtOrBasicAuthMiddleware(container.JwtService, container.AuthService),
+		auth.RequirePermission(model.PermUsersManage),
 		dto_validator.ValidateCreateUserMiddleware(container.Logger),
 		container.UserHandler.CreateUser,
 	)
 
+	// Профиль текущего пользователя
+	app.Get("/users/me",
+		auth.JwtAuthMiddleware(container.JwtService, container.AuthService),
+		container.UserHandler.GetMe,
+	)
+
+	// Смена своего пароля, после неё все сессии завершаются
+	app.Put("/users/me/password",
+		auth.JwtAuthMiddleware(container.JwtService, container.AuthService),
+		dto_validator.ValidateChangePasswordMiddleware(container.Logger),
+		container.PasswordHandler.ChangePassword,
+	)
+
 	app.Delete("/users/:id",
-		auth.BasicAuthMiddleware(),
+		auth.JwtOrBasicAuthMiddleware(container.JwtService, container.AuthService),
+		auth.RequirePermission(model.PermUsersManage),
 		dto_validator.ValidateUserIdMiddleware(container.Logger),
 		container.UserHandler.RemoveUserById,
 	)
 
+	app.Patch("/users/:id",
+		auth.JwtOrBasicAuthMiddleware(container.JwtService, container.AuthService),
+		auth.RequirePermission(model.PermUsersManage),
+		dto_validator.ValidateUserIdMiddleware(container.Logger),
+		dto_validator.ValidatePatchUserMiddleware(container.Logger),
+		container.UserHandler.PatchUserById,
+	)
+
+	// Отключение без удаления: пользователь не может войти, его сессии завершаются
+	app.Post("/users/:id/disable",
+		auth.JwtOrBasicAuthMiddleware(container.JwtService, container.AuthService),
+		auth.RequirePermission(model.PermUsersManage),
+		dto_validator.ValidateUserIdMiddleware(container.Logger),
+		container.UserHandler.DisableUserById,
+	)
+
+	app.Post("/users/:id/enable",
+		auth.JwtOrBasicAuthMiddleware(container.JwtService, container.AuthService),
+		auth.RequirePermission(model.PermUsersManage),
+		dto_validator.ValidateUserIdMiddleware(container.Logger),
+		container.UserHandler.EnableUserById,
+	)
+
 	app.Get("/users",
-		auth.BasicAuthMiddleware(),
+		auth.JwtOrBasicAuthMiddleware(container.JwtService, container.AuthService),
+		auth.RequirePermission(model.PermUsersManage),
 		dto_validator.ValidatePaginationMiddleware(container.Logger),
 		container.UserHandler.GetAllUsers,
 	)
+
+	app.Get("/admin/trash/users",
+		auth.JwtOrBasicAuthMiddleware(container.JwtService, container.AuthService),
+		auth.RequirePermission(model.PermUsersManage),
+		dto_validator.ValidatePaginationMiddleware(container.Logger),
+		container.UserHandler.GetDeletedUsers,
+	)
+
+	app.Post("/admin/trash/users/:id/restore",
+		auth.JwtOrBasicAuthMiddleware(container.JwtService, container.AuthService),
+		auth.RequirePermission(model.PermUsersManage),
+		dto_validator.ValidateUserIdMiddleware(container.Logger),
+		container.UserHandler.RestoreUserById,
+	)
 }
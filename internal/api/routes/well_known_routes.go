@@ -0,0 +1,11 @@
+package routes
+
+import (
+	"edjr-trk/internal/ioc"
+	"github.com/gofiber/fiber/v2"
+)
+
+// RegisterWellKnownRoutes - регистрирует /.well-known маршруты в корне приложения, вне /api
+func RegisterWellKnownRoutes(app fiber.Router, container *ioc.Container) {
+	app.Get("/.well-known/jwks.json", container.JWKSHandler.GetJWKS)
+}
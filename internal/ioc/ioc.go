@@ -2,36 +2,65 @@ package ioc
 
 import (
 	"edjr-trk/configs/env"
+	"edjr-trk/configs/locale"
+	"edjr-trk/configs/mailtemplates"
 	"edjr-trk/configs/mongo"
 	"edjr-trk/internal/api/handlers"
 	"edjr-trk/internal/repository"
 	"edjr-trk/internal/service"
+	"edjr-trk/pkg/imaging"
+	"edjr-trk/pkg/jwtkeys"
 	"edjr-trk/pkg/log"
+	"edjr-trk/pkg/mailtmpl"
 	mongodb "go.mongodb.org/mongo-driver/mongo"
 	"go.uber.org/zap"
+	"strings"
 	"time"
 )
 
 // Container - структура для хранения зависимостей.
 type Container struct {
-	Logger           *zap.Logger
-	MongoClient      *mongodb.Client
-	ArticleRepo      repository.ArticleRepositoryInterface
-	ProductRepo      repository.ProductRepositoryInterface
-	UserRepo         repository.UserRepositoryInterface
-	EmailRepo        repository.EmailRepositoryInterface
-	ArticleService   service.ArticleServiceInterface
-	ProductService   service.ProductServiceInterface
-	UserService      service.UserServiceInterface
-	JwtService       service.JWTServiceInterface
-	AuthService      service.AuthServiceInterface
-	EmailService     service.EmailServiceInterface
-	RateLimitService *service.RateLimiter
-	ArticleHandler   *handlers.ArticleHandler
-	ProductHandler   *handlers.ProductHandler
-	UserHandler      handlers.UserHandlerInterface
-	AuthHandler      handlers.AuthHandlerInterface
-	EmailHandler     handlers.EmailHandlerInterface
+	Logger               *zap.Logger
+	MongoClient          *mongodb.Client
+	ArticleRepo          repository.ArticleRepositoryInterface
+	ProductRepo          repository.ProductRepositoryInterface
+	UserRepo             repository.UserRepositoryInterface
+	EmailRepo            repository.EmailRepositoryInterface
+	MediaRepo            repository.MediaRepositoryInterface
+	BlobRepo             repository.BlobRepositoryInterface
+	RevisionRepo         repository.RevisionRepositoryInterface
+	TaxonomyRepo         repository.TaxonomyRepositoryInterface
+	RefreshTokenRepo     repository.RefreshTokenRepositoryInterface
+	PasswordResetRepo    repository.PasswordResetRepositoryInterface
+	LeadRepo             repository.LeadRepositoryInterface
+	OutboxRepo           repository.OutboxRepositoryInterface
+	ArticleService       service.ArticleServiceInterface
+	ProductService       service.ProductServiceInterface
+	UserService          service.UserServiceInterface
+	JwtService           service.JWTServiceInterface
+	AuthService          service.AuthServiceInterface
+	TwoFactorService     service.TwoFactorServiceInterface
+	PasswordService      service.PasswordServiceInterface
+	EmailService         service.EmailServiceInterface
+	LeadService          service.LeadServiceInterface
+	EmailOutbox          *service.EmailOutbox
+	MediaService         service.MediaServiceInterface
+	RevisionService      service.RevisionServiceInterface
+	TaxonomyService      service.TaxonomyServiceInterface
+	RateLimitService     *service.RateLimiter
+	PasswordResetLimiter *service.RateLimiter
+	TrashPurger          *service.TrashPurger
+	ArticleHandler       *handlers.ArticleHandler
+	ProductHandler       *handlers.ProductHandler
+	UserHandler          handlers.UserHandlerInterface
+	AuthHandler          handlers.AuthHandlerInterface
+	PasswordHandler      handlers.PasswordHandlerInterface
+	EmailHandler         handlers.EmailHandlerInterface
+	LeadHandler          handlers.LeadHandlerInterface
+	OutboxHandler        handlers.OutboxHandlerInterface
+	MediaHandler         handlers.MediaHandlerInterface
+	TaxonomyHandler      *handlers.TaxonomyHandler
+	JWKSHandler          *handlers.JWKSHandler
 }
 
 // NewContainer - создаем контейнер с зависимостями.
@@ -52,42 +81,169 @@ func NewContainer() *Container {
 	articleRepo := repository.NewArticleRepository(clientDB, logger)
 	productRepo := repository.NewProductRepository(clientDB, logger)
 	userRepo := repository.NewUserRepository(clientDB, logger)
-	emailRepo := repository.NewSMTPEmailRepository("smtp.gmail.com", "587", logger)
+	mediaRepo := repository.NewMediaRepository(clientDB, logger)
+	revisionRepo := repository.NewRevisionRepository(clientDB, logger)
+	taxonomyRepo := repository.NewTaxonomyRepository(clientDB, logger)
+	refreshTokenRepo := repository.NewRefreshTokenRepository(clientDB, logger)
+	passwordResetRepo := repository.NewPasswordResetRepository(clientDB, logger)
+	leadRepo := repository.NewLeadRepository(clientDB, logger)
+	outboxRepo := repository.NewOutboxRepository(clientDB, logger)
+	blobRepo, err := repository.NewBlobRepository(clientDB, logger)
+	if err != nil {
+		logger.Fatal("Failed to initialize media storage", zap.Error(err))
+	}
+	// Транспорт почты: smtp, file (maildir) или http, см. MAIL_TRANSPORT
+	emailRepo, err := repository.NewEmailRepository(logger)
+	if err != nil {
+		logger.Fatal("Failed to initialize email transport", zap.Error(err))
+	}
+	// Шаблоны писем: встроенные, любой файл можно заменить в MAIL_TEMPLATES_DIR/<locale>/
+	mailTemplates, err := mailtmpl.New(mailtemplates.FS,
+		env.GetEnv("MAIL_TEMPLATES_DIR", ""),
+		strings.ToLower(env.GetEnv("MAIL_LOCALE", locale.Default())),
+	)
+	if err != nil {
+		logger.Fatal("Failed to load email templates", zap.Error(err))
+	}
+	imageVariants, err := imaging.ParseVariants(env.GetEnv("MEDIA_VARIANTS", "thumb:320,card:800"))
+	if err != nil {
+		logger.Fatal("Invalid MEDIA_VARIANTS", zap.Error(err))
+	}
 	// Create services
-	articleService := service.NewArticleService(articleRepo, logger)
-	productService := service.NewProductService(productRepo, logger)
-	userService := service.NewUserService(userRepo, logger)
-	jwtService := service.NewJWTService(env.GetEnv("JWT_KEY", ""), logger)
-	authService := service.NewAuthService(userRepo, jwtService, logger)
-	emailService := service.NewEmailService(emailRepo, logger)
+	mediaService := service.NewMediaService(mediaRepo, blobRepo, imageVariants, logger)
+	revisionService := service.NewRevisionService(revisionRepo, logger)
+	taxonomyService := service.NewTaxonomyService(taxonomyRepo, articleRepo, productRepo, logger)
+	articleService := service.NewArticleService(articleRepo, mediaService, revisionService, taxonomyService, logger)
+	productService := service.NewProductService(productRepo, mediaService, revisionService, taxonomyService, logger)
+	// Ключ подписи из PEM (RS256/EdDSA) или, для совместимости, секрет HS256
+	jwtKeys, err := jwtkeys.Load(jwtkeys.Config{
+		SigningKeyFile: env.GetEnv("JWT_SIGNING_KEY_FILE", ""),
+		SigningKeyID:   env.GetEnv("JWT_SIGNING_KEY_ID", ""),
+		VerifyKeyFiles: env.GetEnv("JWT_VERIFY_KEY_FILES", ""),
+		HMACSecret:     env.GetEnv("JWT_KEY", ""),
+	})
+	if err != nil {
+		logger.Fatal("Failed to load JWT keys", zap.Error(err))
+	}
+	jwtService := service.NewJWTService(jwtKeys,
+		env.GetEnv("JWT_ISSUER", "edjr-trk"),
+		env.GetEnv("JWT_AUDIENCE", "edjr-trk"),
+		logger,
+	)
+	// Вся почта уходит через очередь: повторы с паузой от OUTBOX_RETRY_BASE_SECONDS,
+	// удваивающейся до OUTBOX_RETRY_MAX_MINUTES; после OUTBOX_MAX_ATTEMPTS письмо становится dead
+	emailOutbox := service.NewEmailOutbox(outboxRepo, leadRepo, emailRepo, service.OutboxConfig{
+		Workers:      env.GetEnvInt("OUTBOX_WORKERS", 2),
+		MaxAttempts:  env.GetEnvInt("OUTBOX_MAX_ATTEMPTS", 8),
+		BaseDelay:    time.Duration(env.GetEnvInt("OUTBOX_RETRY_BASE_SECONDS", 30)) * time.Second,
+		MaxDelay:     time.Duration(env.GetEnvInt("OUTBOX_RETRY_MAX_MINUTES", 60)) * time.Minute,
+		PollInterval: time.Duration(env.GetEnvInt("OUTBOX_POLL_SECONDS", 10)) * time.Second,
+		Retention:    time.Duration(env.GetEnvInt("OUTBOX_RETENTION_DAYS", 30)) * 24 * time.Hour,
+	}, logger)
+	// Короткоживущие access токены и ротируемые refresh токены
+	twoFactorService := service.NewTwoFactorService(userRepo, env.GetEnv("TOTP_ISSUER", "edjr-trk"), logger)
+	// Защита входа от подбора: после каждой ошибки задержка удваивается (до минуты),
+	// после LOGIN_MAX_FAILURES ошибок для аккаунта или LOGIN_IP_MAX_FAILURES для IP - блокировка
+	loginLockout := time.Duration(env.GetEnvInt("LOGIN_LOCKOUT_MINUTES", 15)) * time.Minute
+	loginMaxKeys := env.GetEnvInt("LOGIN_MAX_TRACKED_KEYS", 100_000)
+	loginGuard := service.NewLoginGuard(
+		service.NewLoginThrottle(env.GetEnvInt("LOGIN_MAX_FAILURES", 5), time.Second, time.Minute, loginLockout, loginMaxKeys),
+		service.NewLoginThrottle(env.GetEnvInt("LOGIN_IP_MAX_FAILURES", 20), time.Second, time.Minute, loginLockout, loginMaxKeys),
+		emailOutbox,
+		mailTemplates,
+		logger,
+	)
+	authService := service.NewAuthService(userRepo, refreshTokenRepo, jwtService, twoFactorService, loginGuard,
+		time.Duration(env.GetEnvInt("JWT_ACCESS_TTL_MINUTES", 15))*time.Minute,
+		time.Duration(env.GetEnvInt("JWT_REFRESH_TTL_DAYS", 30))*24*time.Hour,
+		logger,
+	)
+	userService := service.NewUserService(userRepo, mediaService, authService, logger)
+	// Сброс пароля по ссылке из письма; PASSWORD_RESET_URL - страница сайта, принимающая token
+	passwordService := service.NewPasswordService(userRepo, passwordResetRepo, authService, loginGuard, emailOutbox, mailTemplates,
+		time.Duration(env.GetEnvInt("PASSWORD_RESET_TTL_MINUTES", 60))*time.Minute,
+		env.GetEnv("PASSWORD_RESET_URL", ""),
+		logger,
+	)
+	// Подтверждение посетителю: не больше MAIL_AUTOREPLY_LIMIT писем на адрес за MAIL_AUTOREPLY_WINDOW_HOURS
+	var autoReplyLimiter *service.RateLimiter
+	if env.GetEnvBool("MAIL_AUTOREPLY", true) {
+		autoReplyWindow := time.Duration(env.GetEnvInt("MAIL_AUTOREPLY_WINDOW_HOURS", 24)) * time.Hour
+		autoReplyLimiter = service.NewRateLimiter(env.GetEnvInt("MAIL_AUTOREPLY_LIMIT", 3), autoReplyWindow, autoReplyWindow)
+	}
+	emailService := service.NewEmailService(emailOutbox, leadRepo,
+		env.GetEnv("MAIL_TO", env.GetEnv("GMAIL_TO", "")),
+		mailTemplates,
+		autoReplyLimiter,
+		logger,
+	)
+	leadService := service.NewLeadService(leadRepo, userRepo, logger)
 	// Создаем новый RateLimiter: 3 запросов за 1 минуту, блокировка на 5 минут
 	rateLimitService := service.NewRateLimiter(3, time.Minute, 5*time.Minute)
+	// Письма сброса пароля: 3 запроса с IP за 15 минут, блокировка на 15 минут
+	passwordResetLimiter := service.NewRateLimiter(3, 15*time.Minute, 15*time.Minute)
+	// Корзина: срок хранения и период очистки
+	trashPurger := service.NewTrashPurger(articleRepo, productRepo, userRepo, revisionRepo, mediaService,
+		time.Duration(env.GetEnvInt("TRASH_RETENTION_DAYS", 30))*24*time.Hour,
+		time.Duration(env.GetEnvInt("TRASH_PURGE_INTERVAL_MINUTES", 60))*time.Minute,
+		logger,
+	)
 	// Create handlers
-	articleHandler := handlers.NewArticleHandler(articleService, logger)
-	productHandler := handlers.NewProductHandler(productService, logger)
+	articleHandler := handlers.NewArticleHandler(articleService, taxonomyService, logger)
+	productHandler := handlers.NewProductHandler(productService, taxonomyService, logger)
 	userHandler := handlers.NewUserHandler(userService, logger)
-	authHandler := handlers.NewAuthHandler(authService, logger)
+	authHandler := handlers.NewAuthHandler(authService, twoFactorService, logger)
+	passwordHandler := handlers.NewPasswordHandler(passwordService, logger)
 	emailHandler := handlers.NewEmailHandler(emailService, logger)
+	leadHandler := handlers.NewLeadHandler(leadService, logger)
+	outboxHandler := handlers.NewOutboxHandler(emailOutbox, logger)
+	mediaHandler := handlers.NewMediaHandler(mediaService, logger)
+	taxonomyHandler := handlers.NewTaxonomyHandler(taxonomyService, logger)
+	jwksHandler := handlers.NewJWKSHandler(jwtService)
 
 	// Return the container with all dependencies
 	return &Container{
-		Logger:           logger,
-		MongoClient:      clientDB,
-		ArticleRepo:      articleRepo,
-		ProductRepo:      productRepo,
-		UserRepo:         userRepo,
-		EmailRepo:        emailRepo,
-		ArticleService:   articleService,
-		ProductService:   productService,
-		UserService:      userService,
-		JwtService:       jwtService,
-		EmailService:     emailService,
-		RateLimitService: rateLimitService,
-		ArticleHandler:   articleHandler,
-		ProductHandler:   productHandler,
-		UserHandler:      userHandler,
-		AuthHandler:      authHandler,
-		EmailHandler:     emailHandler,
+		Logger:               logger,
+		MongoClient:          clientDB,
+		ArticleRepo:          articleRepo,
+		ProductRepo:          productRepo,
+		UserRepo:             userRepo,
+		EmailRepo:            emailRepo,
+		MediaRepo:            mediaRepo,
+		BlobRepo:             blobRepo,
+		RevisionRepo:         revisionRepo,
+		TaxonomyRepo:         taxonomyRepo,
+		RefreshTokenRepo:     refreshTokenRepo,
+		PasswordResetRepo:    passwordResetRepo,
+		LeadRepo:             leadRepo,
+		OutboxRepo:           outboxRepo,
+		ArticleService:       articleService,
+		ProductService:       productService,
+		UserService:          userService,
+		JwtService:           jwtService,
+		AuthService:          authService,
+		TwoFactorService:     twoFactorService,
+		PasswordService:      passwordService,
+		EmailService:         emailService,
+		LeadService:          leadService,
+		EmailOutbox:          emailOutbox,
+		MediaService:         mediaService,
+		RevisionService:      revisionService,
+		TaxonomyService:      taxonomyService,
+		RateLimitService:     rateLimitService,
+		PasswordResetLimiter: passwordResetLimiter,
+		TrashPurger:          trashPurger,
+		ArticleHandler:       articleHandler,
+		ProductHandler:       productHandler,
+		UserHandler:          userHandler,
+		AuthHandler:          authHandler,
+		PasswordHandler:      passwordHandler,
+		EmailHandler:         emailHandler,
+		LeadHandler:          leadHandler,
+		OutboxHandler:        outboxHandler,
+		MediaHandler:         mediaHandler,
+		TaxonomyHandler:      taxonomyHandler,
+		JWKSHandler:          jwksHandler,
 	}
 }
 
@@ -7,36 +7,147 @@ import (
 
 // Paginate - common structure for pagination
 type Paginate[T any] struct {
-	PageNumber     int `json:"pageNumber"`
-	RowTotalCount  int `json:"rowTotalCount"`
-	TotalPageCount int `json:"totalPageCount"`
-	PageSize       int `json:"pageSize"`
-	Items          []T `json:"items"`
+	PageNumber     int             `json:"pageNumber"`
+	RowTotalCount  int             `json:"rowTotalCount"`
+	TotalPageCount int             `json:"totalPageCount"`
+	PageSize       int             `json:"pageSize"`
+	Items          []T             `json:"items"`
+	Facets         *TaxonomyFacets `json:"facets,omitempty"` // счётчики по тегам и категориям для боковой панели
+}
+
+// CursorPaginate - keyset pagination envelope; cursors are nil when there is no page in that direction
+type CursorPaginate[T any] struct {
+	PageSize   int             `json:"pageSize"`
+	NextCursor *string         `json:"nextCursor"`
+	PrevCursor *string         `json:"prevCursor"`
+	Items      []T             `json:"items"`
+	Facets     *TaxonomyFacets `json:"facets,omitempty"`
+}
+
+// Статусы публикации статьи.
+const (
+	ArticleStatusDraft     = "draft"
+	ArticleStatusScheduled = "scheduled"
+	ArticleStatusPublished = "published"
+	ArticleStatusArchived  = "archived"
+)
+
+// ArticleFilter - условия выборки статей по статусу публикации, тегам и категориям.
+type ArticleFilter struct {
+	PublishedOnly bool   // только опубликованные статьи, чей publishAt уже наступил
+	Status        string // точный статус для админки, пустая строка - любой
+	WithFacets    bool   // посчитать счётчики тегов и категорий (?facets=1)
+	TaxonomyFilter
 }
 
 // RowArticle - структура для хранения данных статьи.
 type RowArticle struct {
-	ID    primitive.ObjectID `bson:"_id"`
-	Text  string             `bson:"text"`
-	Title string             `bson:"title"`
-	Img   *string            `bson:"img"`
-	Date  time.Time          `bson:"date"`
+	ID           primitive.ObjectID     `bson:"_id"`
+	Text         string                 `bson:"text"`
+	Title        string                 `bson:"title"`
+	Locale       string                 `bson:"locale,omitempty"`       // язык title/text, пусто - CONTENT_DEFAULT_LOCALE
+	Translations map[string]Translation `bson:"translations,omitempty"` // переводы на другие языки
+	Slug         string                 `bson:"slug,omitempty"`
+	OldSlugs     []string               `bson:"oldSlugs,omitempty"` // прежние slug, по ним отдаётся редирект
+	Tags         []primitive.ObjectID   `bson:"tags,omitempty"`
+	Categories   []primitive.ObjectID   `bson:"categories,omitempty"`
+	Img          *string                `bson:"img"`
+	Date         time.Time              `bson:"date"`
+	Status       string                 `bson:"status,omitempty"`
+	PublishAt    *time.Time             `bson:"publishAt,omitempty"`
+	AuthorID     *primitive.ObjectID    `bson:"authorId,omitempty"` // создатель статьи, автор может менять только свои
+	DeletedAt    *time.Time             `bson:"deletedAt,omitempty"`
 }
 
 type ArticleResponse struct {
-	ID    primitive.ObjectID `json:"id,omitempty"`
-	Text  string             `json:"text,omitempty"`
-	Title string             `json:"title,omitempty"`
-	Img   *string            `json:"img"`
-	Date  time.Time          `json:"date,omitempty"`
+	ID         primitive.ObjectID   `json:"id,omitempty"`
+	Text       string               `json:"text,omitempty"`
+	Title      string               `json:"title,omitempty"`
+	Slug       string               `json:"slug,omitempty"`
+	Tags       []primitive.ObjectID `json:"tags"`
+	Categories []primitive.ObjectID `json:"categories"`
+	Img        *string              `json:"img"`
+	Images     map[string]string    `json:"images,omitempty"`
+	Snippet    string               `json:"snippet,omitempty"`
+	Date       time.Time            `json:"date,omitempty"`
+	Status     string               `json:"status"`
+	PublishAt  *time.Time           `json:"publishAt"`
+	AuthorID   *primitive.ObjectID  `json:"authorId,omitempty"`
+	DeletedAt  *time.Time           `json:"deletedAt,omitempty"`
+	Locale     string               `json:"locale,omitempty"`
+	Locales    []string             `json:"locales,omitempty"` // языки, на которых есть статья
+	source     localeSource
 }
 
 func (ar *RowArticle) CreateArtResp() *ArticleResponse {
 	return &ArticleResponse{
-		ID:    ar.ID,
-		Title: ar.Title,
-		Text:  ar.Text,
-		Img:   ar.Img,
-		Date:  ar.Date,
+		ID:         ar.ID,
+		Title:      ar.Title,
+		Slug:       ar.Slug,
+		Tags:       idsOrEmpty(ar.Tags),
+		Categories: idsOrEmpty(ar.Categories),
+		Text:       ar.Text,
+		Img:        ar.Img,
+		Date:       ar.Date,
+		Status:     ar.EffectiveStatus(time.Now()),
+		PublishAt:  ar.PublishAt,
+		AuthorID:   ar.AuthorID,
+		DeletedAt:  ar.DeletedAt,
+		Locale:     ar.Locale,
+		source: localeSource{
+			locale:       ar.Locale,
+			original:     Translation{Title: ar.Title, Text: ar.Text},
+			translations: ar.Translations,
+		},
+	}
+}
+
+// Localize - подставляет заголовок и текст на языке из цепочки и список доступных языков.
+func (r *ArticleResponse) Localize(p LocalePreference) {
+	locale, content, locales := r.source.localize(p)
+	r.Locale, r.Locales = locale, locales
+	r.Title, r.Text = content.Title, content.Text
+}
+
+// EffectiveStatus - статус с учётом времени: запланированная статья становится опубликованной
+// после publishAt, а статьи без статуса (созданные до его появления) считаются опубликованными.
+func (ar *RowArticle) EffectiveStatus(now time.Time) string {
+	switch {
+	case ar.Status == "":
+		return ArticleStatusPublished
+	case ar.Status == ArticleStatusScheduled && ar.PublishAt != nil && !ar.PublishAt.After(now):
+		return ArticleStatusPublished
+	default:
+		return ar.Status
+	}
+}
+
+// ListDate - дата, по которой статья стоит в списке: в публичной ленте момент публикации,
+// в админке дата создания.
+func (ar *RowArticle) ListDate(publishedOnly bool) time.Time {
+	if publishedOnly && ar.PublishAt != nil {
+		return *ar.PublishAt
+	}
+	return ar.Date
+}
+
+// IsPublic - видна ли статья на публичных эндпоинтах.
+func (ar *RowArticle) IsPublic(now time.Time) bool {
+	return ar.EffectiveStatus(now) == ArticleStatusPublished
+}
+
+// SlugHistory - список прежних slug после смены current на next, без дублей и без next.
+func SlugHistory(current string, old []string, next string) []string {
+	history := make([]string, 0, len(old)+1)
+	seen := map[string]bool{"": true, next: true}
+	for _, slug := range old {
+		if !seen[slug] {
+			seen[slug] = true
+			history = append(history, slug)
+		}
+	}
+	if !seen[current] {
+		history = append(history, current)
 	}
+	return history
 }
@@ -1,6 +1,39 @@
 package model
 
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
 // LongResponse - for UI response
 type LoginResponse struct {
-	AccessToken string `json:"accessToken"`
+	AccessToken  string `json:"accessToken,omitempty"`
+	RefreshToken string `json:"refreshToken,omitempty"`
+	ExpiresIn    int    `json:"expiresIn,omitempty"` // срок жизни access токена в секундах
+	// При включённой 2FA вместо токенов возвращается challenge для POST /api/auth/login/2fa
+	TwoFactorRequired bool   `json:"twoFactorRequired,omitempty"`
+	ChallengeToken    string `json:"challengeToken,omitempty"`
+}
+
+// RowRefreshToken - refresh токен сессии, в базе хранится только его хэш.
+// Все токены одной цепочки ротаций имеют общий FamilyID.
+type RowRefreshToken struct {
+	ID        primitive.ObjectID `bson:"_id"`
+	UserID    primitive.ObjectID `bson:"userId"`
+	FamilyID  primitive.ObjectID `bson:"familyId"`
+	TokenHash string             `bson:"tokenHash"`
+	CreatedAt time.Time          `bson:"createdAt"`
+	ExpiresAt time.Time          `bson:"expiresAt"`
+	UsedAt    *time.Time         `bson:"usedAt,omitempty"` // токен уже обменян на новый
+	RevokedAt *time.Time         `bson:"revokedAt,omitempty"`
+}
+
+// RowPasswordReset - одноразовый токен сброса пароля, в базе хранится только его хэш.
+type RowPasswordReset struct {
+	ID        primitive.ObjectID `bson:"_id"`
+	UserID    primitive.ObjectID `bson:"userId"`
+	TokenHash string             `bson:"tokenHash"`
+	CreatedAt time.Time          `bson:"createdAt"`
+	ExpiresAt time.Time          `bson:"expiresAt"`
+	UsedAt    *time.Time         `bson:"usedAt,omitempty"` // токен использован или заменён новым
 }
@@ -0,0 +1,145 @@
+package model
+
+import (
+	"crypto/rand"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+// Статусы обращения в админском inbox.
+const (
+	LeadStatusNew        = "new"
+	LeadStatusInProgress = "in-progress"
+	LeadStatusClosed     = "closed"
+)
+
+// Результат отправки письма о новом обращении.
+const (
+	DeliveryPending = "pending" // письмо ещё не отправлялось
+	DeliverySent    = "sent"
+	DeliveryFailed  = "failed"
+)
+
+// LeadStatuses - все статусы обращения по порядку работы с ним.
+func LeadStatuses() []string {
+	return []string{LeadStatusNew, LeadStatusInProgress, LeadStatusClosed}
+}
+
+// IsValidLeadStatus - известен ли статус.
+func IsValidLeadStatus(status string) bool {
+	for _, s := range LeadStatuses() {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// leadReferenceAlphabet - без похожих символов (0/O, 1/I/L), чтобы номер легко продиктовать.
+const leadReferenceAlphabet = "23456789ABCDEFGHJKMNPQRSTUVWXYZ"
+
+// NewLeadReference - номер обращения для посетителя вида 261017-K7M2QX: дата и 6 случайных символов.
+func NewLeadReference(now time.Time) (string, error) {
+	suffix := make([]byte, 0, 6)
+	buf := make([]byte, 16)
+	for len(suffix) < cap(suffix) {
+		if _, err := rand.Read(buf); err != nil {
+			return "", err
+		}
+		for _, b := range buf {
+			// Отбрасываем хвост байта, чтобы все символы были равновероятны
+			if int(b) < 256/len(leadReferenceAlphabet)*len(leadReferenceAlphabet) && len(suffix) < cap(suffix) {
+				suffix = append(suffix, leadReferenceAlphabet[int(b)%len(leadReferenceAlphabet)])
+			}
+		}
+	}
+	return now.UTC().Format("060102") + "-" + string(suffix), nil
+}
+
+// RowLead - обращение посетителя из формы POST /api/email. Сохраняется до отправки письма,
+// поэтому не теряется, даже если почта недоступна.
+type RowLead struct {
+	ID         primitive.ObjectID  `bson:"_id"`
+	Reference  string              `bson:"reference,omitempty"` // номер из письма-подтверждения посетителю
+	Name       string              `bson:"name"`
+	Email      string              `bson:"email"`
+	Phone      string              `bson:"phone"`
+	Text       string              `bson:"text"`
+	IP         string              `bson:"ip,omitempty"`
+	UserAgent  string              `bson:"userAgent,omitempty"`
+	Locale     string              `bson:"locale,omitempty"` // язык посетителя
+	Status     string              `bson:"status"`
+	AssigneeID *primitive.ObjectID `bson:"assigneeId,omitempty"` // кто из пользователей ведёт обращение
+	Notes      []LeadNote          `bson:"notes,omitempty"`
+	Delivery   LeadDelivery        `bson:"delivery"`
+	CreatedAt  time.Time           `bson:"createdAt"`
+	UpdatedAt  time.Time           `bson:"updatedAt"`
+}
+
+// LeadNote - внутренняя заметка к обращению, посетитель её не видит.
+type LeadNote struct {
+	ID        primitive.ObjectID `bson:"_id" json:"id"`
+	AuthorID  primitive.ObjectID `bson:"authorId" json:"authorId"`
+	Text      string             `bson:"text" json:"text"`
+	CreatedAt time.Time          `bson:"createdAt" json:"createdAt"`
+}
+
+// LeadDelivery - чем закончилась отправка письма владельцу сайта.
+type LeadDelivery struct {
+	Status    string     `bson:"status" json:"status"`
+	Attempts  int        `bson:"attempts" json:"attempts"`
+	LastError string     `bson:"lastError,omitempty" json:"lastError,omitempty"`
+	SentAt    *time.Time `bson:"sentAt,omitempty" json:"sentAt,omitempty"`
+	UpdatedAt time.Time  `bson:"updatedAt" json:"updatedAt"`
+}
+
+// LeadResponse - for UI response
+type LeadResponse struct {
+	ID         primitive.ObjectID  `json:"id"`
+	Reference  string              `json:"reference,omitempty"`
+	Name       string              `json:"name"`
+	Email      string              `json:"email"`
+	Phone      string              `json:"phone"`
+	Text       string              `json:"text"`
+	IP         string              `json:"ip,omitempty"`
+	UserAgent  string              `json:"userAgent,omitempty"`
+	Locale     string              `json:"locale,omitempty"`
+	Status     string              `json:"status"`
+	AssigneeID *primitive.ObjectID `json:"assigneeId"`
+	Notes      []LeadNote          `json:"notes"`
+	Delivery   LeadDelivery        `json:"delivery"`
+	CreatedAt  time.Time           `json:"createdAt"`
+	UpdatedAt  time.Time           `json:"updatedAt"`
+}
+
+func (l *RowLead) CreateLeadResp() *LeadResponse {
+	notes := l.Notes
+	if notes == nil {
+		notes = []LeadNote{}
+	}
+
+	return &LeadResponse{
+		ID:         l.ID,
+		Reference:  l.Reference,
+		Name:       l.Name,
+		Email:      l.Email,
+		Phone:      l.Phone,
+		Text:       l.Text,
+		IP:         l.IP,
+		UserAgent:  l.UserAgent,
+		Locale:     l.Locale,
+		Status:     l.Status,
+		AssigneeID: l.AssigneeID,
+		Notes:      notes,
+		Delivery:   l.Delivery,
+		CreatedAt:  l.CreatedAt,
+		UpdatedAt:  l.UpdatedAt,
+	}
+}
+
+// LeadFilter - условия выборки обращений; пустое поле не ограничивает выборку.
+type LeadFilter struct {
+	Reference  string
+	Status     string
+	AssigneeID *primitive.ObjectID
+}
@@ -0,0 +1,90 @@
+package model
+
+import "sort"
+
+// Translation - текстовые поля на одном языке; ShortText есть только у проектов.
+type Translation struct {
+	Title     string `bson:"title,omitempty" json:"title,omitempty"`
+	ShortText string `bson:"shortText,omitempty" json:"shortText,omitempty"`
+	Text      string `bson:"text,omitempty" json:"text,omitempty"`
+}
+
+// localeSource - исходные тексты документа, по ним ответ выбирает язык.
+type localeSource struct {
+	locale       string
+	original     Translation
+	translations map[string]Translation
+}
+
+// localize - язык, содержимое и доступные языки для ответа.
+func (s localeSource) localize(p LocalePreference) (string, Translation, []string) {
+	locale, content := p.Pick(s.original, s.locale, s.translations)
+	return locale, content, p.Available(s.locale, s.translations)
+}
+
+// LocalePreference - в каком порядке выбирать язык ответа.
+type LocalePreference struct {
+	Chain   []string // запрошенный язык, затем запасные
+	Default string   // язык документов, созданных без указания языка
+}
+
+// NewLocalePreference - цепочка из запрошенного языка (может быть пустым) и запасных, без повторов.
+func NewLocalePreference(requested string, fallback []string, defaultLocale string) LocalePreference {
+	chain := make([]string, 0, len(fallback)+1)
+	seen := map[string]bool{"": true}
+	for _, locale := range append([]string{requested}, fallback...) {
+		if !seen[locale] {
+			seen[locale] = true
+			chain = append(chain, locale)
+		}
+	}
+	return LocalePreference{Chain: chain, Default: defaultLocale}
+}
+
+// Pick - язык и содержимое по цепочке. Пустые поля перевода берутся из оригинала,
+// а если ни одного языка из цепочки нет - возвращается оригинал.
+func (p LocalePreference) Pick(original Translation, originalLocale string, translations map[string]Translation) (string, Translation) {
+	originalLocale = p.originalLocale(originalLocale)
+
+	for _, locale := range p.Chain {
+		if locale == originalLocale {
+			return locale, original
+		}
+		if translation, ok := translations[locale]; ok {
+			if translation.Title == "" {
+				translation.Title = original.Title
+			}
+			if translation.ShortText == "" {
+				translation.ShortText = original.ShortText
+			}
+			if translation.Text == "" {
+				translation.Text = original.Text
+			}
+			return locale, translation
+		}
+	}
+
+	return originalLocale, original
+}
+
+// Available - язык оригинала, затем языки переводов по алфавиту.
+func (p LocalePreference) Available(originalLocale string, translations map[string]Translation) []string {
+	originalLocale = p.originalLocale(originalLocale)
+
+	others := make([]string, 0, len(translations))
+	for locale := range translations {
+		if locale != originalLocale {
+			others = append(others, locale)
+		}
+	}
+	sort.Strings(others)
+
+	return append([]string{originalLocale}, others...)
+}
+
+func (p LocalePreference) originalLocale(locale string) string {
+	if locale == "" {
+		return p.Default
+	}
+	return locale
+}
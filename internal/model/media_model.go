@@ -0,0 +1,68 @@
+package model
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"strings"
+	"time"
+)
+
+// MediaURLPrefix - public path under which media files are served.
+const MediaURLPrefix = "/api/media/"
+
+// RowMedia - метаданные загруженного файла, сами байты лежат в blob-хранилище.
+type RowMedia struct {
+	ID          primitive.ObjectID `bson:"_id"`
+	ContentType string             `bson:"contentType"`
+	Size        int64              `bson:"size"`
+	Width       int                `bson:"width,omitempty"`
+	Height      int                `bson:"height,omitempty"`
+	Variants    []MediaVariant     `bson:"variants,omitempty"`
+	Backend     string             `bson:"backend"`
+	CreatedAt   time.Time          `bson:"createdAt"`
+}
+
+// MediaVariant - уменьшенная копия изображения (миниатюра, карточка и т.п.).
+type MediaVariant struct {
+	Name        string `bson:"name"`
+	ContentType string `bson:"contentType"`
+	Size        int64  `bson:"size"`
+	Width       int    `bson:"width"`
+	Height      int    `bson:"height"`
+}
+
+// URL returns the public URL of the media file.
+func (m *RowMedia) URL() string {
+	return MediaURLPrefix + m.ID.Hex()
+}
+
+// Variant finds a stored variant by name.
+func (m *RowMedia) Variant(name string) (*MediaVariant, bool) {
+	for i := range m.Variants {
+		if m.Variants[i].Name == name {
+			return &m.Variants[i], true
+		}
+	}
+	return nil, false
+}
+
+// MediaVariantURL builds the URL of a named variant for an image stored in the media store.
+// Images that are not media URLs (e.g. legacy base64) are returned unchanged.
+func MediaVariantURL(img *string, variant string) *string {
+	if img == nil || !strings.HasPrefix(*img, MediaURLPrefix) {
+		return img
+	}
+
+	url := *img + "/" + variant
+	return &url
+}
+
+// MediaIDFromURL extracts the media ID from a media URL, with or without a variant suffix.
+// Anything else, e.g. an external URL or legacy base64, gives false.
+func MediaIDFromURL(img *string) (string, bool) {
+	if img == nil || !strings.HasPrefix(*img, MediaURLPrefix) {
+		return "", false
+	}
+
+	id, _, _ := strings.Cut(strings.TrimPrefix(*img, MediaURLPrefix), "/")
+	return id, id != ""
+}
@@ -0,0 +1,100 @@
+package model
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+// Состояния письма в очереди исходящей почты.
+const (
+	OutboxPending = "pending" // ждёт отправки или следующей попытки
+	OutboxSending = "sending" // взято воркером
+	OutboxSent    = "sent"
+	OutboxDead    = "dead" // попытки исчерпаны, вернуть в очередь можно вручную
+)
+
+// OutboxStatuses - все состояния письма.
+func OutboxStatuses() []string {
+	return []string{OutboxPending, OutboxSending, OutboxSent, OutboxDead}
+}
+
+// IsValidOutboxStatus - известно ли состояние.
+func IsValidOutboxStatus(status string) bool {
+	for _, s := range OutboxStatuses() {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// RowOutboxMessage - исходящее письмо. Сначала сохраняется в базу, затем его отправляет
+// фоновый воркер, поэтому письма переживают сбои почты и перезапуск сервера.
+type RowOutboxMessage struct {
+	ID            primitive.ObjectID  `bson:"_id"`
+	To            string              `bson:"to"`
+	ReplyTo       string              `bson:"replyTo,omitempty"`
+	Subject       string              `bson:"subject"`
+	Body          string              `bson:"body"`             // HTML; после отправки удаляется
+	Text          string              `bson:"text,omitempty"`   // текстовая версия
+	LeadID        *primitive.ObjectID `bson:"leadId,omitempty"` // обращение, о котором письмо
+	Status        string              `bson:"status"`
+	Attempts      int                 `bson:"attempts"`
+	LastError     string              `bson:"lastError,omitempty"`
+	NextAttemptAt time.Time           `bson:"nextAttemptAt"`
+	LockedUntil   *time.Time          `bson:"lockedUntil,omitempty"` // пока воркер отправляет письмо, другие его не берут
+	LockToken     string              `bson:"lockToken,omitempty"`   // метка захвата: итог попытки записывает только тот, кто её захватил
+	SentAt        *time.Time          `bson:"sentAt,omitempty"`
+	ExpiresAt     *time.Time          `bson:"expiresAt,omitempty"` // отправленное письмо удаляется TTL индексом
+	CreatedAt     time.Time           `bson:"createdAt"`
+	UpdatedAt     time.Time           `bson:"updatedAt"`
+}
+
+// OutboxResponse - for UI response. Тело письма не отдаётся: в нём бывают токены сброса пароля.
+type OutboxResponse struct {
+	ID            primitive.ObjectID  `json:"id"`
+	To            string              `json:"to"`
+	Subject       string              `json:"subject"`
+	LeadID        *primitive.ObjectID `json:"leadId,omitempty"`
+	Status        string              `json:"status"`
+	Attempts      int                 `json:"attempts"`
+	LastError     string              `json:"lastError,omitempty"`
+	NextAttemptAt time.Time           `json:"nextAttemptAt"`
+	SentAt        *time.Time          `json:"sentAt,omitempty"`
+	CreatedAt     time.Time           `json:"createdAt"`
+	UpdatedAt     time.Time           `json:"updatedAt"`
+}
+
+func (m *RowOutboxMessage) CreateOutboxResp() *OutboxResponse {
+	return &OutboxResponse{
+		ID:            m.ID,
+		To:            m.To,
+		Subject:       m.Subject,
+		LeadID:        m.LeadID,
+		Status:        m.Status,
+		Attempts:      m.Attempts,
+		LastError:     m.LastError,
+		NextAttemptAt: m.NextAttemptAt,
+		SentAt:        m.SentAt,
+		CreatedAt:     m.CreatedAt,
+		UpdatedAt:     m.UpdatedAt,
+	}
+}
+
+// LeadDelivery - состояние доставки письма об обращении по состоянию письма в очереди.
+func (m *RowOutboxMessage) LeadDelivery() LeadDelivery {
+	delivery := LeadDelivery{
+		Status:    DeliveryPending,
+		Attempts:  m.Attempts,
+		LastError: m.LastError,
+		SentAt:    m.SentAt,
+		UpdatedAt: m.UpdatedAt,
+	}
+	switch m.Status {
+	case OutboxSent:
+		delivery.Status = DeliverySent
+	case OutboxDead:
+		delivery.Status = DeliveryFailed
+	}
+	return delivery
+}
@@ -6,30 +6,69 @@ import (
 )
 
 type RowProduct struct {
-	ID        primitive.ObjectID `bson:"_id"`
-	Text      string             `bson:"text"`
-	ShortText string             `bson:"shortText"`
-	Title     string             `bson:"title"`
-	Img       *string            `bson:"img"`
-	Date      time.Time          `bson:"date"`
+	ID           primitive.ObjectID     `bson:"_id"`
+	Text         string                 `bson:"text"`
+	ShortText    string                 `bson:"shortText"`
+	Title        string                 `bson:"title"`
+	Locale       string                 `bson:"locale,omitempty"`       // язык текстов, пусто - CONTENT_DEFAULT_LOCALE
+	Translations map[string]Translation `bson:"translations,omitempty"` // переводы на другие языки
+	Slug         string                 `bson:"slug,omitempty"`
+	OldSlugs     []string               `bson:"oldSlugs,omitempty"` // прежние slug, по ним отдаётся редирект
+	Tags         []primitive.ObjectID   `bson:"tags,omitempty"`
+	Categories   []primitive.ObjectID   `bson:"categories,omitempty"`
+	Img          *string                `bson:"img"`
+	Date         time.Time              `bson:"date"`
+	DeletedAt    *time.Time             `bson:"deletedAt,omitempty"`
 }
 
 type ProductResponse struct {
-	ID        primitive.ObjectID `json:"id,omitempty"`
-	Text      string             `json:"text,omitempty"`
-	ShortText string             `json:"shortText"`
-	Title     string             `json:"title,omitempty"`
-	Img       *string            `json:"img"`
-	Date      time.Time          `json:"date,omitempty"`
+	ID         primitive.ObjectID   `json:"id,omitempty"`
+	Text       string               `json:"text,omitempty"`
+	ShortText  string               `json:"shortText"`
+	Title      string               `json:"title,omitempty"`
+	Slug       string               `json:"slug,omitempty"`
+	Tags       []primitive.ObjectID `json:"tags"`
+	Categories []primitive.ObjectID `json:"categories"`
+	Img        *string              `json:"img"`
+	Images     map[string]string    `json:"images,omitempty"`
+	Snippet    string               `json:"snippet,omitempty"`
+	Date       time.Time            `json:"date,omitempty"`
+	DeletedAt  *time.Time           `json:"deletedAt,omitempty"`
+	Locale     string               `json:"locale,omitempty"`
+	Locales    []string             `json:"locales,omitempty"` // языки, на которых есть проект
+	source     localeSource
 }
 
 func (ar *RowProduct) CreateProductResp() *ProductResponse {
 	return &ProductResponse{
-		ID:        ar.ID,
-		Title:     ar.Title,
-		ShortText: ar.ShortText,
-		Text:      ar.Text,
-		Img:       ar.Img,
-		Date:      ar.Date,
+		ID:         ar.ID,
+		Title:      ar.Title,
+		Slug:       ar.Slug,
+		Tags:       idsOrEmpty(ar.Tags),
+		Categories: idsOrEmpty(ar.Categories),
+		ShortText:  ar.ShortText,
+		Text:       ar.Text,
+		Img:        ar.Img,
+		Date:       ar.Date,
+		DeletedAt:  ar.DeletedAt,
+		Locale:     ar.Locale,
+		source: localeSource{
+			locale:       ar.Locale,
+			original:     Translation{Title: ar.Title, ShortText: ar.ShortText, Text: ar.Text},
+			translations: ar.Translations,
+		},
 	}
 }
+
+// Localize - подставляет тексты на языке из цепочки и список доступных языков.
+func (r *ProductResponse) Localize(p LocalePreference) {
+	locale, content, locales := r.source.localize(p)
+	r.Locale, r.Locales = locale, locales
+	r.Title, r.ShortText, r.Text = content.Title, content.ShortText, content.Text
+}
+
+// ProductFilter - условия выборки проектов по тегам и категориям.
+type ProductFilter struct {
+	WithFacets bool // посчитать счётчики тегов и категорий (?facets=1)
+	TaxonomyFilter
+}
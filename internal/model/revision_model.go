@@ -0,0 +1,101 @@
+package model
+
+import (
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// Типы сущностей, для которых ведётся история изменений.
+const (
+	RevisionEntityArticle = "article"
+	RevisionEntityProduct = "product"
+)
+
+// Действия, породившие ревизию.
+const (
+	RevisionActionCreate  = "create"
+	RevisionActionPatch   = "patch"
+	RevisionActionRestore = "restore"
+	// Исходное состояние документа, созданного до появления истории, записанное перед первым patch
+	RevisionActionBaseline = "baseline"
+)
+
+// RowRevision - неизменяемый снимок статьи или проекта после create/patch.
+type RowRevision struct {
+	ID         primitive.ObjectID `bson:"_id"`
+	EntityType string             `bson:"entityType"`
+	EntityID   primitive.ObjectID `bson:"entityId"`
+	Version    int                `bson:"version"`
+	Action     string             `bson:"action"`
+	AuthorID   string             `bson:"authorId"`
+	Snapshot   bson.M             `bson:"snapshot"`
+	CreatedAt  time.Time          `bson:"createdAt"`
+}
+
+type RevisionResponse struct {
+	ID         primitive.ObjectID `json:"id"`
+	EntityType string             `json:"entityType"`
+	EntityID   primitive.ObjectID `json:"entityId"`
+	Version    int                `json:"version"`
+	Action     string             `json:"action"`
+	AuthorID   string             `json:"authorId"`
+	Snapshot   bson.M             `json:"snapshot,omitempty"`
+	CreatedAt  time.Time          `json:"createdAt"`
+}
+
+// RevisionFieldChange - изменение одного поля между двумя ревизиями.
+type RevisionFieldChange struct {
+	Field string      `json:"field"`
+	From  interface{} `json:"from"`
+	To    interface{} `json:"to"`
+}
+
+// RevisionDiff - разница между двумя ревизиями одной сущности.
+type RevisionDiff struct {
+	From    *RevisionResponse     `json:"from"`
+	To      *RevisionResponse     `json:"to"`
+	Changes []RevisionFieldChange `json:"changes"`
+}
+
+// CreateRevisionResp - ответ без снимка (для списков); снимок добавляется явно.
+func (rr *RowRevision) CreateRevisionResp() *RevisionResponse {
+	return &RevisionResponse{
+		ID:         rr.ID,
+		EntityType: rr.EntityType,
+		EntityID:   rr.EntityID,
+		Version:    rr.Version,
+		Action:     rr.Action,
+		AuthorID:   rr.AuthorID,
+		CreatedAt:  rr.CreatedAt,
+	}
+}
+
+// DiffRevisionSnapshots - список изменённых полей между двумя снимками, отсортированный по имени поля.
+// Отсутствующее поле считается равным nil.
+func DiffRevisionSnapshots(from, to bson.M) []RevisionFieldChange {
+	fields := make(map[string]struct{}, len(from)+len(to))
+	for field := range from {
+		fields[field] = struct{}{}
+	}
+	for field := range to {
+		fields[field] = struct{}{}
+	}
+
+	names := make([]string, 0, len(fields))
+	for field := range fields {
+		names = append(names, field)
+	}
+	sort.Strings(names)
+
+	changes := make([]RevisionFieldChange, 0)
+	for _, field := range names {
+		if reflect.DeepEqual(from[field], to[field]) {
+			continue
+		}
+		changes = append(changes, RevisionFieldChange{Field: field, From: from[field], To: to[field]})
+	}
+	return changes
+}
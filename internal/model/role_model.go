@@ -0,0 +1,59 @@
+package model
+
+// Роли пользователей.
+const (
+	RoleOwner  = "owner"  // всё, включая управление пользователями
+	RoleEditor = "editor" // любой контент, теги, категории и корзина
+	RoleAuthor = "author" // только свои статьи и загрузка изображений
+	RoleViewer = "viewer" // чтение админских списков, черновиков и истории
+)
+
+// Permission - право на группу действий, проверяется на уровне маршрутов.
+type Permission string
+
+const (
+	PermContentRead     Permission = "content:read"      // админские списки, черновики, ревизии
+	PermArticlesWrite   Permission = "articles:write"    // создание статей и правка своих
+	PermArticlesEditAny Permission = "articles:edit_any" // правка, публикация и удаление чужих статей
+	PermProjectsWrite   Permission = "projects:write"
+	PermTaxonomyWrite   Permission = "taxonomy:write"
+	PermTrashManage     Permission = "trash:manage" // просмотр и восстановление корзины контента
+	PermUsersManage     Permission = "users:manage"
+	PermLeadsManage     Permission = "leads:manage"  // обращения из формы обратной связи
+	PermOutboxManage    Permission = "outbox:manage" // очередь исходящей почты, в письмах бывают токены сброса
+)
+
+// rolePermissions - матрица прав; роль получает только явно перечисленные права.
+var rolePermissions = map[string][]Permission{
+	RoleOwner: {
+		PermContentRead, PermArticlesWrite, PermArticlesEditAny, PermProjectsWrite,
+		PermTaxonomyWrite, PermTrashManage, PermUsersManage, PermLeadsManage, PermOutboxManage,
+	},
+	RoleEditor: {
+		PermContentRead, PermArticlesWrite, PermArticlesEditAny, PermProjectsWrite,
+		PermTaxonomyWrite, PermTrashManage, PermLeadsManage,
+	},
+	RoleAuthor: {PermContentRead, PermArticlesWrite},
+	RoleViewer: {PermContentRead},
+}
+
+// Roles - все роли от старшей к младшей.
+func Roles() []string {
+	return []string{RoleOwner, RoleEditor, RoleAuthor, RoleViewer}
+}
+
+// IsValidRole - известна ли роль.
+func IsValidRole(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+// HasPermission - есть ли у роли право; неизвестная роль не имеет никаких прав.
+func HasPermission(role string, permission Permission) bool {
+	for _, p := range rolePermissions[role] {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,101 @@
+package model
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+// Виды терминов таксономии.
+const (
+	TermKindTag      = "tag"
+	TermKindCategory = "category"
+)
+
+// RowTerm - тег или категория; категории образуют дерево через ParentID.
+type RowTerm struct {
+	ID        primitive.ObjectID  `bson:"_id"`
+	Kind      string              `bson:"kind"`
+	Name      string              `bson:"name"`
+	Slug      string              `bson:"slug"`
+	ParentID  *primitive.ObjectID `bson:"parentId,omitempty"`
+	CreatedAt time.Time           `bson:"createdAt"`
+}
+
+type TermResponse struct {
+	ID       primitive.ObjectID  `json:"id"`
+	Kind     string              `json:"kind"`
+	Name     string              `json:"name"`
+	Slug     string              `json:"slug"`
+	ParentID *primitive.ObjectID `json:"parentId,omitempty"`
+}
+
+// TermCount - термин с числом элементов, для боковой панели.
+type TermCount struct {
+	ID       primitive.ObjectID  `json:"id"`
+	Name     string              `json:"name"`
+	Slug     string              `json:"slug"`
+	ParentID *primitive.ObjectID `json:"parentId,omitempty"`
+	Count    int                 `json:"count"`
+}
+
+// TaxonomyFacets - количество элементов по каждому тегу и категории.
+type TaxonomyFacets struct {
+	Tags       []TermCount `json:"tags"`
+	Categories []TermCount `json:"categories"`
+}
+
+// TermUsage - сколько элементов ссылается на каждый тег и категорию (результат агрегации).
+type TermUsage struct {
+	Tags       map[primitive.ObjectID]int
+	Categories map[primitive.ObjectID]int
+}
+
+// TaxonomyFilter - выборка по тегам и категориям. nil - фильтра нет; пустой, но не nil
+// срез - фильтр задан, но ни один термин не найден, и результат будет пустым.
+type TaxonomyFilter struct {
+	Tags       []primitive.ObjectID // хотя бы один из тегов
+	Categories []primitive.ObjectID // хотя бы одна из категорий (вместе с подкатегориями)
+}
+
+func (t *RowTerm) CreateTermResp() *TermResponse {
+	return &TermResponse{
+		ID:       t.ID,
+		Kind:     t.Kind,
+		Name:     t.Name,
+		Slug:     t.Slug,
+		ParentID: t.ParentID,
+	}
+}
+
+// DescendantCategories - ids категорий вместе со всеми их подкатегориями.
+func DescendantCategories(categories []RowTerm, roots []primitive.ObjectID) []primitive.ObjectID {
+	children := make(map[primitive.ObjectID][]primitive.ObjectID)
+	for _, category := range categories {
+		if category.ParentID != nil {
+			children[*category.ParentID] = append(children[*category.ParentID], category.ID)
+		}
+	}
+
+	result := make([]primitive.ObjectID, 0, len(roots))
+	seen := make(map[primitive.ObjectID]bool)
+	queue := append([]primitive.ObjectID(nil), roots...)
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		result = append(result, id)
+		queue = append(queue, children[id]...)
+	}
+	return result
+}
+
+// idsOrEmpty - пустой массив вместо null в JSON.
+func idsOrEmpty(ids []primitive.ObjectID) []primitive.ObjectID {
+	if ids == nil {
+		return []primitive.ObjectID{}
+	}
+	return ids
+}
@@ -0,0 +1,26 @@
+package model
+
+// TwoFactor - настройки TOTP пользователя.
+type TwoFactor struct {
+	Enabled       bool     `bson:"enabled"`
+	Secret        string   `bson:"secret,omitempty"`        // base32 секрет подтверждённого приложения
+	PendingSecret string   `bson:"pendingSecret,omitempty"` // новый секрет, ждёт подтверждения первым кодом
+	RecoveryCodes []string `bson:"recoveryCodes,omitempty"` // bcrypt-хэши неиспользованных кодов восстановления
+	LastUsedStep  int64    `bson:"lastUsedStep,omitempty"`  // последний принятый интервал, код нельзя использовать повторно
+}
+
+// IsEnabled - включена ли двухфакторная аутентификация.
+func (t *TwoFactor) IsEnabled() bool {
+	return t != nil && t.Enabled && t.Secret != ""
+}
+
+// TwoFactorSetupResponse - секрет для приложения-аутентификатора.
+type TwoFactorSetupResponse struct {
+	Secret     string `json:"secret"`
+	OtpauthURI string `json:"otpauthUri"`
+}
+
+// RecoveryCodesResponse - коды восстановления, показываются один раз.
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
@@ -6,40 +6,87 @@ import (
 	"time"
 )
 
+// PasswordHashCost - сложность bcrypt для паролей пользователей.
+const PasswordHashCost = 12
+
 // RowUser - структура для хранения данных user.
 type RowUser struct {
-	ID        primitive.ObjectID `bson:"_id"`
-	Email     string             `bson:"email"`
-	Phone     string             `bson:"phone"`
-	IsAdmin   bool               `bson:"IsAdmin"`
-	Password  string             `bson:"password"`
-	CreatedAt time.Time          `bson:"createdAt"`
-	UpdatedAt time.Time          `bson:"updatedAt"`
+	ID    primitive.ObjectID `bson:"_id"`
+	Email string             `bson:"email"`
+	Phone string             `bson:"phone"`
+	// Имя для отображения в интерфейсе и аватар (URL из /api/media)
+	DisplayName string     `bson:"displayName,omitempty"`
+	Avatar      *string    `bson:"avatar,omitempty"`
+	Role        string     `bson:"role,omitempty"`
+	IsAdmin     bool       `bson:"IsAdmin,omitempty"` // устарело: до ролей все пользователи были администраторами
+	Password    string     `bson:"password"`
+	CreatedAt   time.Time  `bson:"createdAt"`
+	UpdatedAt   time.Time  `bson:"updatedAt"`
+	DeletedAt   *time.Time `bson:"deletedAt,omitempty"`
+	// Отключённый пользователь не может войти, но остаётся в списке и не теряет данные
+	DisabledAt *time.Time `bson:"disabledAt,omitempty"`
+	// Access токены, выпущенные раньше этого момента, недействительны (выход из системы)
+	TokensRevokedAt *time.Time `bson:"tokensRevokedAt,omitempty"`
+	TwoFactor       *TwoFactor `bson:"twoFactor,omitempty"`
 }
 
 // UserResponse - for UI response
 type UserResponse struct {
-	ID        primitive.ObjectID `json:"id"`
-	Email     string             `json:"email"`
-	Phone     string             `json:"phone"`
-	IsAdmin   bool               `json:"isAdmin"`
-	CreatedAt time.Time          `json:"createdAt"`
-	UpdatedAt time.Time          `json:"updatedAt"`
+	ID          primitive.ObjectID `json:"id"`
+	Email       string             `json:"email"`
+	Phone       string             `json:"phone"`
+	DisplayName string             `json:"displayName,omitempty"`
+	Avatar      *string            `json:"avatar"`
+	Role        string             `json:"role"`
+	TwoFactor   bool               `json:"twoFactorEnabled"`
+	Disabled    bool               `json:"disabled"`
+	DisabledAt  *time.Time         `json:"disabledAt,omitempty"`
+	CreatedAt   time.Time          `json:"createdAt"`
+	UpdatedAt   time.Time          `json:"updatedAt"`
+	DeletedAt   *time.Time         `json:"deletedAt,omitempty"`
 }
 
 func (u *RowUser) CreateUserResp() *UserResponse {
 	return &UserResponse{
-		ID:        u.ID,
-		Email:     u.Email,
-		Phone:     u.Phone,
-		IsAdmin:   u.IsAdmin,
-		CreatedAt: u.CreatedAt,
-		UpdatedAt: u.UpdatedAt,
+		ID:          u.ID,
+		Email:       u.Email,
+		Phone:       u.Phone,
+		DisplayName: u.DisplayName,
+		Avatar:      u.Avatar,
+		Role:        u.EffectiveRole(),
+		TwoFactor:   u.TwoFactor.IsEnabled(),
+		Disabled:    u.IsDisabled(),
+		DisabledAt:  u.DisabledAt,
+		CreatedAt:   u.CreatedAt,
+		UpdatedAt:   u.UpdatedAt,
+		DeletedAt:   u.DeletedAt,
+	}
+}
+
+// EffectiveRole - роль пользователя. У записей, созданных до появления ролей, её нет:
+// бывшие администраторы становятся владельцами, остальные получают только чтение.
+func (u *RowUser) EffectiveRole() string {
+	if u.Role != "" {
+		return u.Role
+	}
+	if u.IsAdmin {
+		return RoleOwner
 	}
+	return RoleViewer
+}
+
+// IsDisabled - отключён ли пользователь владельцем.
+func (u *RowUser) IsDisabled() bool {
+	return u.DisabledAt != nil
+}
+
+// IsActiveOwner - владелец, который может войти; последнего такого нельзя удалить, понизить или отключить.
+func (u *RowUser) IsActiveOwner() bool {
+	return u.EffectiveRole() == RoleOwner && !u.IsDisabled()
 }
 
 func (u *RowUser) HashPassword() error {
-	hashedPassword, err := utils.HashData(u.Password, 12)
+	hashedPassword, err := utils.HashData(u.Password, PasswordHashCost)
 	if err != nil {
 		return err
 	}
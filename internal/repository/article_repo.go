@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"edjr-trk/configs/env"
+	configMongo "edjr-trk/configs/mongo"
 	"edjr-trk/internal/api/dto"
 	"edjr-trk/internal/model"
 	"edjr-trk/pkg/utils"
@@ -12,6 +13,7 @@ import (
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.uber.org/zap"
+	"time"
 )
 
 // ArticleRepositoryInterface - интерфейс для работы с коллекцией статей.
@@ -19,8 +21,20 @@ type ArticleRepositoryInterface interface {
 	Create(ctx context.Context, article model.RowArticle) (model.RowArticle, error)
 	PatchArticleById(ctx context.Context, dto *dto.PatchArticleRequest, id string) (*model.RowArticle, error)
 	GetArticleById(ctx context.Context, id string) (*model.RowArticle, error)
-	GetAll(ctx context.Context, pageNumber, pageSize int) ([]model.RowArticle, int, error)
+	GetArticleBySlug(ctx context.Context, slug string) (*model.RowArticle, error)
+	IsArticleSlugTaken(ctx context.Context, slug string, exceptID primitive.ObjectID) (bool, error)
+	GetArticlesWithoutSlug(ctx context.Context) ([]model.RowArticle, error)
+	GetAll(ctx context.Context, filter model.ArticleFilter, pageNumber, pageSize int) ([]model.RowArticle, int, error)
+	Search(ctx context.Context, filter model.ArticleFilter, query string, pageNumber, pageSize int) ([]model.RowArticle, int, error)
+	GetAllByCursor(ctx context.Context, filter model.ArticleFilter, cursor *utils.PageCursor, pageSize int) ([]model.RowArticle, bool, error)
+	ReplaceArticle(ctx context.Context, article model.RowArticle) (*model.RowArticle, error)
 	RemoveArticleById(ctx context.Context, id string) error
+	RestoreArticleById(ctx context.Context, id string) error
+	GetDeletedArticles(ctx context.Context, pageNumber, pageSize int) ([]model.RowArticle, int, error)
+	PurgeDeletedArticles(ctx context.Context, before time.Time) ([]model.RowArticle, error)
+	GetArticlesWithInlineImg(ctx context.Context) ([]model.RowArticle, error)
+	CountArticleTerms(ctx context.Context, filter model.ArticleFilter, query string) (model.TermUsage, error)
+	RemoveTermReferences(ctx context.Context, kind string, id primitive.ObjectID) (int64, error)
 }
 
 // articleRepository - конкретная реализация интерфейса.
@@ -33,13 +47,42 @@ type articleRepository struct {
 func NewArticleRepository(client *mongo.Client, logger *zap.Logger) ArticleRepositoryInterface {
 	return &articleRepository{
 		//collection: client.Database("test").Collection("articles"),
-		collection: client.Database(env.GetEnv("MONGO_DB_NAME", "")).Collection("articles"),
+		collection: client.Database(env.GetEnv("MONGO_DB_NAME", "")).Collection(configMongo.ArticleCollection),
 		logger:     logger,
 	}
 }
 
+// articleSortField - поле, по которому упорядочен список: публичная лента по моменту публикации,
+// админка по дате создания.
+func articleSortField(filter model.ArticleFilter) string {
+	if filter.PublishedOnly {
+		return "publishAt"
+	}
+	return "date"
+}
+
+// articleStatusFilter - переводит ArticleFilter в условие MongoDB.
+func articleStatusFilter(filter model.ArticleFilter, now time.Time) bson.M {
+	if filter.PublishedOnly {
+		// Статьи без статуса созданы до появления workflow и считаются опубликованными
+		return bson.M{"$or": bson.A{
+			bson.M{"status": bson.M{"$exists": false}},
+			bson.M{
+				"status":    bson.M{"$in": bson.A{model.ArticleStatusPublished, model.ArticleStatusScheduled}},
+				"publishAt": bson.M{"$lte": now},
+			},
+		}}
+	}
+
+	if filter.Status != "" {
+		return bson.M{"status": filter.Status}
+	}
+
+	return bson.M{}
+}
+
 // GetAll - get all articles with sort(desc) and pagination
-func (r *articleRepository) GetAll(ctx context.Context, pageNumber, pageSize int) ([]model.RowArticle, int, error) {
+func (r *articleRepository) GetAll(ctx context.Context, filter model.ArticleFilter, pageNumber, pageSize int) ([]model.RowArticle, int, error) {
 	if pageNumber < 1 {
 		pageNumber = 1
 	}
@@ -49,21 +92,25 @@ func (r *articleRepository) GetAll(ctx context.Context, pageNumber, pageSize int
 
 	skip := utils.CalculateOffset(pageNumber, pageSize)
 
+	query := withTaxonomy(notDeleted(articleStatusFilter(filter, time.Now())), filter.TaxonomyFilter)
+
 	//common document count
-	totalCount64, err := r.collection.CountDocuments(ctx, bson.M{})
+	totalCount64, err := r.collection.CountDocuments(ctx, query)
 	if err != nil {
 		r.logger.Error("Failed to count articles", zap.Error(err))
 		return nil, 0, err
 	}
 	totalCount := int(totalCount64)
+
 	// Setting up search parameters with sorting
+	sortField := articleSortField(filter)
 	findOptions := options.Find().
 		SetSkip(int64(skip)).
 		SetLimit(int64(pageSize)).
-		SetSort(bson.D{{Key: "date", Value: -1}}) // sort by desc
+		SetSort(bson.D{{Key: sortField, Value: -1}, {Key: "_id", Value: -1}}) // sort by desc
 
 	// Retrieving data with pagination and sorting
-	cursor, err := r.collection.Find(ctx, bson.M{}, findOptions)
+	cursor, err := r.collection.Find(ctx, query, findOptions)
 	if err != nil {
 		r.logger.Error("Failed to find articles", zap.Error(err))
 		return nil, 0, err
@@ -94,6 +141,99 @@ func (r *articleRepository) GetAll(ctx context.Context, pageNumber, pageSize int
 	return articles, totalCount, nil
 }
 
+// GetAllByCursor - keyset pagination by (date, _id), for published articles by (publishAt, _id);
+// returns whether more items exist in the cursor direction
+func (r *articleRepository) GetAllByCursor(ctx context.Context, filter model.ArticleFilter, cursor *utils.PageCursor, pageSize int) ([]model.RowArticle, bool, error) {
+	if pageSize < 1 {
+		pageSize = 10
+	}
+
+	base := withTaxonomy(notDeleted(articleStatusFilter(filter, time.Now())), filter.TaxonomyFilter)
+
+	query, findOptions := keysetQuery(base, articleSortField(filter), cursor, pageSize)
+
+	cursorDB, err := r.collection.Find(ctx, query, findOptions)
+	if err != nil {
+		r.logger.Error("Failed to find articles", zap.Error(err))
+		return nil, false, err
+	}
+	defer func() {
+		if closeErr := cursorDB.Close(ctx); closeErr != nil {
+			r.logger.Warn("Failed to close cursor", zap.Error(closeErr))
+		}
+	}()
+
+	articles, decodeErr := utils.DecodeCursor[model.RowArticle](ctx, cursorDB, r.logger)
+	if decodeErr != nil {
+		return nil, false, decodeErr
+	}
+
+	articles, hasMore := trimKeysetPage(articles, cursor, pageSize)
+
+	r.logger.Info("Articles fetched successfully with cursor pagination",
+		zap.Int("pageSize", pageSize),
+		zap.Int("fetchedItems", len(articles)),
+		zap.Bool("hasMore", hasMore),
+	)
+
+	return articles, hasMore, nil
+}
+
+// Search - full-text search over title/text ranked by relevance, with pagination
+func (r *articleRepository) Search(ctx context.Context, filter model.ArticleFilter, query string, pageNumber, pageSize int) ([]model.RowArticle, int, error) {
+	if pageNumber < 1 {
+		pageNumber = 1
+	}
+	if pageSize < 1 {
+		pageSize = 10
+	}
+
+	skip := utils.CalculateOffset(pageNumber, pageSize)
+	searchFilter := withTaxonomy(notDeleted(articleStatusFilter(filter, time.Now())), filter.TaxonomyFilter)
+	searchFilter["$text"] = bson.M{"$search": query}
+
+	totalCount64, err := r.collection.CountDocuments(ctx, searchFilter)
+	if err != nil {
+		r.logger.Error("Failed to count found articles", zap.Error(err))
+		return nil, 0, err
+	}
+	totalCount := int(totalCount64)
+
+	// Sort by relevance, newest first for equal scores
+	score := bson.M{"$meta": "textScore"}
+	findOptions := options.Find().
+		SetProjection(bson.M{"score": score}).
+		SetSkip(int64(skip)).
+		SetLimit(int64(pageSize)).
+		SetSort(bson.D{{Key: "score", Value: score}, {Key: "date", Value: -1}})
+
+	cursor, err := r.collection.Find(ctx, searchFilter, findOptions)
+	if err != nil {
+		r.logger.Error("Failed to search articles", zap.Error(err))
+		return nil, 0, err
+	}
+	defer func() {
+		if closeErr := cursor.Close(ctx); closeErr != nil {
+			r.logger.Warn("Failed to close cursor", zap.Error(closeErr))
+		}
+	}()
+
+	articles, decodeErr := utils.DecodeCursor[model.RowArticle](ctx, cursor, r.logger)
+	if decodeErr != nil {
+		return nil, 0, decodeErr
+	}
+
+	r.logger.Info("Articles searched successfully",
+		zap.String("query", query),
+		zap.Int("pageNumber", pageNumber),
+		zap.Int("pageSize", pageSize),
+		zap.Int("totalCount", totalCount),
+		zap.Int("fetchedItems", len(articles)),
+	)
+
+	return articles, totalCount, nil
+}
+
 // Create -create new article
 func (r *articleRepository) Create(ctx context.Context, article model.RowArticle) (model.RowArticle, error) {
 	// Вставка статьи в коллекцию.
@@ -130,7 +270,7 @@ func (r *articleRepository) GetArticleById(ctx context.Context, id string) (*mod
 	}
 
 	// Поиск статьи по ID в коллекции MongoDB.
-	err = r.collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&article)
+	err = r.collection.FindOne(ctx, notDeleted(bson.M{"_id": objectID})).Decode(&article)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
 			r.logger.Warn("Article not found", zap.String("id", id))
@@ -152,16 +292,55 @@ func (r *articleRepository) PatchArticleById(ctx context.Context, dto *dto.Patch
 	}
 
 	// Формирование обновления
+	// Тексты на языке, отличном от языка оригинала, пишутся в перевод
+	textField := func(name string) string {
+		if dto.Locale == nil {
+			return name
+		}
+		return "translations." + *dto.Locale + "." + name
+	}
+
 	update := bson.M{}
 	if dto.Title != nil {
-		update["title"] = *dto.Title
+		update[textField("title")] = *dto.Title
 	}
 	if dto.Text != nil {
-		update["text"] = *dto.Text
+		update[textField("text")] = *dto.Text
 	}
 	if dto.Img != nil {
 		update["img"] = *dto.Img
 	}
+	if dto.Status != nil {
+		update["status"] = *dto.Status
+	}
+	if dto.PublishAt != nil {
+		update["publishAt"] = *dto.PublishAt
+	}
+	if dto.Tags != nil {
+		if update["tags"], err = utils.ObjectIDsFromHex(*dto.Tags); err != nil {
+			return nil, err
+		}
+	}
+	if dto.Categories != nil {
+		if update["categories"], err = utils.ObjectIDsFromHex(*dto.Categories); err != nil {
+			return nil, err
+		}
+	}
+
+	if dto.Slug != nil {
+		slugUpdate, err := slugRenameUpdate(ctx, r.collection, objectID, *dto.Slug)
+		if err != nil {
+			if errors.Is(err, mongo.ErrNoDocuments) {
+				r.logger.Warn("Article not found for update", zap.String("id", id))
+				return nil, mongo.ErrNoDocuments
+			}
+			r.logger.Error("Failed to read current slug", zap.String("id", id), zap.Error(err))
+			return nil, err
+		}
+		for key, value := range slugUpdate {
+			update[key] = value
+		}
+	}
 
 	if len(update) == 0 {
 		r.logger.Warn("No fields to update", zap.String("id", id))
@@ -171,7 +350,7 @@ func (r *articleRepository) PatchArticleById(ctx context.Context, dto *dto.Patch
 	// Обновление статьи в MongoDB
 	updateResult, err := r.collection.UpdateOne(
 		ctx,
-		bson.M{"_id": objectID},
+		notDeleted(bson.M{"_id": objectID}),
 		bson.M{"$set": update},
 	)
 	if err != nil {
@@ -196,21 +375,156 @@ func (r *articleRepository) PatchArticleById(ctx context.Context, dto *dto.Patch
 	return updatedArticle, nil
 }
 
+// ReplaceArticle - заменяет документ статьи целиком (восстановление ревизии).
+func (r *articleRepository) ReplaceArticle(ctx context.Context, article model.RowArticle) (*model.RowArticle, error) {
+	result, err := r.collection.ReplaceOne(ctx, notDeleted(bson.M{"_id": article.ID}), article)
+	if err != nil {
+		r.logger.Error("Failed to replace article", zap.String("id", article.ID.Hex()), zap.Error(err))
+		return nil, err
+	}
+
+	if result.MatchedCount == 0 {
+		r.logger.Warn("Article not found for replace", zap.String("id", article.ID.Hex()))
+		return nil, mongo.ErrNoDocuments
+	}
+
+	r.logger.Info("Article replaced successfully", zap.String("id", article.ID.Hex()))
+	return &article, nil
+}
+
 // RemoveArticleById - находит статью по ObjectID.
 func (r *articleRepository) RemoveArticleById(ctx context.Context, id string) error {
 	// Преобразование строки в ObjectID
 	objectID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		r.logger.Error("Invalid ID format", zap.String("id", id), zap.Error(err))
+		return mongo.ErrNoDocuments
+	}
+
+	// Мягкое удаление: документ уходит в корзину и исключается из всех обычных запросов
+	if err := softDelete(ctx, r.collection, objectID); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			r.logger.Warn("Article not found for delete", zap.String("id", id))
+			return err
+		}
+		r.logger.Error("Failed to delete article", zap.String("id", id), zap.Error(err))
 		return err
 	}
 
-	_, err = r.collection.DeleteOne(ctx, bson.M{"_id": objectID})
+	r.logger.Info("Article moved to trash", zap.String("id", id))
+	return nil
+}
+
+// RestoreArticleById - возвращает статью из корзины.
+func (r *articleRepository) RestoreArticleById(ctx context.Context, id string) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		r.logger.Error("Failed to delete article", zap.String("id", id), zap.Error(err))
+		r.logger.Error("Invalid ID format", zap.String("id", id), zap.Error(err))
+		return mongo.ErrNoDocuments
+	}
+
+	if err := restoreDeleted(ctx, r.collection, objectID); err != nil {
+		if !errors.Is(err, mongo.ErrNoDocuments) {
+			r.logger.Error("Failed to restore article", zap.String("id", id), zap.Error(err))
+		}
 		return err
 	}
 
-	r.logger.Info("Article successfully deleted", zap.String("id", id))
+	r.logger.Info("Article restored from trash", zap.String("id", id))
 	return nil
 }
+
+// GetDeletedArticles - содержимое корзины с пагинацией.
+func (r *articleRepository) GetDeletedArticles(ctx context.Context, pageNumber, pageSize int) ([]model.RowArticle, int, error) {
+	return findDeleted[model.RowArticle](ctx, r.collection, pageNumber, pageSize, r.logger)
+}
+
+// PurgeDeletedArticles - окончательно удаляет статьи из корзины, удалённые не позже before, и возвращает их.
+func (r *articleRepository) PurgeDeletedArticles(ctx context.Context, before time.Time) ([]model.RowArticle, error) {
+	purged, err := purgeDeleted[model.RowArticle](ctx, r.collection, before, r.logger)
+	if err != nil {
+		r.logger.Error("Failed to purge deleted articles", zap.Error(err))
+		return purged, err
+	}
+
+	r.logger.Info("Deleted articles purged", zap.Int("count", len(purged)))
+	return purged, nil
+}
+
+// GetArticlesWithInlineImg - статьи, у которых изображение всё ещё хранится как base64 data URI.
+func (r *articleRepository) GetArticlesWithInlineImg(ctx context.Context) ([]model.RowArticle, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{"img": bson.M{"$regex": "^data:"}})
+	if err != nil {
+		r.logger.Error("Failed to find articles with inline images", zap.Error(err))
+		return nil, err
+	}
+	defer func() {
+		if closeErr := cursor.Close(ctx); closeErr != nil {
+			r.logger.Warn("Failed to close cursor", zap.Error(closeErr))
+		}
+	}()
+
+	return utils.DecodeCursor[model.RowArticle](ctx, cursor, r.logger)
+}
+
+// GetArticleBySlug - статья по текущему или прежнему slug.
+func (r *articleRepository) GetArticleBySlug(ctx context.Context, slug string) (*model.RowArticle, error) {
+	article, err := findBySlug[model.RowArticle](ctx, r.collection, slug)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			r.logger.Warn("Article not found by slug", zap.String("slug", slug))
+			return nil, mongo.ErrNoDocuments
+		}
+		r.logger.Error("Failed to query database", zap.String("slug", slug), zap.Error(err))
+		return nil, err
+	}
+
+	return article, nil
+}
+
+// IsArticleSlugTaken - занят ли slug другим документом (exceptID - сам редактируемый документ).
+func (r *articleRepository) IsArticleSlugTaken(ctx context.Context, slug string, exceptID primitive.ObjectID) (bool, error) {
+	taken, err := isSlugTaken(ctx, r.collection, slug, exceptID)
+	if err != nil {
+		r.logger.Error("Failed to check slug", zap.String("slug", slug), zap.Error(err))
+	}
+	return taken, err
+}
+
+// GetArticlesWithoutSlug - документы, созданные до появления slug.
+func (r *articleRepository) GetArticlesWithoutSlug(ctx context.Context) ([]model.RowArticle, error) {
+	cursor, err := r.collection.Find(ctx, notDeleted(bson.M{"slug": bson.M{"$exists": false}}))
+	if err != nil {
+		r.logger.Error("Failed to find articles without slug", zap.Error(err))
+		return nil, err
+	}
+	defer func() {
+		if closeErr := cursor.Close(ctx); closeErr != nil {
+			r.logger.Warn("Failed to close cursor", zap.Error(closeErr))
+		}
+	}()
+
+	return utils.DecodeCursor[model.RowArticle](ctx, cursor, r.logger)
+}
+
+// CountArticleTerms - число статей на каждый тег и категорию; условия по таксономии из filter не учитываются,
+// с query - только среди найденных.
+func (r *articleRepository) CountArticleTerms(ctx context.Context, filter model.ArticleFilter, query string) (model.TermUsage, error) {
+	usage, err := countTerms(ctx, r.collection, withTextSearch(notDeleted(articleStatusFilter(filter, time.Now())), query))
+	if err != nil {
+		r.logger.Error("Failed to count article terms", zap.Error(err))
+	}
+	return usage, err
+}
+
+// RemoveTermReferences - убирает удалённый тег или категорию из статей.
+func (r *articleRepository) RemoveTermReferences(ctx context.Context, kind string, id primitive.ObjectID) (int64, error) {
+	modified, err := pullTermReferences(ctx, r.collection, kind, id)
+	if err != nil {
+		r.logger.Error("Failed to remove term references from articles", zap.String("termId", id.Hex()), zap.Error(err))
+		return 0, err
+	}
+
+	r.logger.Info("Term references removed from articles", zap.String("termId", id.Hex()), zap.Int64("count", modified))
+	return modified, nil
+}
@@ -0,0 +1,206 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"edjr-trk/configs/env"
+	configMongo "edjr-trk/configs/mongo"
+	"errors"
+	"fmt"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/gridfs"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	BlobBackendLocal  = "local"
+	BlobBackendGridFS = "gridfs"
+)
+
+// ErrBlobNotFound is returned when the requested blob does not exist in the store.
+var ErrBlobNotFound = errors.New("blob not found")
+
+// BlobRepositoryInterface - хранилище бинарных данных (изображений и т.п.).
+type BlobRepositoryInterface interface {
+	Backend() string
+	Put(ctx context.Context, key string, data []byte) error
+	Get(ctx context.Context, key string) ([]byte, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// NewBlobRepository - выбирает реализацию хранилища по переменной MEDIA_STORAGE.
+func NewBlobRepository(client *mongo.Client, logger *zap.Logger) (BlobRepositoryInterface, error) {
+	switch backend := env.GetEnv("MEDIA_STORAGE", BlobBackendLocal); backend {
+	case BlobBackendLocal:
+		return NewLocalBlobRepository(env.GetEnv("MEDIA_LOCAL_DIR", "./media"), logger)
+	case BlobBackendGridFS:
+		return NewGridFSBlobRepository(client, logger)
+	default:
+		return nil, fmt.Errorf("unknown MEDIA_STORAGE backend %q", backend)
+	}
+}
+
+// localBlobRepository - хранит файлы в директории на диске.
+type localBlobRepository struct {
+	dir    string
+	logger *zap.Logger
+}
+
+func NewLocalBlobRepository(dir string, logger *zap.Logger) (BlobRepositoryInterface, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &localBlobRepository{dir: dir, logger: logger}, nil
+}
+
+func (r *localBlobRepository) Backend() string {
+	return BlobBackendLocal
+}
+
+func (r *localBlobRepository) Put(_ context.Context, key string, data []byte) error {
+	path, err := r.path(key)
+	if err != nil {
+		return err
+	}
+
+	// Пишем во временный файл и переименовываем, чтобы не отдавать наполовину записанный файл
+	tmp, err := os.CreateTemp(r.dir, ".upload-*")
+	if err != nil {
+		r.logger.Error("Failed to create temp file", zap.Error(err))
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		r.logger.Error("Failed to write blob", zap.String("key", key), zap.Error(err))
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		r.logger.Error("Failed to move blob into place", zap.String("key", key), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (r *localBlobRepository) Get(_ context.Context, key string) ([]byte, error) {
+	path, err := r.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrBlobNotFound
+		}
+		r.logger.Error("Failed to read blob", zap.String("key", key), zap.Error(err))
+		return nil, err
+	}
+	return data, nil
+}
+
+func (r *localBlobRepository) Delete(_ context.Context, key string) error {
+	path, err := r.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		r.logger.Error("Failed to delete blob", zap.String("key", key), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// path - защищает от выхода за пределы директории хранилища.
+func (r *localBlobRepository) path(key string) (string, error) {
+	if key == "" || filepath.Base(key) != key {
+		return "", fmt.Errorf("invalid blob key %q", key)
+	}
+	return filepath.Join(r.dir, key), nil
+}
+
+// gridFSBlobRepository - хранит файлы в MongoDB GridFS.
+type gridFSBlobRepository struct {
+	bucket *gridfs.Bucket
+	logger *zap.Logger
+}
+
+func NewGridFSBlobRepository(client *mongo.Client, logger *zap.Logger) (BlobRepositoryInterface, error) {
+	db := client.Database(env.GetEnv("MONGO_DB_NAME", ""))
+	bucket, err := gridfs.NewBucket(db, options.GridFSBucket().SetName(configMongo.MediaBucket))
+	if err != nil {
+		return nil, err
+	}
+	return &gridFSBlobRepository{bucket: bucket, logger: logger}, nil
+}
+
+func (r *gridFSBlobRepository) Backend() string {
+	return BlobBackendGridFS
+}
+
+func (r *gridFSBlobRepository) Put(ctx context.Context, key string, data []byte) error {
+	stream, err := r.bucket.OpenUploadStreamWithID(key, key)
+	if err != nil {
+		r.logger.Error("Failed to open GridFS upload stream", zap.String("key", key), zap.Error(err))
+		return err
+	}
+	// Дедлайн ставим на поток, а не на bucket: bucket общий для всех запросов
+	_ = stream.SetWriteDeadline(streamDeadline(ctx))
+
+	if _, err := stream.Write(data); err != nil {
+		_ = stream.Abort()
+		r.logger.Error("Failed to upload blob to GridFS", zap.String("key", key), zap.Error(err))
+		return err
+	}
+	if err := stream.Close(); err != nil {
+		r.logger.Error("Failed to upload blob to GridFS", zap.String("key", key), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (r *gridFSBlobRepository) Get(ctx context.Context, key string) ([]byte, error) {
+	stream, err := r.bucket.OpenDownloadStream(key)
+	if err != nil {
+		if errors.Is(err, gridfs.ErrFileNotFound) {
+			return nil, ErrBlobNotFound
+		}
+		r.logger.Error("Failed to open GridFS download stream", zap.String("key", key), zap.Error(err))
+		return nil, err
+	}
+	defer stream.Close()
+	_ = stream.SetReadDeadline(streamDeadline(ctx))
+
+	var buf bytes.Buffer
+	if _, err := io.Copy(&buf, stream); err != nil {
+		r.logger.Error("Failed to download blob from GridFS", zap.String("key", key), zap.Error(err))
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (r *gridFSBlobRepository) Delete(ctx context.Context, key string) error {
+	if err := r.bucket.DeleteContext(ctx, key); err != nil && !errors.Is(err, gridfs.ErrFileNotFound) {
+		r.logger.Error("Failed to delete blob from GridFS", zap.String("key", key), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// streamDeadline - GridFS stream API не принимает context, поэтому переносим его дедлайн.
+// Без дедлайна получаем нулевое время, что снимает ограничение.
+func streamDeadline(ctx context.Context) time.Time {
+	deadline, _ := ctx.Deadline()
+	return deadline
+}
@@ -1,39 +1,182 @@
 package repository
 
 import (
+	"bytes"
+	"context"
+	"edjr-trk/configs/env"
+	"edjr-trk/pkg/utils"
+	"errors"
 	"fmt"
 	"go.uber.org/zap"
-	"net/smtp"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"time"
 )
 
+const (
+	EmailTransportSMTP = "smtp"
+	EmailTransportFile = "file" // maildir на диске, для разработки
+	EmailTransportHTTP = "http" // JSON API почтового провайдера
+)
+
+// ErrMailFromNotSet is returned when sending without a configured sender address.
+var ErrMailFromNotSet = errors.New("MAIL_FROM is not set")
+
+// EmailMessage - письмо для отправки; адрес отправителя задаёт транспорт.
+type EmailMessage struct {
+	To      string
+	ReplyTo string // необязательный, например адрес посетителя сайта
+	Subject string
+	HTML    string
+	Text    string // текстовая версия; пустая - письмо только в HTML
+}
+
+// EmailRepositoryInterface - транспорт исходящей почты. Отправка прерывается,
+// когда истекает ctx, но не позже собственного таймаута транспорта.
 type EmailRepositoryInterface interface {
-	SendEmail(from, password, to, subject, body string) error
+	SendEmail(ctx context.Context, msg *EmailMessage) error
 }
 
-type smtpEmailRepository struct {
-	host   string
-	port   string
-	logger *zap.Logger
+// NewEmailRepository - выбирает транспорт по переменной MAIL_TRANSPORT.
+// Переменные GMAIL_* читаются как значения по умолчанию для старых конфигураций.
+func NewEmailRepository(logger *zap.Logger) (EmailRepositoryInterface, error) {
+	from := env.GetEnv("MAIL_FROM", env.GetEnv("GMAIL_FROM", ""))
+
+	switch transport := env.GetEnv("MAIL_TRANSPORT", EmailTransportSMTP); transport {
+	case EmailTransportSMTP:
+		return NewSMTPEmailRepository(SMTPConfig{
+			Host:        env.GetEnv("SMTP_HOST", "smtp.gmail.com"),
+			Port:        env.GetEnv("SMTP_PORT", "587"),
+			Username:    env.GetEnv("SMTP_USERNAME", env.GetEnv("GMAIL_FROM", "")),
+			Password:    env.GetEnv("SMTP_PASSWORD", env.GetEnv("GMAIL_PASSWORD", "")),
+			TLSMode:     env.GetEnv("SMTP_TLS", SMTPStartTLS),
+			Timeout:     time.Duration(env.GetEnvInt("SMTP_TIMEOUT_SECONDS", 30)) * time.Second,
+			IdleTimeout: time.Duration(env.GetEnvInt("SMTP_IDLE_SECONDS", 60)) * time.Second,
+			MaxIdle:     env.GetEnvInt("SMTP_MAX_IDLE_CONNS", 2),
+		}, from, logger)
+	case EmailTransportFile:
+		return NewFileEmailRepository(env.GetEnv("MAIL_FILE_DIR", "./mail"), from, logger)
+	case EmailTransportHTTP:
+		return NewHTTPEmailRepository(
+			env.GetEnv("MAIL_HTTP_URL", ""),
+			env.GetEnv("MAIL_HTTP_TOKEN", ""),
+			time.Duration(env.GetEnvInt("MAIL_HTTP_TIMEOUT_SECONDS", 30))*time.Second,
+			from,
+			logger,
+		)
+	default:
+		return nil, fmt.Errorf("unknown MAIL_TRANSPORT %q", transport)
+	}
 }
 
-func NewSMTPEmailRepository(host, port string, logger *zap.Logger) EmailRepositoryInterface {
-	return &smtpEmailRepository{host: host, port: port, logger: logger}
+// parseFrom - пустой адрес допустим: сервер стартует, а отправка вернёт ErrMailFromNotSet.
+func parseFrom(from string) (*mail.Address, error) {
+	if from == "" {
+		return nil, nil
+	}
+	addr, err := mail.ParseAddress(from)
+	if err != nil {
+		return nil, fmt.Errorf("invalid MAIL_FROM %q: %w", from, err)
+	}
+	return addr, nil
 }
 
-func (r *smtpEmailRepository) SendEmail(from, password, to, subject, body string) error {
-	msg := []byte(fmt.Sprintf(
-		"Subject: %s\r\n"+
-			"Content-Type: text/html; charset=\"UTF-8\"\r\n"+
-			"\r\n"+
-			"%s",
-		subject, body,
-	))
+// formatMessage - письмо в формате RFC 5322 для SMTP и maildir: multipart/alternative
+// из текстовой и HTML версий, не-ASCII в заголовках кодируется по RFC 2047.
+func formatMessage(from *mail.Address, msg *EmailMessage, date time.Time) ([]byte, error) {
+	to, err := mail.ParseAddress(msg.To)
+	if err != nil {
+		return nil, fmt.Errorf("invalid recipient %q: %w", msg.To, err)
+	}
+	messageID, err := newMessageID(from)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	header := func(key, value string) {
+		fmt.Fprintf(&buf, "%s: %s\r\n", key, value)
+	}
+
+	if from != nil {
+		header("From", from.String())
+	}
+	header("To", to.String())
+	if msg.ReplyTo != "" {
+		replyTo, err := mail.ParseAddress(msg.ReplyTo)
+		if err != nil {
+			return nil, fmt.Errorf("invalid Reply-To %q: %w", msg.ReplyTo, err)
+		}
+		header("Reply-To", replyTo.String())
+	}
+	// Переводы строк в теме недопустимы: через них можно дописать свои заголовки
+	header("Subject", mime.QEncoding.Encode("utf-8", strings.Join(strings.Fields(msg.Subject), " ")))
+	header("Date", date.Format(time.RFC1123Z))
+	header("Message-ID", messageID)
+	header("MIME-Version", "1.0")
+
+	if msg.Text == "" {
+		header("Content-Type", `text/html; charset="UTF-8"`)
+		header("Content-Transfer-Encoding", "quoted-printable")
+		buf.WriteString("\r\n")
+		if err := writeQuotedPrintable(&buf, msg.HTML); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	parts := multipart.NewWriter(&buf)
+	header("Content-Type", mime.FormatMediaType("multipart/alternative", map[string]string{"boundary": parts.Boundary()}))
+	buf.WriteString("\r\n")
+
+	// Клиенты показывают последнюю понятную им часть, поэтому HTML идёт после текста
+	for _, part := range []struct{ contentType, body string }{
+		{"text/plain", msg.Text},
+		{"text/html", msg.HTML},
+	} {
+		w, err := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType + `; charset="UTF-8"`},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if err := writeQuotedPrintable(w, part.body); err != nil {
+			return nil, err
+		}
+	}
+	if err := parts.Close(); err != nil {
+		return nil, err
+	}
 
-	auth := smtp.PlainAuth("", from, password, r.host)
-	addr := fmt.Sprintf("%s:%s", r.host, r.port)
+	return buf.Bytes(), nil
+}
 
-	if err := smtp.SendMail(addr, auth, from, []string{to}, msg); err != nil {
+func writeQuotedPrintable(w io.Writer, body string) error {
+	qp := quotedprintable.NewWriter(w)
+	if _, err := qp.Write([]byte(body)); err != nil {
 		return err
 	}
-	return nil
+	return qp.Close()
+}
+
+// newMessageID - уникальный Message-ID в домене отправителя.
+func newMessageID(from *mail.Address) (string, error) {
+	token, err := utils.RandomToken(12)
+	if err != nil {
+		return "", err
+	}
+
+	domain := "localhost"
+	if from != nil {
+		if at := strings.LastIndexByte(from.Address, '@'); at >= 0 {
+			domain = from.Address[at+1:]
+		}
+	}
+	return fmt.Sprintf("<%d.%s@%s>", time.Now().UnixNano(), token, domain), nil
 }
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+	"edjr-trk/pkg/utils"
+	"fmt"
+	"go.uber.org/zap"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// fileEmailRepository - складывает письма в maildir вместо отправки. Для разработки:
+// каталог открывается любым почтовым клиентом с поддержкой maildir.
+type fileEmailRepository struct {
+	dir    string
+	from   *mail.Address
+	logger *zap.Logger
+}
+
+func NewFileEmailRepository(dir, from string, logger *zap.Logger) (EmailRepositoryInterface, error) {
+	addr, err := parseFrom(from)
+	if err != nil {
+		return nil, err
+	}
+	for _, sub := range []string{"tmp", "new", "cur"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0o755); err != nil {
+			return nil, err
+		}
+	}
+	return &fileEmailRepository{dir: dir, from: addr, logger: logger}, nil
+}
+
+// SendEmail - пишет письмо в tmp и переносит в new, как требует формат maildir.
+func (r *fileEmailRepository) SendEmail(ctx context.Context, msg *EmailMessage) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	data, err := formatMessage(r.from, msg, time.Now())
+	if err != nil {
+		return err
+	}
+
+	suffix, err := utils.RandomToken(8)
+	if err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%d.%s.edjr-trk", time.Now().UnixNano(), suffix)
+
+	tmp := filepath.Join(r.dir, "tmp", name)
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		r.logger.Error("Failed to write email file", zap.Error(err))
+		return err
+	}
+
+	path := filepath.Join(r.dir, "new", name)
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		r.logger.Error("Failed to move email file into place", zap.Error(err))
+		return err
+	}
+
+	r.logger.Info("Email written to maildir", zap.String("path", path), zap.String("to", msg.To))
+	return nil
+}
@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// httpEmailRepository - отправка через HTTP API провайдера: POST JSON
+// {"from", "to", "replyTo", "subject", "html", "text"} с необязательным Bearer токеном.
+// Заголовки и MIME формирует сам провайдер.
+type httpEmailRepository struct {
+	url    string
+	token  string
+	from   string
+	client *http.Client
+	logger *zap.Logger
+}
+
+func NewHTTPEmailRepository(endpoint, token string, timeout time.Duration, from string, logger *zap.Logger) (EmailRepositoryInterface, error) {
+	if endpoint == "" {
+		return nil, errors.New("MAIL_HTTP_URL must be set")
+	}
+	if _, err := url.ParseRequestURI(endpoint); err != nil {
+		return nil, fmt.Errorf("invalid MAIL_HTTP_URL: %w", err)
+	}
+	if _, err := parseFrom(from); err != nil {
+		return nil, err
+	}
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+
+	return &httpEmailRepository{
+		url:    endpoint,
+		token:  token,
+		from:   from,
+		client: &http.Client{Timeout: timeout},
+		logger: logger,
+	}, nil
+}
+
+func (r *httpEmailRepository) SendEmail(ctx context.Context, msg *EmailMessage) error {
+	if r.from == "" {
+		return ErrMailFromNotSet
+	}
+
+	fields := map[string]string{
+		"from":    r.from,
+		"to":      msg.To,
+		"subject": msg.Subject,
+		"html":    msg.HTML,
+	}
+	if msg.ReplyTo != "" {
+		fields["replyTo"] = msg.ReplyTo
+	}
+	if msg.Text != "" {
+		fields["text"] = msg.Text
+	}
+
+	payload, err := json.Marshal(fields)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if r.token != "" {
+		req.Header.Set("Authorization", "Bearer "+r.token)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		// Начало ответа попадает в lastError письма в очереди
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("mail API responded %d: %s", resp.StatusCode, bytes.TrimSpace(body))
+	}
+
+	io.Copy(io.Discard, resp.Body)
+	return nil
+}
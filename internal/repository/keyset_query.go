@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"edjr-trk/pkg/utils"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// keysetQuery builds the filter and find options for one page of a (field, _id) descending list.
+// One extra document is requested so the caller can tell whether another page exists.
+// Backward pages are fetched in ascending order and must be reversed by the caller.
+func keysetQuery(base bson.M, field string, cursor *utils.PageCursor, pageSize int) (bson.M, *options.FindOptions) {
+	order, cmp := keysetDirection(cursor)
+
+	filter := base
+	if cursor != nil {
+		filter = bson.M{"$and": bson.A{base, keysetPosition(field, cmp, cursor)}}
+	}
+
+	findOptions := options.Find().
+		SetLimit(int64(pageSize + 1)).
+		SetSort(bson.D{{Key: field, Value: order}, {Key: "_id", Value: order}})
+
+	return filter, findOptions
+}
+
+// keysetDirection - sort order and comparison operator for the cursor direction.
+func keysetDirection(cursor *utils.PageCursor) (int, string) {
+	if cursor != nil && cursor.Backward {
+		return 1, "$gt"
+	}
+	return -1, "$lt"
+}
+
+// keysetPosition - documents strictly after the cursor position in (field, _id) order.
+func keysetPosition(field, cmp string, cursor *utils.PageCursor) bson.M {
+	return bson.M{"$or": bson.A{
+		bson.M{field: bson.M{cmp: cursor.Date}},
+		bson.M{field: cursor.Date, "_id": bson.M{cmp: cursor.ID}},
+	}}
+}
+
+// trimKeysetPage cuts the extra document off and restores descending order for backward pages.
+func trimKeysetPage[T any](items []T, cursor *utils.PageCursor, pageSize int) ([]T, bool) {
+	hasMore := len(items) > pageSize
+	if hasMore {
+		items = items[:pageSize]
+	}
+
+	if cursor != nil && cursor.Backward {
+		for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
+			items[i], items[j] = items[j], items[i]
+		}
+	}
+
+	return items, hasMore
+}
@@ -0,0 +1,195 @@
+package repository
+
+import (
+	"context"
+	"edjr-trk/configs/env"
+	configMongo "edjr-trk/configs/mongo"
+	"edjr-trk/internal/api/dto"
+	"edjr-trk/internal/model"
+	"edjr-trk/pkg/utils"
+	"errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+	"time"
+)
+
+// LeadRepositoryInterface - интерфейс для работы с обращениями посетителей.
+type LeadRepositoryInterface interface {
+	Create(ctx context.Context, lead *model.RowLead) error
+	GetLeadById(ctx context.Context, id string) (*model.RowLead, error)
+	GetAll(ctx context.Context, filter model.LeadFilter, pageNumber, pageSize int) ([]model.RowLead, int, error)
+	PatchLeadById(ctx context.Context, dto *dto.PatchLeadRequest, id string) (*model.RowLead, error)
+	AddNote(ctx context.Context, id string, note model.LeadNote) (*model.RowLead, error)
+	SetDelivery(ctx context.Context, id primitive.ObjectID, delivery model.LeadDelivery) error
+}
+
+type leadRepository struct {
+	collection *mongo.Collection
+	logger     *zap.Logger
+}
+
+func NewLeadRepository(client *mongo.Client, logger *zap.Logger) LeadRepositoryInterface {
+	return &leadRepository{
+		collection: client.Database(env.GetEnv("MONGO_DB_NAME", "")).Collection(configMongo.LeadCollection),
+		logger:     logger,
+	}
+}
+
+func (r *leadRepository) Create(ctx context.Context, lead *model.RowLead) error {
+	if _, err := r.collection.InsertOne(ctx, lead); err != nil {
+		r.logger.Error("Failed to insert lead", zap.Error(err))
+		return err
+	}
+
+	r.logger.Info("Lead saved", zap.String("id", lead.ID.Hex()))
+	return nil
+}
+
+func (r *leadRepository) GetLeadById(ctx context.Context, id string) (*model.RowLead, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		r.logger.Error("Invalid ID format", zap.String("id", id), zap.Error(err))
+		return nil, mongo.ErrNoDocuments
+	}
+
+	var lead model.RowLead
+	if err := r.collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&lead); err != nil {
+		if !errors.Is(err, mongo.ErrNoDocuments) {
+			r.logger.Error("Failed to query lead", zap.String("id", id), zap.Error(err))
+		}
+		return nil, err
+	}
+	return &lead, nil
+}
+
+// GetAll - обращения от новых к старым с пагинацией.
+func (r *leadRepository) GetAll(ctx context.Context, filter model.LeadFilter, pageNumber, pageSize int) ([]model.RowLead, int, error) {
+	if pageNumber < 1 {
+		pageNumber = 1
+	}
+	if pageSize < 1 {
+		pageSize = 10
+	}
+
+	query := bson.M{}
+	if filter.Reference != "" {
+		query["reference"] = filter.Reference
+	}
+	if filter.Status != "" {
+		query["status"] = filter.Status
+	}
+	if filter.AssigneeID != nil {
+		query["assigneeId"] = *filter.AssigneeID
+	}
+
+	totalCount, err := r.collection.CountDocuments(ctx, query)
+	if err != nil {
+		r.logger.Error("Failed to count leads", zap.Error(err))
+		return nil, 0, err
+	}
+
+	findOptions := options.Find().
+		SetSkip(int64(utils.CalculateOffset(pageNumber, pageSize))).
+		SetLimit(int64(pageSize)).
+		SetSort(bson.D{{Key: "createdAt", Value: -1}, {Key: "_id", Value: -1}})
+
+	cursor, err := r.collection.Find(ctx, query, findOptions)
+	if err != nil {
+		r.logger.Error("Failed to find leads", zap.Error(err))
+		return nil, 0, err
+	}
+	defer func() {
+		if closeErr := cursor.Close(ctx); closeErr != nil {
+			r.logger.Warn("Failed to close cursor", zap.Error(closeErr))
+		}
+	}()
+
+	leads, err := utils.DecodeCursor[model.RowLead](ctx, cursor, r.logger)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return leads, int(totalCount), nil
+}
+
+// PatchLeadById - меняет статус и ответственного; пустой assigneeId снимает назначение.
+func (r *leadRepository) PatchLeadById(ctx context.Context, dto *dto.PatchLeadRequest, id string) (*model.RowLead, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		r.logger.Error("Invalid ID format", zap.String("id", id), zap.Error(err))
+		return nil, mongo.ErrNoDocuments
+	}
+
+	set := bson.M{"updatedAt": time.Now()}
+	unset := bson.M{}
+	if dto.Status != nil {
+		set["status"] = *dto.Status
+	}
+	if dto.AssigneeID != nil {
+		if *dto.AssigneeID == "" {
+			unset["assigneeId"] = ""
+		} else {
+			assigneeID, err := primitive.ObjectIDFromHex(*dto.AssigneeID)
+			if err != nil {
+				return nil, err
+			}
+			set["assigneeId"] = assigneeID
+		}
+	}
+
+	update := bson.M{"$set": set}
+	if len(unset) > 0 {
+		update["$unset"] = unset
+	}
+
+	return r.findOneAndUpdate(ctx, objectID, update)
+}
+
+// AddNote - добавляет заметку в конец списка.
+func (r *leadRepository) AddNote(ctx context.Context, id string, note model.LeadNote) (*model.RowLead, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		r.logger.Error("Invalid ID format", zap.String("id", id), zap.Error(err))
+		return nil, mongo.ErrNoDocuments
+	}
+
+	update := bson.M{
+		"$push": bson.M{"notes": note},
+		"$set":  bson.M{"updatedAt": note.CreatedAt},
+	}
+	return r.findOneAndUpdate(ctx, objectID, update)
+}
+
+// SetDelivery - записывает результат отправки письма об обращении.
+func (r *leadRepository) SetDelivery(ctx context.Context, id primitive.ObjectID, delivery model.LeadDelivery) error {
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{"delivery": delivery}})
+	if err != nil {
+		r.logger.Error("Failed to record lead delivery", zap.String("id", id.Hex()), zap.Error(err))
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+func (r *leadRepository) findOneAndUpdate(ctx context.Context, id primitive.ObjectID, update bson.M) (*model.RowLead, error) {
+	var lead model.RowLead
+	err := r.collection.FindOneAndUpdate(ctx,
+		bson.M{"_id": id},
+		update,
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&lead)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			r.logger.Warn("Lead not found for update", zap.String("id", id.Hex()))
+			return nil, err
+		}
+		r.logger.Error("Failed to update lead", zap.String("id", id.Hex()), zap.Error(err))
+		return nil, err
+	}
+	return &lead, nil
+}
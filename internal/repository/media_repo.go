@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"edjr-trk/configs/env"
+	configMongo "edjr-trk/configs/mongo"
+	"edjr-trk/internal/model"
+	"errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.uber.org/zap"
+)
+
+// MediaRepositoryInterface - интерфейс для работы с метаданными медиафайлов.
+type MediaRepositoryInterface interface {
+	Create(ctx context.Context, media *model.RowMedia) (*model.RowMedia, error)
+	GetMediaById(ctx context.Context, id string) (*model.RowMedia, error)
+	RemoveMediaById(ctx context.Context, id string) error
+}
+
+type mediaRepository struct {
+	collection *mongo.Collection
+	logger     *zap.Logger
+}
+
+func NewMediaRepository(client *mongo.Client, logger *zap.Logger) MediaRepositoryInterface {
+	return &mediaRepository{
+		collection: client.Database(env.GetEnv("MONGO_DB_NAME", "")).Collection(configMongo.MediaCollection),
+		logger:     logger,
+	}
+}
+
+func (r *mediaRepository) Create(ctx context.Context, media *model.RowMedia) (*model.RowMedia, error) {
+	if _, err := r.collection.InsertOne(ctx, media); err != nil {
+		r.logger.Error("Failed to insert media", zap.Error(err))
+		return nil, err
+	}
+
+	r.logger.Info("Media created successfully", zap.String("id", media.ID.Hex()))
+	return media, nil
+}
+
+func (r *mediaRepository) GetMediaById(ctx context.Context, id string) (*model.RowMedia, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		r.logger.Error("Invalid ID format", zap.String("id", id), zap.Error(err))
+		return nil, err
+	}
+
+	var media model.RowMedia
+	err = r.collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&media)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			r.logger.Warn("Media not found", zap.String("id", id))
+			return nil, mongo.ErrNoDocuments
+		}
+		r.logger.Error("Failed to query database", zap.String("id", id), zap.Error(err))
+		return nil, err
+	}
+
+	return &media, nil
+}
+
+func (r *mediaRepository) RemoveMediaById(ctx context.Context, id string) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		r.logger.Error("Invalid ID format", zap.String("id", id), zap.Error(err))
+		return err
+	}
+
+	if _, err := r.collection.DeleteOne(ctx, bson.M{"_id": objectID}); err != nil {
+		r.logger.Error("Failed to delete media", zap.String("id", id), zap.Error(err))
+		return err
+	}
+
+	r.logger.Info("Media successfully deleted", zap.String("id", id))
+	return nil
+}
@@ -0,0 +1,210 @@
+package repository
+
+import (
+	"context"
+	"edjr-trk/configs/env"
+	configMongo "edjr-trk/configs/mongo"
+	"edjr-trk/internal/model"
+	"edjr-trk/pkg/utils"
+	"errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+	"time"
+)
+
+// OutboxRepositoryInterface - очередь исходящих писем.
+type OutboxRepositoryInterface interface {
+	Enqueue(ctx context.Context, msg *model.RowOutboxMessage) error
+	ClaimNext(ctx context.Context, now time.Time, lease time.Duration) (*model.RowOutboxMessage, error)
+	MarkSent(ctx context.Context, id primitive.ObjectID, lockToken string, sentAt, expiresAt time.Time) (*model.RowOutboxMessage, error)
+	MarkFailed(ctx context.Context, id primitive.ObjectID, lockToken string, lastError string, nextAttemptAt time.Time, dead bool) (*model.RowOutboxMessage, error)
+	GetAll(ctx context.Context, status string, pageNumber, pageSize int) ([]model.RowOutboxMessage, int, error)
+	GetMessageById(ctx context.Context, id string) (*model.RowOutboxMessage, error)
+	Requeue(ctx context.Context, id string, now time.Time) (*model.RowOutboxMessage, error)
+}
+
+type outboxRepository struct {
+	collection *mongo.Collection
+	logger     *zap.Logger
+}
+
+func NewOutboxRepository(client *mongo.Client, logger *zap.Logger) OutboxRepositoryInterface {
+	return &outboxRepository{
+		collection: client.Database(env.GetEnv("MONGO_DB_NAME", "")).Collection(configMongo.OutboxCollection),
+		logger:     logger,
+	}
+}
+
+func (r *outboxRepository) Enqueue(ctx context.Context, msg *model.RowOutboxMessage) error {
+	if _, err := r.collection.InsertOne(ctx, msg); err != nil {
+		r.logger.Error("Failed to enqueue email", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// ClaimNext - атомарно берёт самое раннее готовое к отправке письмо и блокирует его на lease.
+// Письмо в состоянии sending с истёкшей блокировкой (воркер упал) берётся заново.
+// Если отправлять нечего, возвращает mongo.ErrNoDocuments.
+func (r *outboxRepository) ClaimNext(ctx context.Context, now time.Time, lease time.Duration) (*model.RowOutboxMessage, error) {
+	filter := bson.M{"$or": bson.A{
+		bson.M{"status": model.OutboxPending, "nextAttemptAt": bson.M{"$lte": now}},
+		bson.M{"status": model.OutboxSending, "lockedUntil": bson.M{"$lte": now}},
+	}}
+	// Письмо с истёкшей арендой может взять другой воркер; новая метка не даст
+	// прежнему воркеру записать итог своей попытки поверх этой
+	update := bson.M{
+		"$set": bson.M{
+			"status":      model.OutboxSending,
+			"lockedUntil": now.Add(lease),
+			"lockToken":   primitive.NewObjectID().Hex(),
+			"updatedAt":   now,
+		},
+		"$inc": bson.M{"attempts": 1},
+	}
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "nextAttemptAt", Value: 1}}).
+		SetReturnDocument(options.After)
+
+	var msg model.RowOutboxMessage
+	if err := r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&msg); err != nil {
+		if !errors.Is(err, mongo.ErrNoDocuments) {
+			r.logger.Error("Failed to claim outbox message", zap.Error(err))
+		}
+		return nil, err
+	}
+	return &msg, nil
+}
+
+// MarkSent - отмечает письмо отправленным. Как и MarkFailed, срабатывает только для захвата с lockToken;
+// если письмо уже взял другой воркер, возвращает mongo.ErrNoDocuments.
+func (r *outboxRepository) MarkSent(ctx context.Context, id primitive.ObjectID, lockToken string, sentAt, expiresAt time.Time) (*model.RowOutboxMessage, error) {
+	// Тело больше не нужно, а в нём бывают токены сброса пароля и данные посетителей;
+	// сама запись живёт до expiresAt, чтобы было видно, что письмо ушло
+	update := bson.M{
+		"$set":   bson.M{"status": model.OutboxSent, "sentAt": sentAt, "expiresAt": expiresAt, "updatedAt": sentAt},
+		"$unset": bson.M{"lockedUntil": "", "lockToken": "", "lastError": "", "body": "", "text": ""},
+	}
+	return r.findOneAndUpdate(ctx, claimedBy(id, lockToken), update)
+}
+
+// MarkFailed - записывает ошибку попытки и назначает следующую либо переводит письмо в dead.
+func (r *outboxRepository) MarkFailed(ctx context.Context, id primitive.ObjectID, lockToken string, lastError string, nextAttemptAt time.Time, dead bool) (*model.RowOutboxMessage, error) {
+	status := model.OutboxPending
+	if dead {
+		status = model.OutboxDead
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"status":        status,
+			"lastError":     lastError,
+			"nextAttemptAt": nextAttemptAt,
+			"updatedAt":     time.Now(),
+		},
+		"$unset": bson.M{"lockedUntil": "", "lockToken": ""},
+	}
+	return r.findOneAndUpdate(ctx, claimedBy(id, lockToken), update)
+}
+
+// claimedBy - письмо, которое всё ещё отправляет захват с lockToken.
+func claimedBy(id primitive.ObjectID, lockToken string) bson.M {
+	return bson.M{"_id": id, "status": model.OutboxSending, "lockToken": lockToken}
+}
+
+// GetAll - письма от новых к старым; пустой status не ограничивает выборку.
+func (r *outboxRepository) GetAll(ctx context.Context, status string, pageNumber, pageSize int) ([]model.RowOutboxMessage, int, error) {
+	if pageNumber < 1 {
+		pageNumber = 1
+	}
+	if pageSize < 1 {
+		pageSize = 10
+	}
+
+	query := bson.M{}
+	if status != "" {
+		query["status"] = status
+	}
+
+	totalCount, err := r.collection.CountDocuments(ctx, query)
+	if err != nil {
+		r.logger.Error("Failed to count outbox messages", zap.Error(err))
+		return nil, 0, err
+	}
+
+	findOptions := options.Find().
+		SetSkip(int64(utils.CalculateOffset(pageNumber, pageSize))).
+		SetLimit(int64(pageSize)).
+		SetSort(bson.D{{Key: "createdAt", Value: -1}, {Key: "_id", Value: -1}})
+
+	cursor, err := r.collection.Find(ctx, query, findOptions)
+	if err != nil {
+		r.logger.Error("Failed to find outbox messages", zap.Error(err))
+		return nil, 0, err
+	}
+	defer func() {
+		if closeErr := cursor.Close(ctx); closeErr != nil {
+			r.logger.Warn("Failed to close cursor", zap.Error(closeErr))
+		}
+	}()
+
+	messages, err := utils.DecodeCursor[model.RowOutboxMessage](ctx, cursor, r.logger)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return messages, int(totalCount), nil
+}
+
+func (r *outboxRepository) GetMessageById(ctx context.Context, id string) (*model.RowOutboxMessage, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		r.logger.Error("Invalid ID format", zap.String("id", id), zap.Error(err))
+		return nil, mongo.ErrNoDocuments
+	}
+
+	var msg model.RowOutboxMessage
+	if err := r.collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&msg); err != nil {
+		if !errors.Is(err, mongo.ErrNoDocuments) {
+			r.logger.Error("Failed to query outbox message", zap.String("id", id), zap.Error(err))
+		}
+		return nil, err
+	}
+	return &msg, nil
+}
+
+// Requeue - возвращает dead письмо в очередь с обнулённым счётчиком попыток.
+// Для письма в другом состоянии возвращает mongo.ErrNoDocuments.
+func (r *outboxRepository) Requeue(ctx context.Context, id string, now time.Time) (*model.RowOutboxMessage, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		r.logger.Error("Invalid ID format", zap.String("id", id), zap.Error(err))
+		return nil, mongo.ErrNoDocuments
+	}
+
+	update := bson.M{"$set": bson.M{
+		"status":        model.OutboxPending,
+		"attempts":      0,
+		"nextAttemptAt": now,
+		"updatedAt":     now,
+	}}
+	return r.findOneAndUpdate(ctx, bson.M{"_id": objectID, "status": model.OutboxDead}, update)
+}
+
+func (r *outboxRepository) findOneAndUpdate(ctx context.Context, filter bson.M, update bson.M) (*model.RowOutboxMessage, error) {
+	var msg model.RowOutboxMessage
+	err := r.collection.FindOneAndUpdate(ctx,
+		filter,
+		update,
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&msg)
+	if err != nil {
+		if !errors.Is(err, mongo.ErrNoDocuments) {
+			r.logger.Error("Failed to update outbox message", zap.Error(err))
+		}
+		return nil, err
+	}
+	return &msg, nil
+}
@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+	"edjr-trk/configs/env"
+	configMongo "edjr-trk/configs/mongo"
+	"edjr-trk/internal/model"
+	"errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.uber.org/zap"
+	"time"
+)
+
+// PasswordResetRepositoryInterface - интерфейс для работы с токенами сброса пароля.
+type PasswordResetRepositoryInterface interface {
+	Create(ctx context.Context, reset *model.RowPasswordReset) error
+	Consume(ctx context.Context, tokenHash string, at time.Time) (*model.RowPasswordReset, error)
+	InvalidateUserResets(ctx context.Context, userID primitive.ObjectID, at time.Time) error
+}
+
+type passwordResetRepository struct {
+	collection *mongo.Collection
+	logger     *zap.Logger
+}
+
+func NewPasswordResetRepository(client *mongo.Client, logger *zap.Logger) PasswordResetRepositoryInterface {
+	return &passwordResetRepository{
+		collection: client.Database(env.GetEnv("MONGO_DB_NAME", "")).Collection(configMongo.PasswordResetCollection),
+		logger:     logger,
+	}
+}
+
+func (r *passwordResetRepository) Create(ctx context.Context, reset *model.RowPasswordReset) error {
+	if _, err := r.collection.InsertOne(ctx, reset); err != nil {
+		r.logger.Error("Failed to insert password reset token", zap.String("userId", reset.UserID.Hex()), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// Consume - находит действующий токен и сразу помечает использованным, одним запросом:
+// из двух одновременных сбросов с одним токеном пройдёт только один.
+// Неизвестный, просроченный или уже использованный токен даёт mongo.ErrNoDocuments.
+func (r *passwordResetRepository) Consume(ctx context.Context, tokenHash string, at time.Time) (*model.RowPasswordReset, error) {
+	filter := bson.M{
+		"tokenHash": tokenHash,
+		"usedAt":    bson.M{"$exists": false},
+		"expiresAt": bson.M{"$gt": at},
+	}
+
+	var reset model.RowPasswordReset
+	err := r.collection.FindOneAndUpdate(ctx, filter, bson.M{"$set": bson.M{"usedAt": at}}).Decode(&reset)
+	if err != nil {
+		if !errors.Is(err, mongo.ErrNoDocuments) {
+			r.logger.Error("Failed to consume password reset token", zap.Error(err))
+		}
+		return nil, err
+	}
+	return &reset, nil
+}
+
+// InvalidateUserResets - помечает использованными все ещё не использованные токены пользователя.
+func (r *passwordResetRepository) InvalidateUserResets(ctx context.Context, userID primitive.ObjectID, at time.Time) error {
+	filter := bson.M{"userId": userID, "usedAt": bson.M{"$exists": false}}
+
+	if _, err := r.collection.UpdateMany(ctx, filter, bson.M{"$set": bson.M{"usedAt": at}}); err != nil {
+		r.logger.Error("Failed to invalidate password reset tokens", zap.String("userId", userID.Hex()), zap.Error(err))
+		return err
+	}
+	return nil
+}
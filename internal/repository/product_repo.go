@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"edjr-trk/configs/env"
+	configMongo "edjr-trk/configs/mongo"
 	"edjr-trk/internal/api/dto"
 	"edjr-trk/internal/model"
 	"edjr-trk/pkg/utils"
@@ -12,14 +13,27 @@ import (
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.uber.org/zap"
+	"time"
 )
 
 type ProductRepositoryInterface interface {
 	CreateProduct(ctx context.Context, article model.RowProduct) (model.RowProduct, error)
 	PatchProductById(ctx context.Context, dto *dto.PatchProductRequest, id string) (*model.RowProduct, error)
 	GetProductById(ctx context.Context, id string) (*model.RowProduct, error)
-	GetAllProducts(ctx context.Context, pageNumber, pageSize int) ([]model.RowProduct, int, error)
+	GetProductBySlug(ctx context.Context, slug string) (*model.RowProduct, error)
+	IsProductSlugTaken(ctx context.Context, slug string, exceptID primitive.ObjectID) (bool, error)
+	GetProductsWithoutSlug(ctx context.Context) ([]model.RowProduct, error)
+	GetAllProducts(ctx context.Context, filter model.ProductFilter, pageNumber, pageSize int) ([]model.RowProduct, int, error)
+	SearchProducts(ctx context.Context, filter model.ProductFilter, query string, pageNumber, pageSize int) ([]model.RowProduct, int, error)
+	GetAllProductsByCursor(ctx context.Context, filter model.ProductFilter, cursor *utils.PageCursor, pageSize int) ([]model.RowProduct, bool, error)
+	ReplaceProduct(ctx context.Context, product model.RowProduct) (*model.RowProduct, error)
 	RemoveProductById(ctx context.Context, id string) error
+	RestoreProductById(ctx context.Context, id string) error
+	GetDeletedProducts(ctx context.Context, pageNumber, pageSize int) ([]model.RowProduct, int, error)
+	PurgeDeletedProducts(ctx context.Context, before time.Time) ([]model.RowProduct, error)
+	GetProductsWithInlineImg(ctx context.Context) ([]model.RowProduct, error)
+	CountProductTerms(ctx context.Context, query string) (model.TermUsage, error)
+	RemoveTermReferences(ctx context.Context, kind string, id primitive.ObjectID) (int64, error)
 }
 
 type productRepository struct {
@@ -29,12 +43,12 @@ type productRepository struct {
 
 func NewProductRepository(client *mongo.Client, logger *zap.Logger) ProductRepositoryInterface {
 	return &productRepository{
-		collection: client.Database(env.GetEnv("MONGO_DB_NAME", "")).Collection("products"),
+		collection: client.Database(env.GetEnv("MONGO_DB_NAME", "")).Collection(configMongo.ProductCollection),
 		logger:     logger,
 	}
 }
 
-func (r *productRepository) GetAllProducts(ctx context.Context, pageNumber, pageSize int) ([]model.RowProduct, int, error) {
+func (r *productRepository) GetAllProducts(ctx context.Context, filter model.ProductFilter, pageNumber, pageSize int) ([]model.RowProduct, int, error) {
 	if pageNumber < 1 {
 		pageNumber = 1
 	}
@@ -43,9 +57,10 @@ func (r *productRepository) GetAllProducts(ctx context.Context, pageNumber, page
 	}
 
 	skip := utils.CalculateOffset(pageNumber, pageSize)
+	query := withTaxonomy(notDeleted(bson.M{}), filter.TaxonomyFilter)
 
 	//common document count
-	totalCount64, err := r.collection.CountDocuments(ctx, bson.M{})
+	totalCount64, err := r.collection.CountDocuments(ctx, query)
 	if err != nil {
 		r.logger.Error("Failed to count articles", zap.Error(err))
 		return nil, 0, err
@@ -58,7 +73,7 @@ func (r *productRepository) GetAllProducts(ctx context.Context, pageNumber, page
 		SetSort(bson.D{{Key: "date", Value: -1}}) // sort by desc
 
 	// Retrieving data with pagination and sorting
-	cursor, err := r.collection.Find(ctx, bson.M{}, findOptions)
+	cursor, err := r.collection.Find(ctx, query, findOptions)
 	if err != nil {
 		r.logger.Error("Failed to find articles", zap.Error(err))
 		return nil, 0, err
@@ -89,6 +104,95 @@ func (r *productRepository) GetAllProducts(ctx context.Context, pageNumber, page
 	return products, totalCount, nil
 }
 
+// GetAllProductsByCursor - keyset pagination by (date, _id); returns whether more items exist in the cursor direction
+func (r *productRepository) GetAllProductsByCursor(ctx context.Context, filter model.ProductFilter, cursor *utils.PageCursor, pageSize int) ([]model.RowProduct, bool, error) {
+	if pageSize < 1 {
+		pageSize = 10
+	}
+
+	query, findOptions := keysetQuery(withTaxonomy(notDeleted(bson.M{}), filter.TaxonomyFilter), "date", cursor, pageSize)
+
+	cursorDB, err := r.collection.Find(ctx, query, findOptions)
+	if err != nil {
+		r.logger.Error("Failed to find products", zap.Error(err))
+		return nil, false, err
+	}
+	defer func() {
+		if closeErr := cursorDB.Close(ctx); closeErr != nil {
+			r.logger.Warn("Failed to close cursor", zap.Error(closeErr))
+		}
+	}()
+
+	products, decodeErr := utils.DecodeCursor[model.RowProduct](ctx, cursorDB, r.logger)
+	if decodeErr != nil {
+		return nil, false, decodeErr
+	}
+
+	products, hasMore := trimKeysetPage(products, cursor, pageSize)
+
+	r.logger.Info("Products fetched successfully with cursor pagination",
+		zap.Int("pageSize", pageSize),
+		zap.Int("fetchedItems", len(products)),
+		zap.Bool("hasMore", hasMore),
+	)
+
+	return products, hasMore, nil
+}
+
+// SearchProducts - full-text search over title/shortText/text ranked by relevance, with pagination
+func (r *productRepository) SearchProducts(ctx context.Context, filter model.ProductFilter, query string, pageNumber, pageSize int) ([]model.RowProduct, int, error) {
+	if pageNumber < 1 {
+		pageNumber = 1
+	}
+	if pageSize < 1 {
+		pageSize = 10
+	}
+
+	skip := utils.CalculateOffset(pageNumber, pageSize)
+	searchFilter := withTaxonomy(notDeleted(bson.M{"$text": bson.M{"$search": query}}), filter.TaxonomyFilter)
+
+	totalCount64, err := r.collection.CountDocuments(ctx, searchFilter)
+	if err != nil {
+		r.logger.Error("Failed to count found products", zap.Error(err))
+		return nil, 0, err
+	}
+	totalCount := int(totalCount64)
+
+	// Sort by relevance, newest first for equal scores
+	score := bson.M{"$meta": "textScore"}
+	findOptions := options.Find().
+		SetProjection(bson.M{"score": score}).
+		SetSkip(int64(skip)).
+		SetLimit(int64(pageSize)).
+		SetSort(bson.D{{Key: "score", Value: score}, {Key: "date", Value: -1}})
+
+	cursor, err := r.collection.Find(ctx, searchFilter, findOptions)
+	if err != nil {
+		r.logger.Error("Failed to search products", zap.Error(err))
+		return nil, 0, err
+	}
+	defer func() {
+		if closeErr := cursor.Close(ctx); closeErr != nil {
+			r.logger.Warn("Failed to close cursor", zap.Error(closeErr))
+		}
+	}()
+
+	products, decodeErr := utils.DecodeCursor[model.RowProduct](ctx, cursor, r.logger)
+	if decodeErr != nil {
+		return nil, 0, decodeErr
+	}
+
+	r.logger.Info("Products searched successfully",
+		zap.String("query", query),
+		zap.Int("pageNumber", pageNumber),
+		zap.Int("pageSize", pageSize),
+		zap.Int("totalCount", totalCount),
+		zap.Int("fetchedItems", len(products)),
+	)
+
+	return products, totalCount, nil
+}
+
 func (r *productRepository) CreateProduct(ctx context.Context, product model.RowProduct) (model.RowProduct, error) {
 	result, err := r.collection.InsertOne(ctx, product)
 	if err != nil {
@@ -119,7 +223,7 @@ func (r *productRepository) GetProductById(ctx context.Context, id string) (*mod
 	}
 
 	// Поиск статьи по ID в коллекции MongoDB.
-	err = r.collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&product)
+	err = r.collection.FindOne(ctx, notDeleted(bson.M{"_id": objectID})).Decode(&product)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
 			r.logger.Warn("Product not found", zap.String("id", id))
@@ -141,19 +245,52 @@ func (r *productRepository) PatchProductById(ctx context.Context, dto *dto.Patch
 	}
 
 	// Формирование обновления
+	// Тексты на языке, отличном от языка оригинала, пишутся в перевод
+	textField := func(name string) string {
+		if dto.Locale == nil {
+			return name
+		}
+		return "translations." + *dto.Locale + "." + name
+	}
+
 	update := bson.M{}
 	if dto.Title != nil {
-		update["title"] = *dto.Title
+		update[textField("title")] = *dto.Title
 	}
 	if dto.Text != nil {
-		update["text"] = *dto.Text
+		update[textField("text")] = *dto.Text
 	}
 	if dto.ShortText != nil {
-		update["shortText"] = *dto.ShortText
+		update[textField("shortText")] = *dto.ShortText
 	}
 	if dto.Img != nil {
 		update["img"] = *dto.Img
 	}
+	if dto.Tags != nil {
+		if update["tags"], err = utils.ObjectIDsFromHex(*dto.Tags); err != nil {
+			return nil, err
+		}
+	}
+	if dto.Categories != nil {
+		if update["categories"], err = utils.ObjectIDsFromHex(*dto.Categories); err != nil {
+			return nil, err
+		}
+	}
+
+	if dto.Slug != nil {
+		slugUpdate, err := slugRenameUpdate(ctx, r.collection, objectID, *dto.Slug)
+		if err != nil {
+			if errors.Is(err, mongo.ErrNoDocuments) {
+				r.logger.Warn("Product not found for update", zap.String("id", id))
+				return nil, mongo.ErrNoDocuments
+			}
+			r.logger.Error("Failed to read current slug", zap.String("id", id), zap.Error(err))
+			return nil, err
+		}
+		for key, value := range slugUpdate {
+			update[key] = value
+		}
+	}
 
 	if len(update) == 0 {
 		r.logger.Warn("No fields to update", zap.String("id", id))
@@ -163,7 +300,7 @@ func (r *productRepository) PatchProductById(ctx context.Context, dto *dto.Patch
 	// Обновление статьи в MongoDB
 	updateResult, err := r.collection.UpdateOne(
 		ctx,
-		bson.M{"_id": objectID},
+		notDeleted(bson.M{"_id": objectID}),
 		bson.M{"$set": update},
 	)
 	if err != nil {
@@ -188,19 +325,153 @@ func (r *productRepository) PatchProductById(ctx context.Context, dto *dto.Patch
 	return updatedProduct, nil
 }
 
+// ReplaceProduct - заменяет документ проекта целиком (восстановление ревизии).
+func (r *productRepository) ReplaceProduct(ctx context.Context, product model.RowProduct) (*model.RowProduct, error) {
+	result, err := r.collection.ReplaceOne(ctx, notDeleted(bson.M{"_id": product.ID}), product)
+	if err != nil {
+		r.logger.Error("Failed to replace product", zap.String("id", product.ID.Hex()), zap.Error(err))
+		return nil, err
+	}
+
+	if result.MatchedCount == 0 {
+		r.logger.Warn("Product not found for replace", zap.String("id", product.ID.Hex()))
+		return nil, mongo.ErrNoDocuments
+	}
+
+	r.logger.Info("Product replaced successfully", zap.String("id", product.ID.Hex()))
+	return &product, nil
+}
+
 func (r *productRepository) RemoveProductById(ctx context.Context, id string) error {
 	objectID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		r.logger.Error("Invalid ID format", zap.String("id", id), zap.Error(err))
+		return mongo.ErrNoDocuments
+	}
+
+	// Мягкое удаление: документ уходит в корзину и исключается из всех обычных запросов
+	if err := softDelete(ctx, r.collection, objectID); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			r.logger.Warn("Product not found for delete", zap.String("id", id))
+			return err
+		}
+		r.logger.Error("Failed to delete product", zap.String("id", id), zap.Error(err))
 		return err
 	}
 
-	_, err = r.collection.DeleteOne(ctx, bson.M{"_id": objectID})
+	r.logger.Info("Product moved to trash", zap.String("id", id))
+	return nil
+}
+
+// RestoreProductById - возвращает проект из корзины.
+func (r *productRepository) RestoreProductById(ctx context.Context, id string) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		r.logger.Error("Failed to delete product", zap.String("id", id), zap.Error(err))
+		r.logger.Error("Invalid ID format", zap.String("id", id), zap.Error(err))
+		return mongo.ErrNoDocuments
+	}
+
+	if err := restoreDeleted(ctx, r.collection, objectID); err != nil {
+		if !errors.Is(err, mongo.ErrNoDocuments) {
+			r.logger.Error("Failed to restore product", zap.String("id", id), zap.Error(err))
+		}
 		return err
 	}
 
-	r.logger.Info("Product successfully deleted", zap.String("id", id))
+	r.logger.Info("Product restored from trash", zap.String("id", id))
 	return nil
 }
+
+// GetDeletedProducts - содержимое корзины с пагинацией.
+func (r *productRepository) GetDeletedProducts(ctx context.Context, pageNumber, pageSize int) ([]model.RowProduct, int, error) {
+	return findDeleted[model.RowProduct](ctx, r.collection, pageNumber, pageSize, r.logger)
+}
+
+// PurgeDeletedProducts - окончательно удаляет проекты из корзины, удалённые не позже before, и возвращает их.
+func (r *productRepository) PurgeDeletedProducts(ctx context.Context, before time.Time) ([]model.RowProduct, error) {
+	purged, err := purgeDeleted[model.RowProduct](ctx, r.collection, before, r.logger)
+	if err != nil {
+		r.logger.Error("Failed to purge deleted products", zap.Error(err))
+		return purged, err
+	}
+
+	r.logger.Info("Deleted products purged", zap.Int("count", len(purged)))
+	return purged, nil
+}
+
+// GetProductsWithInlineImg - проекты, у которых изображение всё ещё хранится как base64 data URI.
+func (r *productRepository) GetProductsWithInlineImg(ctx context.Context) ([]model.RowProduct, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{"img": bson.M{"$regex": "^data:"}})
+	if err != nil {
+		r.logger.Error("Failed to find products with inline images", zap.Error(err))
+		return nil, err
+	}
+	defer func() {
+		if closeErr := cursor.Close(ctx); closeErr != nil {
+			r.logger.Warn("Failed to close cursor", zap.Error(closeErr))
+		}
+	}()
+
+	return utils.DecodeCursor[model.RowProduct](ctx, cursor, r.logger)
+}
+
+// GetProductBySlug - проект по текущему или прежнему slug.
+func (r *productRepository) GetProductBySlug(ctx context.Context, slug string) (*model.RowProduct, error) {
+	product, err := findBySlug[model.RowProduct](ctx, r.collection, slug)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			r.logger.Warn("Product not found by slug", zap.String("slug", slug))
+			return nil, mongo.ErrNoDocuments
+		}
+		r.logger.Error("Failed to query database", zap.String("slug", slug), zap.Error(err))
+		return nil, err
+	}
+
+	return product, nil
+}
+
+// IsProductSlugTaken - занят ли slug другим документом (exceptID - сам редактируемый документ).
+func (r *productRepository) IsProductSlugTaken(ctx context.Context, slug string, exceptID primitive.ObjectID) (bool, error) {
+	taken, err := isSlugTaken(ctx, r.collection, slug, exceptID)
+	if err != nil {
+		r.logger.Error("Failed to check slug", zap.String("slug", slug), zap.Error(err))
+	}
+	return taken, err
+}
+
+// GetProductsWithoutSlug - документы, созданные до появления slug.
+func (r *productRepository) GetProductsWithoutSlug(ctx context.Context) ([]model.RowProduct, error) {
+	cursor, err := r.collection.Find(ctx, notDeleted(bson.M{"slug": bson.M{"$exists": false}}))
+	if err != nil {
+		r.logger.Error("Failed to find products without slug", zap.Error(err))
+		return nil, err
+	}
+	defer func() {
+		if closeErr := cursor.Close(ctx); closeErr != nil {
+			r.logger.Warn("Failed to close cursor", zap.Error(closeErr))
+		}
+	}()
+
+	return utils.DecodeCursor[model.RowProduct](ctx, cursor, r.logger)
+}
+
+// CountProductTerms - число проектов на каждый тег и категорию; с query - только среди найденных.
+func (r *productRepository) CountProductTerms(ctx context.Context, query string) (model.TermUsage, error) {
+	usage, err := countTerms(ctx, r.collection, withTextSearch(notDeleted(bson.M{}), query))
+	if err != nil {
+		r.logger.Error("Failed to count product terms", zap.Error(err))
+	}
+	return usage, err
+}
+
+// RemoveTermReferences - убирает удалённый тег или категорию из проектов.
+func (r *productRepository) RemoveTermReferences(ctx context.Context, kind string, id primitive.ObjectID) (int64, error) {
+	modified, err := pullTermReferences(ctx, r.collection, kind, id)
+	if err != nil {
+		r.logger.Error("Failed to remove term references from products", zap.String("termId", id.Hex()), zap.Error(err))
+		return 0, err
+	}
+
+	r.logger.Info("Term references removed from products", zap.String("termId", id.Hex()), zap.Int64("count", modified))
+	return modified, nil
+}
@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"context"
+	"edjr-trk/configs/env"
+	configMongo "edjr-trk/configs/mongo"
+	"edjr-trk/internal/model"
+	"errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.uber.org/zap"
+	"time"
+)
+
+// RefreshTokenRepositoryInterface - интерфейс для работы с refresh токенами.
+type RefreshTokenRepositoryInterface interface {
+	Create(ctx context.Context, token *model.RowRefreshToken) error
+	GetByHash(ctx context.Context, tokenHash string) (*model.RowRefreshToken, error)
+	MarkUsed(ctx context.Context, id primitive.ObjectID, at time.Time) (bool, error)
+	RevokeFamily(ctx context.Context, familyID primitive.ObjectID, at time.Time) error
+	RevokeUserTokens(ctx context.Context, userID primitive.ObjectID, at time.Time) error
+}
+
+type refreshTokenRepository struct {
+	collection *mongo.Collection
+	logger     *zap.Logger
+}
+
+func NewRefreshTokenRepository(client *mongo.Client, logger *zap.Logger) RefreshTokenRepositoryInterface {
+	return &refreshTokenRepository{
+		collection: client.Database(env.GetEnv("MONGO_DB_NAME", "")).Collection(configMongo.RefreshTokenCollection),
+		logger:     logger,
+	}
+}
+
+func (r *refreshTokenRepository) Create(ctx context.Context, token *model.RowRefreshToken) error {
+	if _, err := r.collection.InsertOne(ctx, token); err != nil {
+		r.logger.Error("Failed to insert refresh token", zap.String("userId", token.UserID.Hex()), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (r *refreshTokenRepository) GetByHash(ctx context.Context, tokenHash string) (*model.RowRefreshToken, error) {
+	var token model.RowRefreshToken
+	err := r.collection.FindOne(ctx, bson.M{"tokenHash": tokenHash}).Decode(&token)
+	if err != nil {
+		if !errors.Is(err, mongo.ErrNoDocuments) {
+			r.logger.Error("Failed to query refresh token", zap.Error(err))
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+// MarkUsed - помечает токен обменянным. Условие в фильтре делает обмен атомарным:
+// из двух одновременных запросов с одним токеном true получит только один.
+func (r *refreshTokenRepository) MarkUsed(ctx context.Context, id primitive.ObjectID, at time.Time) (bool, error) {
+	filter := bson.M{
+		"_id":       id,
+		"usedAt":    bson.M{"$exists": false},
+		"revokedAt": bson.M{"$exists": false},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"usedAt": at}})
+	if err != nil {
+		r.logger.Error("Failed to mark refresh token as used", zap.String("id", id.Hex()), zap.Error(err))
+		return false, err
+	}
+	return result.ModifiedCount == 1, nil
+}
+
+// RevokeFamily - отзывает все токены одной цепочки ротаций.
+func (r *refreshTokenRepository) RevokeFamily(ctx context.Context, familyID primitive.ObjectID, at time.Time) error {
+	return r.revoke(ctx, bson.M{"familyId": familyID}, at)
+}
+
+// RevokeUserTokens - отзывает все токены пользователя, то есть все его сессии.
+func (r *refreshTokenRepository) RevokeUserTokens(ctx context.Context, userID primitive.ObjectID, at time.Time) error {
+	return r.revoke(ctx, bson.M{"userId": userID}, at)
+}
+
+func (r *refreshTokenRepository) revoke(ctx context.Context, filter bson.M, at time.Time) error {
+	filter["revokedAt"] = bson.M{"$exists": false}
+
+	result, err := r.collection.UpdateMany(ctx, filter, bson.M{"$set": bson.M{"revokedAt": at}})
+	if err != nil {
+		r.logger.Error("Failed to revoke refresh tokens", zap.Error(err))
+		return err
+	}
+
+	r.logger.Info("Refresh tokens revoked", zap.Int64("count", result.ModifiedCount))
+	return nil
+}
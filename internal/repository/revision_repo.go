@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"context"
+	"edjr-trk/configs/env"
+	configMongo "edjr-trk/configs/mongo"
+	"edjr-trk/internal/model"
+	"errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+// RevisionRepositoryInterface - интерфейс для работы с историей изменений.
+// Ревизии только добавляются и не изменяются; удаляются лишь вместе с сущностью при очистке корзины.
+type RevisionRepositoryInterface interface {
+	Create(ctx context.Context, revision *model.RowRevision) (*model.RowRevision, error)
+	GetLatestVersion(ctx context.Context, entityType string, entityID primitive.ObjectID) (int, error)
+	GetRevisions(ctx context.Context, entityType string, entityID primitive.ObjectID, pageNumber, pageSize int) ([]model.RowRevision, int, error)
+	GetRevisionById(ctx context.Context, entityType string, entityID primitive.ObjectID, id string) (*model.RowRevision, error)
+	DeleteRevisions(ctx context.Context, entityType string, entityIDs []primitive.ObjectID) (int64, error)
+}
+
+type revisionRepository struct {
+	collection *mongo.Collection
+	logger     *zap.Logger
+}
+
+func NewRevisionRepository(client *mongo.Client, logger *zap.Logger) RevisionRepositoryInterface {
+	return &revisionRepository{
+		collection: client.Database(env.GetEnv("MONGO_DB_NAME", "")).Collection(configMongo.RevisionCollection),
+		logger:     logger,
+	}
+}
+
+func (r *revisionRepository) Create(ctx context.Context, revision *model.RowRevision) (*model.RowRevision, error) {
+	if _, err := r.collection.InsertOne(ctx, revision); err != nil {
+		r.logger.Error("Failed to insert revision", zap.Error(err))
+		return nil, err
+	}
+
+	r.logger.Info("Revision created successfully",
+		zap.String("entityType", revision.EntityType),
+		zap.String("entityId", revision.EntityID.Hex()),
+		zap.Int("version", revision.Version),
+	)
+	return revision, nil
+}
+
+// GetLatestVersion - номер последней ревизии сущности, 0 если истории ещё нет.
+func (r *revisionRepository) GetLatestVersion(ctx context.Context, entityType string, entityID primitive.ObjectID) (int, error) {
+	opts := options.FindOne().
+		SetSort(bson.D{{Key: "version", Value: -1}}).
+		SetProjection(bson.M{"version": 1})
+
+	var latest model.RowRevision
+	err := r.collection.FindOne(ctx, bson.M{"entityType": entityType, "entityId": entityID}, opts).Decode(&latest)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return 0, nil
+	}
+	if err != nil {
+		r.logger.Error("Failed to query latest revision", zap.String("entityId", entityID.Hex()), zap.Error(err))
+		return 0, err
+	}
+
+	return latest.Version, nil
+}
+
+// GetRevisions - ревизии сущности от новых к старым, без снимков.
+func (r *revisionRepository) GetRevisions(ctx context.Context, entityType string, entityID primitive.ObjectID, pageNumber, pageSize int) ([]model.RowRevision, int, error) {
+	filter := bson.M{"entityType": entityType, "entityId": entityID}
+
+	totalCount, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		r.logger.Error("Failed to count revisions", zap.Error(err))
+		return nil, 0, err
+	}
+
+	skip := (pageNumber - 1) * pageSize
+	opts := options.Find().
+		SetSort(bson.D{{Key: "version", Value: -1}}).
+		SetSkip(int64(skip)).
+		SetLimit(int64(pageSize)).
+		SetProjection(bson.M{"snapshot": 0})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		r.logger.Error("Failed to query revisions", zap.Error(err))
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var revisions []model.RowRevision
+	if err := cursor.All(ctx, &revisions); err != nil {
+		r.logger.Error("Failed to decode revisions", zap.Error(err))
+		return nil, 0, err
+	}
+
+	return revisions, int(totalCount), nil
+}
+
+// GetRevisionById - ревизия со снимком; ревизия другой сущности считается отсутствующей.
+func (r *revisionRepository) GetRevisionById(ctx context.Context, entityType string, entityID primitive.ObjectID, id string) (*model.RowRevision, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		r.logger.Error("Invalid ID format", zap.String("id", id), zap.Error(err))
+		return nil, err
+	}
+
+	var revision model.RowRevision
+	err = r.collection.FindOne(ctx, bson.M{"_id": objectID, "entityType": entityType, "entityId": entityID}).Decode(&revision)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			r.logger.Warn("Revision not found", zap.String("id", id))
+			return nil, mongo.ErrNoDocuments
+		}
+		r.logger.Error("Failed to query database", zap.String("id", id), zap.Error(err))
+		return nil, err
+	}
+
+	return &revision, nil
+}
+
+// DeleteRevisions - удаляет всю историю окончательно удалённых сущностей.
+func (r *revisionRepository) DeleteRevisions(ctx context.Context, entityType string, entityIDs []primitive.ObjectID) (int64, error) {
+	if len(entityIDs) == 0 {
+		return 0, nil
+	}
+
+	result, err := r.collection.DeleteMany(ctx, bson.M{"entityType": entityType, "entityId": bson.M{"$in": entityIDs}})
+	if err != nil {
+		r.logger.Error("Failed to delete revisions", zap.String("entityType", entityType), zap.Error(err))
+		return 0, err
+	}
+
+	r.logger.Info("Revisions deleted", zap.String("entityType", entityType), zap.Int64("count", result.DeletedCount))
+	return result.DeletedCount, nil
+}
@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"context"
+	"edjr-trk/internal/model"
+	"errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// isSlugTaken - занят ли slug (текущий или прежний) другим документом коллекции, включая корзину.
+func isSlugTaken(ctx context.Context, collection *mongo.Collection, slug string, exceptID primitive.ObjectID) (bool, error) {
+	count, err := collection.CountDocuments(ctx, bson.M{
+		"_id": bson.M{"$ne": exceptID},
+		"$or": bson.A{bson.M{"slug": slug}, bson.M{"oldSlugs": slug}},
+	}, options.Count().SetLimit(1))
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// findBySlug - документ по текущему slug, а если такого нет - по одному из прежних.
+func findBySlug[T any](ctx context.Context, collection *mongo.Collection, slug string) (*T, error) {
+	var doc T
+	err := collection.FindOne(ctx, notDeleted(bson.M{"slug": slug})).Decode(&doc)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		err = collection.FindOne(ctx, notDeleted(bson.M{"oldSlugs": slug})).Decode(&doc)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+// slugRenameUpdate - поля для смены slug: прежний slug уходит в историю редиректов,
+// а новый из неё убирается (возврат к старому адресу).
+func slugRenameUpdate(ctx context.Context, collection *mongo.Collection, id primitive.ObjectID, slug string) (bson.M, error) {
+	var current model.RowArticle // нужны только slug и oldSlugs, они общие для статей и проектов
+	err := collection.FindOne(ctx, notDeleted(bson.M{"_id": id}),
+		options.FindOne().SetProjection(bson.M{"slug": 1, "oldSlugs": 1}),
+	).Decode(&current)
+	if err != nil {
+		return nil, err
+	}
+
+	return bson.M{"slug": slug, "oldSlugs": model.SlugHistory(current.Slug, current.OldSlugs, slug)}, nil
+}
@@ -0,0 +1,242 @@
+package repository
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"sync"
+	"time"
+)
+
+// Режимы шифрования SMTP.
+const (
+	SMTPStartTLS    = "starttls" // обычный порт (587), затем STARTTLS; без поддержки сервером - ошибка
+	SMTPImplicitTLS = "tls"      // TLS с первого байта (465)
+	SMTPNoTLS       = "none"     // без шифрования, только для локальных релеев
+)
+
+// SMTPConfig - параметры SMTP сервера.
+type SMTPConfig struct {
+	Host        string
+	Port        string
+	Username    string // пустой - без авторизации
+	Password    string
+	TLSMode     string
+	Timeout     time.Duration // на подключение и на отправку одного письма
+	IdleTimeout time.Duration // после такого простоя соединение не переиспользуется
+	MaxIdle     int           // сколько открытых соединений держать между письмами
+}
+
+// smtpEmailRepository - отправка через SMTP с переиспользованием соединений.
+type smtpEmailRepository struct {
+	config SMTPConfig
+	from   *mail.Address
+	mu     sync.Mutex
+	idle   []*smtpConn
+	logger *zap.Logger
+}
+
+type smtpConn struct {
+	conn     net.Conn
+	client   *smtp.Client
+	lastUsed time.Time
+}
+
+func NewSMTPEmailRepository(config SMTPConfig, from string, logger *zap.Logger) (EmailRepositoryInterface, error) {
+	switch config.TLSMode {
+	case SMTPStartTLS, SMTPImplicitTLS, SMTPNoTLS:
+	default:
+		return nil, fmt.Errorf("unknown SMTP_TLS mode %q", config.TLSMode)
+	}
+	if config.Host == "" || config.Port == "" {
+		return nil, errors.New("SMTP_HOST and SMTP_PORT must be set")
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 30 * time.Second
+	}
+	if config.MaxIdle < 0 {
+		config.MaxIdle = 0
+	}
+
+	addr, err := parseFrom(from)
+	if err != nil {
+		return nil, err
+	}
+
+	return &smtpEmailRepository{config: config, from: addr, logger: logger}, nil
+}
+
+func (r *smtpEmailRepository) SendEmail(ctx context.Context, msg *EmailMessage) error {
+	if r.from == nil {
+		return ErrMailFromNotSet
+	}
+
+	data, err := formatMessage(r.from, msg, time.Now())
+	if err != nil {
+		return err
+	}
+
+	c, err := r.take(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := r.send(ctx, c, msg.To, data); err != nil {
+		// Соединение могло остаться посреди транзакции, дальше его не используем
+		c.client.Close()
+		return err
+	}
+
+	r.put(c)
+	return nil
+}
+
+func (r *smtpEmailRepository) send(ctx context.Context, c *smtpConn, to string, data []byte) error {
+	rcpt, err := mail.ParseAddress(to)
+	if err != nil {
+		return err
+	}
+
+	if err := c.conn.SetDeadline(r.deadline(ctx)); err != nil {
+		return err
+	}
+	// Отмена ctx без срока тоже обрывает отправку
+	stop := context.AfterFunc(ctx, func() { c.conn.SetDeadline(time.Now()) })
+	defer stop()
+
+	if err := c.client.Mail(r.from.Address); err != nil {
+		return err
+	}
+	if err := c.client.Rcpt(rcpt.Address); err != nil {
+		return err
+	}
+
+	w, err := c.client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+// deadline - срок операции: Timeout от текущего момента, но не позже срока ctx.
+func (r *smtpEmailRepository) deadline(ctx context.Context) time.Time {
+	deadline := time.Now().Add(r.config.Timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		return ctxDeadline
+	}
+	return deadline
+}
+
+// take - свободное живое соединение из пула или новое.
+func (r *smtpEmailRepository) take(ctx context.Context) (*smtpConn, error) {
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		r.mu.Lock()
+		if len(r.idle) == 0 {
+			r.mu.Unlock()
+			return r.dial(ctx)
+		}
+		c := r.idle[len(r.idle)-1]
+		r.idle = r.idle[:len(r.idle)-1]
+		r.mu.Unlock()
+
+		if r.config.IdleTimeout > 0 && time.Since(c.lastUsed) > r.config.IdleTimeout {
+			r.quit(c)
+			continue
+		}
+		// Сервер мог закрыть соединение, пока оно простаивало
+		if err := c.conn.SetDeadline(r.deadline(ctx)); err == nil && c.client.Noop() == nil {
+			return c, nil
+		}
+		c.client.Close()
+	}
+}
+
+func (r *smtpEmailRepository) put(c *smtpConn) {
+	c.lastUsed = time.Now()
+
+	r.mu.Lock()
+	if len(r.idle) < r.config.MaxIdle {
+		r.idle = append(r.idle, c)
+		r.mu.Unlock()
+		return
+	}
+	r.mu.Unlock()
+
+	r.quit(c)
+}
+
+func (r *smtpEmailRepository) quit(c *smtpConn) {
+	if err := c.client.Quit(); err != nil {
+		c.client.Close()
+	}
+}
+
+func (r *smtpEmailRepository) dial(ctx context.Context) (*smtpConn, error) {
+	addr := net.JoinHostPort(r.config.Host, r.config.Port)
+	dialer := &net.Dialer{Timeout: r.config.Timeout}
+	tlsConfig := &tls.Config{ServerName: r.config.Host, MinVersion: tls.VersionTLS12}
+
+	var conn net.Conn
+	var err error
+	if r.config.TLSMode == SMTPImplicitTLS {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: tlsConfig}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		r.logger.Error("Failed to connect to SMTP server", zap.String("addr", addr), zap.Error(err))
+		return nil, err
+	}
+	if err := conn.SetDeadline(r.deadline(ctx)); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	client, err := smtp.NewClient(conn, r.config.Host)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	if err := r.handshake(client, tlsConfig); err != nil {
+		client.Close()
+		r.logger.Error("SMTP handshake failed", zap.String("addr", addr), zap.Error(err))
+		return nil, err
+	}
+
+	return &smtpConn{conn: conn, client: client, lastUsed: time.Now()}, nil
+}
+
+// handshake - STARTTLS и авторизация. PlainAuth сам откажет в передаче пароля
+// без шифрования на удалённый сервер.
+func (r *smtpEmailRepository) handshake(client *smtp.Client, tlsConfig *tls.Config) error {
+	if r.config.TLSMode == SMTPStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return errors.New("SMTP server does not support STARTTLS")
+		}
+		if err := client.StartTLS(tlsConfig); err != nil {
+			return err
+		}
+	}
+
+	if r.config.Username == "" {
+		return nil
+	}
+	if ok, _ := client.Extension("AUTH"); !ok {
+		return errors.New("SMTP server does not support AUTH")
+	}
+	return client.Auth(smtp.PlainAuth("", r.config.Username, r.config.Password, r.config.Host))
+}
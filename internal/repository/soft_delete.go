@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+	"edjr-trk/pkg/utils"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+	"time"
+)
+
+// notDeleted - добавляет к фильтру условие, исключающее документы из корзины.
+func notDeleted(filter bson.M) bson.M {
+	filter["deletedAt"] = bson.M{"$exists": false}
+	return filter
+}
+
+// softDelete - помечает документ удалённым; уже удалённый или отсутствующий документ даёт ErrNoDocuments.
+func softDelete(ctx context.Context, collection *mongo.Collection, id primitive.ObjectID) error {
+	result, err := collection.UpdateOne(ctx,
+		notDeleted(bson.M{"_id": id}),
+		bson.M{"$set": bson.M{"deletedAt": time.Now()}},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// restoreDeleted - возвращает документ из корзины; документ не в корзине даёт ErrNoDocuments.
+func restoreDeleted(ctx context.Context, collection *mongo.Collection, id primitive.ObjectID) error {
+	result, err := collection.UpdateOne(ctx,
+		bson.M{"_id": id, "deletedAt": bson.M{"$exists": true}},
+		bson.M{"$unset": bson.M{"deletedAt": ""}},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// findDeleted - содержимое корзины, недавно удалённые первыми.
+func findDeleted[T any](ctx context.Context, collection *mongo.Collection, pageNumber, pageSize int, logger *zap.Logger) ([]T, int, error) {
+	filter := bson.M{"deletedAt": bson.M{"$exists": true}}
+
+	totalCount, err := collection.CountDocuments(ctx, filter)
+	if err != nil {
+		logger.Error("Failed to count deleted documents", zap.Error(err))
+		return nil, 0, err
+	}
+
+	findOptions := options.Find().
+		SetSkip(int64(utils.CalculateOffset(pageNumber, pageSize))).
+		SetLimit(int64(pageSize)).
+		SetSort(bson.D{{Key: "deletedAt", Value: -1}})
+
+	cursor, err := collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		logger.Error("Failed to find deleted documents", zap.Error(err))
+		return nil, 0, err
+	}
+	defer func() {
+		if closeErr := cursor.Close(ctx); closeErr != nil {
+			logger.Warn("Failed to close cursor", zap.Error(closeErr))
+		}
+	}()
+
+	items, err := utils.DecodeCursor[T](ctx, cursor, logger)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return items, int(totalCount), nil
+}
+
+// purgeDeleted - окончательно удаляет документы, попавшие в корзину не позже before, и возвращает их,
+// чтобы вызывающий убрал связанные данные. Документ, восстановленный во время очистки, не удаляется.
+func purgeDeleted[T any](ctx context.Context, collection *mongo.Collection, before time.Time, logger *zap.Logger) ([]T, error) {
+	filter := bson.M{"deletedAt": bson.M{"$lte": before}}
+
+	cursor, err := collection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if closeErr := cursor.Close(ctx); closeErr != nil {
+			logger.Warn("Failed to close cursor", zap.Error(closeErr))
+		}
+	}()
+
+	var purged []T
+	for cursor.Next(ctx) {
+		id := cursor.Current.Lookup("_id")
+		result, err := collection.DeleteOne(ctx, bson.M{"_id": id, "deletedAt": bson.M{"$lte": before}})
+		if err != nil {
+			return purged, err
+		}
+		if result.DeletedCount == 0 {
+			continue
+		}
+
+		var item T
+		if err := cursor.Decode(&item); err != nil {
+			return purged, err
+		}
+		purged = append(purged, item)
+	}
+	return purged, cursor.Err()
+}
@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"edjr-trk/internal/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// withTaxonomy - добавляет к фильтру условия по тегам и категориям.
+// Пустой, но не nil список даёт $in: [] и, как и задумано, пустую выборку.
+func withTaxonomy(filter bson.M, taxonomy model.TaxonomyFilter) bson.M {
+	if taxonomy.Tags != nil {
+		filter["tags"] = bson.M{"$in": taxonomy.Tags}
+	}
+	if taxonomy.Categories != nil {
+		filter["categories"] = bson.M{"$in": taxonomy.Categories}
+	}
+	return filter
+}
+
+// withTextSearch - ограничивает фильтр результатами полнотекстового поиска; пустой query ничего не меняет.
+func withTextSearch(filter bson.M, query string) bson.M {
+	if query != "" {
+		filter["$text"] = bson.M{"$search": query}
+	}
+	return filter
+}
+
+// termField - поле документа, в котором хранятся ссылки на термины вида kind.
+func termField(kind string) string {
+	if kind == model.TermKindCategory {
+		return "categories"
+	}
+	return "tags"
+}
+
+// countTerms - число документов на каждый тег и категорию среди подходящих под match.
+func countTerms(ctx context.Context, collection *mongo.Collection, match bson.M) (model.TermUsage, error) {
+	countBy := func(field string) bson.A {
+		return bson.A{
+			bson.M{"$unwind": "$" + field},
+			bson.M{"$group": bson.M{"_id": "$" + field, "count": bson.M{"$sum": 1}}},
+		}
+	}
+
+	cursor, err := collection.Aggregate(ctx, bson.A{
+		bson.M{"$match": match},
+		bson.M{"$facet": bson.M{"tags": countBy("tags"), "categories": countBy("categories")}},
+	})
+	if err != nil {
+		return model.TermUsage{}, err
+	}
+	defer cursor.Close(ctx)
+
+	type termCount struct {
+		ID    primitive.ObjectID `bson:"_id"`
+		Count int                `bson:"count"`
+	}
+	var facets []struct {
+		Tags       []termCount `bson:"tags"`
+		Categories []termCount `bson:"categories"`
+	}
+	if err := cursor.All(ctx, &facets); err != nil {
+		return model.TermUsage{}, err
+	}
+
+	usage := model.TermUsage{
+		Tags:       make(map[primitive.ObjectID]int),
+		Categories: make(map[primitive.ObjectID]int),
+	}
+	for _, facet := range facets {
+		for _, tag := range facet.Tags {
+			usage.Tags[tag.ID] = tag.Count
+		}
+		for _, category := range facet.Categories {
+			usage.Categories[category.ID] = category.Count
+		}
+	}
+	return usage, nil
+}
+
+// pullTermReferences - убирает ссылки на удалённый термин из всех документов коллекции, включая корзину.
+func pullTermReferences(ctx context.Context, collection *mongo.Collection, kind string, id primitive.ObjectID) (int64, error) {
+	field := termField(kind)
+	result, err := collection.UpdateMany(ctx, bson.M{field: id}, bson.M{"$pull": bson.M{field: id}})
+	if err != nil {
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"context"
+	"edjr-trk/configs/env"
+	configMongo "edjr-trk/configs/mongo"
+	"edjr-trk/internal/model"
+	"edjr-trk/pkg/utils"
+	"errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+// TaxonomyRepositoryInterface - интерфейс для работы с тегами и категориями.
+type TaxonomyRepositoryInterface interface {
+	CreateTerm(ctx context.Context, term model.RowTerm) (model.RowTerm, error)
+	GetTermById(ctx context.Context, kind, id string) (*model.RowTerm, error)
+	GetTermsByKind(ctx context.Context, kind string) ([]model.RowTerm, error)
+	GetTermsByIds(ctx context.Context, kind string, ids []primitive.ObjectID) ([]model.RowTerm, error)
+	GetTermsBySlugs(ctx context.Context, kind string, slugs []string) ([]model.RowTerm, error)
+	ReplaceTerm(ctx context.Context, term model.RowTerm) (*model.RowTerm, error)
+	RemoveTermById(ctx context.Context, kind string, id primitive.ObjectID) error
+	HasChildTerms(ctx context.Context, id primitive.ObjectID) (bool, error)
+	IsTermSlugTaken(ctx context.Context, kind, slug string, exceptID primitive.ObjectID) (bool, error)
+}
+
+type taxonomyRepository struct {
+	collection *mongo.Collection
+	logger     *zap.Logger
+}
+
+func NewTaxonomyRepository(client *mongo.Client, logger *zap.Logger) TaxonomyRepositoryInterface {
+	return &taxonomyRepository{
+		collection: client.Database(env.GetEnv("MONGO_DB_NAME", "")).Collection(configMongo.TaxonomyCollection),
+		logger:     logger,
+	}
+}
+
+func (r *taxonomyRepository) CreateTerm(ctx context.Context, term model.RowTerm) (model.RowTerm, error) {
+	if _, err := r.collection.InsertOne(ctx, term); err != nil {
+		r.logger.Error("Failed to insert term", zap.String("kind", term.Kind), zap.Error(err))
+		return model.RowTerm{}, err
+	}
+
+	r.logger.Info("Term created successfully", zap.String("kind", term.Kind), zap.String("id", term.ID.Hex()))
+	return term, nil
+}
+
+// GetTermById - термин заданного вида; неверный id тоже считается отсутствующим термином.
+func (r *taxonomyRepository) GetTermById(ctx context.Context, kind, id string) (*model.RowTerm, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		r.logger.Warn("Invalid ID format", zap.String("id", id), zap.Error(err))
+		return nil, mongo.ErrNoDocuments
+	}
+
+	var term model.RowTerm
+	err = r.collection.FindOne(ctx, bson.M{"_id": objectID, "kind": kind}).Decode(&term)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			r.logger.Warn("Term not found", zap.String("kind", kind), zap.String("id", id))
+			return nil, mongo.ErrNoDocuments
+		}
+		r.logger.Error("Failed to query database", zap.String("id", id), zap.Error(err))
+		return nil, err
+	}
+
+	return &term, nil
+}
+
+// GetTermsByKind - все термины вида, по алфавиту.
+func (r *taxonomyRepository) GetTermsByKind(ctx context.Context, kind string) ([]model.RowTerm, error) {
+	return r.find(ctx, bson.M{"kind": kind})
+}
+
+func (r *taxonomyRepository) GetTermsByIds(ctx context.Context, kind string, ids []primitive.ObjectID) ([]model.RowTerm, error) {
+	return r.find(ctx, bson.M{"kind": kind, "_id": bson.M{"$in": ids}})
+}
+
+func (r *taxonomyRepository) GetTermsBySlugs(ctx context.Context, kind string, slugs []string) ([]model.RowTerm, error) {
+	return r.find(ctx, bson.M{"kind": kind, "slug": bson.M{"$in": slugs}})
+}
+
+func (r *taxonomyRepository) ReplaceTerm(ctx context.Context, term model.RowTerm) (*model.RowTerm, error) {
+	result, err := r.collection.ReplaceOne(ctx, bson.M{"_id": term.ID, "kind": term.Kind}, term)
+	if err != nil {
+		r.logger.Error("Failed to replace term", zap.String("id", term.ID.Hex()), zap.Error(err))
+		return nil, err
+	}
+
+	if result.MatchedCount == 0 {
+		r.logger.Warn("Term not found for update", zap.String("id", term.ID.Hex()))
+		return nil, mongo.ErrNoDocuments
+	}
+
+	r.logger.Info("Term updated successfully", zap.String("id", term.ID.Hex()))
+	return &term, nil
+}
+
+// RemoveTermById - удаляет термин безвозвратно: у таксономии корзины нет.
+func (r *taxonomyRepository) RemoveTermById(ctx context.Context, kind string, id primitive.ObjectID) error {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": id, "kind": kind})
+	if err != nil {
+		r.logger.Error("Failed to delete term", zap.String("id", id.Hex()), zap.Error(err))
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		r.logger.Warn("Term not found for delete", zap.String("id", id.Hex()))
+		return mongo.ErrNoDocuments
+	}
+
+	r.logger.Info("Term deleted successfully", zap.String("kind", kind), zap.String("id", id.Hex()))
+	return nil
+}
+
+// HasChildTerms - есть ли у категории подкатегории.
+func (r *taxonomyRepository) HasChildTerms(ctx context.Context, id primitive.ObjectID) (bool, error) {
+	count, err := r.collection.CountDocuments(ctx, bson.M{"parentId": id}, options.Count().SetLimit(1))
+	if err != nil {
+		r.logger.Error("Failed to count child terms", zap.String("id", id.Hex()), zap.Error(err))
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// IsTermSlugTaken - занят ли slug другим термином того же вида.
+func (r *taxonomyRepository) IsTermSlugTaken(ctx context.Context, kind, slug string, exceptID primitive.ObjectID) (bool, error) {
+	count, err := r.collection.CountDocuments(ctx,
+		bson.M{"kind": kind, "slug": slug, "_id": bson.M{"$ne": exceptID}},
+		options.Count().SetLimit(1),
+	)
+	if err != nil {
+		r.logger.Error("Failed to check term slug", zap.String("slug", slug), zap.Error(err))
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *taxonomyRepository) find(ctx context.Context, filter bson.M) ([]model.RowTerm, error) {
+	cursor, err := r.collection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "name", Value: 1}}))
+	if err != nil {
+		r.logger.Error("Failed to find terms", zap.Error(err))
+		return nil, err
+	}
+	defer func() {
+		if closeErr := cursor.Close(ctx); closeErr != nil {
+			r.logger.Warn("Failed to close cursor", zap.Error(closeErr))
+		}
+	}()
+
+	return utils.DecodeCursor[model.RowTerm](ctx, cursor, r.logger)
+}
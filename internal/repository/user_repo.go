@@ -4,6 +4,7 @@ import (
 	"context"
 	"edjr-trk/configs/env"
 	configMongo "edjr-trk/configs/mongo"
+	"edjr-trk/internal/api/dto"
 	"edjr-trk/internal/model"
 	"edjr-trk/pkg/utils"
 	"errors"
@@ -12,15 +13,28 @@ import (
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.uber.org/zap"
+	"time"
 )
 
 // UserRepositoryInterface - интерфейс для работы с коллекцией пользователей.
 type UserRepositoryInterface interface {
-	CreateNewAdmin(ctx context.Context, user *model.RowUser) (*model.RowUser, error)
+	CreateUser(ctx context.Context, user *model.RowUser) (*model.RowUser, error)
 	RemoveUserById(ctx context.Context, id string) error
+	RestoreUserById(ctx context.Context, id string) error
+	GetDeletedUsers(ctx context.Context, pageNumber, pageSize int) ([]model.RowUser, int, error)
+	PurgeDeletedUsers(ctx context.Context, before time.Time) ([]model.RowUser, error)
 	GetAll(ctx context.Context, pageNumber, pageSize int) (*[]model.RowUser, int, error)
 	GetUserByEmail(ctx context.Context, email string) (*model.RowUser, error)
 	GetUserById(ctx context.Context, id string) (*model.RowUser, error)
+	SetTokensRevokedAt(ctx context.Context, id primitive.ObjectID, at time.Time) error
+	UpdatePassword(ctx context.Context, id primitive.ObjectID, passwordHash string) error
+	PatchUserById(ctx context.Context, dto *dto.PatchUserRequest, id string) (*model.RowUser, error)
+	SetDisabled(ctx context.Context, id primitive.ObjectID, disabledAt *time.Time) error
+	SetRole(ctx context.Context, id primitive.ObjectID, role string) error
+	CountOwners(ctx context.Context) (int64, error)
+	SetTwoFactor(ctx context.Context, id primitive.ObjectID, twoFactor *model.TwoFactor) error
+	UseTwoFactorStep(ctx context.Context, id primitive.ObjectID, step int64) (bool, error)
+	UseRecoveryCode(ctx context.Context, id primitive.ObjectID, codeHash string) (bool, error)
 }
 
 // userRepository - конкретная реализация интерфейса.
@@ -36,8 +50,8 @@ func NewUserRepository(client *mongo.Client, logger *zap.Logger) UserRepositoryI
 	}
 }
 
-// CreateNewAdmin - create admin as  super-user
-func (r *userRepository) CreateNewAdmin(ctx context.Context, user *model.RowUser) (*model.RowUser, error) {
+// CreateUser - inserts a new user
+func (r *userRepository) CreateUser(ctx context.Context, user *model.RowUser) (*model.RowUser, error) {
 	result, err := r.collection.InsertOne(ctx, user)
 	if err != nil {
 		r.logger.Error("Failed to insert user", zap.Error(err))
@@ -62,16 +76,20 @@ func (r *userRepository) RemoveUserById(ctx context.Context, id string) error {
 	objectID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		r.logger.Error("Invalid ID format", zap.String("id", id), zap.Error(err))
-		return err
+		return mongo.ErrNoDocuments
 	}
 
-	_, err = r.collection.DeleteOne(ctx, bson.M{"_id": objectID})
-	if err != nil {
+	// Мягкое удаление: документ уходит в корзину и исключается из всех обычных запросов
+	if err := softDelete(ctx, r.collection, objectID); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			r.logger.Warn("User not found for delete", zap.String("id", id))
+			return err
+		}
 		r.logger.Error("Failed to delete user", zap.String("id", id), zap.Error(err))
 		return err
 	}
 
-	r.logger.Info("User successfully deleted", zap.String("id", id))
+	r.logger.Info("User moved to trash", zap.String("id", id))
 	return nil
 }
 
@@ -87,7 +105,7 @@ func (r *userRepository) GetAll(ctx context.Context, pageNumber, pageSize int) (
 	skip := utils.CalculateOffset(pageNumber, pageSize)
 
 	//common document count
-	totalCount64, err := r.collection.CountDocuments(ctx, bson.M{})
+	totalCount64, err := r.collection.CountDocuments(ctx, notDeleted(bson.M{}))
 	if err != nil {
 		r.logger.Error("Failed to count users", zap.Error(err))
 		return nil, 0, err
@@ -101,7 +119,7 @@ func (r *userRepository) GetAll(ctx context.Context, pageNumber, pageSize int) (
 		SetSort(bson.D{{Key: "date", Value: -1}}) // sort by desc
 
 	// Retrieving data with pagination and sorting
-	cursor, err := r.collection.Find(ctx, bson.M{}, findOptions)
+	cursor, err := r.collection.Find(ctx, notDeleted(bson.M{}), findOptions)
 	if err != nil {
 		r.logger.Error("Failed to find users", zap.Error(err))
 		return nil, 0, err
@@ -138,7 +156,7 @@ func (r *userRepository) GetUserByEmail(ctx context.Context, email string) (*mod
 	var user model.RowUser
 
 	// Поиск пользователя по email
-	err := r.collection.FindOne(ctx, bson.M{"email": email}).Decode(&user)
+	err := r.collection.FindOne(ctx, notDeleted(bson.M{"email": email})).Decode(&user)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
 			r.logger.Warn("User not found", zap.String("email", email))
@@ -165,7 +183,7 @@ func (r *userRepository) GetUserById(ctx context.Context, id string) (*model.Row
 	}
 
 	// Поиск статьи по ID в коллекции MongoDB.
-	err = r.collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&user)
+	err = r.collection.FindOne(ctx, notDeleted(bson.M{"_id": objectID})).Decode(&user)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
 			r.logger.Warn("User not found", zap.String("id", id))
@@ -178,3 +196,240 @@ func (r *userRepository) GetUserById(ctx context.Context, id string) (*model.Row
 	r.logger.Info("User found", zap.String("id", user.ID.Hex()))
 	return &user, nil
 }
+
+// RestoreUserById - возвращает пользователя из корзины.
+func (r *userRepository) RestoreUserById(ctx context.Context, id string) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		r.logger.Error("Invalid ID format", zap.String("id", id), zap.Error(err))
+		return mongo.ErrNoDocuments
+	}
+
+	if err := restoreDeleted(ctx, r.collection, objectID); err != nil {
+		if !errors.Is(err, mongo.ErrNoDocuments) {
+			r.logger.Error("Failed to restore user", zap.String("id", id), zap.Error(err))
+		}
+		return err
+	}
+
+	r.logger.Info("User restored from trash", zap.String("id", id))
+	return nil
+}
+
+// GetDeletedUsers - содержимое корзины с пагинацией.
+func (r *userRepository) GetDeletedUsers(ctx context.Context, pageNumber, pageSize int) ([]model.RowUser, int, error) {
+	return findDeleted[model.RowUser](ctx, r.collection, pageNumber, pageSize, r.logger)
+}
+
+// PurgeDeletedUsers - окончательно удаляет пользователей из корзины, удалённые не позже before, и возвращает их.
+func (r *userRepository) PurgeDeletedUsers(ctx context.Context, before time.Time) ([]model.RowUser, error) {
+	purged, err := purgeDeleted[model.RowUser](ctx, r.collection, before, r.logger)
+	if err != nil {
+		r.logger.Error("Failed to purge deleted users", zap.Error(err))
+		return purged, err
+	}
+
+	r.logger.Info("Deleted users purged", zap.Int("count", len(purged)))
+	return purged, nil
+}
+
+// SetTokensRevokedAt - access токены пользователя, выпущенные раньше at, перестают приниматься.
+func (r *userRepository) SetTokensRevokedAt(ctx context.Context, id primitive.ObjectID, at time.Time) error {
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{"tokensRevokedAt": at}})
+	if err != nil {
+		r.logger.Error("Failed to revoke user tokens", zap.String("id", id.Hex()), zap.Error(err))
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// UpdatePassword - сохраняет новый хэш пароля.
+func (r *userRepository) UpdatePassword(ctx context.Context, id primitive.ObjectID, passwordHash string) error {
+	update := bson.M{"$set": bson.M{"password": passwordHash, "updatedAt": time.Now()}}
+
+	result, err := r.collection.UpdateOne(ctx, notDeleted(bson.M{"_id": id}), update)
+	if err != nil {
+		r.logger.Error("Failed to update password", zap.String("id", id.Hex()), zap.Error(err))
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// PatchUserById - изменяет переданные поля, пустые displayName и avatar удаляются.
+// Смена роли отзывает выданные access токены: новая роль попадёт в токен при обновлении.
+func (r *userRepository) PatchUserById(ctx context.Context, dto *dto.PatchUserRequest, id string) (*model.RowUser, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		r.logger.Error("Invalid ID format", zap.String("id", id), zap.Error(err))
+		return nil, mongo.ErrNoDocuments
+	}
+
+	now := time.Now()
+	set := bson.M{"updatedAt": now}
+	unset := bson.M{}
+	if dto.Email != nil {
+		set["email"] = *dto.Email
+	}
+	if dto.Phone != nil {
+		set["phone"] = *dto.Phone
+	}
+	if dto.Role != nil {
+		set["role"] = *dto.Role
+		// JWT хранит iat с точностью до секунды
+		set["tokensRevokedAt"] = now.Truncate(time.Second)
+	}
+	if dto.DisplayName != nil {
+		if *dto.DisplayName == "" {
+			unset["displayName"] = ""
+		} else {
+			set["displayName"] = *dto.DisplayName
+		}
+	}
+	if dto.Avatar != nil {
+		if *dto.Avatar == "" {
+			unset["avatar"] = ""
+		} else {
+			set["avatar"] = *dto.Avatar
+		}
+	}
+
+	update := bson.M{"$set": set}
+	if len(unset) > 0 {
+		update["$unset"] = unset
+	}
+
+	var user model.RowUser
+	err = r.collection.FindOneAndUpdate(ctx,
+		notDeleted(bson.M{"_id": objectID}),
+		update,
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&user)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			r.logger.Warn("User not found for update", zap.String("id", id))
+			return nil, err
+		}
+		r.logger.Error("Failed to update user", zap.String("id", id), zap.Error(err))
+		return nil, err
+	}
+
+	r.logger.Info("User updated successfully", zap.String("id", id))
+	return &user, nil
+}
+
+// SetDisabled - отключает пользователя с момента disabledAt, nil включает обратно.
+func (r *userRepository) SetDisabled(ctx context.Context, id primitive.ObjectID, disabledAt *time.Time) error {
+	update := bson.M{"$set": bson.M{"disabledAt": disabledAt, "updatedAt": time.Now()}}
+	if disabledAt == nil {
+		update = bson.M{"$unset": bson.M{"disabledAt": ""}, "$set": bson.M{"updatedAt": time.Now()}}
+	}
+
+	result, err := r.collection.UpdateOne(ctx, notDeleted(bson.M{"_id": id}), update)
+	if err != nil {
+		r.logger.Error("Failed to change user status", zap.String("id", id.Hex()), zap.Error(err))
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// SetRole - меняет роль и отзывает выданные access токены. Пустая роль удаляет поле,
+// возвращая запись, созданную до появления ролей, к прежнему виду.
+func (r *userRepository) SetRole(ctx context.Context, id primitive.ObjectID, role string) error {
+	now := time.Now()
+	// JWT хранит iat с точностью до секунды
+	set := bson.M{"updatedAt": now, "tokensRevokedAt": now.Truncate(time.Second)}
+	update := bson.M{"$set": set}
+	if role == "" {
+		update["$unset"] = bson.M{"role": ""}
+	} else {
+		set["role"] = role
+	}
+
+	result, err := r.collection.UpdateOne(ctx, notDeleted(bson.M{"_id": id}), update)
+	if err != nil {
+		r.logger.Error("Failed to change user role", zap.String("id", id.Hex()), zap.Error(err))
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// CountOwners - число действующих владельцев: вне корзины и не отключённых,
+// включая администраторов, созданных до появления ролей.
+func (r *userRepository) CountOwners(ctx context.Context) (int64, error) {
+	filter := notDeleted(bson.M{
+		"disabledAt": bson.M{"$exists": false},
+		"$or": bson.A{
+			bson.M{"role": model.RoleOwner},
+			bson.M{"role": bson.M{"$exists": false}, "IsAdmin": true},
+		},
+	})
+
+	count, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		r.logger.Error("Failed to count owners", zap.Error(err))
+		return 0, err
+	}
+	return count, nil
+}
+
+// SetTwoFactor - сохраняет настройки 2FA целиком, nil их удаляет.
+func (r *userRepository) SetTwoFactor(ctx context.Context, id primitive.ObjectID, twoFactor *model.TwoFactor) error {
+	update := bson.M{"$set": bson.M{"twoFactor": twoFactor, "updatedAt": time.Now()}}
+	if twoFactor == nil {
+		update = bson.M{"$unset": bson.M{"twoFactor": ""}, "$set": bson.M{"updatedAt": time.Now()}}
+	}
+
+	result, err := r.collection.UpdateOne(ctx, notDeleted(bson.M{"_id": id}), update)
+	if err != nil {
+		r.logger.Error("Failed to update two-factor settings", zap.String("id", id.Hex()), zap.Error(err))
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// UseTwoFactorStep - запоминает интервал принятого TOTP-кода. Возвращает false, если этот
+// или более поздний интервал уже использован: перехваченный код не сработает второй раз.
+func (r *userRepository) UseTwoFactorStep(ctx context.Context, id primitive.ObjectID, step int64) (bool, error) {
+	filter := bson.M{
+		"_id":               id,
+		"twoFactor.enabled": true,
+		"$or": bson.A{
+			bson.M{"twoFactor.lastUsedStep": bson.M{"$exists": false}},
+			bson.M{"twoFactor.lastUsedStep": bson.M{"$lt": step}},
+		},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"twoFactor.lastUsedStep": step}})
+	if err != nil {
+		r.logger.Error("Failed to record TOTP step", zap.String("id", id.Hex()), zap.Error(err))
+		return false, err
+	}
+	return result.ModifiedCount == 1, nil
+}
+
+// UseRecoveryCode - удаляет использованный код восстановления; false, если его уже нет.
+func (r *userRepository) UseRecoveryCode(ctx context.Context, id primitive.ObjectID, codeHash string) (bool, error) {
+	filter := bson.M{"_id": id, "twoFactor.recoveryCodes": codeHash}
+
+	result, err := r.collection.UpdateOne(ctx, filter, bson.M{"$pull": bson.M{"twoFactor.recoveryCodes": codeHash}})
+	if err != nil {
+		r.logger.Error("Failed to consume recovery code", zap.String("id", id.Hex()), zap.Error(err))
+		return false, err
+	}
+	return result.ModifiedCount == 1, nil
+}
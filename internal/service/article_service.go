@@ -6,33 +6,57 @@ import (
 	"edjr-trk/internal/model"
 	"edjr-trk/internal/repository"
 	"edjr-trk/pkg/utils"
+	"errors"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 	"go.uber.org/zap"
 	"time"
 )
 
 type ArticleService struct {
-	repo   repository.ArticleRepositoryInterface // Интерфейс репозитория
-	logger *zap.Logger
+	repo      repository.ArticleRepositoryInterface // Интерфейс репозитория
+	media     MediaServiceInterface                 // Хранилище изображений
+	revisions RevisionServiceInterface              // История изменений
+	taxonomy  TaxonomyServiceInterface              // Теги и категории
+	logger    *zap.Logger
 }
 
 // ArticleServiceInterface - интерфейс для работы с сервисом статей.
 type ArticleServiceInterface interface {
-	CreateArticle(ctx context.Context, dto dto.CreateArticleRequest) (*model.ArticleResponse, error)
+	CreateArticle(ctx context.Context, dto dto.CreateArticleRequest, authorID string) (*model.ArticleResponse, error)
 	RemoveArticleById(ctx context.Context, id string) (string, error)
-	PatchArticleById(ctx context.Context, dto dto.PatchArticleRequest, id string) (*model.ArticleResponse, error)
+	RestoreArticleById(ctx context.Context, id string) (*model.ArticleResponse, error)
+	GetDeletedArticles(ctx context.Context, pageNumber, pageSize int) (*model.Paginate[*model.ArticleResponse], error)
+	PatchArticleById(ctx context.Context, dto dto.PatchArticleRequest, id, authorID string) (*model.ArticleResponse, error)
 	GetArticleById(ctx context.Context, id string) (*model.ArticleResponse, error)
-	GetAllArticles(ctx context.Context, pageNumber, pageSize int) (*model.Paginate[*model.ArticleResponse], error)
+	GetPublishedArticleById(ctx context.Context, id string) (*model.ArticleResponse, error)
+	GetPublishedArticleBySlug(ctx context.Context, slug string) (*model.ArticleResponse, error)
+	BackfillSlugs(ctx context.Context) (int, error)
+	GetAllArticles(ctx context.Context, filter model.ArticleFilter, pageNumber, pageSize int) (*model.Paginate[*model.ArticleResponse], error)
+	SearchArticles(ctx context.Context, filter model.ArticleFilter, query string, pageNumber, pageSize int) (*model.Paginate[*model.ArticleResponse], error)
+	GetArticlesByCursor(ctx context.Context, filter model.ArticleFilter, cursor *utils.PageCursor, pageSize int) (*model.CursorPaginate[*model.ArticleResponse], error)
+	PublishArticle(ctx context.Context, id string, publishAt *time.Time, authorID string) (*model.ArticleResponse, error)
+	UnpublishArticle(ctx context.Context, id, authorID string) (*model.ArticleResponse, error)
+	GetArticleRevisions(ctx context.Context, id string, pageNumber, pageSize int) (*model.Paginate[*model.RevisionResponse], error)
+	GetArticleRevision(ctx context.Context, id, revisionID string) (*model.RevisionResponse, error)
+	DiffArticleRevisions(ctx context.Context, id, fromID, toID string) (*model.RevisionDiff, error)
+	RestoreArticleRevision(ctx context.Context, id, revisionID, authorID string) (*model.ArticleResponse, error)
 }
 
+// ErrInvalidPublishState is returned when the requested status and publishAt contradict each other.
+var ErrInvalidPublishState = errors.New("scheduled articles require a publishAt in the future")
+
+// snippetRadius - количество символов вокруг совпадения в сниппете поиска.
+const snippetRadius = 80
+
 // NewArticleService - создаёт новый экземпляр ArticleService.
-func NewArticleService(repo repository.ArticleRepositoryInterface, logger *zap.Logger) *ArticleService {
-	return &ArticleService{repo: repo, logger: logger}
+func NewArticleService(repo repository.ArticleRepositoryInterface, media MediaServiceInterface, revisions RevisionServiceInterface, taxonomy TaxonomyServiceInterface, logger *zap.Logger) *ArticleService {
+	return &ArticleService{repo: repo, media: media, revisions: revisions, taxonomy: taxonomy, logger: logger}
 }
 
 // GetAllArticles - получает статьи с пагинацией.
-func (s *ArticleService) GetAllArticles(ctx context.Context, pageNumber, pageSize int) (*model.Paginate[*model.ArticleResponse], error) {
-	articles, totalCount, err := s.repo.GetAll(ctx, pageNumber, pageSize)
+func (s *ArticleService) GetAllArticles(ctx context.Context, filter model.ArticleFilter, pageNumber, pageSize int) (*model.Paginate[*model.ArticleResponse], error) {
+	articles, totalCount, err := s.repo.GetAll(ctx, filter, pageNumber, pageSize)
 	if err != nil {
 		s.logger.Error("Failed to fetch all articles", zap.Error(err))
 		return nil, err
@@ -42,6 +66,13 @@ func (s *ArticleService) GetAllArticles(ctx context.Context, pageNumber, pageSiz
 	transformedResp := make([]*model.ArticleResponse, len(articles))
 	for i, article := range articles {
 		transformedResp[i] = article.CreateArtResp()
+		// В списках отдаём только миниатюру
+		transformedResp[i].Img = s.media.ThumbnailURL(article.Img)
+	}
+
+	facets, err := s.facets(ctx, filter, "")
+	if err != nil {
+		return nil, err
 	}
 
 	// Формируем структуру Paginate с типом ArticleResponse
@@ -51,6 +82,7 @@ func (s *ArticleService) GetAllArticles(ctx context.Context, pageNumber, pageSiz
 		TotalPageCount: utils.CalculateTotalPages(totalCount, pageSize),
 		PageSize:       pageSize,
 		Items:          transformedResp,
+		Facets:         facets,
 	}
 
 	s.logger.Info("All articles fetched successfully with pagination",
@@ -63,25 +95,143 @@ func (s *ArticleService) GetAllArticles(ctx context.Context, pageNumber, pageSiz
 	return result, nil
 }
 
+// GetArticlesByCursor - получает статьи с курсорной (keyset) пагинацией.
+func (s *ArticleService) GetArticlesByCursor(ctx context.Context, filter model.ArticleFilter, cursor *utils.PageCursor, pageSize int) (*model.CursorPaginate[*model.ArticleResponse], error) {
+	articles, hasMore, err := s.repo.GetAllByCursor(ctx, filter, cursor, pageSize)
+	if err != nil {
+		s.logger.Error("Failed to fetch articles by cursor", zap.Error(err))
+		return nil, err
+	}
+
+	result := newCursorPage(articles, hasMore, cursor, pageSize,
+		func(a model.RowArticle) (time.Time, primitive.ObjectID) {
+			return a.ListDate(filter.PublishedOnly), a.ID
+		},
+		func(a model.RowArticle) *model.ArticleResponse {
+			resp := a.CreateArtResp()
+			resp.Img = s.media.ThumbnailURL(a.Img)
+			return resp
+		},
+	)
+
+	if result.Facets, err = s.facets(ctx, filter, ""); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Articles fetched successfully with cursor pagination",
+		zap.Int("pageSize", pageSize),
+		zap.Int("fetchedItems", len(result.Items)),
+	)
+
+	return result, nil
+}
+
+// SearchArticles - полнотекстовый поиск статей, отсортированных по релевантности.
+func (s *ArticleService) SearchArticles(ctx context.Context, filter model.ArticleFilter, query string, pageNumber, pageSize int) (*model.Paginate[*model.ArticleResponse], error) {
+	articles, totalCount, err := s.repo.Search(ctx, filter, query, pageNumber, pageSize)
+	if err != nil {
+		s.logger.Error("Failed to search articles", zap.Error(err))
+		return nil, err
+	}
+
+	transformedResp := make([]*model.ArticleResponse, len(articles))
+	for i, article := range articles {
+		transformedResp[i] = article.CreateArtResp()
+		transformedResp[i].Img = s.media.ThumbnailURL(article.Img)
+		transformedResp[i].Snippet = utils.HighlightSnippet(article.Title+"\n"+article.Text, query, snippetRadius)
+	}
+
+	facets, err := s.facets(ctx, filter, query)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &model.Paginate[*model.ArticleResponse]{
+		PageNumber:     pageNumber,
+		RowTotalCount:  totalCount,
+		TotalPageCount: utils.CalculateTotalPages(totalCount, pageSize),
+		PageSize:       pageSize,
+		Items:          transformedResp,
+		Facets:         facets,
+	}
+
+	s.logger.Info("Articles searched successfully",
+		zap.String("query", query),
+		zap.Int("totalCount", totalCount),
+		zap.Int("fetchedItems", len(transformedResp)),
+	)
+
+	return result, nil
+}
+
 // CreateArticle - создаёт новую статью.
-func (s *ArticleService) CreateArticle(ctx context.Context, req dto.CreateArticleRequest) (*model.ArticleResponse, error) {
+func (s *ArticleService) CreateArticle(ctx context.Context, req dto.CreateArticleRequest, authorID string) (*model.ArticleResponse, error) {
+	// По умолчанию статья публикуется сразу.
+	status := model.ArticleStatusPublished
+	if req.Status != nil {
+		status = *req.Status
+	}
+	status, publishAt, err := resolvePublishState(status, req.PublishAt, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	id := primitive.NewObjectID()
+	slug, err := s.slugForNewArticle(ctx, req, id)
+	if err != nil {
+		return nil, err
+	}
+
+	tags, categories, err := s.taxonomy.ResolveReferences(ctx, req.Tags, req.Categories)
+	if err != nil {
+		return nil, err
+	}
+
+	// Выносим base64 изображение в медиахранилище, в документе остаётся только URL.
+	img, err := s.media.StoreInlineImage(ctx, req.Img)
+	if err != nil {
+		s.logger.Error("Failed to store article image", zap.Error(err))
+		return nil, err
+	}
+
 	// Создание новой статьи.
 	newArticle := model.RowArticle{
-		ID:    primitive.NewObjectID(),
-		Title: req.Title,
-		Text:  req.Text,
-		Img:   req.Img,
-		Date:  time.Now(), // Используем primitive.DateTime для MongoDB
+		ID:         id,
+		Title:      req.Title,
+		Locale:     contentLocale(req.Locale),
+		Slug:       slug,
+		Tags:       tags,
+		Categories: categories,
+		Text:       req.Text,
+		Img:        img,
+		Date:       time.Now(), // Используем primitive.DateTime для MongoDB
+		Status:     status,
+		PublishAt:  publishAt,
+	}
+	if authorObjectID, err := primitive.ObjectIDFromHex(authorID); err == nil {
+		newArticle.AuthorID = &authorObjectID
 	}
 
 	// Сохранение статьи в репозитории.
 	createdArticle, err := s.repo.Create(ctx, newArticle)
 	if err != nil {
 		s.logger.Error("Failed to save article", zap.Error(err))
+		if req.Img != nil && utils.IsDataURI(*req.Img) {
+			s.media.RemoveImage(ctx, img)
+		}
+		// Slug мог занять параллельный запрос - уникальный индекс не даст дубль
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, ErrSlugTaken
+		}
 		return nil, err
 	}
 
+	if err := s.revisions.Record(ctx, model.RevisionEntityArticle, newArticle.ID, newArticle, model.RevisionActionCreate, authorID); err != nil {
+		s.logger.Error("Failed to record article revision", zap.Error(err))
+	}
+
 	transformedResp := newArticle.CreateArtResp()
+	transformedResp.Images = s.media.VariantURLs(newArticle.Img)
 
 	s.logger.Info("Article created successfully", zap.String("id", createdArticle.ID.Hex()))
 	return transformedResp, nil
@@ -95,18 +245,147 @@ func (s *ArticleService) GetArticleById(ctx context.Context, id string) (*model.
 	}
 
 	result := article.CreateArtResp()
+	result.Images = s.media.VariantURLs(article.Img)
 	return result, err
 }
 
+// IsArticleAuthor - создал ли статью пользователь userID. У статей, созданных до появления ролей, автора нет.
+func (s *ArticleService) IsArticleAuthor(ctx context.Context, id, userID string) (bool, error) {
+	article, err := s.repo.GetArticleById(ctx, id)
+	if err != nil {
+		s.logger.Error("Failed to fetch article", zap.String("id", id), zap.Error(err))
+		return false, err
+	}
+
+	return article.AuthorID != nil && article.AuthorID.Hex() == userID, nil
+}
+
+// GetPublishedArticleById - статья для публичного API, неопубликованные считаются отсутствующими.
+func (s *ArticleService) GetPublishedArticleById(ctx context.Context, id string) (*model.ArticleResponse, error) {
+	article, err := s.repo.GetArticleById(ctx, id)
+	if err != nil {
+		s.logger.Error("Failed to fetch article", zap.Error(err))
+		return nil, err
+	}
+
+	if !article.IsPublic(time.Now()) {
+		s.logger.Warn("Article is not published", zap.String("id", id))
+		return nil, mongo.ErrNoDocuments
+	}
+
+	result := article.CreateArtResp()
+	result.Images = s.media.VariantURLs(article.Img)
+	return result, nil
+}
+
 // PatchArticleById - обновляет существующую статью частично.
-func (s *ArticleService) PatchArticleById(ctx context.Context, dto dto.PatchArticleRequest, id string) (*model.ArticleResponse, error) {
+func (s *ArticleService) PatchArticleById(ctx context.Context, dto dto.PatchArticleRequest, id, authorID string) (*model.ArticleResponse, error) {
+	// Проверяем итоговое сочетание статуса и времени публикации
+	if dto.Status != nil || dto.PublishAt != nil {
+		current, err := s.repo.GetArticleById(ctx, id)
+		if err != nil {
+			s.logger.Error("Failed to fetch article", zap.Error(err))
+			return nil, err
+		}
+
+		status, publishAt := current.EffectiveStatus(time.Now()), current.PublishAt
+		if dto.Status != nil {
+			status = *dto.Status
+		}
+		if dto.PublishAt != nil {
+			publishAt = dto.PublishAt
+		}
+
+		status, publishAt, err = resolvePublishState(status, publishAt, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		dto.Status, dto.PublishAt = &status, publishAt
+	}
+
+	if dto.Slug != nil {
+		objectID, err := primitive.ObjectIDFromHex(id)
+		if err != nil {
+			return nil, mongo.ErrNoDocuments
+		}
+		if err := ensureSlugFree(ctx, *dto.Slug, objectID, s.repo.IsArticleSlugTaken); err != nil {
+			s.logger.Warn("Article slug is not available", zap.String("slug", *dto.Slug), zap.Error(err))
+			return nil, err
+		}
+	}
+
+	if dto.Tags != nil || dto.Categories != nil {
+		if _, _, err := s.taxonomy.ResolveReferences(ctx, derefIDs(dto.Tags), derefIDs(dto.Categories)); err != nil {
+			return nil, err
+		}
+	}
+
+	if dto.Locale != nil {
+		current, err := s.repo.GetArticleById(ctx, id)
+		if err != nil {
+			s.logger.Error("Failed to fetch article", zap.Error(err))
+			return nil, err
+		}
+		dto.Locale = translationLocale(dto.Locale, current.Locale)
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, mongo.ErrNoDocuments
+	}
+	// Документ без истории сначала сохраняем как есть, чтобы patch не стёр исходное содержимое
+	if err := s.revisions.EnsureBaseline(ctx, model.RevisionEntityArticle, objectID, func() (interface{}, error) {
+		return s.repo.GetArticleById(ctx, id)
+	}); err != nil {
+		s.logger.Error("Failed to record article baseline revision", zap.Error(err))
+		return nil, err
+	}
+
+	// Прежнее изображение удаляется только после успешного изменения
+	var previousImg *string
+	if dto.Img != nil {
+		current, err := s.repo.GetArticleById(ctx, id)
+		if err != nil {
+			s.logger.Error("Failed to fetch article", zap.Error(err))
+			return nil, err
+		}
+		previousImg = current.Img
+	}
+
+	stored := dto.Img != nil && utils.IsDataURI(*dto.Img)
+	img, err := s.media.StoreInlineImage(ctx, dto.Img)
+	if err != nil {
+		s.logger.Error("Failed to store article image", zap.Error(err))
+		return nil, err
+	}
+	dto.Img = img
+
 	patchedArticle, err := s.repo.PatchArticleById(ctx, &dto, id)
 
 	if err != nil {
 		s.logger.Error("Failed to save article", zap.Error(err))
+		// Изменение не сохранилось - только что загруженное изображение никому не нужно
+		if stored {
+			s.media.RemoveImage(ctx, img)
+		}
+		// Slug мог занять параллельный запрос - уникальный индекс не даст дубль
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, ErrSlugTaken
+		}
 		return nil, err
 	}
+
+	if err := s.revisions.Record(ctx, model.RevisionEntityArticle, patchedArticle.ID, patchedArticle, model.RevisionActionPatch, authorID); err != nil {
+		// Изменение уже сохранено - без ревизии история неполна, но запрос успешен
+		s.logger.Error("Failed to record article revision", zap.Error(err))
+	}
+
+	if imageReplaced(previousImg, patchedArticle.Img) {
+		s.media.RemoveImage(ctx, previousImg)
+	}
+
 	transformedResp := patchedArticle.CreateArtResp()
+	transformedResp.Images = s.media.VariantURLs(patchedArticle.Img)
 	return transformedResp, nil
 }
 
@@ -121,3 +400,219 @@ func (s *ArticleService) RemoveArticleById(ctx context.Context, id string) (stri
 
 	return id, nil
 }
+
+// PublishArticle - публикует статью сейчас или планирует её на publishAt.
+func (s *ArticleService) PublishArticle(ctx context.Context, id string, publishAt *time.Time, authorID string) (*model.ArticleResponse, error) {
+	status := model.ArticleStatusPublished
+	return s.PatchArticleById(ctx, dto.PatchArticleRequest{Status: &status, PublishAt: publishAtOrNow(publishAt)}, id, authorID)
+}
+
+// UnpublishArticle - возвращает статью в черновики.
+func (s *ArticleService) UnpublishArticle(ctx context.Context, id, authorID string) (*model.ArticleResponse, error) {
+	status := model.ArticleStatusDraft
+	return s.PatchArticleById(ctx, dto.PatchArticleRequest{Status: &status}, id, authorID)
+}
+
+// GetArticleRevisions - история изменений статьи, от новых ревизий к старым.
+func (s *ArticleService) GetArticleRevisions(ctx context.Context, id string, pageNumber, pageSize int) (*model.Paginate[*model.RevisionResponse], error) {
+	return s.revisions.GetRevisions(ctx, model.RevisionEntityArticle, id, pageNumber, pageSize)
+}
+
+// GetArticleRevision - ревизия статьи вместе со снимком.
+func (s *ArticleService) GetArticleRevision(ctx context.Context, id, revisionID string) (*model.RevisionResponse, error) {
+	revision, err := s.revisions.GetRevision(ctx, model.RevisionEntityArticle, id, revisionID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := revision.CreateRevisionResp()
+	result.Snapshot = revision.Snapshot
+	return result, nil
+}
+
+func (s *ArticleService) DiffArticleRevisions(ctx context.Context, id, fromID, toID string) (*model.RevisionDiff, error) {
+	return s.revisions.DiffRevisions(ctx, model.RevisionEntityArticle, id, fromID, toID)
+}
+
+// RestoreArticleRevision - делает снимок ревизии текущей версией статьи и записывает это новой ревизией.
+func (s *ArticleService) RestoreArticleRevision(ctx context.Context, id, revisionID, authorID string) (*model.ArticleResponse, error) {
+	revision, err := s.revisions.GetRevision(ctx, model.RevisionEntityArticle, id, revisionID)
+	if err != nil {
+		s.logger.Error("Failed to fetch article revision", zap.String("revisionId", revisionID), zap.Error(err))
+		return nil, err
+	}
+
+	current, err := s.repo.GetArticleById(ctx, id)
+	if err != nil {
+		s.logger.Error("Failed to fetch article", zap.String("id", id), zap.Error(err))
+		return nil, err
+	}
+
+	var article model.RowArticle
+	if err := s.revisions.DecodeSnapshot(revision, &article); err != nil {
+		s.logger.Error("Failed to decode article revision", zap.String("revisionId", revisionID), zap.Error(err))
+		return nil, err
+	}
+	article.ID = revision.EntityID
+	// Адрес статьи не откатываем: старые ссылки продолжают работать через редиректы
+	article.Slug, article.OldSlugs = current.Slug, current.OldSlugs
+	// Автор статьи не меняется при откате
+	article.AuthorID = current.AuthorID
+	// Термины, удалённые после ревизии, не возвращаем
+	if article.Tags, err = s.taxonomy.KnownTermIDs(ctx, model.TermKindTag, article.Tags); err != nil {
+		return nil, err
+	}
+	if article.Categories, err = s.taxonomy.KnownTermIDs(ctx, model.TermKindCategory, article.Categories); err != nil {
+		return nil, err
+	}
+
+	restored, err := s.repo.ReplaceArticle(ctx, article)
+	if err != nil {
+		s.logger.Error("Failed to restore article", zap.String("id", id), zap.Error(err))
+		return nil, err
+	}
+
+	if err := s.revisions.Record(ctx, model.RevisionEntityArticle, restored.ID, restored, model.RevisionActionRestore, authorID); err != nil {
+		s.logger.Error("Failed to record article revision", zap.Error(err))
+	}
+
+	s.logger.Info("Article restored from revision", zap.String("id", id), zap.Int("version", revision.Version))
+	transformedResp := restored.CreateArtResp()
+	transformedResp.Images = s.media.VariantURLs(restored.Img)
+	return transformedResp, nil
+}
+
+// resolvePublishState - приводит статус и время публикации к согласованному виду:
+// опубликованная статья с publishAt в будущем становится запланированной,
+// а без publishAt публикуется сейчас.
+func resolvePublishState(status string, publishAt *time.Time, now time.Time) (string, *time.Time, error) {
+	switch status {
+	case model.ArticleStatusPublished:
+		if publishAt == nil {
+			return status, &now, nil
+		}
+		if publishAt.After(now) {
+			return model.ArticleStatusScheduled, publishAt, nil
+		}
+	case model.ArticleStatusScheduled:
+		if publishAt == nil || !publishAt.After(now) {
+			return "", nil, ErrInvalidPublishState
+		}
+	}
+	return status, publishAt, nil
+}
+
+func publishAtOrNow(publishAt *time.Time) *time.Time {
+	if publishAt != nil {
+		return publishAt
+	}
+	now := time.Now()
+	return &now
+}
+
+// RestoreArticleById - возвращает статью из корзины.
+func (s *ArticleService) RestoreArticleById(ctx context.Context, id string) (*model.ArticleResponse, error) {
+	if err := s.repo.RestoreArticleById(ctx, id); err != nil {
+		s.logger.Error("Failed to restore article", zap.String("id", id), zap.Error(err))
+		return nil, err
+	}
+
+	restored, err := s.repo.GetArticleById(ctx, id)
+	if err != nil {
+		s.logger.Error("Failed to fetch restored article", zap.String("id", id), zap.Error(err))
+		return nil, err
+	}
+
+	result := restored.CreateArtResp()
+	result.Images = s.media.VariantURLs(restored.Img)
+	return result, nil
+}
+
+// GetDeletedArticles - содержимое корзины с пагинацией.
+func (s *ArticleService) GetDeletedArticles(ctx context.Context, pageNumber, pageSize int) (*model.Paginate[*model.ArticleResponse], error) {
+	items, totalCount, err := s.repo.GetDeletedArticles(ctx, pageNumber, pageSize)
+	if err != nil {
+		s.logger.Error("Failed to fetch deleted articles", zap.Error(err))
+		return nil, err
+	}
+
+	transformedResp := make([]*model.ArticleResponse, len(items))
+	for i, item := range items {
+		transformedResp[i] = item.CreateArtResp()
+	}
+
+	return &model.Paginate[*model.ArticleResponse]{
+		PageNumber:     pageNumber,
+		RowTotalCount:  totalCount,
+		TotalPageCount: utils.CalculateTotalPages(totalCount, pageSize),
+		PageSize:       pageSize,
+		Items:          transformedResp,
+	}, nil
+}
+
+// GetPublishedArticleBySlug - опубликованная статья по текущему или прежнему slug.
+// Если slug прежний, в ответе будет актуальный - по нему вызывающий делает редирект.
+func (s *ArticleService) GetPublishedArticleBySlug(ctx context.Context, slug string) (*model.ArticleResponse, error) {
+	article, err := s.repo.GetArticleBySlug(ctx, slug)
+	if err != nil {
+		s.logger.Error("Failed to fetch article by slug", zap.String("slug", slug), zap.Error(err))
+		return nil, err
+	}
+
+	if !article.IsPublic(time.Now()) {
+		s.logger.Warn("Article is not published", zap.String("slug", slug))
+		return nil, mongo.ErrNoDocuments
+	}
+
+	result := article.CreateArtResp()
+	result.Images = s.media.VariantURLs(article.Img)
+	return result, nil
+}
+
+// BackfillSlugs - генерирует slug для статей, созданных до их появления.
+func (s *ArticleService) BackfillSlugs(ctx context.Context) (int, error) {
+	articles, err := s.repo.GetArticlesWithoutSlug(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	for i, article := range articles {
+		slug, err := uniqueSlug(ctx, article.Title, "article", article.ID, s.repo.IsArticleSlugTaken)
+		if err != nil {
+			return i, err
+		}
+		if _, err := s.repo.PatchArticleById(ctx, &dto.PatchArticleRequest{Slug: &slug}, article.ID.Hex()); err != nil {
+			return i, err
+		}
+		s.logger.Info("Article slug generated", zap.String("id", article.ID.Hex()), zap.String("slug", slug))
+	}
+
+	return len(articles), nil
+}
+
+// slugForNewArticle - slug из запроса, если он свободен, иначе сгенерированный из заголовка.
+func (s *ArticleService) slugForNewArticle(ctx context.Context, req dto.CreateArticleRequest, id primitive.ObjectID) (string, error) {
+	if req.Slug == nil {
+		return uniqueSlug(ctx, req.Title, "article", id, s.repo.IsArticleSlugTaken)
+	}
+
+	if err := ensureSlugFree(ctx, *req.Slug, id, s.repo.IsArticleSlugTaken); err != nil {
+		s.logger.Warn("Article slug is not available", zap.String("slug", *req.Slug), zap.Error(err))
+		return "", err
+	}
+	return *req.Slug, nil
+}
+
+// facets - счётчики тегов и категорий среди статей, видимых с этим фильтром и найденных по query.
+// Считаются только по запросу (filter.WithFacets), иначе nil.
+func (s *ArticleService) facets(ctx context.Context, filter model.ArticleFilter, query string) (*model.TaxonomyFacets, error) {
+	if !filter.WithFacets {
+		return nil, nil
+	}
+
+	usage, err := s.repo.CountArticleTerms(ctx, filter, query)
+	if err != nil {
+		return nil, err
+	}
+	return s.taxonomy.Facets(ctx, usage)
+}
@@ -6,54 +6,370 @@ import (
 	"edjr-trk/internal/repository"
 	"edjr-trk/pkg/utils"
 	"errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 	"go.uber.org/zap"
+	"sync"
 	"time"
 )
 
+const (
+	// refreshTokenSize - длина refresh токена в байтах до кодирования.
+	refreshTokenSize = 32
+	// challengeTokenTTL - сколько времени есть на ввод кода 2FA после пароля.
+	challengeTokenTTL = 5 * time.Minute
+)
+
+var (
+	// ErrInvalidRefreshToken is returned for an unknown, expired or revoked refresh token.
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	// ErrRefreshTokenReused is returned when an already rotated refresh token is presented again.
+	// The whole token family is revoked, since the token has probably been stolen.
+	ErrRefreshTokenReused = errors.New("refresh token has already been used")
+	// ErrInvalidChallengeToken is returned for an expired or forged 2FA login challenge.
+	ErrInvalidChallengeToken = errors.New("invalid or expired two-factor challenge")
+	// ErrInvalidCredentials is returned for an unknown email and for a wrong password alike.
+	ErrInvalidCredentials = errors.New("invalid email or password")
+	// ErrAccountDisabled is returned on login to an account disabled by an owner.
+	ErrAccountDisabled = errors.New("account is disabled")
+	// ErrTooManyLoginAttempts matches every *LoginThrottledError.
+	ErrTooManyLoginAttempts = errors.New("too many failed login attempts")
+)
+
+// LoginThrottledError - вход временно запрещён после неудачных попыток.
+type LoginThrottledError struct {
+	RetryAfter time.Duration // через сколько можно повторить
+}
+
+func (e *LoginThrottledError) Error() string {
+	return ErrTooManyLoginAttempts.Error()
+}
+
+func (e *LoginThrottledError) Is(target error) bool {
+	return target == ErrTooManyLoginAttempts
+}
+
+// dummyPasswordHash - с ним сравнивается пароль, если email не найден, чтобы ответ
+// занимал столько же времени, сколько для неверного пароля.
+var dummyPasswordHash = sync.OnceValue(func() string {
+	hash, _ := utils.HashData("edjr-trk-dummy-password", model.PasswordHashCost)
+	return hash
+})
+
 type AuthServiceInterface interface {
-	Login(ctx context.Context, email, password string) (*model.LoginResponse, error)
+	Login(ctx context.Context, email, password, ip string) (*model.LoginResponse, error)
+	LoginTwoFactor(ctx context.Context, challengeToken, code, ip string) (*model.LoginResponse, error)
+	Refresh(ctx context.Context, refreshToken string) (*model.LoginResponse, error)
+	Logout(ctx context.Context, refreshToken string, allSessions bool) error
+	RevokeSessions(ctx context.Context, userID primitive.ObjectID) error
+	IsTokenRevoked(ctx context.Context, userID string, issuedAt time.Time) (bool, error)
 }
 
 type authService struct {
-	repo       repository.UserRepositoryInterface
-	jwtService JWTServiceInterface
-	logger     *zap.Logger
+	repo            repository.UserRepositoryInterface
+	refreshTokens   repository.RefreshTokenRepositoryInterface
+	jwtService      JWTServiceInterface
+	twoFactor       TwoFactorServiceInterface
+	loginGuard      *LoginGuard
+	accessTokenTTL  time.Duration
+	refreshTokenTTL time.Duration
+	logger          *zap.Logger
 }
 
 // NewAuthService - создаёт новый экземпляр AuthService
-func NewAuthService(repo repository.UserRepositoryInterface, jwtService JWTServiceInterface, logger *zap.Logger) AuthServiceInterface {
-	return &authService{repo: repo, jwtService: jwtService, logger: logger}
+func NewAuthService(
+	repo repository.UserRepositoryInterface,
+	refreshTokens repository.RefreshTokenRepositoryInterface,
+	jwtService JWTServiceInterface,
+	twoFactor TwoFactorServiceInterface,
+	loginGuard *LoginGuard,
+	accessTokenTTL, refreshTokenTTL time.Duration,
+	logger *zap.Logger,
+) AuthServiceInterface {
+	return &authService{
+		repo:            repo,
+		refreshTokens:   refreshTokens,
+		jwtService:      jwtService,
+		twoFactor:       twoFactor,
+		loginGuard:      loginGuard,
+		accessTokenTTL:  accessTokenTTL,
+		refreshTokenTTL: refreshTokenTTL,
+		logger:          logger,
+	}
 }
 
-func (s *authService) Login(ctx context.Context, email, password string) (*model.LoginResponse, error) {
+func (s *authService) Login(ctx context.Context, email, password, ip string) (*model.LoginResponse, error) {
+	if err := s.loginGuard.Check(email, ip); err != nil {
+		s.logger.Warn("Login throttled", zap.String("ip", ip), zap.Error(err))
+		return nil, err
+	}
+
 	user, err := s.repo.GetUserByEmail(ctx, email)
-	if err != nil {
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
 		s.logger.Error("Failed to fetch user by email", zap.Error(err))
 		return nil, err
 	}
 
+	// Для неизвестного email пароль всё равно проверяется, чтобы время ответа не выдавало его
+	passwordHash := dummyPasswordHash()
+	if user != nil {
+		passwordHash = user.Password
+	}
+
 	// Проверяем пароль
-	isValidPassword, err := utils.CompareHashes(password, user.Password)
+	isValidPassword, err := utils.CompareHashes(password, passwordHash)
 	if err != nil {
 		s.logger.Error("Failed to compare password hashes", zap.Error(err))
 		return nil, err
 	}
 
-	if !isValidPassword {
-		s.logger.Warn("Invalid password provided")
-		return nil, errors.New("invalid email or password")
+	if user == nil || !isValidPassword {
+		s.logger.Warn("Invalid email or password provided", zap.String("ip", ip))
+		s.loginGuard.Fail(user, email, ip)
+		return nil, ErrInvalidCredentials
+	}
+
+	if user.IsDisabled() {
+		s.logger.Warn("Login to a disabled account", zap.String("userId", user.ID.Hex()))
+		return nil, ErrAccountDisabled
+	}
+
+	// С включённой 2FA токены выдаются только после кода: POST /api/auth/login/2fa
+	if user.TwoFactor.IsEnabled() {
+		challenge, err := s.jwtService.GenerateChallengeToken(user.ID.Hex(), challengeTokenTTL)
+		if err != nil {
+			return nil, err
+		}
+		s.logger.Info("Password accepted, waiting for two-factor code", zap.String("userId", user.ID.Hex()))
+		return &model.LoginResponse{TwoFactorRequired: true, ChallengeToken: challenge}, nil
+	}
+
+	return s.completeLogin(ctx, user)
+}
+
+// LoginTwoFactor - второй шаг входа: challenge из Login и код из приложения или код восстановления.
+func (s *authService) LoginTwoFactor(ctx context.Context, challengeToken, code, ip string) (*model.LoginResponse, error) {
+	userID, err := s.jwtService.ValidateChallengeToken(challengeToken)
+	if err != nil {
+		return nil, ErrInvalidChallengeToken
+	}
+
+	user, err := s.repo.GetUserById(ctx, userID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrInvalidChallengeToken
+		}
+		return nil, err
+	}
+	if user.IsDisabled() {
+		return nil, ErrAccountDisabled
+	}
+
+	// Коды 2FA перебираются так же, как пароли, поэтому ошибки идут в те же счётчики
+	if err := s.loginGuard.Check(user.Email, ip); err != nil {
+		s.logger.Warn("Two-factor login throttled", zap.String("userId", userID), zap.Error(err))
+		return nil, err
+	}
+
+	if err := s.twoFactor.Verify(ctx, user, code); err != nil {
+		if errors.Is(err, ErrTwoFactorDisabled) {
+			return nil, ErrInvalidChallengeToken
+		}
+		s.logger.Warn("Invalid two-factor code on login", zap.String("userId", userID), zap.Error(err))
+		if errors.Is(err, ErrInvalidTwoFactorCode) {
+			s.loginGuard.Fail(user, user.Email, ip)
+		}
+		return nil, err
+	}
+
+	return s.completeLogin(ctx, user)
+}
+
+// completeLogin - выдаёт токены после всех проверок; каждый вход начинает новую цепочку refresh токенов.
+// Счётчик ошибок сбрасывается только здесь: верный пароль без кода 2FA его не обнуляет.
+func (s *authService) completeLogin(ctx context.Context, user *model.RowUser) (*model.LoginResponse, error) {
+	s.loginGuard.Succeed(user.Email)
+
+	result, err := s.issueTokens(ctx, user, primitive.NewObjectID())
+	if err != nil {
+		return nil, err
+	}
+
+	// Логируем успешный вход
+	s.logger.Info("User logged in successfully", zap.String("userId", user.ID.Hex()))
+	return result, nil
+}
+
+// Refresh - обменивает refresh токен на новую пару токенов. Старый токен одноразовый:
+// повторное предъявление отзывает всю цепочку и все выданные пользователю access токены.
+func (s *authService) Refresh(ctx context.Context, refreshToken string) (*model.LoginResponse, error) {
+	token, err := s.findActiveToken(ctx, refreshToken)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if token.UsedAt != nil {
+		return nil, s.handleReuse(ctx, token, now)
+	}
+
+	swapped, err := s.refreshTokens.MarkUsed(ctx, token.ID, now)
+	if err != nil {
+		return nil, err
+	}
+	if !swapped {
+		// Параллельный запрос успел обменять этот же токен
+		return nil, s.handleReuse(ctx, token, now)
+	}
+
+	// Роль берём заново: её изменение вступает в силу при следующем обновлении токенов
+	user, err := s.repo.GetUserById(ctx, token.UserID.Hex())
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+	if user.IsDisabled() {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	return s.issueTokens(ctx, user, token.FamilyID)
+}
+
+// Logout - отзывает цепочку токенов текущей сессии или, с allSessions, все сессии пользователя.
+// Уже выданные access токены пользователя тоже перестают приниматься.
+func (s *authService) Logout(ctx context.Context, refreshToken string, allSessions bool) error {
+	token, err := s.refreshTokens.GetByHash(ctx, utils.HashToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return ErrInvalidRefreshToken
+		}
+		return err
+	}
+
+	now := time.Now()
+	if allSessions {
+		err = s.refreshTokens.RevokeUserTokens(ctx, token.UserID, now)
+	} else {
+		err = s.refreshTokens.RevokeFamily(ctx, token.FamilyID, now)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := s.revokeAccessTokens(ctx, token.UserID, now); err != nil {
+		return err
+	}
+
+	s.logger.Info("User logged out", zap.String("userId", token.UserID.Hex()), zap.Bool("allSessions", allSessions))
+	return nil
+}
+
+// RevokeSessions - завершает все сессии пользователя: refresh токены и уже выданные access токены.
+func (s *authService) RevokeSessions(ctx context.Context, userID primitive.ObjectID) error {
+	now := time.Now()
+	if err := s.refreshTokens.RevokeUserTokens(ctx, userID, now); err != nil {
+		return err
+	}
+	if err := s.revokeAccessTokens(ctx, userID, now); err != nil {
+		return err
+	}
+
+	s.logger.Info("All user sessions revoked", zap.String("userId", userID.Hex()))
+	return nil
+}
+
+// IsTokenRevoked - отозван ли access токен, выпущенный в issuedAt.
+// Токены удалённого или отключённого пользователя тоже считаются отозванными.
+func (s *authService) IsTokenRevoked(ctx context.Context, userID string, issuedAt time.Time) (bool, error) {
+	user, err := s.repo.GetUserById(ctx, userID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return true, nil
+		}
+		return false, err
+	}
+
+	if user.IsDisabled() {
+		return true, nil
+	}
+	return user.TokensRevokedAt != nil && issuedAt.Before(*user.TokensRevokedAt), nil
+}
+
+// findActiveToken - токен по его значению; неизвестный, просроченный и отозванный дают ErrInvalidRefreshToken.
+func (s *authService) findActiveToken(ctx context.Context, refreshToken string) (*model.RowRefreshToken, error) {
+	token, err := s.refreshTokens.GetByHash(ctx, utils.HashToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+
+	// TTL-индекс удаляет просроченные токены не сразу
+	if token.RevokedAt != nil || time.Now().After(token.ExpiresAt) {
+		return nil, ErrInvalidRefreshToken
+	}
+	return token, nil
+}
+
+func (s *authService) handleReuse(ctx context.Context, token *model.RowRefreshToken, now time.Time) error {
+	s.logger.Warn("Refresh token reuse detected, revoking token family",
+		zap.String("userId", token.UserID.Hex()),
+		zap.String("familyId", token.FamilyID.Hex()),
+	)
+
+	if err := s.refreshTokens.RevokeFamily(ctx, token.FamilyID, now); err != nil {
+		return err
+	}
+	if err := s.revokeAccessTokens(ctx, token.UserID, now); err != nil {
+		return err
+	}
+	return ErrRefreshTokenReused
+}
+
+// revokeAccessTokens - JWT хранит iat с точностью до секунды, поэтому момент отзыва округляется вниз:
+// токены, выпущенные в ту же секунду после отзыва, остаются действительными.
+func (s *authService) revokeAccessTokens(ctx context.Context, userID primitive.ObjectID, now time.Time) error {
+	err := s.repo.SetTokensRevokedAt(ctx, userID, now.Truncate(time.Second))
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil
 	}
+	return err
+}
 
-	userIdStr := user.ID.Hex()
+// issueTokens - новый access токен и refresh токен в цепочке familyID.
+func (s *authService) issueTokens(ctx context.Context, user *model.RowUser, familyID primitive.ObjectID) (*model.LoginResponse, error) {
 	// Генерируем access токен
-	accessToken, err := s.jwtService.GenerateAccessToken(userIdStr, 24*time.Hour)
+	accessToken, err := s.jwtService.GenerateAccessToken(user.ID.Hex(), user.EffectiveRole(), s.accessTokenTTL)
 	if err != nil {
 		s.logger.Error("Failed to generate access token", zap.Error(err))
 		return nil, err
 	}
 
-	result := model.LoginResponse{AccessToken: accessToken}
-	// Логируем успешный вход
-	s.logger.Info("User logged in successfully", zap.String("userId", userIdStr))
-	return &result, nil
+	refreshToken, err := utils.RandomToken(refreshTokenSize)
+	if err != nil {
+		s.logger.Error("Failed to generate refresh token", zap.Error(err))
+		return nil, err
+	}
+
+	now := time.Now()
+	err = s.refreshTokens.Create(ctx, &model.RowRefreshToken{
+		ID:        primitive.NewObjectID(),
+		UserID:    user.ID,
+		FamilyID:  familyID,
+		TokenHash: utils.HashToken(refreshToken),
+		CreatedAt: now,
+		ExpiresAt: now.Add(s.refreshTokenTTL),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.LoginResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int(s.accessTokenTTL.Seconds()),
+	}, nil
 }
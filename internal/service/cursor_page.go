@@ -0,0 +1,49 @@
+package service
+
+import (
+	"edjr-trk/internal/model"
+	"edjr-trk/pkg/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+// newCursorPage builds the keyset pagination envelope with next/prev cursors.
+// key returns the (date, _id) sort key of a row.
+func newCursorPage[T, R any](rows []T, hasMore bool, cursor *utils.PageCursor, pageSize int,
+	key func(T) (time.Time, primitive.ObjectID), transform func(T) R) *model.CursorPaginate[R] {
+	items := make([]R, len(rows))
+	for i, row := range rows {
+		items[i] = transform(row)
+	}
+
+	result := &model.CursorPaginate[R]{PageSize: pageSize, Items: items}
+	if len(rows) == 0 {
+		return result
+	}
+
+	encode := func(row T, backward bool) *string {
+		date, id := key(row)
+		str := utils.EncodePageCursor(utils.PageCursor{Date: date, ID: id, Backward: backward})
+		return &str
+	}
+
+	first, last := rows[0], rows[len(rows)-1]
+	backward := cursor != nil && cursor.Backward
+
+	// hasMore относится к направлению запроса, противоположная сторона есть, если мы пришли по курсору
+	if backward {
+		result.NextCursor = encode(last, false)
+		if hasMore {
+			result.PrevCursor = encode(first, true)
+		}
+	} else {
+		if hasMore {
+			result.NextCursor = encode(last, false)
+		}
+		if cursor != nil {
+			result.PrevCursor = encode(first, true)
+		}
+	}
+
+	return result
+}
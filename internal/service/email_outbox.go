@@ -0,0 +1,336 @@
+package service
+
+import (
+	"context"
+	"edjr-trk/internal/model"
+	"edjr-trk/internal/repository"
+	"edjr-trk/pkg/utils"
+	"errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.uber.org/zap"
+	"sync"
+	"time"
+)
+
+// ErrOutboxNotDead is returned when requeueing a message that has not exhausted its attempts.
+var ErrOutboxNotDead = errors.New("only dead messages can be requeued")
+
+// outboxOpTimeout - предел для одной операции воркера с базой.
+const outboxOpTimeout = 10 * time.Second
+
+// EmailQueueInterface - постановка письма в очередь; само письмо отправит воркер.
+type EmailQueueInterface interface {
+	Enqueue(ctx context.Context, msg *model.RowOutboxMessage) error
+}
+
+// OutboxServiceInterface - админский просмотр очереди исходящей почты.
+type OutboxServiceInterface interface {
+	GetMessages(ctx context.Context, status string, pageNumber, pageSize int) (*model.Paginate[*model.OutboxResponse], error)
+	GetMessageById(ctx context.Context, id string) (*model.OutboxResponse, error)
+	Requeue(ctx context.Context, id string) (*model.OutboxResponse, error)
+}
+
+// OutboxConfig - параметры воркеров; нулевые значения заменяются значениями по умолчанию.
+type OutboxConfig struct {
+	Workers      int           // число параллельных отправителей
+	MaxAttempts  int           // после стольких неудач письмо становится dead
+	BaseDelay    time.Duration // пауза после первой неудачи, дальше удваивается
+	MaxDelay     time.Duration
+	PollInterval time.Duration // как часто проверять очередь без новых писем
+	Lease        time.Duration // сколько письмо считается занятым воркером
+	Retention    time.Duration // сколько хранится отправленное письмо
+}
+
+// EmailOutbox - очередь исходящей почты в MongoDB и пул воркеров, которые её отправляют.
+// Неудачная попытка повторяется с экспоненциальной паузой, после MaxAttempts письмо
+// переходит в dead и ждёт ручного Requeue.
+type EmailOutbox struct {
+	repo      repository.OutboxRepositoryInterface
+	leads     repository.LeadRepositoryInterface
+	sender    repository.EmailRepositoryInterface
+	config    OutboxConfig
+	wake      chan struct{}
+	stop      chan struct{}
+	abort     chan struct{}
+	stopOnce  sync.Once
+	abortOnce sync.Once
+	wg        sync.WaitGroup
+	logger    *zap.Logger
+}
+
+func NewEmailOutbox(repo repository.OutboxRepositoryInterface, leads repository.LeadRepositoryInterface, sender repository.EmailRepositoryInterface, config OutboxConfig, logger *zap.Logger) *EmailOutbox {
+	if config.Workers <= 0 {
+		config.Workers = 1
+	}
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = 8
+	}
+	if config.BaseDelay <= 0 {
+		config.BaseDelay = 30 * time.Second
+	}
+	if config.MaxDelay < config.BaseDelay {
+		config.MaxDelay = config.BaseDelay
+	}
+	if config.PollInterval <= 0 {
+		config.PollInterval = 10 * time.Second
+	}
+	// Аренда должна вмещать захват, отправку и запись результата
+	if config.Lease <= 2*outboxOpTimeout {
+		config.Lease = 5 * time.Minute
+	}
+	if config.Retention <= 0 {
+		config.Retention = 30 * 24 * time.Hour
+	}
+	return &EmailOutbox{
+		repo:   repo,
+		leads:  leads,
+		sender: sender,
+		config: config,
+		wake:   make(chan struct{}, 1),
+		stop:   make(chan struct{}),
+		abort:  make(chan struct{}),
+		logger: logger,
+	}
+}
+
+// OutboxRetryDelay - пауза перед следующей попыткой после attempt неудачных:
+// base, 2*base, 4*base... но не больше max.
+func OutboxRetryDelay(attempt int, base, max time.Duration) time.Duration {
+	delay := base
+	for i := 1; i < attempt && delay < max; i++ {
+		delay *= 2
+	}
+	return min(delay, max)
+}
+
+// Enqueue - сохраняет письмо в очередь и будит воркер. Заполняются только адреса, тема, тексты и LeadID.
+func (o *EmailOutbox) Enqueue(ctx context.Context, msg *model.RowOutboxMessage) error {
+	now := time.Now()
+	msg.ID = primitive.NewObjectID()
+	msg.Status = model.OutboxPending
+	msg.Attempts = 0
+	msg.NextAttemptAt = now
+	msg.CreatedAt = now
+	msg.UpdatedAt = now
+
+	if err := o.repo.Enqueue(ctx, msg); err != nil {
+		return err
+	}
+
+	o.notify()
+	return nil
+}
+
+// Start - запускает воркеры. Остановить их можно только через Shutdown.
+func (o *EmailOutbox) Start() {
+	o.logger.Info("Email outbox started",
+		zap.Int("workers", o.config.Workers),
+		zap.Int("maxAttempts", o.config.MaxAttempts),
+	)
+
+	for i := 0; i < o.config.Workers; i++ {
+		o.wg.Add(1)
+		go o.work()
+	}
+}
+
+// Shutdown - перестаёт ждать новые письма, досылает готовые и ждёт воркеры.
+// Если ctx истёк раньше, воркеры завершают текущее письмо и выходят; остальные
+// письма остаются в базе и уйдут после следующего запуска.
+func (o *EmailOutbox) Shutdown(ctx context.Context) error {
+	o.stopOnce.Do(func() { close(o.stop) })
+
+	done := make(chan struct{})
+	go func() {
+		o.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		o.logger.Info("Email outbox drained")
+		return nil
+	case <-ctx.Done():
+		o.abortOnce.Do(func() { close(o.abort) })
+		o.logger.Warn("Email outbox stopped before the queue was drained")
+		return ctx.Err()
+	}
+}
+
+func (o *EmailOutbox) GetMessages(ctx context.Context, status string, pageNumber, pageSize int) (*model.Paginate[*model.OutboxResponse], error) {
+	messages, totalCount, err := o.repo.GetAll(ctx, status, pageNumber, pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]*model.OutboxResponse, len(messages))
+	for i, msg := range messages {
+		items[i] = msg.CreateOutboxResp()
+	}
+
+	return &model.Paginate[*model.OutboxResponse]{
+		PageNumber:     pageNumber,
+		RowTotalCount:  totalCount,
+		TotalPageCount: utils.CalculateTotalPages(totalCount, pageSize),
+		PageSize:       pageSize,
+		Items:          items,
+	}, nil
+}
+
+func (o *EmailOutbox) GetMessageById(ctx context.Context, id string) (*model.OutboxResponse, error) {
+	msg, err := o.repo.GetMessageById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return msg.CreateOutboxResp(), nil
+}
+
+// Requeue - возвращает dead письмо в очередь с новым набором попыток.
+func (o *EmailOutbox) Requeue(ctx context.Context, id string) (*model.OutboxResponse, error) {
+	msg, err := o.repo.GetMessageById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if msg.Status != model.OutboxDead {
+		return nil, ErrOutboxNotDead
+	}
+
+	msg, err = o.repo.Requeue(ctx, id, time.Now())
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		// Состояние изменилось между чтением и обновлением
+		return nil, ErrOutboxNotDead
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	o.recordLeadDelivery(ctx, msg)
+	o.notify()
+
+	o.logger.Info("Outbox message requeued", zap.String("id", id))
+	return msg.CreateOutboxResp(), nil
+}
+
+func (o *EmailOutbox) notify() {
+	select {
+	case o.wake <- struct{}{}:
+	default:
+	}
+}
+
+// work - цикл воркера: отправить всё готовое, затем ждать нового письма или тика.
+func (o *EmailOutbox) work() {
+	defer o.wg.Done()
+
+	ticker := time.NewTicker(o.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		o.sendReady()
+
+		select {
+		case <-o.stop:
+			o.sendReady()
+			return
+		case <-o.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+// sendReady - отправляет письма, пока очередь не опустеет или не прервут остановку.
+func (o *EmailOutbox) sendReady() {
+	for {
+		select {
+		case <-o.abort:
+			return
+		default:
+		}
+
+		if !o.sendNext() {
+			return
+		}
+	}
+}
+
+// sendNext - берёт и отправляет одно письмо; false, если брать нечего.
+func (o *EmailOutbox) sendNext() bool {
+	ctx, cancel := context.WithTimeout(context.Background(), outboxOpTimeout)
+	msg, err := o.repo.ClaimNext(ctx, time.Now(), o.config.Lease)
+	cancel()
+	if err != nil {
+		// Ошибка базы уже залогирована, следующая попытка будет по тику
+		return false
+	}
+
+	// Отправка должна закончиться до конца аренды, иначе письмо возьмёт другой воркер и отправит ещё раз
+	sendCtx, cancelSend := context.WithTimeout(context.Background(), o.sendTimeout())
+	sendErr := o.sender.SendEmail(sendCtx, &repository.EmailMessage{
+		To:      msg.To,
+		ReplyTo: msg.ReplyTo,
+		Subject: msg.Subject,
+		HTML:    msg.Body,
+		Text:    msg.Text,
+	})
+	cancelSend()
+
+	ctx, cancel = context.WithTimeout(context.Background(), outboxOpTimeout)
+	defer cancel()
+
+	if sendErr == nil {
+		sentAt := time.Now()
+		id := msg.ID
+		msg, err = o.repo.MarkSent(ctx, id, msg.LockToken, sentAt, sentAt.Add(o.config.Retention))
+		if err != nil {
+			o.logLeaseLost(id, err)
+			return true
+		}
+		o.logger.Info("Email sent", zap.String("id", msg.ID.Hex()), zap.Int("attempts", msg.Attempts))
+		o.recordLeadDelivery(ctx, msg)
+		return true
+	}
+
+	dead := msg.Attempts >= o.config.MaxAttempts
+	next := time.Now().Add(OutboxRetryDelay(msg.Attempts, o.config.BaseDelay, o.config.MaxDelay))
+	id, attempts := msg.ID, msg.Attempts
+	msg, err = o.repo.MarkFailed(ctx, id, msg.LockToken, sendErr.Error(), next, dead)
+	if err != nil {
+		o.logLeaseLost(id, err)
+		return true
+	}
+
+	if dead {
+		o.logger.Error("Email moved to dead letters",
+			zap.String("id", id.Hex()), zap.Int("attempts", attempts), zap.Error(sendErr))
+	} else {
+		o.logger.Warn("Email delivery failed, will retry",
+			zap.String("id", id.Hex()), zap.Int("attempts", attempts), zap.Time("nextAttemptAt", next), zap.Error(sendErr))
+	}
+	o.recordLeadDelivery(ctx, msg)
+	return true
+}
+
+// sendTimeout - сколько может длиться отправка одного письма: аренда за вычетом времени
+// на захват и на запись результата.
+func (o *EmailOutbox) sendTimeout() time.Duration {
+	return o.config.Lease - 2*outboxOpTimeout
+}
+
+// logLeaseLost - результат попытки не записан. Ошибка базы уже залогирована репозиторием;
+// ErrNoDocuments значит, что аренда истекла и письмо взял другой воркер.
+func (o *EmailOutbox) logLeaseLost(id primitive.ObjectID, err error) {
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		o.logger.Warn("Outbox lease expired during delivery, result discarded", zap.String("id", id.Hex()))
+	}
+}
+
+// recordLeadDelivery - переносит состояние письма в обращение, к которому оно относится.
+func (o *EmailOutbox) recordLeadDelivery(ctx context.Context, msg *model.RowOutboxMessage) {
+	if msg.LeadID == nil {
+		return
+	}
+	if err := o.leads.SetDelivery(ctx, *msg.LeadID, msg.LeadDelivery()); err != nil {
+		o.logger.Error("Failed to record lead delivery", zap.String("leadId", msg.LeadID.Hex()), zap.Error(err))
+	}
+}
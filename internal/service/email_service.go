@@ -1,48 +1,168 @@
 package service
 
 import (
-	"edjr-trk/configs/env"
+	"context"
 	"edjr-trk/internal/api/dto"
+	"edjr-trk/internal/model"
 	"edjr-trk/internal/repository"
-	"fmt"
+	"edjr-trk/pkg/mailtmpl"
+	"errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 	"go.uber.org/zap"
+	"net/mail"
+	"time"
 )
 
+// errMailToNotSet - некуда отправлять письма об обращениях; обращения всё равно сохраняются.
+var errMailToNotSet = errors.New("MAIL_TO is not set")
+
 type EmailServiceInterface interface {
-	SendMessage(dto *dto.SendEmailRequest) error
+	SendMessage(ctx context.Context, dto *dto.SendEmailRequest, ip, userAgent string) (*model.LeadResponse, error)
 }
 
 type emailService struct {
-	repo   repository.EmailRepositoryInterface
-	logger *zap.Logger
-}
-
-func NewEmailService(repo repository.EmailRepositoryInterface, logger *zap.Logger) EmailServiceInterface {
-	return &emailService{repo, logger}
-}
-
-func (s *emailService) SendMessage(dto *dto.SendEmailRequest) error {
-	from := env.GetEnv("GMAIL_FROM", "")
-	password := env.GetEnv("GMAIL_PASSWORD", "")
-	to := env.GetEnv("GMAIL_TO", "")
-	subject := "Message from your website!"
-	body := fmt.Sprintf(
-		`<html>
-		<body>
-			<p><strong>Email:</strong> %s</p>
-			<p><strong>Name:</strong> %s</p>
-			<p><strong>Phone:</strong> <a href="tel:%s">%s</a></p>
-			<p><strong>Message:</strong></p>
-			<p>%s</p>
-			<p>Best wishes,<br>Your team.</p>
-		</body>
-		</html>`,
-		dto.Email, dto.Name, dto.Phone, dto.Phone, dto.Text,
-	)
-
-	err := s.repo.SendEmail(from, password, to, subject, body)
+	queue     EmailQueueInterface
+	leads     repository.LeadRepositoryInterface
+	notifyTo  string // адрес владельца сайта для писем об обращениях
+	templates *mailtmpl.Renderer
+	autoReply *RateLimiter // лимит подтверждений на адрес посетителя; nil - подтверждения выключены
+	logger    *zap.Logger
+}
+
+func NewEmailService(queue EmailQueueInterface, leads repository.LeadRepositoryInterface, notifyTo string, templates *mailtmpl.Renderer, autoReply *RateLimiter, logger *zap.Logger) EmailServiceInterface {
+	return &emailService{
+		queue:     queue,
+		leads:     leads,
+		notifyTo:  notifyTo,
+		templates: templates,
+		autoReply: autoReply,
+		logger:    logger,
+	}
+}
+
+// SendMessage - сохраняет обращение и ставит письмо владельцу сайта в очередь. Обращение пишется
+// в базу первым, поэтому сбой почты его не теряет: результат отправки записывается в delivery.
+// Посетителю уходит подтверждение с номером обращения, если оно включено.
+func (s *emailService) SendMessage(ctx context.Context, dto *dto.SendEmailRequest, ip, userAgent string) (*model.LeadResponse, error) {
+	now := time.Now()
+	lead := &model.RowLead{
+		ID:        primitive.NewObjectID(),
+		Name:      dto.Name,
+		Email:     dto.Email,
+		Phone:     dto.Phone,
+		Text:      dto.Text,
+		IP:        ip,
+		UserAgent: userAgent,
+		Locale:    dto.Locale,
+		Status:    model.LeadStatusNew,
+		Delivery:  model.LeadDelivery{Status: model.DeliveryPending, UpdatedAt: now},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err := s.create(ctx, lead); err != nil {
+		s.logger.Error("Failed to save lead", zap.Error(err))
+		return nil, err
+	}
+
+	s.notifyOwner(ctx, lead)
+	s.acknowledge(ctx, lead)
+	return lead.CreateLeadResp(), nil
+}
+
+// create - сохраняет обращение; при совпадении номера с существующим берёт новый.
+func (s *emailService) create(ctx context.Context, lead *model.RowLead) error {
+	for attempt := 0; ; attempt++ {
+		reference, err := model.NewLeadReference(lead.CreatedAt)
+		if err != nil {
+			return err
+		}
+		lead.Reference = reference
+
+		err = s.leads.Create(ctx, lead)
+		if !mongo.IsDuplicateKeyError(err) || attempt == 2 {
+			return err
+		}
+	}
+}
+
+// acknowledge - подтверждение посетителю с копией сообщения. Адрес в форме может быть чужим,
+// поэтому на один адрес уходит ограниченное число подтверждений; сверх лимита они молча
+// пропускаются, а обращение сохраняется как обычно.
+func (s *emailService) acknowledge(ctx context.Context, lead *model.RowLead) {
+	if s.autoReply == nil {
+		return
+	}
+	if err := s.autoReply.Allow(accountKey(lead.Email)); err != nil {
+		s.logger.Warn("Auto-reply throttled", zap.String("leadId", lead.ID.Hex()), zap.Error(err))
+		return
+	}
+
+	email, err := s.templates.Render("lead_confirmation", lead.Locale, struct {
+		Name      string
+		Reference string
+		Phone     string
+		Text      string
+	}{lead.Name, lead.Reference, lead.Phone, lead.Text})
+	if err != nil {
+		s.logger.Error("Failed to render auto-reply", zap.String("leadId", lead.ID.Hex()), zap.Error(err))
+		return
+	}
+
+	// Без LeadID: delivery обращения описывает письмо владельцу, а не подтверждение
+	err = s.queue.Enqueue(ctx, &model.RowOutboxMessage{
+		To:      (&mail.Address{Name: lead.Name, Address: lead.Email}).String(),
+		ReplyTo: s.notifyTo,
+		Subject: email.Subject,
+		Body:    email.HTML,
+		Text:    email.Text,
+	})
+	if err != nil {
+		s.logger.Error("Failed to enqueue auto-reply", zap.String("leadId", lead.ID.Hex()), zap.Error(err))
+	}
+}
+
+// notifyOwner - ставит в очередь письмо об обращении; ответ на него уйдёт посетителю (Reply-To).
+// Если даже очередь недоступна, это записывается в обращение, а посетитель всё равно
+// получает успешный ответ.
+func (s *emailService) notifyOwner(ctx context.Context, lead *model.RowLead) {
+	err := errMailToNotSet
+	if s.notifyTo != "" {
+		err = s.enqueueOwnerEmail(ctx, lead)
+	}
+	if err == nil {
+		return
+	}
+
+	s.logger.Error("Failed to enqueue lead email", zap.String("leadId", lead.ID.Hex()), zap.Error(err))
+	delivery := model.LeadDelivery{Status: model.DeliveryFailed, LastError: err.Error(), UpdatedAt: time.Now()}
+	if err := s.leads.SetDelivery(ctx, lead.ID, delivery); err != nil {
+		s.logger.Error("Failed to record lead delivery", zap.String("leadId", lead.ID.Hex()), zap.Error(err))
+		return
+	}
+	lead.Delivery = delivery
+}
+
+func (s *emailService) enqueueOwnerEmail(ctx context.Context, lead *model.RowLead) error {
+	// Имя и текст посетителя экранирует html/template
+	email, err := s.templates.Render("lead_notification", s.templates.DefaultLocale(), struct {
+		Name  string
+		Email string
+		Phone string
+		Text  string
+	}{lead.Name, lead.Email, lead.Phone, lead.Text})
 	if err != nil {
-		s.logger.Error("Ошибка при отправке письма: %v", zap.Error(err))
+		return err
 	}
-	return nil
+
+	replyTo := (&mail.Address{Name: lead.Name, Address: lead.Email}).String()
+	return s.queue.Enqueue(ctx, &model.RowOutboxMessage{
+		To:      s.notifyTo,
+		ReplyTo: replyTo,
+		Subject: email.Subject,
+		Body:    email.HTML,
+		Text:    email.Text,
+		LeadID:  &lead.ID,
+	})
 }
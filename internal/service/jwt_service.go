@@ -1,6 +1,7 @@
 package service
 
 import (
+	"edjr-trk/pkg/jwtkeys"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -11,58 +12,92 @@ import (
 type JWTServiceInterface interface {
 	// GenerateAccessToken генерирует JWT access токен
 	// userId - уникальный идентификатор пользователя
+	// role - роль пользователя, по ней проверяются права
 	// expiresIn - продолжительность времени действия токена
-	GenerateAccessToken(userId string, expiresIn time.Duration) (string, error)
+	GenerateAccessToken(userId, role string, expiresIn time.Duration) (string, error)
 
 	// ValidateToken проверяет валидность предоставленного токена
 	// Возвращает объект токена или ошибку
 	ValidateToken(token string) (*jwt.Token, error)
+
+	// GenerateChallengeToken генерирует токен второго шага входа при включённой 2FA.
+	// У него своя аудитория, поэтому как access токен он не принимается
+	GenerateChallengeToken(userId string, expiresIn time.Duration) (string, error)
+
+	// ValidateChallengeToken проверяет токен второго шага входа и возвращает userId
+	ValidateChallengeToken(token string) (string, error)
+
+	// PublicKeys возвращает открытые ключи проверки подписи для JWKS
+	PublicKeys() jwtkeys.JWKSet
 }
 
 // jwtService структура, содержащая настройки для работы с JWT
 type jwtService struct {
-	secretKey string      // секретный ключ для подписи токенов
-	logger    *zap.Logger // логгер для записи действий
+	keys     *jwtkeys.KeySet // ключ подписи и все ключи, которые принимаются при проверке
+	issuer   string          // iss выпускаемых и проверяемых токенов
+	audience string          // aud выпускаемых и проверяемых токенов
+	logger   *zap.Logger     // логгер для записи действий
 }
 
 // Claims определяет пользовательские данные для хранения в JWT токене
 // UserID - идентификатор пользователя
+// Role - роль пользователя на момент выпуска токена
 // RegisteredClaims - встроенные поля JWT (например, время истечения)
 type Claims struct {
 	UserId               string `json:"userId"` // Уникальный идентификатор пользователя
+	Role                 string `json:"role"`   // Роль пользователя
 	jwt.RegisteredClaims        // Встроенные стандартные claims (exp, iat и т.д.)
 }
 
 // NewJWTService создает новый экземпляр JWTServiceInterface
-// secretKey - строка, используемая для подписи токенов
+// keys - ключ подписи и ключи проверки
+// issuer, audience - значения iss и aud
 // logger - объект логгера для записи действий
-func NewJWTService(secretKey string, logger *zap.Logger) JWTServiceInterface {
+func NewJWTService(keys *jwtkeys.KeySet, issuer, audience string, logger *zap.Logger) JWTServiceInterface {
 	return &jwtService{
-		secretKey: secretKey,
-		logger:    logger,
+		keys:     keys,
+		issuer:   issuer,
+		audience: audience,
+		logger:   logger,
 	}
 }
 
 // GenerateAccessToken генерирует JWT токен с заданным userID и временем действия
 // Возвращает подписанный токен в виде строки или ошибку
-func (s *jwtService) GenerateAccessToken(userId string, expiresIn time.Duration) (string, error) {
+func (s *jwtService) GenerateAccessToken(userId, role string, expiresIn time.Duration) (string, error) {
 	// Логируем начало генерации токена
 	s.logger.Info("Generating access token", zap.String("userId", userId), zap.Duration("expiresIn", expiresIn))
 
+	return s.sign(userId, role, s.audience, expiresIn)
+}
+
+// GenerateChallengeToken генерирует токен второго шага входа без роли и с аудиторией 2FA
+func (s *jwtService) GenerateChallengeToken(userId string, expiresIn time.Duration) (string, error) {
+	s.logger.Info("Generating 2FA challenge token", zap.String("userId", userId))
+	return s.sign(userId, "", s.challengeAudience(), expiresIn)
+}
+
+// sign подписывает claims текущим ключом
+func (s *jwtService) sign(userId, role, audience string, expiresIn time.Duration) (string, error) {
 	// Создаем claims с пользовательскими и стандартными данными
 	claims := Claims{
 		UserId: userId,
+		Role:   role,
 		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    s.issuer,
+			Audience:  jwt.ClaimStrings{audience},
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(expiresIn)), // Устанавливаем время истечения токена
 			IssuedAt:  jwt.NewNumericDate(time.Now()),                // Устанавливаем время создания токена
 		},
 	}
 
-	// Создаем новый токен с методом подписи HS256 и нашими claims
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	// Создаем новый токен с методом подписи текущего ключа; kid указывает, каким ключом проверять
+	key := s.keys.Signing()
+	token := jwt.NewWithClaims(key.Method, claims)
+	token.Header["kid"] = key.ID
 
-	// Подписываем токен с помощью секретного ключа
-	signedToken, err := token.SignedString([]byte(s.secretKey))
+	// Подписываем токен
+	signedToken, err := token.SignedString(key.Sign)
 	if err != nil {
 		s.logger.Error("Failed to sign token", zap.Error(err))
 		return "", err
@@ -80,10 +115,7 @@ func (s *jwtService) ValidateToken(tokenStr string) (*jwt.Token, error) {
 	// Логируем начало валидации токена
 	s.logger.Info("Validating token")
 
-	// Разбираем токен и проверяем его подпись с использованием секретного ключа
-	token, err := jwt.ParseWithClaims(tokenStr, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		return []byte(s.secretKey), nil
-	})
+	token, err := s.parse(tokenStr, s.audience)
 	if err != nil {
 		s.logger.Error("Failed to validate token", zap.Error(err))
 		return nil, err
@@ -93,3 +125,52 @@ func (s *jwtService) ValidateToken(tokenStr string) (*jwt.Token, error) {
 	s.logger.Info("Token validated successfully")
 	return token, nil
 }
+
+// ValidateChallengeToken проверяет токен второго шага входа
+func (s *jwtService) ValidateChallengeToken(tokenStr string) (string, error) {
+	token, err := s.parse(tokenStr, s.challengeAudience())
+	if err != nil {
+		s.logger.Warn("Invalid 2FA challenge token", zap.Error(err))
+		return "", err
+	}
+
+	claims, ok := token.Claims.(*Claims)
+	if !ok || claims.UserId == "" {
+		return "", jwt.ErrTokenInvalidClaims
+	}
+	return claims.UserId, nil
+}
+
+// challengeAudience - аудитория токенов второго шага входа
+func (s *jwtService) challengeAudience() string {
+	return s.audience + ":2fa"
+}
+
+// parse проверяет подпись, iss, aud и срок действия токена
+func (s *jwtService) parse(tokenStr, audience string) (*jwt.Token, error) {
+	// Ключ выбирается по kid и должен совпадать с алгоритмом токена: HS256-токен
+	// не проверяется открытым RSA-ключом как секретом
+	token, err := jwt.ParseWithClaims(tokenStr, &Claims{}, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		key, err := s.keys.Lookup(kid, token.Method.Alg())
+		if err != nil {
+			return nil, err
+		}
+		return key.Verify, nil
+	},
+		jwt.WithValidMethods(s.keys.Methods()),
+		jwt.WithIssuer(s.issuer),
+		jwt.WithAudience(audience),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+// PublicKeys возвращает открытые ключи в формате JWKS; секрет HS256 не публикуется
+func (s *jwtService) PublicKeys() jwtkeys.JWKSet {
+	return s.keys.JWKS()
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"edjr-trk/internal/api/dto"
+	"edjr-trk/internal/model"
+	"edjr-trk/internal/repository"
+	"edjr-trk/pkg/utils"
+	"errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.uber.org/zap"
+	"time"
+)
+
+// ErrAssigneeNotFound is returned when a lead is assigned to an unknown or disabled user.
+var ErrAssigneeNotFound = errors.New("assignee not found")
+
+// LeadServiceInterface - админский inbox обращений посетителей.
+type LeadServiceInterface interface {
+	GetLeads(ctx context.Context, filter model.LeadFilter, pageNumber, pageSize int) (*model.Paginate[*model.LeadResponse], error)
+	GetLeadById(ctx context.Context, id string) (*model.LeadResponse, error)
+	PatchLeadById(ctx context.Context, dto dto.PatchLeadRequest, id string) (*model.LeadResponse, error)
+	AddNote(ctx context.Context, id, authorID, text string) (*model.LeadResponse, error)
+}
+
+type leadService struct {
+	repo   repository.LeadRepositoryInterface
+	users  repository.UserRepositoryInterface
+	logger *zap.Logger
+}
+
+func NewLeadService(repo repository.LeadRepositoryInterface, users repository.UserRepositoryInterface, logger *zap.Logger) LeadServiceInterface {
+	return &leadService{repo: repo, users: users, logger: logger}
+}
+
+func (s *leadService) GetLeads(ctx context.Context, filter model.LeadFilter, pageNumber, pageSize int) (*model.Paginate[*model.LeadResponse], error) {
+	leads, totalCount, err := s.repo.GetAll(ctx, filter, pageNumber, pageSize)
+	if err != nil {
+		s.logger.Error("Failed to fetch leads", zap.Error(err))
+		return nil, err
+	}
+
+	items := make([]*model.LeadResponse, len(leads))
+	for i, lead := range leads {
+		items[i] = lead.CreateLeadResp()
+	}
+
+	return &model.Paginate[*model.LeadResponse]{
+		PageNumber:     pageNumber,
+		RowTotalCount:  totalCount,
+		TotalPageCount: utils.CalculateTotalPages(totalCount, pageSize),
+		PageSize:       pageSize,
+		Items:          items,
+	}, nil
+}
+
+func (s *leadService) GetLeadById(ctx context.Context, id string) (*model.LeadResponse, error) {
+	lead, err := s.repo.GetLeadById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return lead.CreateLeadResp(), nil
+}
+
+// PatchLeadById - меняет статус и ответственного. Назначить можно только действующего пользователя.
+func (s *leadService) PatchLeadById(ctx context.Context, dto dto.PatchLeadRequest, id string) (*model.LeadResponse, error) {
+	if dto.AssigneeID != nil && *dto.AssigneeID != "" {
+		assignee, err := s.users.GetUserById(ctx, *dto.AssigneeID)
+		if errors.Is(err, mongo.ErrNoDocuments) || (err == nil && assignee.IsDisabled()) {
+			return nil, ErrAssigneeNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	lead, err := s.repo.PatchLeadById(ctx, &dto, id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Lead updated", zap.String("id", id), zap.String("status", lead.Status))
+	return lead.CreateLeadResp(), nil
+}
+
+// AddNote - добавляет внутреннюю заметку от имени текущего пользователя.
+func (s *leadService) AddNote(ctx context.Context, id, authorID, text string) (*model.LeadResponse, error) {
+	author, err := primitive.ObjectIDFromHex(authorID)
+	if err != nil {
+		return nil, err
+	}
+
+	lead, err := s.repo.AddNote(ctx, id, model.LeadNote{
+		ID:        primitive.NewObjectID(),
+		AuthorID:  author,
+		Text:      text,
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return lead.CreateLeadResp(), nil
+}
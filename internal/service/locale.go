@@ -0,0 +1,22 @@
+package service
+
+import "edjr-trk/configs/locale"
+
+// contentLocale - язык текстов нового документа: из запроса или CONTENT_DEFAULT_LOCALE.
+func contentLocale(requested *string) string {
+	if requested != nil {
+		return *requested
+	}
+	return locale.Default()
+}
+
+// translationLocale - язык перевода для patch-запроса; nil, если тексты относятся к оригиналу.
+func translationLocale(requested *string, originalLocale string) *string {
+	if originalLocale == "" {
+		originalLocale = locale.Default()
+	}
+	if requested == nil || *requested == originalLocale {
+		return nil
+	}
+	return requested
+}
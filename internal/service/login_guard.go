@@ -0,0 +1,220 @@
+package service
+
+import (
+	"context"
+	"edjr-trk/internal/model"
+	"edjr-trk/pkg/mailtmpl"
+	"go.uber.org/zap"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LoginThrottle - счётчик неудачных входов по ключу (email или IP). После каждой ошибки
+// следующая попытка разрешена только через задержку, которая растёт вдвое, а после
+// maxFailures ошибок подряд ключ блокируется на lockout.
+type LoginThrottle struct {
+	attempts    map[string]*loginAttempts
+	mu          sync.Mutex
+	maxFailures int
+	baseDelay   time.Duration // задержка после первой ошибки
+	maxDelay    time.Duration // предел роста задержки
+	lockout     time.Duration // время блокировки; столько же живёт счётчик без новых ошибок
+	maxKeys     int           // предел числа счётчиков в памяти
+}
+
+type loginAttempts struct {
+	failures     int
+	lastFailure  time.Time
+	blockedUntil time.Time
+}
+
+// NewLoginThrottle - создание счётчика неудачных входов; maxKeys ограничивает число ключей в памяти
+func NewLoginThrottle(maxFailures int, baseDelay, maxDelay, lockout time.Duration, maxKeys int) *LoginThrottle {
+	t := &LoginThrottle{
+		attempts:    make(map[string]*loginAttempts),
+		maxFailures: maxFailures,
+		baseDelay:   baseDelay,
+		maxDelay:    maxDelay,
+		lockout:     lockout,
+		maxKeys:     maxKeys,
+	}
+
+	// Старые счётчики удаляются раз в час
+	go t.cleanup(time.Hour)
+
+	return t
+}
+
+// Blocked - сколько ещё ждать до следующей попытки; 0, если вход разрешён.
+func (t *LoginThrottle) Blocked(key string, now time.Time) time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	a, ok := t.attempts[key]
+	if !ok || !now.Before(a.blockedUntil) {
+		return 0
+	}
+	return a.blockedUntil.Sub(now)
+}
+
+// Fail - учитывает неудачную попытку. Возвращает true, если именно она привела к блокировке.
+func (t *LoginThrottle) Fail(key string, now time.Time) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	a, ok := t.attempts[key]
+	if !ok && len(t.attempts) >= t.maxKeys {
+		t.evict(now)
+	}
+	if !ok || now.Sub(a.lastFailure) > t.lockout {
+		a = &loginAttempts{}
+		t.attempts[key] = a
+	}
+
+	a.failures++
+	a.lastFailure = now
+	if a.failures >= t.maxFailures {
+		// После блокировки отсчёт начинается заново
+		a.failures = 0
+		a.blockedUntil = now.Add(t.lockout)
+		return true
+	}
+
+	a.blockedUntil = now.Add(t.delay(a.failures))
+	return false
+}
+
+// Reset - забывает ошибки после успешного входа.
+func (t *LoginThrottle) Reset(key string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.attempts, key)
+}
+
+// Lockout - время блокировки после maxFailures ошибок.
+func (t *LoginThrottle) Lockout() time.Duration {
+	return t.lockout
+}
+
+// delay - baseDelay, удвоенная за каждую ошибку после первой, но не больше maxDelay.
+func (t *LoginThrottle) delay(failures int) time.Duration {
+	d := t.baseDelay
+	for i := 1; i < failures && d < t.maxDelay; i++ {
+		d *= 2
+	}
+	return min(d, t.maxDelay)
+}
+
+// evict - освобождает место под новый ключ: сначала удаляются устаревшие счётчики,
+// если их нет - счётчик с самой давней ошибкой. Вызывается под t.mu.
+func (t *LoginThrottle) evict(now time.Time) {
+	t.prune(now)
+	if len(t.attempts) < t.maxKeys {
+		return
+	}
+
+	var oldestKey string
+	var oldest time.Time
+	for key, a := range t.attempts {
+		if oldestKey == "" || a.lastFailure.Before(oldest) {
+			oldestKey, oldest = key, a.lastFailure
+		}
+	}
+	delete(t.attempts, oldestKey)
+}
+
+// prune - удаляет счётчики без блокировки и без ошибок за последний lockout. Вызывается под t.mu.
+func (t *LoginThrottle) prune(now time.Time) {
+	for key, a := range t.attempts {
+		if now.After(a.blockedUntil) && now.Sub(a.lastFailure) > t.lockout {
+			delete(t.attempts, key)
+		}
+	}
+}
+
+func (t *LoginThrottle) cleanup(interval time.Duration) {
+	for {
+		time.Sleep(interval)
+
+		t.mu.Lock()
+		t.prune(time.Now())
+		t.mu.Unlock()
+	}
+}
+
+// LoginGuard - защита входа от подбора пароля: ошибки считаются отдельно по аккаунту и по IP,
+// владельцу аккаунта при блокировке уходит письмо. Неизвестные email учитываются так же,
+// как существующие, поэтому по ответам нельзя понять, есть ли такой пользователь.
+type LoginGuard struct {
+	accounts  *LoginThrottle
+	ips       *LoginThrottle
+	mailer    EmailQueueInterface
+	templates *mailtmpl.Renderer
+	logger    *zap.Logger
+}
+
+func NewLoginGuard(accounts, ips *LoginThrottle, mailer EmailQueueInterface, templates *mailtmpl.Renderer, logger *zap.Logger) *LoginGuard {
+	return &LoginGuard{accounts: accounts, ips: ips, mailer: mailer, templates: templates, logger: logger}
+}
+
+// Check - возвращает *LoginThrottledError, если вход для email или с ip пока запрещён.
+func (g *LoginGuard) Check(email, ip string) error {
+	now := time.Now()
+	wait := max(g.accounts.Blocked(accountKey(email), now), g.ips.Blocked(ip, now))
+	if wait > 0 {
+		return &LoginThrottledError{RetryAfter: wait}
+	}
+	return nil
+}
+
+// Fail - учитывает неудачную попытку; user равен nil, если email не найден.
+func (g *LoginGuard) Fail(user *model.RowUser, email, ip string) {
+	now := time.Now()
+
+	if g.ips.Fail(ip, now) {
+		g.logger.Warn("IP locked out after failed logins", zap.String("ip", ip))
+	}
+
+	if !g.accounts.Fail(accountKey(email), now) {
+		return
+	}
+	g.logger.Warn("Account locked out after failed logins", zap.String("email", email), zap.String("ip", ip))
+
+	// Письмо ставится в очередь в фоне, чтобы время ответа не зависело от того, есть ли аккаунт
+	if user != nil {
+		go g.notifyLockout(user.Email, ip, now.Add(g.accounts.Lockout()))
+	}
+}
+
+// Succeed - сбрасывает счётчик аккаунта после входа. Счётчик IP не сбрасывается,
+// иначе один свой аккаунт позволял бы бесконечно перебирать чужие.
+func (g *LoginGuard) Succeed(email string) {
+	g.accounts.Reset(accountKey(email))
+}
+
+func (g *LoginGuard) notifyLockout(to, ip string, until time.Time) {
+	email, err := g.templates.Render("account_locked", g.templates.DefaultLocale(), struct {
+		IP    string
+		Until string
+	}{ip, until.UTC().Format("2006-01-02 15:04 MST")})
+	if err != nil {
+		g.logger.Error("Failed to render lockout notification", zap.Error(err))
+		return
+	}
+
+	err = g.mailer.Enqueue(context.Background(), &model.RowOutboxMessage{
+		To:      to,
+		Subject: email.Subject,
+		Body:    email.HTML,
+		Text:    email.Text,
+	})
+	if err != nil {
+		g.logger.Error("Failed to send lockout notification", zap.String("email", to), zap.Error(err))
+	}
+}
+
+// accountKey - email без учёта регистра и пробелов по краям.
+func accountKey(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
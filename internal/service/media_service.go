@@ -0,0 +1,236 @@
+package service
+
+import (
+	"context"
+	"edjr-trk/internal/model"
+	"edjr-trk/internal/repository"
+	"edjr-trk/pkg/imaging"
+	"edjr-trk/pkg/utils"
+	"errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.uber.org/zap"
+	"strings"
+	"time"
+)
+
+// MediaServiceInterface - интерфейс для работы с медиафайлами.
+type MediaServiceInterface interface {
+	SaveDataURI(ctx context.Context, dataURI string) (*model.RowMedia, error)
+	StoreInlineImage(ctx context.Context, img *string) (*string, error)
+	GetMediaById(ctx context.Context, id string) (*model.RowMedia, error)
+	GetMediaContent(ctx context.Context, media *model.RowMedia, variant string) (string, []byte, error)
+	RemoveMediaById(ctx context.Context, id string) error
+	RemoveImage(ctx context.Context, img *string)
+	ThumbnailURL(img *string) *string
+	VariantURLs(img *string) map[string]string
+}
+
+type mediaService struct {
+	repo     repository.MediaRepositoryInterface
+	blobs    repository.BlobRepositoryInterface
+	variants []imaging.Variant
+	logger   *zap.Logger
+}
+
+func NewMediaService(repo repository.MediaRepositoryInterface, blobs repository.BlobRepositoryInterface, variants []imaging.Variant, logger *zap.Logger) MediaServiceInterface {
+	return &mediaService{repo: repo, blobs: blobs, variants: variants, logger: logger}
+}
+
+// SaveDataURI - декодирует data URI, кладёт байты в хранилище и сохраняет метаданные.
+func (s *mediaService) SaveDataURI(ctx context.Context, dataURI string) (*model.RowMedia, error) {
+	contentType, data, err := utils.ParseDataURI(dataURI)
+	if err != nil {
+		s.logger.Error("Failed to parse data URI", zap.Error(err))
+		return nil, err
+	}
+
+	media := &model.RowMedia{
+		ID:          primitive.NewObjectID(),
+		ContentType: contentType,
+		Size:        int64(len(data)),
+		Backend:     s.blobs.Backend(),
+		CreatedAt:   time.Now(),
+	}
+
+	if err := s.blobs.Put(ctx, media.ID.Hex(), data); err != nil {
+		s.logger.Error("Failed to store media blob", zap.Error(err))
+		return nil, err
+	}
+
+	if err := s.storeVariants(ctx, media, data); err != nil {
+		s.removeBlobs(ctx, media)
+		return nil, err
+	}
+
+	createdMedia, err := s.repo.Create(ctx, media)
+	if err != nil {
+		// Метаданные не сохранились - убираем осиротевшие blob'ы
+		s.removeBlobs(ctx, media)
+		return nil, err
+	}
+
+	s.logger.Info("Media saved successfully", zap.String("id", createdMedia.ID.Hex()), zap.Int64("size", createdMedia.Size))
+	return createdMedia, nil
+}
+
+// StoreInlineImage - заменяет base64 data URI на URL медиафайла.
+// nil, пустая строка и уже сохранённые URL возвращаются без изменений.
+func (s *mediaService) StoreInlineImage(ctx context.Context, img *string) (*string, error) {
+	if img == nil || !utils.IsDataURI(*img) {
+		return img, nil
+	}
+
+	media, err := s.SaveDataURI(ctx, *img)
+	if err != nil {
+		return nil, err
+	}
+
+	url := media.URL()
+	return &url, nil
+}
+
+// GetMediaById - метаданные медиафайла.
+func (s *mediaService) GetMediaById(ctx context.Context, id string) (*model.RowMedia, error) {
+	media, err := s.repo.GetMediaById(ctx, id)
+	if err != nil {
+		s.logger.Error("Failed to fetch media", zap.String("id", id), zap.Error(err))
+		return nil, err
+	}
+	return media, nil
+}
+
+// GetMediaContent - возвращает байты варианта изображения и его Content-Type.
+// Если вариант не был создан (картинка меньше его ширины), отдаётся оригинал.
+func (s *mediaService) GetMediaContent(ctx context.Context, media *model.RowMedia, variant string) (string, []byte, error) {
+	key, contentType := media.ID.Hex(), media.ContentType
+	if v, ok := media.Variant(variant); ok {
+		key, contentType = variantKey(media.ID.Hex(), v.Name), v.ContentType
+	}
+
+	data, err := s.blobs.Get(ctx, key)
+	if err != nil {
+		s.logger.Error("Failed to fetch media blob", zap.String("id", media.ID.Hex()), zap.String("variant", variant), zap.Error(err))
+		return "", nil, err
+	}
+
+	return contentType, data, nil
+}
+
+func (s *mediaService) RemoveMediaById(ctx context.Context, id string) error {
+	media, err := s.repo.GetMediaById(ctx, id)
+	if err != nil {
+		s.logger.Error("Failed to fetch media", zap.String("id", id), zap.Error(err))
+		return err
+	}
+
+	s.removeBlobs(ctx, media)
+
+	if err := s.repo.RemoveMediaById(ctx, id); err != nil {
+		s.logger.Error("Failed to remove media", zap.String("id", id), zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// RemoveImage - удаляет медиафайл, на который ссылается img, когда документ перестал его использовать.
+// nil и URL вне медиахранилища игнорируются; ошибка только логируется, изменение документа уже сохранено.
+func (s *mediaService) RemoveImage(ctx context.Context, img *string) {
+	id, ok := model.MediaIDFromURL(img)
+	if !ok {
+		return
+	}
+
+	if err := s.RemoveMediaById(ctx, id); err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		s.logger.Warn("Failed to remove unused media", zap.String("id", id), zap.Error(err))
+	}
+}
+
+// ThumbnailURL - URL миниатюры для списков.
+func (s *mediaService) ThumbnailURL(img *string) *string {
+	return model.MediaVariantURL(img, imaging.VariantThumb)
+}
+
+// VariantURLs - URL всех настроенных вариантов изображения, включая оригинал.
+func (s *mediaService) VariantURLs(img *string) map[string]string {
+	if img == nil || !strings.HasPrefix(*img, model.MediaURLPrefix) {
+		return nil
+	}
+
+	urls := map[string]string{imaging.VariantFull: *img}
+	for _, v := range s.variants {
+		urls[v.Name] = *model.MediaVariantURL(img, v.Name)
+	}
+	return urls
+}
+
+// storeVariants - генерирует уменьшенные копии изображения и кладёт их в хранилище.
+func (s *mediaService) storeVariants(ctx context.Context, media *model.RowMedia, data []byte) error {
+	cfg, _, err := imaging.DecodeConfig(data)
+	if err != nil {
+		// Не изображение - хранится только оригинал
+		s.logger.Warn("Skipping variants for non-image media", zap.String("id", media.ID.Hex()), zap.Error(err))
+		return nil
+	}
+	media.Width, media.Height = cfg.Width, cfg.Height
+	if len(s.variants) == 0 {
+		return nil
+	}
+
+	// Пиксели декодируются один раз на все варианты
+	img, err := imaging.Decode(data)
+	if err != nil {
+		s.logger.Error("Failed to decode image", zap.String("id", media.ID.Hex()), zap.Error(err))
+		return err
+	}
+
+	for _, v := range s.variants {
+		resized, ok, err := img.Resize(v.MaxWidth)
+		if err != nil {
+			s.logger.Error("Failed to resize image", zap.String("id", media.ID.Hex()), zap.String("variant", v.Name), zap.Error(err))
+			return err
+		}
+		if !ok {
+			continue
+		}
+
+		if err := s.blobs.Put(ctx, variantKey(media.ID.Hex(), v.Name), resized.Data); err != nil {
+			s.logger.Error("Failed to store image variant", zap.String("id", media.ID.Hex()), zap.String("variant", v.Name), zap.Error(err))
+			return err
+		}
+
+		media.Variants = append(media.Variants, model.MediaVariant{
+			Name:        v.Name,
+			ContentType: resized.ContentType,
+			Size:        int64(len(resized.Data)),
+			Width:       resized.Width,
+			Height:      resized.Height,
+		})
+	}
+
+	return nil
+}
+
+// removeBlobs - удаляет оригинал и все варианты, ошибки только логируются.
+func (s *mediaService) removeBlobs(ctx context.Context, media *model.RowMedia) {
+	keys := []string{media.ID.Hex()}
+	for _, v := range media.Variants {
+		keys = append(keys, variantKey(media.ID.Hex(), v.Name))
+	}
+
+	for _, key := range keys {
+		if err := s.blobs.Delete(ctx, key); err != nil {
+			s.logger.Warn("Failed to remove media blob", zap.String("key", key), zap.Error(err))
+		}
+	}
+}
+
+func variantKey(id, variant string) string {
+	return id + "_" + variant
+}
+
+// imageReplaced - изменение заменило или убрало изображение previous.
+func imageReplaced(previous, current *string) bool {
+	return previous != nil && (current == nil || *current != *previous)
+}
@@ -0,0 +1,212 @@
+package service
+
+import (
+	"context"
+	"edjr-trk/internal/model"
+	"edjr-trk/internal/repository"
+	"edjr-trk/pkg/mailtmpl"
+	"edjr-trk/pkg/utils"
+	"errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.uber.org/zap"
+	"net/url"
+	"time"
+)
+
+// passwordResetTokenSize - длина токена сброса пароля в байтах до кодирования.
+const passwordResetTokenSize = 32
+
+var (
+	// ErrInvalidResetToken is returned for an unknown, expired or already used password reset token.
+	ErrInvalidResetToken = errors.New("invalid or expired password reset token")
+	// ErrWrongPassword is returned when the current password does not match on a password change.
+	ErrWrongPassword = errors.New("current password is incorrect")
+)
+
+// PasswordServiceInterface - смена и восстановление пароля.
+type PasswordServiceInterface interface {
+	Forgot(ctx context.Context, email string) error
+	Reset(ctx context.Context, token, newPassword string) error
+	Change(ctx context.Context, userID, oldPassword, newPassword, ip string) error
+}
+
+type passwordService struct {
+	users     repository.UserRepositoryInterface
+	resets    repository.PasswordResetRepositoryInterface
+	auth      AuthServiceInterface
+	guard     *LoginGuard // Текущий пароль подбирается так же, как при входе
+	mailer    EmailQueueInterface
+	templates *mailtmpl.Renderer
+	resetTTL  time.Duration
+	resetURL  string // ссылка на страницу сброса, токен добавляется параметром token
+	logger    *zap.Logger
+}
+
+func NewPasswordService(
+	users repository.UserRepositoryInterface,
+	resets repository.PasswordResetRepositoryInterface,
+	auth AuthServiceInterface,
+	guard *LoginGuard,
+	mailer EmailQueueInterface,
+	templates *mailtmpl.Renderer,
+	resetTTL time.Duration,
+	resetURL string,
+	logger *zap.Logger,
+) PasswordServiceInterface {
+	return &passwordService{
+		users:     users,
+		resets:    resets,
+		auth:      auth,
+		guard:     guard,
+		mailer:    mailer,
+		templates: templates,
+		resetTTL:  resetTTL,
+		resetURL:  resetURL,
+		logger:    logger,
+	}
+}
+
+// Forgot - отправляет письмо с одноразовым токеном сброса. Для неизвестного email ничего
+// не делает и не возвращает ошибку, чтобы по ответу нельзя было проверить адрес.
+func (s *passwordService) Forgot(ctx context.Context, email string) error {
+	user, err := s.users.GetUserByEmail(ctx, email)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		s.logger.Info("Password reset requested for unknown email")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if user.IsDisabled() {
+		s.logger.Info("Password reset requested for a disabled account", zap.String("userId", user.ID.Hex()))
+		return nil
+	}
+
+	token, err := utils.RandomToken(passwordResetTokenSize)
+	if err != nil {
+		s.logger.Error("Failed to generate password reset token", zap.Error(err))
+		return err
+	}
+
+	// Действует только последний запрошенный токен
+	now := time.Now()
+	if err := s.resets.InvalidateUserResets(ctx, user.ID, now); err != nil {
+		return err
+	}
+	err = s.resets.Create(ctx, &model.RowPasswordReset{
+		ID:        primitive.NewObjectID(),
+		UserID:    user.ID,
+		TokenHash: utils.HashToken(token),
+		CreatedAt: now,
+		ExpiresAt: now.Add(s.resetTTL),
+	})
+	if err != nil {
+		return err
+	}
+
+	go s.sendResetEmail(user.Email, token)
+
+	s.logger.Info("Password reset token issued", zap.String("userId", user.ID.Hex()))
+	return nil
+}
+
+// Reset - задаёт новый пароль по токену из письма. Токен срабатывает один раз.
+func (s *passwordService) Reset(ctx context.Context, token, newPassword string) error {
+	now := time.Now()
+	reset, err := s.resets.Consume(ctx, utils.HashToken(token), now)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return ErrInvalidResetToken
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := s.setPassword(ctx, reset.UserID, newPassword); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return ErrInvalidResetToken
+		}
+		return err
+	}
+
+	if err := s.resets.InvalidateUserResets(ctx, reset.UserID, now); err != nil {
+		return err
+	}
+
+	s.logger.Info("Password reset completed", zap.String("userId", reset.UserID.Hex()))
+	return nil
+}
+
+// Change - смена пароля самим пользователем, нужен текущий пароль.
+// Неверный пароль учитывается в тех же счётчиках, что и ошибки входа.
+func (s *passwordService) Change(ctx context.Context, userID, oldPassword, newPassword, ip string) error {
+	user, err := s.users.GetUserById(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.guard.Check(user.Email, ip); err != nil {
+		s.logger.Warn("Password change throttled", zap.String("userId", userID), zap.Error(err))
+		return err
+	}
+
+	matches, err := utils.CompareHashes(oldPassword, user.Password)
+	if err != nil {
+		s.logger.Error("Failed to compare password hashes", zap.Error(err))
+		return err
+	}
+	if !matches {
+		s.logger.Warn("Wrong current password on password change", zap.String("userId", userID), zap.String("ip", ip))
+		s.guard.Fail(user, user.Email, ip)
+		return ErrWrongPassword
+	}
+
+	if err := s.setPassword(ctx, user.ID, newPassword); err != nil {
+		return err
+	}
+
+	s.logger.Info("Password changed", zap.String("userId", userID))
+	return nil
+}
+
+// setPassword - сохраняет новый пароль и завершает все сессии пользователя, включая текущую.
+func (s *passwordService) setPassword(ctx context.Context, userID primitive.ObjectID, password string) error {
+	hash, err := utils.HashData(password, model.PasswordHashCost)
+	if err != nil {
+		s.logger.Error("Failed to hash password", zap.Error(err))
+		return err
+	}
+
+	if err := s.users.UpdatePassword(ctx, userID, hash); err != nil {
+		return err
+	}
+	return s.auth.RevokeSessions(ctx, userID)
+}
+
+func (s *passwordService) sendResetEmail(to, token string) {
+	// Без PASSWORD_RESET_URL в письме только сам токен для POST /api/auth/password/reset
+	var link string
+	if s.resetURL != "" {
+		link = s.resetURL + "?token=" + url.QueryEscape(token)
+	}
+
+	email, err := s.templates.Render("password_reset", s.templates.DefaultLocale(), struct {
+		Token      string
+		Link       string
+		TTLMinutes int
+	}{token, link, int(s.resetTTL.Minutes())})
+	if err != nil {
+		s.logger.Error("Failed to render password reset email", zap.Error(err))
+		return
+	}
+
+	err = s.mailer.Enqueue(context.Background(), &model.RowOutboxMessage{
+		To:      to,
+		Subject: email.Subject,
+		Body:    email.HTML,
+		Text:    email.Text,
+	})
+	if err != nil {
+		s.logger.Error("Failed to send password reset email", zap.String("email", to), zap.Error(err))
+	}
+}
@@ -7,29 +7,43 @@ import (
 	"edjr-trk/internal/repository"
 	"edjr-trk/pkg/utils"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 	"go.uber.org/zap"
 	"time"
 )
 
 type productService struct {
-	repo   repository.ProductRepositoryInterface
-	logger *zap.Logger
+	repo      repository.ProductRepositoryInterface
+	media     MediaServiceInterface
+	revisions RevisionServiceInterface
+	taxonomy  TaxonomyServiceInterface
+	logger    *zap.Logger
 }
 
 type ProductServiceInterface interface {
-	CreateProduct(ctx context.Context, dto dto.CreateProductRequest) (*model.ProductResponse, error)
+	CreateProduct(ctx context.Context, dto dto.CreateProductRequest, authorID string) (*model.ProductResponse, error)
 	RemoveProductById(ctx context.Context, id string) (string, error)
-	PatchProductById(ctx context.Context, dto dto.PatchProductRequest, id string) (*model.ProductResponse, error)
+	RestoreProductById(ctx context.Context, id string) (*model.ProductResponse, error)
+	GetDeletedProducts(ctx context.Context, pageNumber, pageSize int) (*model.Paginate[*model.ProductResponse], error)
+	PatchProductById(ctx context.Context, dto dto.PatchProductRequest, id, authorID string) (*model.ProductResponse, error)
 	GetProductById(ctx context.Context, id string) (*model.ProductResponse, error)
-	GetAllProducts(ctx context.Context, pageNumber, pageSize int) (*model.Paginate[*model.ProductResponse], error)
+	GetProductBySlug(ctx context.Context, slug string) (*model.ProductResponse, error)
+	BackfillSlugs(ctx context.Context) (int, error)
+	GetAllProducts(ctx context.Context, filter model.ProductFilter, pageNumber, pageSize int) (*model.Paginate[*model.ProductResponse], error)
+	SearchProducts(ctx context.Context, filter model.ProductFilter, query string, pageNumber, pageSize int) (*model.Paginate[*model.ProductResponse], error)
+	GetProductsByCursor(ctx context.Context, filter model.ProductFilter, cursor *utils.PageCursor, pageSize int) (*model.CursorPaginate[*model.ProductResponse], error)
+	GetProductRevisions(ctx context.Context, id string, pageNumber, pageSize int) (*model.Paginate[*model.RevisionResponse], error)
+	GetProductRevision(ctx context.Context, id, revisionID string) (*model.RevisionResponse, error)
+	DiffProductRevisions(ctx context.Context, id, fromID, toID string) (*model.RevisionDiff, error)
+	RestoreProductRevision(ctx context.Context, id, revisionID, authorID string) (*model.ProductResponse, error)
 }
 
-func NewProductService(repo repository.ProductRepositoryInterface, logger *zap.Logger) ProductServiceInterface {
-	return &productService{repo: repo, logger: logger}
+func NewProductService(repo repository.ProductRepositoryInterface, media MediaServiceInterface, revisions RevisionServiceInterface, taxonomy TaxonomyServiceInterface, logger *zap.Logger) ProductServiceInterface {
+	return &productService{repo: repo, media: media, revisions: revisions, taxonomy: taxonomy, logger: logger}
 }
 
-func (s *productService) GetAllProducts(ctx context.Context, pageNumber, pageSize int) (*model.Paginate[*model.ProductResponse], error) {
-	products, totalCount, err := s.repo.GetAllProducts(ctx, pageNumber, pageSize)
+func (s *productService) GetAllProducts(ctx context.Context, filter model.ProductFilter, pageNumber, pageSize int) (*model.Paginate[*model.ProductResponse], error) {
+	products, totalCount, err := s.repo.GetAllProducts(ctx, filter, pageNumber, pageSize)
 	if err != nil {
 		s.logger.Error("Failed to fetch all products", zap.Error(err))
 		return nil, err
@@ -38,6 +52,13 @@ func (s *productService) GetAllProducts(ctx context.Context, pageNumber, pageSiz
 	transformedResp := make([]*model.ProductResponse, len(products))
 	for i, product := range products {
 		transformedResp[i] = product.CreateProductResp()
+		// В списках отдаём только миниатюру
+		transformedResp[i].Img = s.media.ThumbnailURL(product.Img)
+	}
+
+	facets, err := s.facets(ctx, filter, "")
+	if err != nil {
+		return nil, err
 	}
 
 	result := &model.Paginate[*model.ProductResponse]{
@@ -46,6 +67,7 @@ func (s *productService) GetAllProducts(ctx context.Context, pageNumber, pageSiz
 		TotalPageCount: utils.CalculateTotalPages(totalCount, pageSize),
 		PageSize:       pageSize,
 		Items:          transformedResp,
+		Facets:         facets,
 	}
 
 	s.logger.Info("All products fetched successfully with pagination",
@@ -58,23 +80,121 @@ func (s *productService) GetAllProducts(ctx context.Context, pageNumber, pageSiz
 	return result, nil
 }
 
-func (s *productService) CreateProduct(ctx context.Context, req dto.CreateProductRequest) (*model.ProductResponse, error) {
+func (s *productService) GetProductsByCursor(ctx context.Context, filter model.ProductFilter, cursor *utils.PageCursor, pageSize int) (*model.CursorPaginate[*model.ProductResponse], error) {
+	products, hasMore, err := s.repo.GetAllProductsByCursor(ctx, filter, cursor, pageSize)
+	if err != nil {
+		s.logger.Error("Failed to fetch products by cursor", zap.Error(err))
+		return nil, err
+	}
+
+	result := newCursorPage(products, hasMore, cursor, pageSize,
+		func(p model.RowProduct) (time.Time, primitive.ObjectID) { return p.Date, p.ID },
+		func(p model.RowProduct) *model.ProductResponse {
+			resp := p.CreateProductResp()
+			resp.Img = s.media.ThumbnailURL(p.Img)
+			return resp
+		},
+	)
+
+	if result.Facets, err = s.facets(ctx, filter, ""); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Products fetched successfully with cursor pagination",
+		zap.Int("pageSize", pageSize),
+		zap.Int("fetchedItems", len(result.Items)),
+	)
+
+	return result, nil
+}
+
+func (s *productService) SearchProducts(ctx context.Context, filter model.ProductFilter, query string, pageNumber, pageSize int) (*model.Paginate[*model.ProductResponse], error) {
+	products, totalCount, err := s.repo.SearchProducts(ctx, filter, query, pageNumber, pageSize)
+	if err != nil {
+		s.logger.Error("Failed to search products", zap.Error(err))
+		return nil, err
+	}
+
+	transformedResp := make([]*model.ProductResponse, len(products))
+	for i, product := range products {
+		transformedResp[i] = product.CreateProductResp()
+		transformedResp[i].Img = s.media.ThumbnailURL(product.Img)
+		transformedResp[i].Snippet = utils.HighlightSnippet(product.ShortText+"\n"+product.Text, query, snippetRadius)
+	}
+
+	facets, err := s.facets(ctx, filter, query)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &model.Paginate[*model.ProductResponse]{
+		PageNumber:     pageNumber,
+		RowTotalCount:  totalCount,
+		TotalPageCount: utils.CalculateTotalPages(totalCount, pageSize),
+		PageSize:       pageSize,
+		Items:          transformedResp,
+		Facets:         facets,
+	}
+
+	s.logger.Info("Products searched successfully",
+		zap.String("query", query),
+		zap.Int("totalCount", totalCount),
+		zap.Int("fetchedItems", len(transformedResp)),
+	)
+
+	return result, nil
+}
+
+func (s *productService) CreateProduct(ctx context.Context, req dto.CreateProductRequest, authorID string) (*model.ProductResponse, error) {
+	id := primitive.NewObjectID()
+	slug, err := s.slugForNewProduct(ctx, req, id)
+	if err != nil {
+		return nil, err
+	}
+
+	tags, categories, err := s.taxonomy.ResolveReferences(ctx, req.Tags, req.Categories)
+	if err != nil {
+		return nil, err
+	}
+
+	img, err := s.media.StoreInlineImage(ctx, req.Img)
+	if err != nil {
+		s.logger.Error("Failed to store product image", zap.Error(err))
+		return nil, err
+	}
+
 	newArticle := model.RowProduct{
-		ID:        primitive.NewObjectID(),
-		Title:     req.Title,
-		Text:      req.Text,
-		ShortText: req.ShortText,
-		Img:       req.Img,
-		Date:      time.Now(),
+		ID:         id,
+		Title:      req.Title,
+		Locale:     contentLocale(req.Locale),
+		Slug:       slug,
+		Tags:       tags,
+		Categories: categories,
+		Text:       req.Text,
+		ShortText:  req.ShortText,
+		Img:        img,
+		Date:       time.Now(),
 	}
 
 	createdArticle, err := s.repo.CreateProduct(ctx, newArticle)
 	if err != nil {
 		s.logger.Error("Failed to save product", zap.Error(err))
+		if req.Img != nil && utils.IsDataURI(*req.Img) {
+			s.media.RemoveImage(ctx, img)
+		}
+		// Slug мог занять параллельный запрос - уникальный индекс не даст дубль
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, ErrSlugTaken
+		}
 		return nil, err
 	}
 
+	if err := s.revisions.Record(ctx, model.RevisionEntityProduct, newArticle.ID, newArticle, model.RevisionActionCreate, authorID); err != nil {
+		s.logger.Error("Failed to record product revision", zap.Error(err))
+	}
+
 	transformedResp := newArticle.CreateProductResp()
+	transformedResp.Images = s.media.VariantURLs(newArticle.Img)
 
 	s.logger.Info("Product created successfully", zap.String("id", createdArticle.ID.Hex()))
 	return transformedResp, nil
@@ -88,17 +208,94 @@ func (s *productService) GetProductById(ctx context.Context, id string) (*model.
 	}
 
 	result := product.CreateProductResp()
+	result.Images = s.media.VariantURLs(product.Img)
 	return result, err
 }
 
-func (s *productService) PatchProductById(ctx context.Context, dto dto.PatchProductRequest, id string) (*model.ProductResponse, error) {
+func (s *productService) PatchProductById(ctx context.Context, dto dto.PatchProductRequest, id, authorID string) (*model.ProductResponse, error) {
+	if dto.Slug != nil {
+		objectID, err := primitive.ObjectIDFromHex(id)
+		if err != nil {
+			return nil, mongo.ErrNoDocuments
+		}
+		if err := ensureSlugFree(ctx, *dto.Slug, objectID, s.repo.IsProductSlugTaken); err != nil {
+			s.logger.Warn("Product slug is not available", zap.String("slug", *dto.Slug), zap.Error(err))
+			return nil, err
+		}
+	}
+
+	if dto.Tags != nil || dto.Categories != nil {
+		if _, _, err := s.taxonomy.ResolveReferences(ctx, derefIDs(dto.Tags), derefIDs(dto.Categories)); err != nil {
+			return nil, err
+		}
+	}
+
+	if dto.Locale != nil {
+		current, err := s.repo.GetProductById(ctx, id)
+		if err != nil {
+			s.logger.Error("Failed to fetch product", zap.Error(err))
+			return nil, err
+		}
+		dto.Locale = translationLocale(dto.Locale, current.Locale)
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, mongo.ErrNoDocuments
+	}
+	// Документ без истории сначала сохраняем как есть, чтобы patch не стёр исходное содержимое
+	if err := s.revisions.EnsureBaseline(ctx, model.RevisionEntityProduct, objectID, func() (interface{}, error) {
+		return s.repo.GetProductById(ctx, id)
+	}); err != nil {
+		s.logger.Error("Failed to record product baseline revision", zap.Error(err))
+		return nil, err
+	}
+
+	// Прежнее изображение удаляется только после успешного изменения
+	var previousImg *string
+	if dto.Img != nil {
+		current, err := s.repo.GetProductById(ctx, id)
+		if err != nil {
+			s.logger.Error("Failed to fetch product", zap.Error(err))
+			return nil, err
+		}
+		previousImg = current.Img
+	}
+
+	stored := dto.Img != nil && utils.IsDataURI(*dto.Img)
+	img, err := s.media.StoreInlineImage(ctx, dto.Img)
+	if err != nil {
+		s.logger.Error("Failed to store product image", zap.Error(err))
+		return nil, err
+	}
+	dto.Img = img
+
 	patchedProduct, err := s.repo.PatchProductById(ctx, &dto, id)
 
 	if err != nil {
 		s.logger.Error("Failed to save product", zap.Error(err))
+		// Изменение не сохранилось - только что загруженное изображение никому не нужно
+		if stored {
+			s.media.RemoveImage(ctx, img)
+		}
+		// Slug мог занять параллельный запрос - уникальный индекс не даст дубль
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, ErrSlugTaken
+		}
 		return nil, err
 	}
+
+	if err := s.revisions.Record(ctx, model.RevisionEntityProduct, patchedProduct.ID, patchedProduct, model.RevisionActionPatch, authorID); err != nil {
+		// Изменение уже сохранено - без ревизии история неполна, но запрос успешен
+		s.logger.Error("Failed to record product revision", zap.Error(err))
+	}
+
+	if imageReplaced(previousImg, patchedProduct.Img) {
+		s.media.RemoveImage(ctx, previousImg)
+	}
+
 	transformedResp := patchedProduct.CreateProductResp()
+	transformedResp.Images = s.media.VariantURLs(patchedProduct.Img)
 	return transformedResp, nil
 }
 
@@ -112,3 +309,167 @@ func (s *productService) RemoveProductById(ctx context.Context, id string) (stri
 
 	return id, nil
 }
+
+func (s *productService) GetProductRevisions(ctx context.Context, id string, pageNumber, pageSize int) (*model.Paginate[*model.RevisionResponse], error) {
+	return s.revisions.GetRevisions(ctx, model.RevisionEntityProduct, id, pageNumber, pageSize)
+}
+
+func (s *productService) GetProductRevision(ctx context.Context, id, revisionID string) (*model.RevisionResponse, error) {
+	revision, err := s.revisions.GetRevision(ctx, model.RevisionEntityProduct, id, revisionID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := revision.CreateRevisionResp()
+	result.Snapshot = revision.Snapshot
+	return result, nil
+}
+
+func (s *productService) DiffProductRevisions(ctx context.Context, id, fromID, toID string) (*model.RevisionDiff, error) {
+	return s.revisions.DiffRevisions(ctx, model.RevisionEntityProduct, id, fromID, toID)
+}
+
+func (s *productService) RestoreProductRevision(ctx context.Context, id, revisionID, authorID string) (*model.ProductResponse, error) {
+	revision, err := s.revisions.GetRevision(ctx, model.RevisionEntityProduct, id, revisionID)
+	if err != nil {
+		s.logger.Error("Failed to fetch product revision", zap.String("revisionId", revisionID), zap.Error(err))
+		return nil, err
+	}
+
+	current, err := s.repo.GetProductById(ctx, id)
+	if err != nil {
+		s.logger.Error("Failed to fetch product", zap.String("id", id), zap.Error(err))
+		return nil, err
+	}
+
+	var product model.RowProduct
+	if err := s.revisions.DecodeSnapshot(revision, &product); err != nil {
+		s.logger.Error("Failed to decode product revision", zap.String("revisionId", revisionID), zap.Error(err))
+		return nil, err
+	}
+	product.ID = revision.EntityID
+	// Адрес проекта не откатываем: старые ссылки продолжают работать через редиректы
+	product.Slug, product.OldSlugs = current.Slug, current.OldSlugs
+	// Термины, удалённые после ревизии, не возвращаем
+	if product.Tags, err = s.taxonomy.KnownTermIDs(ctx, model.TermKindTag, product.Tags); err != nil {
+		return nil, err
+	}
+	if product.Categories, err = s.taxonomy.KnownTermIDs(ctx, model.TermKindCategory, product.Categories); err != nil {
+		return nil, err
+	}
+
+	restored, err := s.repo.ReplaceProduct(ctx, product)
+	if err != nil {
+		s.logger.Error("Failed to restore product", zap.String("id", id), zap.Error(err))
+		return nil, err
+	}
+
+	if err := s.revisions.Record(ctx, model.RevisionEntityProduct, restored.ID, restored, model.RevisionActionRestore, authorID); err != nil {
+		s.logger.Error("Failed to record product revision", zap.Error(err))
+	}
+
+	s.logger.Info("Product restored from revision", zap.String("id", id), zap.Int("version", revision.Version))
+	transformedResp := restored.CreateProductResp()
+	transformedResp.Images = s.media.VariantURLs(restored.Img)
+	return transformedResp, nil
+}
+
+// RestoreProductById - возвращает проект из корзины.
+func (s *productService) RestoreProductById(ctx context.Context, id string) (*model.ProductResponse, error) {
+	if err := s.repo.RestoreProductById(ctx, id); err != nil {
+		s.logger.Error("Failed to restore product", zap.String("id", id), zap.Error(err))
+		return nil, err
+	}
+
+	restored, err := s.repo.GetProductById(ctx, id)
+	if err != nil {
+		s.logger.Error("Failed to fetch restored product", zap.String("id", id), zap.Error(err))
+		return nil, err
+	}
+
+	result := restored.CreateProductResp()
+	result.Images = s.media.VariantURLs(restored.Img)
+	return result, nil
+}
+
+// GetDeletedProducts - содержимое корзины с пагинацией.
+func (s *productService) GetDeletedProducts(ctx context.Context, pageNumber, pageSize int) (*model.Paginate[*model.ProductResponse], error) {
+	items, totalCount, err := s.repo.GetDeletedProducts(ctx, pageNumber, pageSize)
+	if err != nil {
+		s.logger.Error("Failed to fetch deleted products", zap.Error(err))
+		return nil, err
+	}
+
+	transformedResp := make([]*model.ProductResponse, len(items))
+	for i, item := range items {
+		transformedResp[i] = item.CreateProductResp()
+	}
+
+	return &model.Paginate[*model.ProductResponse]{
+		PageNumber:     pageNumber,
+		RowTotalCount:  totalCount,
+		TotalPageCount: utils.CalculateTotalPages(totalCount, pageSize),
+		PageSize:       pageSize,
+		Items:          transformedResp,
+	}, nil
+}
+
+// GetProductBySlug - проект по текущему или прежнему slug.
+func (s *productService) GetProductBySlug(ctx context.Context, slug string) (*model.ProductResponse, error) {
+	product, err := s.repo.GetProductBySlug(ctx, slug)
+	if err != nil {
+		s.logger.Error("Failed to fetch product by slug", zap.String("slug", slug), zap.Error(err))
+		return nil, err
+	}
+
+	result := product.CreateProductResp()
+	result.Images = s.media.VariantURLs(product.Img)
+	return result, nil
+}
+
+// BackfillSlugs - генерирует slug для проектов, созданных до их появления.
+func (s *productService) BackfillSlugs(ctx context.Context) (int, error) {
+	products, err := s.repo.GetProductsWithoutSlug(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	for i, product := range products {
+		slug, err := uniqueSlug(ctx, product.Title, "project", product.ID, s.repo.IsProductSlugTaken)
+		if err != nil {
+			return i, err
+		}
+		if _, err := s.repo.PatchProductById(ctx, &dto.PatchProductRequest{Slug: &slug}, product.ID.Hex()); err != nil {
+			return i, err
+		}
+		s.logger.Info("Product slug generated", zap.String("id", product.ID.Hex()), zap.String("slug", slug))
+	}
+
+	return len(products), nil
+}
+
+func (s *productService) slugForNewProduct(ctx context.Context, req dto.CreateProductRequest, id primitive.ObjectID) (string, error) {
+	if req.Slug == nil {
+		return uniqueSlug(ctx, req.Title, "project", id, s.repo.IsProductSlugTaken)
+	}
+
+	if err := ensureSlugFree(ctx, *req.Slug, id, s.repo.IsProductSlugTaken); err != nil {
+		s.logger.Warn("Product slug is not available", zap.String("slug", *req.Slug), zap.Error(err))
+		return "", err
+	}
+	return *req.Slug, nil
+}
+
+// facets - счётчики тегов и категорий среди всех проектов или найденных по query.
+// Считаются только по запросу (filter.WithFacets), иначе nil.
+func (s *productService) facets(ctx context.Context, filter model.ProductFilter, query string) (*model.TaxonomyFacets, error) {
+	if !filter.WithFacets {
+		return nil, nil
+	}
+
+	usage, err := s.repo.CountProductTerms(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return s.taxonomy.Facets(ctx, usage)
+}
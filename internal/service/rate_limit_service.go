@@ -8,6 +8,9 @@ import (
 	"time"
 )
 
+// rateLimiterMaxKeys - предел числа ключей в памяти лимитера
+const rateLimiterMaxKeys = 100_000
+
 type RateLimiter struct {
 	requests  map[string][]time.Time // Карта для хранения временных меток запросов по IP
 	blocked   map[string]time.Time   // Карта для хранения времени блокировки IP
@@ -35,70 +38,107 @@ func NewRateLimiter(limit int, window, blockTime time.Duration) *RateLimiter {
 
 // Проверка запроса
 func (rl *RateLimiter) ValidateRequest(c *fiber.Ctx) error {
-	clientIP := utils.GetClientIP(c)
+	return rl.Allow(utils.GetClientIP(c))
+}
 
+// Allow - учитывает действие для ключа (IP, email и т.п.) и возвращает ошибку, если лимит исчерпан
+func (rl *RateLimiter) Allow(key string) error {
 	rl.mu.Lock()
 	defer rl.mu.Unlock()
 
 	// Проверка на блокировку
-	if unblockTime, ok := rl.blocked[clientIP]; ok {
+	if unblockTime, ok := rl.blocked[key]; ok {
 		if time.Now().Before(unblockTime) {
-			return fmt.Errorf("IP %s заблокирован до %v", clientIP, unblockTime)
+			return fmt.Errorf("%s заблокирован до %v", key, unblockTime)
 		}
-		delete(rl.blocked, clientIP) // Убираем блокировку, если срок истёк
+		delete(rl.blocked, key) // Убираем блокировку, если срок истёк
 	}
 
 	// Удаляем старые записи из окна
 	now := time.Now()
-	requestTimes := rl.requests[clientIP]
+	requestTimes, known := rl.requests[key]
+	if !known && len(rl.requests)+len(rl.blocked) >= rateLimiterMaxKeys {
+		rl.evict(now)
+	}
 	var filtered []time.Time
 	for _, t := range requestTimes {
 		if now.Sub(t) <= rl.window {
 			filtered = append(filtered, t)
 		}
 	}
-	rl.requests[clientIP] = filtered
+	rl.requests[key] = filtered
 
 	// Проверка количества запросов
 	if len(filtered) >= rl.limit {
-		rl.blocked[clientIP] = now.Add(rl.blockTime)
-		delete(rl.requests, clientIP) // Убираем историю запросов для заблокированного IP
-		return fmt.Errorf("IP %s заблокирован на %v", clientIP, rl.blockTime)
+		rl.blocked[key] = now.Add(rl.blockTime)
+		delete(rl.requests, key) // Убираем историю запросов для заблокированного ключа
+		return fmt.Errorf("%s заблокирован на %v", key, rl.blockTime)
 	}
 
 	// Добавляем текущий запрос
-	rl.requests[clientIP] = append(rl.requests[clientIP], now)
+	rl.requests[key] = append(rl.requests[key], now)
 	return nil
 }
 
+// evict - освобождает место под новый ключ: сначала удаляются устаревшие записи,
+// если их нет - ключ с самым давним последним запросом или самой ранней разблокировкой. Вызывается под rl.mu.
+func (rl *RateLimiter) evict(now time.Time) {
+	rl.prune(now)
+	if len(rl.requests)+len(rl.blocked) < rateLimiterMaxKeys {
+		return
+	}
+
+	var oldestKey string
+	var oldest time.Time
+	for key, times := range rl.requests {
+		if last := times[len(times)-1]; oldestKey == "" || last.Before(oldest) {
+			oldestKey, oldest = key, last
+		}
+	}
+	if oldestKey != "" {
+		delete(rl.requests, oldestKey)
+		return
+	}
+
+	for key, unblockTime := range rl.blocked {
+		if oldestKey == "" || unblockTime.Before(oldest) {
+			oldestKey, oldest = key, unblockTime
+		}
+	}
+	delete(rl.blocked, oldestKey)
+}
+
 // Очистка карты
 func (rl *RateLimiter) cleanup(interval time.Duration) {
 	for {
 		time.Sleep(interval)
 
 		rl.mu.Lock()
-		now := time.Now()
+		rl.prune(time.Now())
+		rl.mu.Unlock()
+	}
+}
 
-		// Удаляем данные о запросах и блокировках
-		for ip, t := range rl.blocked {
-			if now.After(t) {
-				delete(rl.blocked, ip)
-			}
+// prune - удаляет истёкшие блокировки и запросы вне окна. Вызывается под rl.mu.
+func (rl *RateLimiter) prune(now time.Time) {
+	// Удаляем данные о запросах и блокировках
+	for ip, t := range rl.blocked {
+		if now.After(t) {
+			delete(rl.blocked, ip)
 		}
+	}
 
-		for ip, times := range rl.requests {
-			var filtered []time.Time
-			for _, t := range times {
-				if now.Sub(t) <= rl.window {
-					filtered = append(filtered, t)
-				}
-			}
-			if len(filtered) == 0 {
-				delete(rl.requests, ip)
-			} else {
-				rl.requests[ip] = filtered
+	for ip, times := range rl.requests {
+		var filtered []time.Time
+		for _, t := range times {
+			if now.Sub(t) <= rl.window {
+				filtered = append(filtered, t)
 			}
 		}
-		rl.mu.Unlock()
+		if len(filtered) == 0 {
+			delete(rl.requests, ip)
+		} else {
+			rl.requests[ip] = filtered
+		}
 	}
 }
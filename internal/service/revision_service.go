@@ -0,0 +1,181 @@
+package service
+
+import (
+	"context"
+	"edjr-trk/internal/model"
+	"edjr-trk/internal/repository"
+	"edjr-trk/pkg/utils"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.uber.org/zap"
+	"time"
+)
+
+// revisionVersionAttempts - сколько раз пробуем занять следующий номер версии при конкурентной записи.
+const revisionVersionAttempts = 3
+
+// RevisionServiceInterface - история изменений статей и проектов.
+type RevisionServiceInterface interface {
+	Record(ctx context.Context, entityType string, entityID primitive.ObjectID, entity interface{}, action, authorID string) error
+	EnsureBaseline(ctx context.Context, entityType string, entityID primitive.ObjectID, load func() (interface{}, error)) error
+	GetRevisions(ctx context.Context, entityType, entityID string, pageNumber, pageSize int) (*model.Paginate[*model.RevisionResponse], error)
+	GetRevision(ctx context.Context, entityType, entityID, revisionID string) (*model.RowRevision, error)
+	DiffRevisions(ctx context.Context, entityType, entityID, fromID, toID string) (*model.RevisionDiff, error)
+	DecodeSnapshot(revision *model.RowRevision, out interface{}) error
+}
+
+type revisionService struct {
+	repo   repository.RevisionRepositoryInterface
+	logger *zap.Logger
+}
+
+func NewRevisionService(repo repository.RevisionRepositoryInterface, logger *zap.Logger) RevisionServiceInterface {
+	return &revisionService{repo: repo, logger: logger}
+}
+
+// Record - сохраняет снимок сущности как следующую ревизию.
+func (s *revisionService) Record(ctx context.Context, entityType string, entityID primitive.ObjectID, entity interface{}, action, authorID string) error {
+	snapshot, err := toSnapshot(entity)
+	if err != nil {
+		s.logger.Error("Failed to build revision snapshot", zap.Error(err))
+		return err
+	}
+
+	for attempt := 1; ; attempt++ {
+		latest, err := s.repo.GetLatestVersion(ctx, entityType, entityID)
+		if err != nil {
+			return err
+		}
+
+		revision := &model.RowRevision{
+			ID:         primitive.NewObjectID(),
+			EntityType: entityType,
+			EntityID:   entityID,
+			Version:    latest + 1,
+			Action:     action,
+			AuthorID:   authorID,
+			Snapshot:   snapshot,
+			CreatedAt:  time.Now(),
+		}
+
+		_, err = s.repo.Create(ctx, revision)
+		// Версию занял параллельный запрос - берём следующую
+		if mongo.IsDuplicateKeyError(err) && attempt < revisionVersionAttempts {
+			continue
+		}
+		return err
+	}
+}
+
+// EnsureBaseline - если у сущности ещё нет ревизий, сохраняет её текущее состояние (load) как первую.
+// Вызывается до изменения документа, иначе документы, созданные до появления истории,
+// потеряли бы исходное содержимое при первом patch.
+func (s *revisionService) EnsureBaseline(ctx context.Context, entityType string, entityID primitive.ObjectID, load func() (interface{}, error)) error {
+	latest, err := s.repo.GetLatestVersion(ctx, entityType, entityID)
+	if err != nil || latest > 0 {
+		return err
+	}
+
+	entity, err := load()
+	if err != nil {
+		return err
+	}
+	snapshot, err := toSnapshot(entity)
+	if err != nil {
+		s.logger.Error("Failed to build revision snapshot", zap.Error(err))
+		return err
+	}
+
+	_, err = s.repo.Create(ctx, &model.RowRevision{
+		ID:         primitive.NewObjectID(),
+		EntityType: entityType,
+		EntityID:   entityID,
+		Version:    1,
+		Action:     model.RevisionActionBaseline,
+		Snapshot:   snapshot,
+		CreatedAt:  time.Now(),
+	})
+	// Первую ревизию уже записал параллельный запрос
+	if mongo.IsDuplicateKeyError(err) {
+		return nil
+	}
+	return err
+}
+
+func (s *revisionService) GetRevisions(ctx context.Context, entityType, entityID string, pageNumber, pageSize int) (*model.Paginate[*model.RevisionResponse], error) {
+	objectID, err := primitive.ObjectIDFromHex(entityID)
+	if err != nil {
+		return nil, mongo.ErrNoDocuments
+	}
+
+	revisions, totalCount, err := s.repo.GetRevisions(ctx, entityType, objectID, pageNumber, pageSize)
+	if err != nil {
+		s.logger.Error("Failed to fetch revisions", zap.Error(err))
+		return nil, err
+	}
+
+	transformedResp := make([]*model.RevisionResponse, len(revisions))
+	for i, revision := range revisions {
+		transformedResp[i] = revision.CreateRevisionResp()
+	}
+
+	return &model.Paginate[*model.RevisionResponse]{
+		PageNumber:     pageNumber,
+		RowTotalCount:  totalCount,
+		TotalPageCount: utils.CalculateTotalPages(totalCount, pageSize),
+		PageSize:       pageSize,
+		Items:          transformedResp,
+	}, nil
+}
+
+func (s *revisionService) GetRevision(ctx context.Context, entityType, entityID, revisionID string) (*model.RowRevision, error) {
+	objectID, err := primitive.ObjectIDFromHex(entityID)
+	if err != nil {
+		return nil, mongo.ErrNoDocuments
+	}
+
+	return s.repo.GetRevisionById(ctx, entityType, objectID, revisionID)
+}
+
+// DiffRevisions - изменения полей при переходе от ревизии fromID к ревизии toID.
+func (s *revisionService) DiffRevisions(ctx context.Context, entityType, entityID, fromID, toID string) (*model.RevisionDiff, error) {
+	from, err := s.GetRevision(ctx, entityType, entityID, fromID)
+	if err != nil {
+		return nil, err
+	}
+	to, err := s.GetRevision(ctx, entityType, entityID, toID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.RevisionDiff{
+		From:    from.CreateRevisionResp(),
+		To:      to.CreateRevisionResp(),
+		Changes: model.DiffRevisionSnapshots(from.Snapshot, to.Snapshot),
+	}, nil
+}
+
+// DecodeSnapshot - восстанавливает сущность из снимка ревизии.
+func (s *revisionService) DecodeSnapshot(revision *model.RowRevision, out interface{}) error {
+	data, err := bson.Marshal(revision.Snapshot)
+	if err != nil {
+		return err
+	}
+	return bson.Unmarshal(data, out)
+}
+
+// toSnapshot - BSON-представление сущности без _id (он хранится в entityId ревизии).
+func toSnapshot(entity interface{}) (bson.M, error) {
+	data, err := bson.Marshal(entity)
+	if err != nil {
+		return nil, err
+	}
+
+	var snapshot bson.M
+	if err := bson.Unmarshal(data, &snapshot); err != nil {
+		return nil, err
+	}
+	delete(snapshot, "_id")
+	return snapshot, nil
+}
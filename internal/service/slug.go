@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"edjr-trk/pkg/utils"
+	"errors"
+	"fmt"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"strings"
+)
+
+// ErrSlugTaken is returned when a slug set by an admin already belongs to another document.
+var ErrSlugTaken = errors.New("slug is already taken")
+
+// maxSlugSuffix - сколько вариантов "slug-N" перебираем до ошибки.
+const maxSlugSuffix = 1000
+
+// slugChecker - проверка занятости slug в конкретной коллекции.
+type slugChecker func(ctx context.Context, slug string, exceptID primitive.ObjectID) (bool, error)
+
+// uniqueSlug - свободный slug из заголовка: "title", затем "title-2", "title-3"...
+func uniqueSlug(ctx context.Context, title, fallback string, exceptID primitive.ObjectID, taken slugChecker) (string, error) {
+	base := utils.Slugify(title)
+	if base == "" {
+		base = fallback
+	}
+
+	for n := 1; n <= maxSlugSuffix; n++ {
+		candidate := base
+		if n > 1 {
+			suffix := fmt.Sprintf("-%d", n)
+			candidate = strings.TrimRight(truncateSlug(base, utils.MaxSlugLength-len(suffix)), "-") + suffix
+		}
+
+		isTaken, err := taken(ctx, candidate, exceptID)
+		if err != nil {
+			return "", err
+		}
+		if !isTaken {
+			return candidate, nil
+		}
+	}
+
+	return "", ErrSlugTaken
+}
+
+// ensureSlugFree - проверяет slug, заданный вручную.
+func ensureSlugFree(ctx context.Context, slug string, exceptID primitive.ObjectID, taken slugChecker) error {
+	isTaken, err := taken(ctx, slug, exceptID)
+	if err != nil {
+		return err
+	}
+	if isTaken {
+		return ErrSlugTaken
+	}
+	return nil
+}
+
+func truncateSlug(slug string, max int) string {
+	if len(slug) > max {
+		return slug[:max]
+	}
+	return slug
+}
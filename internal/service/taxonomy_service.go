@@ -0,0 +1,351 @@
+package service
+
+import (
+	"context"
+	"edjr-trk/internal/api/dto"
+	"edjr-trk/internal/model"
+	"edjr-trk/internal/repository"
+	"edjr-trk/pkg/utils"
+	"errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.uber.org/zap"
+	"time"
+)
+
+var (
+	// ErrUnknownTerm is returned when an article or project references a tag or category that does not exist.
+	ErrUnknownTerm = errors.New("referenced tag or category does not exist")
+	// ErrInvalidTermParent is returned for a tag with a parent, a missing parent or a parent that would create a cycle.
+	ErrInvalidTermParent = errors.New("invalid parent category")
+	// ErrTermHasChildren is returned when deleting a category that still has subcategories.
+	ErrTermHasChildren = errors.New("category has subcategories")
+)
+
+type taxonomyService struct {
+	repo     repository.TaxonomyRepositoryInterface
+	articles repository.ArticleRepositoryInterface
+	products repository.ProductRepositoryInterface
+	logger   *zap.Logger
+}
+
+// TaxonomyServiceInterface - теги и категории статей и проектов.
+type TaxonomyServiceInterface interface {
+	CreateTerm(ctx context.Context, kind string, req dto.CreateTermRequest) (*model.TermResponse, error)
+	GetTerms(ctx context.Context, kind string) ([]*model.TermResponse, error)
+	PatchTerm(ctx context.Context, kind, id string, req dto.PatchTermRequest) (*model.TermResponse, error)
+	RemoveTerm(ctx context.Context, kind, id string) error
+	ResolveReferences(ctx context.Context, tagIDs, categoryIDs []string) ([]primitive.ObjectID, []primitive.ObjectID, error)
+	KnownTermIDs(ctx context.Context, kind string, ids []primitive.ObjectID) ([]primitive.ObjectID, error)
+	ResolveFilter(ctx context.Context, tagSlugs, categorySlugs []string) (model.TaxonomyFilter, error)
+	Facets(ctx context.Context, usage model.TermUsage) (*model.TaxonomyFacets, error)
+}
+
+func NewTaxonomyService(repo repository.TaxonomyRepositoryInterface, articles repository.ArticleRepositoryInterface, products repository.ProductRepositoryInterface, logger *zap.Logger) TaxonomyServiceInterface {
+	return &taxonomyService{repo: repo, articles: articles, products: products, logger: logger}
+}
+
+func (s *taxonomyService) CreateTerm(ctx context.Context, kind string, req dto.CreateTermRequest) (*model.TermResponse, error) {
+	term := model.RowTerm{
+		ID:        primitive.NewObjectID(),
+		Kind:      kind,
+		Name:      req.Name,
+		CreatedAt: time.Now(),
+	}
+
+	if req.ParentID != nil {
+		parentID, err := s.checkParent(ctx, term, *req.ParentID)
+		if err != nil {
+			return nil, err
+		}
+		term.ParentID = &parentID
+	}
+
+	slug, err := s.slugForNewTerm(ctx, term, req.Slug)
+	if err != nil {
+		s.logger.Warn("Term slug is not available", zap.String("kind", kind), zap.Error(err))
+		return nil, err
+	}
+	term.Slug = slug
+
+	created, err := s.repo.CreateTerm(ctx, term)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, ErrSlugTaken
+		}
+		return nil, err
+	}
+
+	return created.CreateTermResp(), nil
+}
+
+func (s *taxonomyService) GetTerms(ctx context.Context, kind string) ([]*model.TermResponse, error) {
+	terms, err := s.repo.GetTermsByKind(ctx, kind)
+	if err != nil {
+		s.logger.Error("Failed to fetch terms", zap.String("kind", kind), zap.Error(err))
+		return nil, err
+	}
+
+	result := make([]*model.TermResponse, len(terms))
+	for i, term := range terms {
+		result[i] = term.CreateTermResp()
+	}
+	return result, nil
+}
+
+func (s *taxonomyService) PatchTerm(ctx context.Context, kind, id string, req dto.PatchTermRequest) (*model.TermResponse, error) {
+	term, err := s.repo.GetTermById(ctx, kind, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		term.Name = *req.Name
+	}
+
+	if req.Slug != nil && *req.Slug != term.Slug {
+		if err := ensureSlugFree(ctx, *req.Slug, term.ID, s.slugChecker(kind)); err != nil {
+			s.logger.Warn("Term slug is not available", zap.String("slug", *req.Slug), zap.Error(err))
+			return nil, err
+		}
+		term.Slug = *req.Slug
+	}
+
+	if req.ParentID != nil {
+		// Пустая строка переносит категорию в корень
+		term.ParentID = nil
+		if *req.ParentID != "" {
+			parentID, err := s.checkParent(ctx, *term, *req.ParentID)
+			if err != nil {
+				return nil, err
+			}
+			term.ParentID = &parentID
+		}
+	}
+
+	updated, err := s.repo.ReplaceTerm(ctx, *term)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, ErrSlugTaken
+		}
+		return nil, err
+	}
+
+	return updated.CreateTermResp(), nil
+}
+
+// RemoveTerm - удаляет термин и убирает ссылки на него из статей и проектов.
+func (s *taxonomyService) RemoveTerm(ctx context.Context, kind, id string) error {
+	term, err := s.repo.GetTermById(ctx, kind, id)
+	if err != nil {
+		return err
+	}
+
+	if kind == model.TermKindCategory {
+		hasChildren, err := s.repo.HasChildTerms(ctx, term.ID)
+		if err != nil {
+			return err
+		}
+		if hasChildren {
+			return ErrTermHasChildren
+		}
+	}
+
+	if err := s.repo.RemoveTermById(ctx, kind, term.ID); err != nil {
+		return err
+	}
+
+	if _, err := s.articles.RemoveTermReferences(ctx, kind, term.ID); err != nil {
+		return err
+	}
+	if _, err := s.products.RemoveTermReferences(ctx, kind, term.ID); err != nil {
+		return err
+	}
+
+	s.logger.Info("Term removed", zap.String("kind", kind), zap.String("id", id))
+	return nil
+}
+
+// ResolveReferences - проверяет, что теги и категории из запроса существуют; nil остаётся nil.
+func (s *taxonomyService) ResolveReferences(ctx context.Context, tagIDs, categoryIDs []string) ([]primitive.ObjectID, []primitive.ObjectID, error) {
+	tags, err := s.resolveTermIDs(ctx, model.TermKindTag, tagIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	categories, err := s.resolveTermIDs(ctx, model.TermKindCategory, categoryIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return tags, categories, nil
+}
+
+// KnownTermIDs - только существующие термины из списка, порядок сохраняется.
+// Нужен при восстановлении ревизии: термины, удалённые после неё, молча отбрасываются.
+func (s *taxonomyService) KnownTermIDs(ctx context.Context, kind string, ids []primitive.ObjectID) ([]primitive.ObjectID, error) {
+	if len(ids) == 0 {
+		return ids, nil
+	}
+
+	terms, err := s.repo.GetTermsByIds(ctx, kind, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	known := make(map[primitive.ObjectID]bool, len(terms))
+	for _, term := range terms {
+		known[term.ID] = true
+	}
+
+	result := make([]primitive.ObjectID, 0, len(ids))
+	for _, id := range ids {
+		if known[id] {
+			result = append(result, id)
+		}
+	}
+	return result, nil
+}
+
+// ResolveFilter - переводит slug тегов и категорий из запроса в фильтр по id.
+// Категория включает все свои подкатегории; неизвестные slug ничего не находят.
+func (s *taxonomyService) ResolveFilter(ctx context.Context, tagSlugs, categorySlugs []string) (model.TaxonomyFilter, error) {
+	var filter model.TaxonomyFilter
+
+	if tagSlugs != nil {
+		tags, err := s.repo.GetTermsBySlugs(ctx, model.TermKindTag, tagSlugs)
+		if err != nil {
+			return model.TaxonomyFilter{}, err
+		}
+		filter.Tags = make([]primitive.ObjectID, len(tags))
+		for i, tag := range tags {
+			filter.Tags[i] = tag.ID
+		}
+	}
+
+	if categorySlugs != nil {
+		roots, err := s.repo.GetTermsBySlugs(ctx, model.TermKindCategory, categorySlugs)
+		if err != nil {
+			return model.TaxonomyFilter{}, err
+		}
+		rootIDs := make([]primitive.ObjectID, len(roots))
+		for i, root := range roots {
+			rootIDs[i] = root.ID
+		}
+
+		all, err := s.repo.GetTermsByKind(ctx, model.TermKindCategory)
+		if err != nil {
+			return model.TaxonomyFilter{}, err
+		}
+		filter.Categories = model.DescendantCategories(all, rootIDs)
+	}
+
+	return filter, nil
+}
+
+// Facets - все теги и категории с числом элементов, для боковой панели.
+func (s *taxonomyService) Facets(ctx context.Context, usage model.TermUsage) (*model.TaxonomyFacets, error) {
+	tags, err := s.repo.GetTermsByKind(ctx, model.TermKindTag)
+	if err != nil {
+		return nil, err
+	}
+
+	categories, err := s.repo.GetTermsByKind(ctx, model.TermKindCategory)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.TaxonomyFacets{
+		Tags:       termCounts(tags, usage.Tags),
+		Categories: termCounts(categories, usage.Categories),
+	}, nil
+}
+
+func (s *taxonomyService) resolveTermIDs(ctx context.Context, kind string, hexes []string) ([]primitive.ObjectID, error) {
+	if hexes == nil {
+		return nil, nil
+	}
+
+	ids, err := utils.ObjectIDsFromHex(hexes)
+	if err != nil {
+		return nil, ErrUnknownTerm
+	}
+
+	known, err := s.KnownTermIDs(ctx, kind, ids)
+	if err != nil {
+		return nil, err
+	}
+	if len(known) != len(ids) {
+		s.logger.Warn("Unknown term referenced", zap.String("kind", kind), zap.Strings("ids", hexes))
+		return nil, ErrUnknownTerm
+	}
+
+	return ids, nil
+}
+
+// checkParent - родителем может быть только существующая категория, не сама категория и не её потомок.
+func (s *taxonomyService) checkParent(ctx context.Context, term model.RowTerm, parentHex string) (primitive.ObjectID, error) {
+	if term.Kind != model.TermKindCategory {
+		return primitive.NilObjectID, ErrInvalidTermParent
+	}
+
+	parent, err := s.repo.GetTermById(ctx, model.TermKindCategory, parentHex)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return primitive.NilObjectID, ErrInvalidTermParent
+	}
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+
+	all, err := s.repo.GetTermsByKind(ctx, model.TermKindCategory)
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+	for _, id := range model.DescendantCategories(all, []primitive.ObjectID{term.ID}) {
+		if id == parent.ID {
+			return primitive.NilObjectID, ErrInvalidTermParent
+		}
+	}
+
+	return parent.ID, nil
+}
+
+// slugForNewTerm - slug из запроса, если он свободен, иначе сгенерированный из имени.
+func (s *taxonomyService) slugForNewTerm(ctx context.Context, term model.RowTerm, slug *string) (string, error) {
+	if slug == nil {
+		return uniqueSlug(ctx, term.Name, term.Kind, term.ID, s.slugChecker(term.Kind))
+	}
+
+	if err := ensureSlugFree(ctx, *slug, term.ID, s.slugChecker(term.Kind)); err != nil {
+		return "", err
+	}
+	return *slug, nil
+}
+
+func (s *taxonomyService) slugChecker(kind string) slugChecker {
+	return func(ctx context.Context, slug string, exceptID primitive.ObjectID) (bool, error) {
+		return s.repo.IsTermSlugTaken(ctx, kind, slug, exceptID)
+	}
+}
+
+func termCounts(terms []model.RowTerm, counts map[primitive.ObjectID]int) []model.TermCount {
+	result := make([]model.TermCount, len(terms))
+	for i, term := range terms {
+		result[i] = model.TermCount{
+			ID:       term.ID,
+			Name:     term.Name,
+			Slug:     term.Slug,
+			ParentID: term.ParentID,
+			Count:    counts[term.ID],
+		}
+	}
+	return result
+}
+
+// derefIDs - список id из необязательного поля patch-запроса.
+func derefIDs(ids *[]string) []string {
+	if ids == nil {
+		return nil
+	}
+	return *ids
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+	"edjr-trk/internal/model"
+	"edjr-trk/internal/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+	"time"
+)
+
+// TrashPurger - фоновая задача, окончательно удаляющая документы,
+// которые пролежали в корзине дольше срока хранения.
+type TrashPurger struct {
+	articles  repository.ArticleRepositoryInterface
+	products  repository.ProductRepositoryInterface
+	users     repository.UserRepositoryInterface
+	revisions repository.RevisionRepositoryInterface
+	media     MediaServiceInterface // Изображения удалённых документов
+	retention time.Duration         // Срок хранения в корзине
+	interval  time.Duration         // Период запуска очистки
+	logger    *zap.Logger
+}
+
+func NewTrashPurger(articles repository.ArticleRepositoryInterface, products repository.ProductRepositoryInterface, users repository.UserRepositoryInterface, revisions repository.RevisionRepositoryInterface, media MediaServiceInterface, retention, interval time.Duration, logger *zap.Logger) *TrashPurger {
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	return &TrashPurger{
+		articles:  articles,
+		products:  products,
+		users:     users,
+		revisions: revisions,
+		media:     media,
+		retention: retention,
+		interval:  interval,
+		logger:    logger,
+	}
+}
+
+// Run - очищает корзину сразу и затем каждые interval, пока не отменён ctx.
+func (p *TrashPurger) Run(ctx context.Context) {
+	p.logger.Info("Trash purger started",
+		zap.Duration("retention", p.retention),
+		zap.Duration("interval", p.interval),
+	)
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		p.Purge(ctx)
+
+		select {
+		case <-ctx.Done():
+			p.logger.Info("Trash purger stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// purgedDoc - окончательно удалённый документ, после которого нужно убрать связанные данные.
+type purgedDoc struct {
+	id  primitive.ObjectID
+	img *string
+}
+
+// Purge - один проход очистки; ошибка одной коллекции не мешает остальным.
+func (p *TrashPurger) Purge(ctx context.Context) {
+	before := time.Now().Add(-p.retention)
+
+	purges := map[string]func(context.Context, time.Time) ([]purgedDoc, error){
+		"articles": func(ctx context.Context, before time.Time) ([]purgedDoc, error) {
+			articles, err := p.articles.PurgeDeletedArticles(ctx, before)
+			return purgedDocs(articles, err, func(a model.RowArticle) purgedDoc { return purgedDoc{id: a.ID, img: a.Img} })
+		},
+		"products": func(ctx context.Context, before time.Time) ([]purgedDoc, error) {
+			products, err := p.products.PurgeDeletedProducts(ctx, before)
+			return purgedDocs(products, err, func(pr model.RowProduct) purgedDoc { return purgedDoc{id: pr.ID, img: pr.Img} })
+		},
+		"users": func(ctx context.Context, before time.Time) ([]purgedDoc, error) {
+			users, err := p.users.PurgeDeletedUsers(ctx, before)
+			return purgedDocs(users, err, func(u model.RowUser) purgedDoc { return purgedDoc{id: u.ID, img: u.Avatar} })
+		},
+	}
+
+	// Тип сущности в истории изменений; у пользователей истории нет
+	revisionTypes := map[string]string{
+		"articles": model.RevisionEntityArticle,
+		"products": model.RevisionEntityProduct,
+	}
+
+	for name, purge := range purges {
+		docs, err := purge(ctx, before)
+		if err != nil {
+			p.logger.Error("Failed to purge trash", zap.String("collection", name), zap.Error(err))
+		}
+		// Документы, удалённые до ошибки, тоже чистим
+		ids := make([]primitive.ObjectID, len(docs))
+		for i, doc := range docs {
+			ids[i] = doc.id
+			p.media.RemoveImage(ctx, doc.img)
+		}
+
+		if entityType, ok := revisionTypes[name]; ok {
+			if _, err := p.revisions.DeleteRevisions(ctx, entityType, ids); err != nil {
+				p.logger.Error("Failed to purge revisions", zap.String("collection", name), zap.Error(err))
+			}
+		}
+	}
+}
+
+func purgedDocs[T any](items []T, err error, doc func(T) purgedDoc) ([]purgedDoc, error) {
+	docs := make([]purgedDoc, len(items))
+	for i, item := range items {
+		docs[i] = doc(item)
+	}
+	return docs, err
+}
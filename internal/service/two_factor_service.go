@@ -0,0 +1,228 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"edjr-trk/internal/model"
+	"edjr-trk/internal/repository"
+	"edjr-trk/pkg/totp"
+	"edjr-trk/pkg/utils"
+	"errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.uber.org/zap"
+	"strings"
+	"time"
+)
+
+const (
+	// recoveryCodeCount - сколько кодов восстановления выдаётся при включении 2FA.
+	recoveryCodeCount = 10
+	// recoveryCodeLength - длина кода восстановления без дефиса.
+	recoveryCodeLength = 10
+	// recoveryCodeAlphabet - 32 символа без похожих l, o, 0 и 1; 256 делится на 32, поэтому без смещения.
+	recoveryCodeAlphabet = "abcdefghijkmnpqrstuvwxyz23456789"
+	// recoveryCodeCost - сложность bcrypt для кодов восстановления: при проверке хэши перебираются,
+	// поэтому она ниже, чем у паролей.
+	recoveryCodeCost = 10
+)
+
+var (
+	// ErrInvalidTwoFactorCode is returned for a wrong, expired or already used TOTP or recovery code.
+	ErrInvalidTwoFactorCode = errors.New("invalid two-factor code")
+	// ErrTwoFactorNotPending is returned when enabling 2FA without calling setup first.
+	ErrTwoFactorNotPending = errors.New("two-factor setup has not been started")
+	// ErrTwoFactorEnabled is returned when starting setup while 2FA is already on.
+	ErrTwoFactorEnabled = errors.New("two-factor authentication is already enabled")
+	// ErrTwoFactorDisabled is returned when disabling 2FA that is not on.
+	ErrTwoFactorDisabled = errors.New("two-factor authentication is not enabled")
+)
+
+// TwoFactorServiceInterface - подключение TOTP и проверка второго фактора при входе.
+type TwoFactorServiceInterface interface {
+	Setup(ctx context.Context, userID string) (*model.TwoFactorSetupResponse, error)
+	Enable(ctx context.Context, userID, code string) (*model.RecoveryCodesResponse, error)
+	Disable(ctx context.Context, userID, code string) error
+	Verify(ctx context.Context, user *model.RowUser, code string) error
+}
+
+type twoFactorService struct {
+	repo   repository.UserRepositoryInterface
+	issuer string // название сервиса в приложении-аутентификаторе
+	logger *zap.Logger
+}
+
+func NewTwoFactorService(repo repository.UserRepositoryInterface, issuer string, logger *zap.Logger) TwoFactorServiceInterface {
+	return &twoFactorService{repo: repo, issuer: issuer, logger: logger}
+}
+
+// Setup - создаёт новый секрет; 2FA включится только после подтверждения кодом из приложения.
+func (s *twoFactorService) Setup(ctx context.Context, userID string) (*model.TwoFactorSetupResponse, error) {
+	user, err := s.repo.GetUserById(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TwoFactor.IsEnabled() {
+		return nil, ErrTwoFactorEnabled
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		s.logger.Error("Failed to generate TOTP secret", zap.Error(err))
+		return nil, err
+	}
+
+	if err := s.repo.SetTwoFactor(ctx, user.ID, &model.TwoFactor{PendingSecret: secret}); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Two-factor setup started", zap.String("userId", userID))
+	return &model.TwoFactorSetupResponse{
+		Secret:     secret,
+		OtpauthURI: totp.URI(s.issuer, user.Email, secret),
+	}, nil
+}
+
+// Enable - подтверждает секрет первым кодом, включает 2FA и выдаёт коды восстановления.
+func (s *twoFactorService) Enable(ctx context.Context, userID, code string) (*model.RecoveryCodesResponse, error) {
+	user, err := s.repo.GetUserById(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TwoFactor.IsEnabled() {
+		return nil, ErrTwoFactorEnabled
+	}
+	if user.TwoFactor == nil || user.TwoFactor.PendingSecret == "" {
+		return nil, ErrTwoFactorNotPending
+	}
+
+	step, ok := totp.Validate(user.TwoFactor.PendingSecret, code, time.Now())
+	if !ok {
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		s.logger.Error("Failed to generate recovery codes", zap.Error(err))
+		return nil, err
+	}
+
+	err = s.repo.SetTwoFactor(ctx, user.ID, &model.TwoFactor{
+		Enabled:       true,
+		Secret:        user.TwoFactor.PendingSecret,
+		RecoveryCodes: hashes,
+		LastUsedStep:  step,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Two-factor authentication enabled", zap.String("userId", userID))
+	return &model.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// Disable - выключает 2FA; нужен действующий код из приложения или код восстановления.
+func (s *twoFactorService) Disable(ctx context.Context, userID, code string) error {
+	user, err := s.repo.GetUserById(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !user.TwoFactor.IsEnabled() {
+		return ErrTwoFactorDisabled
+	}
+
+	if err := s.Verify(ctx, user, code); err != nil {
+		return err
+	}
+
+	if err := s.repo.SetTwoFactor(ctx, user.ID, nil); err != nil {
+		return err
+	}
+
+	s.logger.Info("Two-factor authentication disabled", zap.String("userId", userID))
+	return nil
+}
+
+// Verify - проверяет TOTP-код или код восстановления. Каждый код принимается только один раз.
+func (s *twoFactorService) Verify(ctx context.Context, user *model.RowUser, code string) error {
+	if !user.TwoFactor.IsEnabled() {
+		return ErrTwoFactorDisabled
+	}
+
+	code = normalizeTwoFactorCode(code)
+	if len(code) == totp.Digits {
+		step, ok := totp.Validate(user.TwoFactor.Secret, code, time.Now())
+		if !ok {
+			return ErrInvalidTwoFactorCode
+		}
+		return s.useStep(ctx, user.ID, step)
+	}
+
+	for _, hash := range user.TwoFactor.RecoveryCodes {
+		matches, err := utils.CompareHashes(code, hash)
+		if err != nil {
+			return err
+		}
+		if !matches {
+			continue
+		}
+
+		used, err := s.repo.UseRecoveryCode(ctx, user.ID, hash)
+		if err != nil {
+			return err
+		}
+		if !used {
+			return ErrInvalidTwoFactorCode
+		}
+		s.logger.Warn("Recovery code used", zap.String("userId", user.ID.Hex()),
+			zap.Int("remaining", len(user.TwoFactor.RecoveryCodes)-1))
+		return nil
+	}
+
+	return ErrInvalidTwoFactorCode
+}
+
+func (s *twoFactorService) useStep(ctx context.Context, userID primitive.ObjectID, step int64) error {
+	fresh, err := s.repo.UseTwoFactorStep(ctx, userID, step)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return ErrInvalidTwoFactorCode
+	}
+	if err != nil {
+		return err
+	}
+	if !fresh {
+		s.logger.Warn("TOTP code reused", zap.String("userId", userID.Hex()))
+		return ErrInvalidTwoFactorCode
+	}
+	return nil
+}
+
+// generateRecoveryCodes - коды для пользователя (вида abcde-fghij) и их bcrypt-хэши для базы.
+func generateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+
+	for i := range codes {
+		raw := make([]byte, recoveryCodeLength)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, nil, err
+		}
+		for j, b := range raw {
+			raw[j] = recoveryCodeAlphabet[int(b)%len(recoveryCodeAlphabet)]
+		}
+		code := string(raw)
+
+		hash, err := utils.HashData(code, recoveryCodeCost)
+		if err != nil {
+			return nil, nil, err
+		}
+		half := recoveryCodeLength / 2
+		codes[i], hashes[i] = code[:half]+"-"+code[half:], hash
+	}
+	return codes, hashes, nil
+}
+
+// normalizeTwoFactorCode - код без пробелов и дефисов, в нижнем регистре.
+func normalizeTwoFactorCode(code string) string {
+	return strings.ToLower(strings.NewReplacer(" ", "", "-", "", "_", "").Replace(code))
+}
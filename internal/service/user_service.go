@@ -6,63 +6,121 @@ import (
 	"edjr-trk/internal/model"
 	"edjr-trk/internal/repository"
 	"edjr-trk/pkg/utils"
+	"errors"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 	"go.uber.org/zap"
 	"time"
 )
 
+var (
+	// ErrOwnerExists is returned by BootstrapOwner once the initial owner has been created.
+	ErrOwnerExists = errors.New("an owner account already exists")
+	// ErrLastOwner is returned when a change would leave no active owner.
+	ErrLastOwner = errors.New("the last owner cannot be removed, demoted or disabled")
+	// ErrEmailTaken is returned when another user already has the email.
+	ErrEmailTaken = errors.New("email is already in use")
+)
+
 type UserServiceInterface interface {
-	CreateNewAdmin(ctx context.Context, dto *dto.CreateUserRequest) (*model.UserResponse, error)
+	CreateUser(ctx context.Context, dto *dto.CreateUserRequest) (*model.UserResponse, error)
+	BootstrapOwner(ctx context.Context, dto *dto.CreateUserRequest) (*model.UserResponse, error)
 	RemoveUserById(ctx context.Context, id string) (string, error)
+	RestoreUserById(ctx context.Context, id string) (*model.UserResponse, error)
+	GetDeletedUsers(ctx context.Context, pageNumber, pageSize int) (*model.Paginate[*model.UserResponse], error)
 	GetAllUsers(ctx context.Context, pageNumber, pageSize int) (*model.Paginate[*model.UserResponse], error)
 	GetUserByEmail(ctx context.Context, email string) (*model.UserResponse, error)
+	GetUserById(ctx context.Context, id string) (*model.UserResponse, error)
+	PatchUserById(ctx context.Context, dto dto.PatchUserRequest, id string) (*model.UserResponse, error)
+	DisableUserById(ctx context.Context, id string) (*model.UserResponse, error)
+	EnableUserById(ctx context.Context, id string) (*model.UserResponse, error)
 }
 
 type userService struct {
 	repo   repository.UserRepositoryInterface
+	media  MediaServiceInterface
+	auth   AuthServiceInterface
 	logger *zap.Logger
 }
 
 // NewUserService - создаёт новый экземпляр UserService.
-func NewUserService(repo repository.UserRepositoryInterface, logger *zap.Logger) UserServiceInterface {
-	return &userService{repo: repo, logger: logger}
+func NewUserService(
+	repo repository.UserRepositoryInterface,
+	media MediaServiceInterface,
+	auth AuthServiceInterface,
+	logger *zap.Logger,
+) UserServiceInterface {
+	return &userService{repo: repo, media: media, auth: auth, logger: logger}
 }
 
-// CreateNewAdmin - creat admin as user-user
-func (s *userService) CreateNewAdmin(ctx context.Context, dto *dto.CreateUserRequest) (*model.UserResponse, error) {
+// CreateUser - создаёт пользователя с ролью из запроса, без роли - только для чтения.
+func (s *userService) CreateUser(ctx context.Context, dto *dto.CreateUserRequest) (*model.UserResponse, error) {
+	role := dto.Role
+	if role == "" {
+		role = model.RoleViewer
+	}
 
-	newAdmin := model.RowUser{
-		ID:        primitive.NewObjectID(),
-		Email:     dto.Email,
-		Phone:     dto.Phone,
-		IsAdmin:   true,
-		Password:  dto.Password,
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+	newUser := model.RowUser{
+		ID:          primitive.NewObjectID(),
+		Email:       dto.Email,
+		Phone:       dto.Phone,
+		DisplayName: dto.DisplayName,
+		Role:        role,
+		Password:    dto.Password,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
 	}
 
-	err := newAdmin.HashPassword()
+	err := newUser.HashPassword()
 	if err != nil {
 		s.logger.Error("Failed to hash password", zap.Error(err))
 		return nil, err
 	}
 
-	createdAdmin, err := s.repo.CreateNewAdmin(ctx, &newAdmin)
+	createdUser, err := s.repo.CreateUser(ctx, &newUser)
 	if err != nil {
-		s.logger.Error("Failed to save admin", zap.Error(err))
+		// Email защищён уникальным индексом, в том числе у пользователей в корзине
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, ErrEmailTaken
+		}
+		s.logger.Error("Failed to save user", zap.Error(err))
 		return nil, err
 	}
 
-	transformedResp := createdAdmin.CreateUserResp()
-	s.logger.Info("Admin created successfully", zap.String("id", transformedResp.ID.Hex()))
+	transformedResp := createdUser.CreateUserResp()
+	s.logger.Info("User created successfully", zap.String("id", transformedResp.ID.Hex()), zap.String("role", role))
 	return transformedResp, nil
 }
 
+// BootstrapOwner - создаёт первого владельца при первом запуске; если владелец уже есть, ничего не делает.
+func (s *userService) BootstrapOwner(ctx context.Context, dto *dto.CreateUserRequest) (*model.UserResponse, error) {
+	owners, err := s.repo.CountOwners(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if owners > 0 {
+		s.logger.Warn("Owner bootstrap skipped, an owner already exists", zap.Int64("owners", owners))
+		return nil, ErrOwnerExists
+	}
+
+	dto.Role = model.RoleOwner
+	return s.CreateUser(ctx, dto)
+}
+
 // RemoveUserById - remove user by id
 func (s *userService) RemoveUserById(ctx context.Context, id string) (string, error) {
-	err := s.repo.RemoveUserById(ctx, id)
-
+	user, err := s.getUser(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	err = s.guardLastOwner(ctx, user,
+		func() error { return s.repo.RemoveUserById(ctx, id) },
+		func() error { return s.repo.RestoreUserById(ctx, id) },
+	)
 	if err != nil {
+		if errors.Is(err, ErrLastOwner) {
+			return "", err
+		}
 		s.logger.Error("Failed to remove user", zap.Error(err))
 		return "", err
 	}
@@ -121,3 +179,227 @@ func (s *userService) GetUserByEmail(ctx context.Context, email string) (*model.
 	result := user.CreateUserResp()
 	return result, nil
 }
+
+// RestoreUserById - возвращает пользователя из корзины.
+func (s *userService) RestoreUserById(ctx context.Context, id string) (*model.UserResponse, error) {
+	if err := s.repo.RestoreUserById(ctx, id); err != nil {
+		s.logger.Error("Failed to restore user", zap.String("id", id), zap.Error(err))
+		return nil, err
+	}
+
+	restored, err := s.repo.GetUserById(ctx, id)
+	if err != nil {
+		s.logger.Error("Failed to fetch restored user", zap.String("id", id), zap.Error(err))
+		return nil, err
+	}
+
+	result := restored.CreateUserResp()
+	return result, nil
+}
+
+// GetDeletedUsers - содержимое корзины с пагинацией.
+func (s *userService) GetDeletedUsers(ctx context.Context, pageNumber, pageSize int) (*model.Paginate[*model.UserResponse], error) {
+	items, totalCount, err := s.repo.GetDeletedUsers(ctx, pageNumber, pageSize)
+	if err != nil {
+		s.logger.Error("Failed to fetch deleted users", zap.Error(err))
+		return nil, err
+	}
+
+	transformedResp := make([]*model.UserResponse, len(items))
+	for i, item := range items {
+		transformedResp[i] = item.CreateUserResp()
+	}
+
+	return &model.Paginate[*model.UserResponse]{
+		PageNumber:     pageNumber,
+		RowTotalCount:  totalCount,
+		TotalPageCount: utils.CalculateTotalPages(totalCount, pageSize),
+		PageSize:       pageSize,
+		Items:          transformedResp,
+	}, nil
+}
+
+// GetUserById - пользователь вне корзины, в том числе отключённый.
+func (s *userService) GetUserById(ctx context.Context, id string) (*model.UserResponse, error) {
+	user, err := s.getUser(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return user.CreateUserResp(), nil
+}
+
+// PatchUserById - изменяет email, телефон, роль, имя и аватар. Последнего владельца понизить нельзя.
+func (s *userService) PatchUserById(ctx context.Context, dto dto.PatchUserRequest, id string) (*model.UserResponse, error) {
+	var demoted *model.RowUser
+	if dto.Role != nil && *dto.Role != model.RoleOwner {
+		current, err := s.getUser(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if current.IsActiveOwner() {
+			// Роль владельца меняется отдельно и под защитой, остальные поля - обычным patch
+			role := *dto.Role
+			err := s.guardLastOwner(ctx, current,
+				func() error { return s.repo.SetRole(ctx, current.ID, role) },
+				func() error { return s.repo.SetRole(ctx, current.ID, current.Role) },
+			)
+			if err != nil {
+				if errors.Is(err, ErrLastOwner) {
+					s.logger.Warn("Refused to demote the last owner", zap.String("id", id))
+				}
+				return nil, err
+			}
+			dto.Role = nil
+			demoted = current
+		}
+	}
+
+	// При ошибке patch возвращаем и роль, чтобы запрос не применился частично
+	restoreRole := func() {
+		if demoted == nil {
+			return
+		}
+		if err := s.repo.SetRole(ctx, demoted.ID, demoted.Role); err != nil {
+			s.logger.Error("Failed to restore owner role", zap.String("id", id), zap.Error(err))
+		}
+	}
+
+	// Прежний аватар удаляется только после успешного изменения
+	var previousAvatar *string
+	if dto.Avatar != nil {
+		current, err := s.getUser(ctx, id)
+		if err != nil {
+			restoreRole()
+			return nil, err
+		}
+		previousAvatar = current.Avatar
+	}
+
+	stored := dto.Avatar != nil && utils.IsDataURI(*dto.Avatar)
+	avatar, err := s.media.StoreInlineImage(ctx, dto.Avatar)
+	if err != nil {
+		s.logger.Error("Failed to store user avatar", zap.Error(err))
+		restoreRole()
+		return nil, err
+	}
+	dto.Avatar = avatar
+
+	patchedUser, err := s.repo.PatchUserById(ctx, &dto, id)
+	if err != nil {
+		restoreRole()
+		if stored {
+			s.media.RemoveImage(ctx, avatar)
+		}
+		// Email защищён уникальным индексом
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, ErrEmailTaken
+		}
+		return nil, err
+	}
+
+	if imageReplaced(previousAvatar, patchedUser.Avatar) {
+		s.media.RemoveImage(ctx, previousAvatar)
+	}
+
+	s.logger.Info("User patched successfully", zap.String("id", id))
+	return patchedUser.CreateUserResp(), nil
+}
+
+// DisableUserById - отключает пользователя и завершает все его сессии. Данные не удаляются.
+func (s *userService) DisableUserById(ctx context.Context, id string) (*model.UserResponse, error) {
+	user, err := s.getUser(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !user.IsDisabled() {
+		now := time.Now()
+		err := s.guardLastOwner(ctx, user,
+			func() error { return s.repo.SetDisabled(ctx, user.ID, &now) },
+			func() error { return s.repo.SetDisabled(ctx, user.ID, nil) },
+		)
+		if err != nil {
+			if errors.Is(err, ErrLastOwner) {
+				s.logger.Warn("Refused to disable the last owner", zap.String("id", id))
+			}
+			return nil, err
+		}
+		// Иначе после включения старые refresh токены снова бы заработали
+		if err := s.auth.RevokeSessions(ctx, user.ID); err != nil {
+			return nil, err
+		}
+		s.logger.Info("User disabled", zap.String("id", id))
+	}
+
+	return s.GetUserById(ctx, id)
+}
+
+// EnableUserById - снова разрешает вход отключённому пользователю.
+func (s *userService) EnableUserById(ctx context.Context, id string) (*model.UserResponse, error) {
+	user, err := s.getUser(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.IsDisabled() {
+		if err := s.repo.SetDisabled(ctx, user.ID, nil); err != nil {
+			return nil, err
+		}
+		s.logger.Info("User enabled", zap.String("id", id))
+	}
+
+	return s.GetUserById(ctx, id)
+}
+
+// getUser - пользователь по id; некорректный id считается ненайденным.
+func (s *userService) getUser(ctx context.Context, id string) (*model.RowUser, error) {
+	if !primitive.IsValidObjectID(id) {
+		return nil, mongo.ErrNoDocuments
+	}
+	return s.repo.GetUserById(ctx, id)
+}
+
+// ensureNotLastOwner - ErrLastOwner, если user единственный действующий владелец.
+func (s *userService) ensureNotLastOwner(ctx context.Context, user *model.RowUser) error {
+	if !user.IsActiveOwner() {
+		return nil
+	}
+
+	owners, err := s.repo.CountOwners(ctx)
+	if err != nil {
+		return err
+	}
+	if owners <= 1 {
+		return ErrLastOwner
+	}
+	return nil
+}
+
+// guardLastOwner - выполняет change и, если user был действующим владельцем, пересчитывает владельцев
+// уже после изменения. Предварительной проверки недостаточно: два параллельных запроса могут
+// понизить двух последних владельцев, каждый видя другого. Пересчёт видит результат обоих,
+// и если владельцев не осталось, изменение откатывается через undo.
+func (s *userService) guardLastOwner(ctx context.Context, user *model.RowUser, change, undo func() error) error {
+	if !user.IsActiveOwner() {
+		return change()
+	}
+	// Быстрый отказ без изменения и отката
+	if err := s.ensureNotLastOwner(ctx, user); err != nil {
+		return err
+	}
+	if err := change(); err != nil {
+		return err
+	}
+
+	owners, err := s.repo.CountOwners(ctx)
+	if err == nil && owners > 0 {
+		return nil
+	}
+	if err == nil {
+		err = ErrLastOwner
+	}
+	if undoErr := undo(); undoErr != nil {
+		s.logger.Error("Failed to roll back owner change", zap.String("id", user.ID.Hex()), zap.Error(undoErr))
+	}
+	return err
+}
@@ -0,0 +1,65 @@
+package imaging
+
+import (
+	"edjr-trk/pkg/utils"
+	"errors"
+	"net/http"
+)
+
+var (
+	// ErrContentMismatch is returned when the payload is not the image type declared in the data URI.
+	ErrContentMismatch = errors.New("image content does not match the declared type")
+	// ErrTooLarge is returned when the decoded payload exceeds Limits.MaxSize.
+	ErrTooLarge = errors.New("image exceeds the maximum size")
+	// ErrTooManyPixels is returned when the image is wider or taller than Limits allow.
+	ErrTooManyPixels = errors.New("image exceeds the maximum dimensions")
+)
+
+// uploadTypes are the image MIME types accepted for upload.
+var uploadTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+// Limits bounds an uploaded image.
+type Limits struct {
+	MaxSize   int // bytes of the decoded payload
+	MaxWidth  int
+	MaxHeight int
+}
+
+// IsUploadType reports whether images of the MIME type may be uploaded.
+func IsUploadType(contentType string) bool {
+	return uploadTypes[contentType]
+}
+
+// CheckDataURI validates a base64 image data URI, decoding the payload only once. The checks run in order
+// and the first failure is returned:
+//   - utils.ErrInvalidDataURI: not a base64 data URI of an upload type;
+//   - ErrContentMismatch: the bytes are not the declared type;
+//   - ErrTooLarge: the payload exceeds limits.MaxSize;
+//   - ErrUnsupportedImage: the image header cannot be decoded;
+//   - ErrTooManyPixels: the image exceeds limits.MaxWidth x limits.MaxHeight.
+func CheckDataURI(uri string, limits Limits) error {
+	declaredType, data, err := utils.ParseDataURI(uri)
+	if err != nil || !IsUploadType(declaredType) {
+		return utils.ErrInvalidDataURI
+	}
+	if http.DetectContentType(data) != declaredType {
+		return ErrContentMismatch
+	}
+	if len(data) > limits.MaxSize {
+		return ErrTooLarge
+	}
+
+	cfg, _, err := DecodeConfig(data)
+	if err != nil {
+		return err
+	}
+	if cfg.Width > limits.MaxWidth || cfg.Height > limits.MaxHeight {
+		return ErrTooManyPixels
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif" // register GIF decoder
+	"image/jpeg"
+	"image/png"
+	"strconv"
+	"strings"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp" // register WebP decoder
+)
+
+const (
+	// VariantFull is the name of the original, unresized image.
+	VariantFull = "full"
+	// VariantThumb is the variant returned by list endpoints.
+	VariantThumb = "thumb"
+
+	jpegQuality = 85
+)
+
+// ErrUnsupportedImage is returned when the payload cannot be decoded as an image.
+var ErrUnsupportedImage = errors.New("unsupported image format")
+
+// Variant describes a resized copy of an uploaded image.
+type Variant struct {
+	Name     string
+	MaxWidth int
+}
+
+// Resized is the result of resizing an image for a single variant.
+type Resized struct {
+	Data        []byte
+	ContentType string
+	Width       int
+	Height      int
+}
+
+// ParseVariants parses a spec like "thumb:320,card:800" into a list of variants.
+func ParseVariants(spec string) ([]Variant, error) {
+	var variants []Variant
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		name, widthStr, found := strings.Cut(part, ":")
+		width, err := strconv.Atoi(widthStr)
+		if !found || name == "" || name == VariantFull || err != nil || width < 1 {
+			return nil, fmt.Errorf("invalid image variant %q", part)
+		}
+
+		variants = append(variants, Variant{Name: name, MaxWidth: width})
+	}
+	return variants, nil
+}
+
+// DecodeConfig returns the dimensions of the image without decoding the pixels.
+func DecodeConfig(data []byte) (image.Config, string, error) {
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return image.Config{}, "", ErrUnsupportedImage
+	}
+	return cfg, format, nil
+}
+
+// Image is a decoded image that can be resized into several variants without decoding it again.
+type Image struct {
+	src    image.Image
+	format string
+}
+
+// Decode decodes the image pixels once; use Image.Resize for each variant.
+func Decode(data []byte) (*Image, error) {
+	src, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedImage
+	}
+	return &Image{src: src, format: format}, nil
+}
+
+// Resize produces a copy of the image scaled down to maxWidth, keeping the aspect ratio.
+// ok is false when the image is already narrow enough and the original should be used.
+func (img *Image) Resize(maxWidth int) (*Resized, bool, error) {
+	src, format := img.src, img.format
+
+	bounds := src.Bounds()
+	if bounds.Dx() <= maxWidth {
+		return nil, false, nil
+	}
+
+	height := bounds.Dy() * maxWidth / bounds.Dx()
+	if height < 1 {
+		height = 1
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, maxWidth, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, bounds, draw.Src, nil)
+
+	var buf bytes.Buffer
+	var err error
+	contentType := "image/jpeg"
+	// PNG и картинки с прозрачностью храним в PNG, остальное - в JPEG
+	if format == "png" || !isOpaque(src) {
+		contentType = "image/png"
+		err = png.Encode(&buf, dst)
+	} else {
+		err = jpeg.Encode(&buf, dst, &jpeg.Options{Quality: jpegQuality})
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	return &Resized{
+		Data:        buf.Bytes(),
+		ContentType: contentType,
+		Width:       maxWidth,
+		Height:      height,
+	}, true, nil
+}
+
+func isOpaque(img image.Image) bool {
+	if o, ok := img.(interface{ Opaque() bool }); ok {
+		return o.Opaque()
+	}
+	return true
+}
@@ -0,0 +1,301 @@
+package jwtkeys
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// minRSABits - минимальная длина RSA ключа для подписи и проверки.
+const minRSABits = 2048
+
+// ErrNoSigningKey - не задан ни PEM ключ подписи, ни HMAC секрет.
+var ErrNoSigningKey = errors.New("no JWT signing key configured")
+
+// Key - ключ, привязанный ровно к одному алгоритму подписи, поэтому токен,
+// подписанный другим алгоритмом, им никогда не проверяется.
+type Key struct {
+	ID     string
+	Method jwt.SigningMethod
+	// Sign - закрытый ключ или HMAC секрет; nil у ключей только для проверки.
+	Sign interface{}
+	// Verify - открытый ключ или HMAC секрет.
+	Verify interface{}
+}
+
+// KeySet - ключ подписи новых токенов и все ключи, которые принимаются при проверке.
+type KeySet struct {
+	signing Key
+	verify  map[string]Key
+}
+
+// Config - откуда берутся ключи.
+type Config struct {
+	// SigningKeyFile - закрытый PEM ключ (RSA или Ed25519) для подписи новых токенов.
+	SigningKeyFile string
+	// SigningKeyID - kid ключа подписи; по умолчанию JWK thumbprint.
+	SigningKeyID string
+	// VerifyKeyFiles - PEM ключи через запятую, которые ещё принимаются при проверке,
+	// например прежний ключ подписи во время ротации.
+	VerifyKeyFiles string
+	// HMACSecret - прежний HS256 секрет. Подписывает токены, только если SigningKeyFile не задан.
+	HMACSecret string
+}
+
+// legacyKeyID - kid HS256 ключа; у токенов, выпущенных до появления kid, его нет, и они проверяются этим ключом.
+const legacyKeyID = "hs256"
+
+// Load - читает с диска ключи, описанные в cfg.
+func Load(cfg Config) (*KeySet, error) {
+	set := &KeySet{verify: map[string]Key{}}
+
+	if cfg.HMACSecret != "" {
+		secret := []byte(cfg.HMACSecret)
+		set.add(Key{ID: legacyKeyID, Method: jwt.SigningMethodHS256, Sign: secret, Verify: secret})
+	}
+
+	if cfg.SigningKeyFile != "" {
+		data, err := os.ReadFile(cfg.SigningKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("read JWT signing key: %w", err)
+		}
+		signer, err := ParsePrivateKeyPEM(data)
+		if err != nil {
+			return nil, fmt.Errorf("parse JWT signing key: %w", err)
+		}
+		key, err := NewKey(cfg.SigningKeyID, signer.Public())
+		if err != nil {
+			return nil, err
+		}
+		key.Sign = signer
+		set.add(key)
+	} else if cfg.HMACSecret == "" {
+		return nil, ErrNoSigningKey
+	}
+
+	for _, path := range strings.Split(cfg.VerifyKeyFiles, ",") {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read JWT verification key: %w", err)
+		}
+		public, err := ParsePublicKeyPEM(data)
+		if err != nil {
+			return nil, fmt.Errorf("parse JWT verification key %s: %w", path, err)
+		}
+		key, err := NewKey("", public)
+		if err != nil {
+			return nil, err
+		}
+		if _, exists := set.verify[key.ID]; !exists {
+			set.verify[key.ID] = key
+		}
+	}
+
+	return set, nil
+}
+
+// NewKey - ключ проверки для открытого RSA или Ed25519 ключа. Пустой id заменяется
+// thumbprint по RFC 7638, поэтому у одного ключа всегда один и тот же kid.
+func NewKey(id string, public crypto.PublicKey) (Key, error) {
+	var method jwt.SigningMethod
+	switch k := public.(type) {
+	case *rsa.PublicKey:
+		if k.N.BitLen() < minRSABits {
+			return Key{}, fmt.Errorf("RSA key must be at least %d bits", minRSABits)
+		}
+		method = jwt.SigningMethodRS256
+	case ed25519.PublicKey:
+		method = jwt.SigningMethodEdDSA
+	default:
+		return Key{}, fmt.Errorf("unsupported key type %T, expected RSA or Ed25519", public)
+	}
+
+	key := Key{ID: id, Method: method, Verify: public}
+	if key.ID == "" {
+		jwk, err := key.JWK()
+		if err != nil {
+			return Key{}, err
+		}
+		key.ID = jwk.Thumbprint()
+	}
+	return key, nil
+}
+
+// add - добавляет ключ для проверки и делает его ключом подписи, если он умеет подписывать.
+// PEM ключ подписи всегда важнее HMAC секрета.
+func (s *KeySet) add(key Key) {
+	s.verify[key.ID] = key
+	if key.Sign != nil && (s.signing.Sign == nil || s.signing.ID == legacyKeyID) {
+		s.signing = key
+	}
+}
+
+// Signing - ключ для новых токенов.
+func (s *KeySet) Signing() Key {
+	return s.signing
+}
+
+// Lookup - ключ проверки по заголовку токена. Алгоритм должен точно совпадать с ключом.
+func (s *KeySet) Lookup(kid, alg string) (Key, error) {
+	if kid == "" {
+		kid = legacyKeyID
+	}
+
+	key, ok := s.verify[kid]
+	if !ok {
+		return Key{}, fmt.Errorf("unknown key id %q", kid)
+	}
+	if key.Method.Alg() != alg {
+		return Key{}, fmt.Errorf("unexpected signing method %q for key %q", alg, kid)
+	}
+	return key, nil
+}
+
+// Methods - алгоритмы всех ключей проверки.
+func (s *KeySet) Methods() []string {
+	seen := map[string]bool{}
+	var methods []string
+	for _, key := range s.verify {
+		if alg := key.Method.Alg(); !seen[alg] {
+			seen[alg] = true
+			methods = append(methods, alg)
+		}
+	}
+	return methods
+}
+
+// JWKS - открытые ключи для других сервисов. HMAC секреты никогда не публикуются.
+func (s *KeySet) JWKS() JWKSet {
+	set := JWKSet{Keys: []JWK{}}
+	for _, key := range s.verify {
+		if jwk, err := key.JWK(); err == nil {
+			set.Keys = append(set.Keys, jwk)
+		}
+	}
+	return set
+}
+
+// JWK - открытый ключ в формате RFC 7517.
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JWKSet - тело ответа JWKS endpoint.
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWK - открытый JWK асимметричного ключа.
+func (k Key) JWK() (JWK, error) {
+	switch public := k.Verify.(type) {
+	case *rsa.PublicKey:
+		return JWK{
+			Kty: "RSA",
+			Kid: k.ID,
+			Use: "sig",
+			Alg: k.Method.Alg(),
+			N:   base64.RawURLEncoding.EncodeToString(public.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes()),
+		}, nil
+	case ed25519.PublicKey:
+		return JWK{
+			Kty: "OKP",
+			Kid: k.ID,
+			Use: "sig",
+			Alg: k.Method.Alg(),
+			Crv: "Ed25519",
+			X:   base64.RawURLEncoding.EncodeToString(public),
+		}, nil
+	}
+	return JWK{}, errors.New("key has no public JWK form")
+}
+
+// Thumbprint - SHA-256 thumbprint ключа по RFC 7638.
+func (j JWK) Thumbprint() string {
+	// Только обязательные поля, в лексикографическом порядке
+	var members interface{}
+	if j.Kty == "RSA" {
+		members = struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{j.E, j.Kty, j.N}
+	} else {
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+		}{j.Crv, j.Kty, j.X}
+	}
+
+	data, _ := json.Marshal(members)
+	sum := sha256.Sum256(data)
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// ParsePrivateKeyPEM - разбирает закрытый ключ PKCS#8 или PKCS#1.
+func ParsePrivateKeyPEM(data []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	if block.Type == "RSA PRIVATE KEY" {
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported private key type %T", key)
+	}
+	return signer, nil
+}
+
+// ParsePublicKeyPEM - разбирает открытый ключ PKIX или PKCS#1. Закрытый ключ тоже принимается,
+// чтобы при ротации можно было указать файл прежнего ключа подписи как есть.
+func ParsePublicKeyPEM(data []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	switch block.Type {
+	case "PUBLIC KEY":
+		return x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+	}
+
+	signer, err := ParsePrivateKeyPEM(data)
+	if err != nil {
+		return nil, err
+	}
+	return signer.Public(), nil
+}
@@ -0,0 +1,179 @@
+package mailtmpl
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	texttemplate "text/template"
+)
+
+// ErrTemplateNotFound is returned when a template exists neither in the requested nor in the default locale.
+var ErrTemplateNotFound = errors.New("email template not found")
+
+// subjectBlock is the block of the text template that holds the subject line.
+const subjectBlock = "subject"
+
+// Email is a rendered message.
+type Email struct {
+	Subject string
+	HTML    string
+	Text    string
+}
+
+// pair is one template in one locale: <name>.html for the HTML part (auto-escaped)
+// and <name>.txt for the plain-text part plus the {{define "subject"}} block.
+type pair struct {
+	html *htmltemplate.Template
+	text *texttemplate.Template
+}
+
+// Renderer renders email templates laid out as <locale>/<name>.html and <locale>/<name>.txt.
+type Renderer struct {
+	templates     map[string]map[string]*pair // locale -> name -> templates
+	defaultLocale string
+}
+
+// New parses every template in defaults. A file with the same path under dir replaces
+// the built-in one, and dir may add new locales or templates. An empty dir disables overrides.
+func New(defaults fs.FS, dir, defaultLocale string) (*Renderer, error) {
+	sources := []fs.FS{defaults}
+	if dir != "" {
+		if _, err := os.Stat(dir); err != nil {
+			return nil, fmt.Errorf("email templates directory: %w", err)
+		}
+		sources = append(sources, os.DirFS(dir))
+	}
+
+	// Later sources win, so files from dir replace the built-in ones
+	files := map[string]map[string][]byte{} // locale -> file name -> content
+	for _, source := range sources {
+		if err := collect(source, files); err != nil {
+			return nil, err
+		}
+	}
+
+	r := &Renderer{templates: map[string]map[string]*pair{}, defaultLocale: defaultLocale}
+	for locale, byFile := range files {
+		for file := range byFile {
+			name, ext := strings.TrimSuffix(file, path.Ext(file)), path.Ext(file)
+			if ext != ".html" {
+				continue
+			}
+			text, ok := byFile[name+".txt"]
+			if !ok {
+				return nil, fmt.Errorf("email template %s/%s has no .txt part", locale, name)
+			}
+
+			p, err := parse(locale+"/"+name, byFile[file], text)
+			if err != nil {
+				return nil, err
+			}
+			if r.templates[locale] == nil {
+				r.templates[locale] = map[string]*pair{}
+			}
+			r.templates[locale][name] = p
+		}
+	}
+
+	if _, ok := r.templates[defaultLocale]; !ok {
+		return nil, fmt.Errorf("no email templates for the default locale %q", defaultLocale)
+	}
+	return r, nil
+}
+
+// collect reads <locale>/<file> entries of source into files.
+func collect(source fs.FS, files map[string]map[string][]byte) error {
+	locales, err := fs.ReadDir(source, ".")
+	if err != nil {
+		return err
+	}
+
+	for _, locale := range locales {
+		if !locale.IsDir() {
+			continue
+		}
+		entries, err := fs.ReadDir(source, locale.Name())
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			ext := filepath.Ext(entry.Name())
+			if entry.IsDir() || (ext != ".html" && ext != ".txt") {
+				continue
+			}
+			content, err := fs.ReadFile(source, locale.Name()+"/"+entry.Name())
+			if err != nil {
+				return err
+			}
+			if files[locale.Name()] == nil {
+				files[locale.Name()] = map[string][]byte{}
+			}
+			files[locale.Name()][entry.Name()] = content
+		}
+	}
+	return nil
+}
+
+func parse(name string, html, text []byte) (*pair, error) {
+	h, err := htmltemplate.New(name).Option("missingkey=error").Parse(string(html))
+	if err != nil {
+		return nil, fmt.Errorf("email template %s.html: %w", name, err)
+	}
+	t, err := texttemplate.New(name).Option("missingkey=error").Parse(string(text))
+	if err != nil {
+		return nil, fmt.Errorf("email template %s.txt: %w", name, err)
+	}
+	if t.Lookup(subjectBlock) == nil {
+		return nil, fmt.Errorf("email template %s.txt has no {{define %q}} block", name, subjectBlock)
+	}
+	return &pair{html: h, text: t}, nil
+}
+
+// Render renders the template in locale, falling back from "ru-RU" to "ru" and then
+// to the default locale.
+func (r *Renderer) Render(name, locale string, data any) (*Email, error) {
+	p := r.lookup(name, locale)
+	if p == nil {
+		return nil, fmt.Errorf("%w: %s", ErrTemplateNotFound, name)
+	}
+
+	var subject, text, html bytes.Buffer
+	if err := p.text.ExecuteTemplate(&subject, subjectBlock, data); err != nil {
+		return nil, err
+	}
+	if err := p.text.Execute(&text, data); err != nil {
+		return nil, err
+	}
+	if err := p.html.Execute(&html, data); err != nil {
+		return nil, err
+	}
+
+	return &Email{
+		Subject: strings.Join(strings.Fields(subject.String()), " "),
+		HTML:    html.String(),
+		Text:    strings.TrimSpace(text.String()) + "\n",
+	}, nil
+}
+
+// DefaultLocale is the locale used when nothing better is available.
+func (r *Renderer) DefaultLocale() string {
+	return r.defaultLocale
+}
+
+func (r *Renderer) lookup(name, locale string) *pair {
+	locale = strings.ToLower(locale)
+	base, _, _ := strings.Cut(locale, "-")
+
+	for _, l := range []string{locale, base, r.defaultLocale} {
+		if p, ok := r.templates[l][name]; ok {
+			return p
+		}
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package utils
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ParseAcceptLanguage - основные языковые подтеги из Accept-Language по убыванию q
+// ("en-US,ru;q=0.8" -> [en ru]). Языки с q=0 и "*" отбрасываются.
+func ParseAcceptLanguage(header string) []string {
+	type weighted struct {
+		lang string
+		q    float64
+	}
+
+	var langs []weighted
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		lang := strings.ToLower(strings.TrimSpace(fields[0]))
+		if i := strings.IndexByte(lang, '-'); i >= 0 {
+			lang = lang[:i]
+		}
+		if lang == "" || lang == "*" {
+			continue
+		}
+
+		q := 1.0
+		for _, param := range fields[1:] {
+			if value, ok := strings.CutPrefix(strings.TrimSpace(param), "q="); ok {
+				if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+					q = parsed
+				}
+			}
+		}
+		if q > 0 {
+			langs = append(langs, weighted{lang, q})
+		}
+	}
+
+	sort.SliceStable(langs, func(i, j int) bool { return langs[i].q > langs[j].q })
+
+	result := make([]string, 0, len(langs))
+	seen := make(map[string]bool, len(langs))
+	for _, l := range langs {
+		if !seen[l.lang] {
+			seen[l.lang] = true
+			result = append(result, l.lang)
+		}
+	}
+	return result
+}
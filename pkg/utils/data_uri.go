@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+)
+
+// ErrInvalidDataURI is returned when a string is not a valid base64 data URI.
+var ErrInvalidDataURI = errors.New("invalid base64 data URI")
+
+// IsDataURI reports whether the string looks like an inline "data:" URI.
+func IsDataURI(str string) bool {
+	return strings.HasPrefix(str, "data:")
+}
+
+// ParseDataURI splits a base64 data URI into its MIME type and decoded payload.
+//
+// Example:
+//
+//	ParseDataURI("data:image/png;base64,iVBORw0...") returns "image/png" and the PNG bytes.
+func ParseDataURI(str string) (string, []byte, error) {
+	if !IsDataURI(str) {
+		return "", nil, ErrInvalidDataURI
+	}
+
+	header, payload, found := strings.Cut(strings.TrimPrefix(str, "data:"), ",")
+	if !found || !strings.HasSuffix(header, ";base64") {
+		return "", nil, ErrInvalidDataURI
+	}
+
+	mimeType := strings.TrimSuffix(header, ";base64")
+	if mimeType == "" {
+		return "", nil, ErrInvalidDataURI
+	}
+
+	data, err := base64.StdEncoding.DecodeString(payload)
+	if err != nil {
+		return "", nil, ErrInvalidDataURI
+	}
+
+	return mimeType, data, nil
+}
@@ -1,16 +1,121 @@
 package utils
 
 import (
+	"errors"
 	"fmt"
-	"github.com/gofiber/fiber/v2"
+	"net"
 	"strings"
+
+	"github.com/gofiber/fiber/v2"
 )
 
-// GetClientIP extracts the client's IP address from the request context.
-// It checks the following headers in order of priority:
-// 1. X-Real-IP: This header is often set by proxies and load balancers to indicate the real IP of the client.
-// 2. X-Forwarded-For: This header contains a list of IPs, where the first IP represents the original client IP.
-// 3. c.IP(): Falls back to the IP from the connection if the above headers are not set.
+// clientIPKey is the fiber.Ctx local holding the IP resolved by ClientIPResolver.Middleware.
+const clientIPKey = "clientIP"
+
+// ErrNoTrustedProxies is returned by NewClientIPResolver when proxy mode is required
+// but no trusted proxy addresses are configured.
+var ErrNoTrustedProxies = errors.New("behind a proxy, but no trusted proxies are configured")
+
+// ClientIPResolver determines the client IP from the connection address and, for requests
+// coming from a trusted proxy, from the forwarding header that proxy sets.
+type ClientIPResolver struct {
+	trusted []*net.IPNet
+	header  string
+}
+
+// NewClientIPResolver creates a resolver.
+//
+// Parameters:
+//   - trustedProxies: IPs or CIDR ranges of the reverse proxies in front of the server.
+//   - header: The forwarding header, e.g. X-Forwarded-For or X-Real-IP.
+//   - behindProxy: Whether the server is expected to run behind a proxy; then an empty
+//     trustedProxies is a configuration error, because every client would get the proxy's IP.
+//
+// Returns:
+//   - The resolver, or an error for an invalid address or a missing proxy list.
+func NewClientIPResolver(trustedProxies []string, header string, behindProxy bool) (*ClientIPResolver, error) {
+	if behindProxy && len(trustedProxies) == 0 {
+		return nil, ErrNoTrustedProxies
+	}
+
+	r := &ClientIPResolver{header: header}
+	for _, proxy := range trustedProxies {
+		if !strings.Contains(proxy, "/") {
+			if ip := net.ParseIP(proxy); ip != nil && ip.To4() != nil {
+				proxy += "/32"
+			} else {
+				proxy += "/128"
+			}
+		}
+		_, network, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", proxy, err)
+		}
+		r.trusted = append(r.trusted, network)
+	}
+	return r, nil
+}
+
+// HasTrustedProxies reports whether any trusted proxy is configured.
+func (r *ClientIPResolver) HasTrustedProxies() bool {
+	return len(r.trusted) > 0
+}
+
+// Resolve returns the client IP for a connection from remote with the given forwarding header value.
+//
+// The header is only read when remote is a trusted proxy. X-Forwarded-For is appended to by every
+// hop, and its leftmost entries come from the client, so it is walked from the right and the first
+// address that is not a trusted proxy is taken. Any other header is expected to hold the single
+// address the proxy has set, e.g. X-Real-IP. Without a usable value the remote address is returned.
+func (r *ClientIPResolver) Resolve(remote net.IP, headerValue string) string {
+	if !r.isTrusted(remote) || headerValue == "" {
+		return remote.String()
+	}
+
+	if !strings.EqualFold(r.header, fiber.HeaderXForwardedFor) {
+		if ip := net.ParseIP(strings.TrimSpace(headerValue)); ip != nil {
+			return ip.String()
+		}
+		return remote.String()
+	}
+
+	hops := strings.Split(headerValue, ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		ip := net.ParseIP(strings.TrimSpace(hops[i]))
+		if ip == nil {
+			// Дальше идут значения, которые никто из доверенных не проверял
+			break
+		}
+		if !r.isTrusted(ip) {
+			return ip.String()
+		}
+	}
+	return remote.String()
+}
+
+// Middleware resolves the client IP once per request for GetClientIP.
+func (r *ClientIPResolver) Middleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.Locals(clientIPKey, r.Resolve(c.Context().RemoteIP(), c.Get(r.header)))
+		return c.Next()
+	}
+}
+
+func (r *ClientIPResolver) isTrusted(ip net.IP) bool {
+	for _, network := range r.trusted {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetClientIP returns the client's IP address for the request.
+//
+// Forwarding headers such as X-Real-IP or X-Forwarded-For are set by the client as easily
+// as by a proxy, so they are never read here directly. The value comes from
+// ClientIPResolver.Middleware, which trusts the header only on connections from a configured
+// proxy. Without the middleware the remote address of the connection is used.
 //
 // Parameters:
 // - c: The *fiber.Ctx object representing the current request context.
@@ -18,14 +123,7 @@ import (
 // Returns:
 // - string: The determined client IP address.
 func GetClientIP(c *fiber.Ctx) string {
-	// Priority: X-Real-IP -> X-Forwarded-For -> c.IP()
-	if realIP := c.Get("X-Real-IP"); realIP != "" {
-		fmt.Println("X-Real-IP:", realIP)
-		return realIP
-	} else if forwardedFor := c.Get("X-Forwarded-For"); forwardedFor != "" {
-
-		ip := strings.Split(forwardedFor, ",")[0]
-		fmt.Println("X-Forwarded-For:", ip)
+	if ip, ok := c.Locals(clientIPKey).(string); ok {
 		return ip
 	}
 	return c.IP()
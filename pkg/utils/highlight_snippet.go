@@ -0,0 +1,135 @@
+package utils
+
+import (
+	"html"
+	"strings"
+	"unicode"
+)
+
+// minStemLength is the shortest prefix of a query term that is still compared to words of the text.
+const minStemLength = 4
+
+// HighlightSnippet cuts a fragment of text around the first word matching any query term
+// and wraps every matching word in <mark></mark>. The text is HTML-escaped before marking.
+//
+// Full-text search stems words (e.g. "проекты" finds "проекта"), so a word matches a term
+// when both start with the term's stem: the term without its last two letters, but never
+// shorter than four letters. Shorter terms must be a prefix of the word.
+//
+// Parameters:
+//   - text: The full text to search in.
+//   - query: The user search query, split into terms on anything but letters and digits.
+//   - radius: The number of runes to keep on each side of the first match.
+//
+// Returns:
+//   - The highlighted snippet, or the beginning of the text if nothing matched.
+func HighlightSnippet(text, query string, radius int) string {
+	runes := []rune(text)
+	lower := toLowerRunes(runes)
+
+	var stems [][]rune
+	for _, term := range strings.FieldsFunc(query, isNotWordRune) {
+		stems = append(stems, termStem(toLowerRunes([]rune(term))))
+	}
+
+	// Слова текста, совпавшие с каким-либо словом запроса
+	var matches [][2]int
+	for _, word := range wordSpans(lower) {
+		for _, stem := range stems {
+			if hasRunePrefix(lower[word[0]:word[1]], stem) {
+				matches = append(matches, word)
+				break
+			}
+		}
+	}
+
+	start, end := 0, len(runes)
+	if len(matches) > 0 {
+		first := matches[0][0]
+		start = max(first-radius, 0)
+		end = min(first+radius, len(runes))
+	} else {
+		end = min(2*radius, len(runes))
+	}
+
+	// Отмечаем все совпавшие слова внутри фрагмента
+	marked := make([]bool, len(runes))
+	for _, word := range matches {
+		for i := max(word[0], start); i < word[1] && i < end; i++ {
+			marked[i] = true
+		}
+	}
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("…")
+	}
+	for i := start; i < end; i++ {
+		if marked[i] && (i == start || !marked[i-1]) {
+			b.WriteString("<mark>")
+		}
+		b.WriteString(html.EscapeString(string(runes[i])))
+		if marked[i] && (i == end-1 || !marked[i+1]) {
+			b.WriteString("</mark>")
+		}
+	}
+	if end < len(runes) {
+		b.WriteString("…")
+	}
+
+	return b.String()
+}
+
+// termStem drops up to two trailing letters (a typical inflection ending) from terms longer than minStemLength.
+func termStem(term []rune) []rune {
+	if len(term) <= minStemLength {
+		return term
+	}
+	return term[:max(len(term)-2, minStemLength)]
+}
+
+// wordSpans returns the [start, end) rune ranges of the words in s.
+func wordSpans(s []rune) [][2]int {
+	var spans [][2]int
+	start := -1
+	for i, r := range s {
+		switch {
+		case !isNotWordRune(r) && start == -1:
+			start = i
+		case isNotWordRune(r) && start != -1:
+			spans = append(spans, [2]int{start, i})
+			start = -1
+		}
+	}
+	if start != -1 {
+		spans = append(spans, [2]int{start, len(s)})
+	}
+	return spans
+}
+
+// isNotWordRune reports whether r separates words.
+func isNotWordRune(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
+
+// hasRunePrefix reports whether s starts with prefix.
+func hasRunePrefix(s, prefix []rune) bool {
+	if len(prefix) == 0 || len(s) < len(prefix) {
+		return false
+	}
+	for i := range prefix {
+		if s[i] != prefix[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// toLowerRunes lowercases rune by rune so that indexes stay aligned with the original text.
+func toLowerRunes(runes []rune) []rune {
+	lower := make([]rune, len(runes))
+	for i, r := range runes {
+		lower[i] = unicode.ToLower(r)
+	}
+	return lower
+}
@@ -0,0 +1,20 @@
+package utils
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+// ObjectIDsFromHex - переводит hex-строки в ObjectID, убирая повторы с сохранением порядка.
+func ObjectIDsFromHex(hexes []string) ([]primitive.ObjectID, error) {
+	ids := make([]primitive.ObjectID, 0, len(hexes))
+	seen := make(map[primitive.ObjectID]bool, len(hexes))
+	for _, hex := range hexes {
+		id, err := primitive.ObjectIDFromHex(hex)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
@@ -0,0 +1,61 @@
+package utils
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+// ErrInvalidPageCursor is returned when a cursor string cannot be decoded.
+var ErrInvalidPageCursor = errors.New("invalid page cursor")
+
+// PageCursor is a keyset position in a list sorted by (date, _id) descending.
+// Backward means the page before the position is requested.
+type PageCursor struct {
+	Date     time.Time
+	ID       primitive.ObjectID
+	Backward bool
+}
+
+// pageCursorPayload - компактное JSON-представление курсора.
+type pageCursorPayload struct {
+	Date     int64  `json:"d"`
+	ID       string `json:"i"`
+	Backward bool   `json:"b,omitempty"`
+}
+
+// EncodePageCursor encodes the cursor into an opaque URL-safe string.
+func EncodePageCursor(cursor PageCursor) string {
+	payload, _ := json.Marshal(pageCursorPayload{
+		Date:     cursor.Date.UnixMilli(),
+		ID:       cursor.ID.Hex(),
+		Backward: cursor.Backward,
+	})
+	return base64.RawURLEncoding.EncodeToString(payload)
+}
+
+// DecodePageCursor decodes a string produced by EncodePageCursor.
+func DecodePageCursor(str string) (*PageCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(str)
+	if err != nil {
+		return nil, ErrInvalidPageCursor
+	}
+
+	var payload pageCursorPayload
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		return nil, ErrInvalidPageCursor
+	}
+
+	id, err := primitive.ObjectIDFromHex(payload.ID)
+	if err != nil {
+		return nil, ErrInvalidPageCursor
+	}
+
+	return &PageCursor{
+		Date:     time.UnixMilli(payload.Date),
+		ID:       id,
+		Backward: payload.Backward,
+	}, nil
+}
@@ -0,0 +1,71 @@
+package utils
+
+import (
+	"golang.org/x/text/unicode/norm"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// MaxSlugLength - ограничение длины slug в символах.
+const MaxSlugLength = 80
+
+// slugPattern - допустимый slug: латиница в нижнем регистре, цифры и одиночные дефисы.
+var slugPattern = regexp.MustCompile(`^[a-z0-9]+(?:-[a-z0-9]+)*$`)
+
+// cyrillicTranslit - транслитерация кириллицы (русский и украинский алфавиты).
+var cyrillicTranslit = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "yo", 'ж': "zh",
+	'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o",
+	'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts",
+	'ч': "ch", 'ш': "sh", 'щ': "shch", 'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu",
+	'я': "ya", 'є': "ye", 'і': "i", 'ї': "yi", 'ґ': "g",
+}
+
+// Slugify - строит slug из заголовка: транслитерирует кириллицу, убирает диакритику,
+// заменяет всё, кроме латиницы и цифр, дефисами. Может вернуть пустую строку.
+func Slugify(title string) string {
+	var b strings.Builder
+	pendingDash := false // нужен дефис перед следующей частью
+
+	for _, r := range strings.ToLower(title) {
+		// Кириллицу транслитерируем до NFD-разложения, иначе й и ё потеряют знаки
+		part, ok := cyrillicTranslit[r]
+		if !ok {
+			part = asciiBase(r)
+		}
+
+		if part == "" {
+			// ъ и ь просто пропадают, остальное становится разделителем
+			pendingDash = pendingDash || (!ok && b.Len() > 0)
+			continue
+		}
+		if pendingDash {
+			b.WriteByte('-')
+			pendingDash = false
+		}
+		b.WriteString(part)
+	}
+
+	slug := b.String()
+	if len(slug) > MaxSlugLength {
+		slug = strings.TrimRight(slug[:MaxSlugLength], "-")
+	}
+	return slug
+}
+
+// asciiBase - латинская буква или цифра без диакритики (é -> e), иначе пустая строка.
+func asciiBase(r rune) string {
+	var b strings.Builder
+	for _, d := range norm.NFD.String(string(r)) {
+		if d < unicode.MaxASCII && (unicode.IsLetter(d) || unicode.IsDigit(d)) {
+			b.WriteRune(d)
+		}
+	}
+	return b.String()
+}
+
+// IsValidSlug - проверяет формат slug, заданного вручную.
+func IsValidSlug(slug string) bool {
+	return len(slug) <= MaxSlugLength && slugPattern.MatchString(slug)
+}
@@ -0,0 +1,115 @@
+package imaging_test
+
+import (
+	"bytes"
+	"edjr-trk/pkg/imaging"
+	"edjr-trk/pkg/utils"
+	"encoding/base64"
+	"github.com/stretchr/testify/assert"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+func TestParseVariants(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		variants, err := imaging.ParseVariants("thumb:320, card:800")
+
+		assert.NoError(t, err)
+		assert.Equal(t, []imaging.Variant{{Name: "thumb", MaxWidth: 320}, {Name: "card", MaxWidth: 800}}, variants)
+	})
+
+	t.Run("Invalid width", func(t *testing.T) {
+		_, err := imaging.ParseVariants("thumb:abc")
+		assert.Error(t, err)
+	})
+
+	t.Run("Reserved name", func(t *testing.T) {
+		_, err := imaging.ParseVariants("full:1024")
+		assert.Error(t, err)
+	})
+}
+
+func TestResize(t *testing.T) {
+	// Подготовка тестового JPEG 640x480
+	src := image.NewRGBA(image.Rect(0, 0, 640, 480))
+	for x := 0; x < 640; x++ {
+		for y := 0; y < 480; y++ {
+			src.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 100, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	assert.NoError(t, jpeg.Encode(&buf, src, nil))
+
+	img, err := imaging.Decode(buf.Bytes())
+	assert.NoError(t, err)
+
+	t.Run("Downscale", func(t *testing.T) {
+		resized, ok, err := img.Resize(320)
+
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, "image/jpeg", resized.ContentType)
+		assert.Equal(t, 320, resized.Width)
+		assert.Equal(t, 240, resized.Height)
+	})
+
+	t.Run("Already small", func(t *testing.T) {
+		resized, ok, err := img.Resize(800)
+
+		assert.NoError(t, err)
+		assert.False(t, ok)
+		assert.Nil(t, resized)
+	})
+
+	t.Run("Decoded once, resized per variant", func(t *testing.T) {
+		img, err := imaging.Decode(buf.Bytes())
+		assert.NoError(t, err)
+
+		thumb, ok, err := img.Resize(160)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, 120, thumb.Height)
+
+		card, ok, err := img.Resize(480)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, 360, card.Height)
+	})
+
+	t.Run("Not an image", func(t *testing.T) {
+		_, err := imaging.Decode([]byte("MZ not an image"))
+		assert.ErrorIs(t, err, imaging.ErrUnsupportedImage)
+	})
+}
+
+func TestCheckDataURI(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 40, 30))))
+	pngURI := "data:image/png;base64," + base64.StdEncoding.EncodeToString(buf.Bytes())
+	limits := imaging.Limits{MaxSize: 1 << 20, MaxWidth: 100, MaxHeight: 100}
+
+	t.Run("Valid image", func(t *testing.T) {
+		assert.NoError(t, imaging.CheckDataURI(pngURI, limits))
+	})
+
+	t.Run("Not a data URI", func(t *testing.T) {
+		assert.ErrorIs(t, imaging.CheckDataURI("https://example.com/a.png", limits), utils.ErrInvalidDataURI)
+		assert.ErrorIs(t, imaging.CheckDataURI("data:text/plain;base64,aGk=", limits), utils.ErrInvalidDataURI)
+	})
+
+	t.Run("Declared type differs from content", func(t *testing.T) {
+		uri := "data:image/jpeg;base64," + base64.StdEncoding.EncodeToString(buf.Bytes())
+		assert.ErrorIs(t, imaging.CheckDataURI(uri, limits), imaging.ErrContentMismatch)
+	})
+
+	t.Run("Too large", func(t *testing.T) {
+		assert.ErrorIs(t, imaging.CheckDataURI(pngURI, imaging.Limits{MaxSize: 10, MaxWidth: 100, MaxHeight: 100}), imaging.ErrTooLarge)
+	})
+
+	t.Run("Too many pixels", func(t *testing.T) {
+		assert.ErrorIs(t, imaging.CheckDataURI(pngURI, imaging.Limits{MaxSize: 1 << 20, MaxWidth: 20, MaxHeight: 100}), imaging.ErrTooManyPixels)
+	})
+}
@@ -0,0 +1,99 @@
+package jwtkeys_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"edjr-trk/pkg/jwtkeys"
+	"encoding/pem"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writePrivateKey(t *testing.T, key interface{}) string {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	assert.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "key.pem")
+	assert.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600))
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+
+	edPath := writePrivateKey(t, edKey)
+	rsaPath := writePrivateKey(t, rsaKey)
+
+	t.Run("No key configured", func(t *testing.T) {
+		_, err := jwtkeys.Load(jwtkeys.Config{})
+		assert.ErrorIs(t, err, jwtkeys.ErrNoSigningKey)
+	})
+
+	t.Run("HMAC secret only", func(t *testing.T) {
+		keys, err := jwtkeys.Load(jwtkeys.Config{HMACSecret: "secret"})
+		assert.NoError(t, err)
+
+		assert.Equal(t, jwt.SigningMethodHS256, keys.Signing().Method)
+		assert.Empty(t, keys.JWKS().Keys, "HMAC secret must never be published")
+
+		// Токены без kid, выпущенные до появления ключей
+		_, err = keys.Lookup("", "HS256")
+		assert.NoError(t, err)
+	})
+
+	t.Run("PEM key signs, previous key still verifies", func(t *testing.T) {
+		keys, err := jwtkeys.Load(jwtkeys.Config{
+			SigningKeyFile: edPath,
+			VerifyKeyFiles: rsaPath,
+			HMACSecret:     "secret",
+		})
+		assert.NoError(t, err)
+
+		signing := keys.Signing()
+		assert.Equal(t, jwt.SigningMethodEdDSA, signing.Method)
+		assert.NotEmpty(t, signing.ID)
+		assert.Len(t, keys.JWKS().Keys, 2)
+
+		token := jwt.NewWithClaims(signing.Method, jwt.MapClaims{"sub": "1"})
+		signed, err := token.SignedString(signing.Sign)
+		assert.NoError(t, err)
+
+		parsed, err := jwt.Parse(signed, func(token *jwt.Token) (interface{}, error) {
+			key, err := keys.Lookup(signing.ID, token.Method.Alg())
+			return key.Verify, err
+		})
+		assert.NoError(t, err)
+		assert.True(t, parsed.Valid)
+	})
+
+	t.Run("Algorithm must match the key", func(t *testing.T) {
+		keys, err := jwtkeys.Load(jwtkeys.Config{SigningKeyFile: rsaPath})
+		assert.NoError(t, err)
+
+		_, err = keys.Lookup(keys.Signing().ID, "HS256")
+		assert.Error(t, err)
+		_, err = keys.Lookup("unknown", "RS256")
+		assert.Error(t, err)
+		assert.Equal(t, []string{"RS256"}, keys.Methods())
+	})
+
+	t.Run("Key id is stable across restarts", func(t *testing.T) {
+		first, err := jwtkeys.Load(jwtkeys.Config{SigningKeyFile: rsaPath})
+		assert.NoError(t, err)
+		second, err := jwtkeys.Load(jwtkeys.Config{SigningKeyFile: rsaPath})
+		assert.NoError(t, err)
+		named, err := jwtkeys.Load(jwtkeys.Config{SigningKeyFile: rsaPath, SigningKeyID: "2026-10"})
+		assert.NoError(t, err)
+
+		assert.Equal(t, first.Signing().ID, second.Signing().ID)
+		assert.Equal(t, "2026-10", named.Signing().ID)
+	})
+}
@@ -0,0 +1,110 @@
+package mailtmpl_test
+
+import (
+	"edjr-trk/configs/mailtemplates"
+	"edjr-trk/pkg/mailtmpl"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+type lead struct {
+	Name, Email, Phone, Text string
+}
+
+var visitor = lead{Name: `<script>alert("x")</script>`, Email: "anna@example.com", Phone: "+100", Text: "Hello & bye"}
+
+func TestRender(t *testing.T) {
+	renderer, err := mailtmpl.New(mailtemplates.FS, "", "en")
+	assert.NoError(t, err)
+
+	t.Run("HTML is escaped, text is not", func(t *testing.T) {
+		email, err := renderer.Render("lead_notification", "en", visitor)
+		assert.NoError(t, err)
+		assert.Equal(t, "Message from your website", email.Subject)
+		assert.NotContains(t, email.HTML, "<script>")
+		assert.Contains(t, email.HTML, "&lt;script&gt;")
+		assert.Contains(t, email.HTML, "Hello &amp; bye")
+		assert.Contains(t, email.Text, `<script>alert("x")</script>`)
+		assert.NotContains(t, email.Text, "subject")
+	})
+
+	t.Run("Region falls back to the language", func(t *testing.T) {
+		email, err := renderer.Render("lead_notification", "ru-RU", visitor)
+		assert.NoError(t, err)
+		assert.Equal(t, "Сообщение с сайта", email.Subject)
+	})
+
+	t.Run("Unknown locale falls back to the default", func(t *testing.T) {
+		email, err := renderer.Render("lead_notification", "de", visitor)
+		assert.NoError(t, err)
+		assert.Equal(t, "Message from your website", email.Subject)
+	})
+
+	t.Run("Unknown template", func(t *testing.T) {
+		_, err := renderer.Render("newsletter", "en", visitor)
+		assert.True(t, errors.Is(err, mailtmpl.ErrTemplateNotFound))
+	})
+
+	t.Run("Built-in templates render in every locale", func(t *testing.T) {
+		data := map[string]any{
+			"lead_notification": visitor,
+			"lead_confirmation": struct{ Name, Reference, Phone, Text string }{"Anna", "261017-K7M2QX", "+100", "Hi"},
+			"account_locked":    struct{ IP, Until string }{"10.0.0.1", "2026-01-01 10:00 UTC"},
+			"password_reset": struct {
+				Token, Link string
+				TTLMinutes  int
+			}{"tok", "https://example.com/reset?token=tok", 60},
+		}
+		for _, locale := range []string{"en", "ru"} {
+			for name, d := range data {
+				email, err := renderer.Render(name, locale, d)
+				assert.NoError(t, err, "%s/%s", locale, name)
+				assert.NotEmpty(t, email.Subject, "%s/%s", locale, name)
+			}
+		}
+	})
+}
+
+func TestOverrides(t *testing.T) {
+	defaults := fstest.MapFS{
+		"en/hello.html": {Data: []byte("<p>Hello {{.}}</p>")},
+		"en/hello.txt":  {Data: []byte(`{{define "subject"}}Hi{{end}}Hello {{.}}`)},
+	}
+
+	dir := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "en"), 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "en", "hello.html"), []byte("<b>Custom {{.}}</b>"), 0o644))
+
+	renderer, err := mailtmpl.New(defaults, dir, "en")
+	assert.NoError(t, err)
+
+	email, err := renderer.Render("hello", "en", "Anna")
+	assert.NoError(t, err)
+	assert.Equal(t, "<b>Custom Anna</b>", email.HTML)
+	assert.Equal(t, "Hello Anna\n", email.Text, "the .txt part is still the built-in one")
+	assert.Equal(t, "Hi", email.Subject)
+}
+
+func TestNewErrors(t *testing.T) {
+	t.Run("Missing text part", func(t *testing.T) {
+		_, err := mailtmpl.New(fstest.MapFS{"en/a.html": {Data: []byte("x")}}, "", "en")
+		assert.Error(t, err)
+	})
+
+	t.Run("Missing subject", func(t *testing.T) {
+		_, err := mailtmpl.New(fstest.MapFS{
+			"en/a.html": {Data: []byte("x")},
+			"en/a.txt":  {Data: []byte("x")},
+		}, "", "en")
+		assert.Error(t, err)
+	})
+
+	t.Run("No default locale", func(t *testing.T) {
+		_, err := mailtmpl.New(mailtemplates.FS, "", "de")
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,33 @@
+package model_test
+
+import (
+	"edjr-trk/internal/model"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"testing"
+)
+
+func TestDescendantCategories(t *testing.T) {
+	root, child, grandchild, other := primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()
+	categories := []model.RowTerm{
+		{ID: root},
+		{ID: child, ParentID: &root},
+		{ID: grandchild, ParentID: &child},
+		{ID: other},
+	}
+
+	t.Run("Includes the whole subtree", func(t *testing.T) {
+		assert.ElementsMatch(t, []primitive.ObjectID{root, child, grandchild}, model.DescendantCategories(categories, []primitive.ObjectID{root}))
+	})
+
+	t.Run("Overlapping roots are returned once", func(t *testing.T) {
+		assert.ElementsMatch(t, []primitive.ObjectID{root, child, grandchild, other},
+			model.DescendantCategories(categories, []primitive.ObjectID{child, root, other}))
+	})
+
+	t.Run("No roots gives an empty, non-nil filter", func(t *testing.T) {
+		result := model.DescendantCategories(categories, nil)
+		assert.NotNil(t, result)
+		assert.Empty(t, result)
+	})
+}
@@ -0,0 +1,45 @@
+package model_test
+
+import (
+	"edjr-trk/internal/model"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestIsValidLeadStatus(t *testing.T) {
+	for _, status := range model.LeadStatuses() {
+		assert.True(t, model.IsValidLeadStatus(status), status)
+	}
+
+	assert.False(t, model.IsValidLeadStatus(""))
+	assert.False(t, model.IsValidLeadStatus("done"))
+}
+
+func TestCreateLeadResp(t *testing.T) {
+	t.Run("notes are never null", func(t *testing.T) {
+		resp := (&model.RowLead{Status: model.LeadStatusNew}).CreateLeadResp()
+		assert.NotNil(t, resp.Notes)
+		assert.Empty(t, resp.Notes)
+		assert.Nil(t, resp.AssigneeID)
+	})
+
+	t.Run("keeps notes in order", func(t *testing.T) {
+		resp := (&model.RowLead{Notes: []model.LeadNote{{Text: "first"}, {Text: "second"}}}).CreateLeadResp()
+		assert.Equal(t, "first", resp.Notes[0].Text)
+		assert.Equal(t, "second", resp.Notes[1].Text)
+	})
+}
+
+func TestNewLeadReference(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+
+	seen := map[string]bool{}
+	for i := 0; i < 1000; i++ {
+		reference, err := model.NewLeadReference(now)
+		assert.NoError(t, err)
+		assert.Regexp(t, `^261017-[2-9A-HJKMNP-Z]{6}$`, reference)
+		seen[reference] = true
+	}
+	assert.Greater(t, len(seen), 990)
+}
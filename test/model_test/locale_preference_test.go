@@ -0,0 +1,35 @@
+package model_test
+
+import (
+	"edjr-trk/internal/model"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestLocalePreference(t *testing.T) {
+	original := model.Translation{Title: "Привет", Text: "Текст статьи"}
+	translations := map[string]model.Translation{"en": {Title: "Hello"}}
+
+	t.Run("Requested translation with missing fields taken from the original", func(t *testing.T) {
+		locale, content := model.NewLocalePreference("en", []string{"ru"}, "ru").Pick(original, "ru", translations)
+		assert.Equal(t, "en", locale)
+		assert.Equal(t, model.Translation{Title: "Hello", Text: "Текст статьи"}, content)
+	})
+
+	t.Run("Missing locale falls back along the chain", func(t *testing.T) {
+		locale, content := model.NewLocalePreference("de", []string{"en", "ru"}, "ru").Pick(original, "ru", translations)
+		assert.Equal(t, "en", locale)
+		assert.Equal(t, "Hello", content.Title)
+	})
+
+	t.Run("Document without a locale is in the default one", func(t *testing.T) {
+		locale, content := model.NewLocalePreference("", []string{"ru"}, "ru").Pick(original, "", nil)
+		assert.Equal(t, "ru", locale)
+		assert.Equal(t, original, content)
+	})
+
+	t.Run("Available lists the original first", func(t *testing.T) {
+		preference := model.NewLocalePreference("", nil, "ru")
+		assert.Equal(t, []string{"ru", "de", "en"}, preference.Available("", map[string]model.Translation{"en": {}, "de": {}}))
+	})
+}
@@ -0,0 +1,28 @@
+package model_test
+
+import (
+	"edjr-trk/internal/model"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestMediaIDFromURL(t *testing.T) {
+	url := func(s string) *string { return &s }
+
+	t.Run("Original and variant URLs give the media ID", func(t *testing.T) {
+		id, ok := model.MediaIDFromURL(url("/api/media/6650a1b2c3d4e5f601234567"))
+		assert.True(t, ok)
+		assert.Equal(t, "6650a1b2c3d4e5f601234567", id)
+
+		id, ok = model.MediaIDFromURL(url("/api/media/6650a1b2c3d4e5f601234567/thumb"))
+		assert.True(t, ok)
+		assert.Equal(t, "6650a1b2c3d4e5f601234567", id)
+	})
+
+	t.Run("Other values are not media", func(t *testing.T) {
+		for _, img := range []*string{nil, url(""), url("/api/media/"), url("https://example.com/a.png"), url("data:image/png;base64,AAAA")} {
+			_, ok := model.MediaIDFromURL(img)
+			assert.False(t, ok)
+		}
+	})
+}
@@ -0,0 +1,37 @@
+package model_test
+
+import (
+	"edjr-trk/internal/model"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestOutboxLeadDelivery(t *testing.T) {
+	sentAt := time.Now()
+
+	t.Run("waiting for a retry stays pending", func(t *testing.T) {
+		delivery := (&model.RowOutboxMessage{Status: model.OutboxPending, Attempts: 2, LastError: "timeout"}).LeadDelivery()
+		assert.Equal(t, model.DeliveryPending, delivery.Status)
+		assert.Equal(t, 2, delivery.Attempts)
+		assert.Equal(t, "timeout", delivery.LastError)
+	})
+
+	t.Run("sent", func(t *testing.T) {
+		delivery := (&model.RowOutboxMessage{Status: model.OutboxSent, Attempts: 1, SentAt: &sentAt}).LeadDelivery()
+		assert.Equal(t, model.DeliverySent, delivery.Status)
+		assert.Equal(t, &sentAt, delivery.SentAt)
+	})
+
+	t.Run("dead letter is a failed delivery", func(t *testing.T) {
+		delivery := (&model.RowOutboxMessage{Status: model.OutboxDead, Attempts: 8}).LeadDelivery()
+		assert.Equal(t, model.DeliveryFailed, delivery.Status)
+	})
+}
+
+func TestIsValidOutboxStatus(t *testing.T) {
+	for _, status := range model.OutboxStatuses() {
+		assert.True(t, model.IsValidOutboxStatus(status), status)
+	}
+	assert.False(t, model.IsValidOutboxStatus("failed"))
+}
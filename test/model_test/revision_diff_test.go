@@ -0,0 +1,29 @@
+package model_test
+
+import (
+	"edjr-trk/internal/model"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"testing"
+)
+
+func TestDiffRevisionSnapshots(t *testing.T) {
+	t.Run("Changed, added and removed fields", func(t *testing.T) {
+		from := bson.M{"title": "Old", "text": "Same", "img": "/api/media/1"}
+		to := bson.M{"title": "New", "text": "Same", "status": "draft"}
+
+		changes := model.DiffRevisionSnapshots(from, to)
+
+		assert.Equal(t, []model.RevisionFieldChange{
+			{Field: "img", From: "/api/media/1", To: nil},
+			{Field: "status", From: nil, To: "draft"},
+			{Field: "title", From: "Old", To: "New"},
+		}, changes)
+	})
+
+	t.Run("Identical snapshots", func(t *testing.T) {
+		snapshot := bson.M{"title": "Same", "img": nil}
+
+		assert.Empty(t, model.DiffRevisionSnapshots(snapshot, snapshot))
+	})
+}
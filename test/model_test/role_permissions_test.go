@@ -0,0 +1,58 @@
+package model_test
+
+import (
+	"edjr-trk/internal/model"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestHasPermission(t *testing.T) {
+	t.Run("owner can manage users", func(t *testing.T) {
+		assert.True(t, model.HasPermission(model.RoleOwner, model.PermUsersManage))
+		assert.False(t, model.HasPermission(model.RoleEditor, model.PermUsersManage))
+	})
+
+	t.Run("only owner sees the outbox", func(t *testing.T) {
+		assert.True(t, model.HasPermission(model.RoleOwner, model.PermOutboxManage))
+		assert.False(t, model.HasPermission(model.RoleEditor, model.PermOutboxManage))
+	})
+
+	t.Run("editor manages leads", func(t *testing.T) {
+		assert.True(t, model.HasPermission(model.RoleEditor, model.PermLeadsManage))
+		assert.False(t, model.HasPermission(model.RoleAuthor, model.PermLeadsManage))
+		assert.False(t, model.HasPermission(model.RoleViewer, model.PermLeadsManage))
+	})
+
+	t.Run("author writes only articles", func(t *testing.T) {
+		assert.True(t, model.HasPermission(model.RoleAuthor, model.PermArticlesWrite))
+		assert.False(t, model.HasPermission(model.RoleAuthor, model.PermArticlesEditAny))
+		assert.False(t, model.HasPermission(model.RoleAuthor, model.PermProjectsWrite))
+		assert.False(t, model.HasPermission(model.RoleAuthor, model.PermTrashManage))
+	})
+
+	t.Run("viewer only reads", func(t *testing.T) {
+		assert.True(t, model.HasPermission(model.RoleViewer, model.PermContentRead))
+		assert.False(t, model.HasPermission(model.RoleViewer, model.PermArticlesWrite))
+	})
+
+	t.Run("unknown or missing role has no permissions", func(t *testing.T) {
+		assert.False(t, model.HasPermission("", model.PermContentRead))
+		assert.False(t, model.HasPermission("admin", model.PermContentRead))
+	})
+}
+
+func TestEffectiveRole(t *testing.T) {
+	t.Run("explicit role", func(t *testing.T) {
+		user := model.RowUser{Role: model.RoleAuthor, IsAdmin: true}
+		assert.Equal(t, model.RoleAuthor, user.EffectiveRole())
+	})
+
+	t.Run("legacy admin becomes owner", func(t *testing.T) {
+		user := model.RowUser{IsAdmin: true}
+		assert.Equal(t, model.RoleOwner, user.EffectiveRole())
+	})
+
+	t.Run("legacy non-admin becomes viewer", func(t *testing.T) {
+		assert.Equal(t, model.RoleViewer, (&model.RowUser{}).EffectiveRole())
+	})
+}
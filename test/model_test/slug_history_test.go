@@ -0,0 +1,21 @@
+package model_test
+
+import (
+	"edjr-trk/internal/model"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestSlugHistory(t *testing.T) {
+	t.Run("Rename keeps the previous slug", func(t *testing.T) {
+		assert.Equal(t, []string{"first", "second"}, model.SlugHistory("second", []string{"first"}, "third"))
+	})
+
+	t.Run("Renaming back drops the slug from history", func(t *testing.T) {
+		assert.Equal(t, []string{"second"}, model.SlugHistory("second", []string{"first"}, "first"))
+	})
+
+	t.Run("Document without a slug", func(t *testing.T) {
+		assert.Empty(t, model.SlugHistory("", nil, "new"))
+	})
+}
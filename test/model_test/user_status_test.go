@@ -0,0 +1,47 @@
+package model_test
+
+import (
+	"edjr-trk/internal/model"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestIsActiveOwner(t *testing.T) {
+	disabledAt := time.Now()
+
+	t.Run("owner", func(t *testing.T) {
+		assert.True(t, (&model.RowUser{Role: model.RoleOwner}).IsActiveOwner())
+	})
+
+	t.Run("legacy admin is an owner", func(t *testing.T) {
+		assert.True(t, (&model.RowUser{IsAdmin: true}).IsActiveOwner())
+	})
+
+	t.Run("disabled owner does not count", func(t *testing.T) {
+		user := &model.RowUser{Role: model.RoleOwner, DisabledAt: &disabledAt}
+		assert.True(t, user.IsDisabled())
+		assert.False(t, user.IsActiveOwner())
+	})
+
+	t.Run("other roles", func(t *testing.T) {
+		assert.False(t, (&model.RowUser{Role: model.RoleEditor}).IsActiveOwner())
+		assert.False(t, (&model.RowUser{}).IsActiveOwner())
+	})
+}
+
+func TestCreateUserResp(t *testing.T) {
+	disabledAt := time.Now()
+	avatar := "/api/media/65a000000000000000000001"
+
+	resp := (&model.RowUser{
+		DisplayName: "Anna",
+		Avatar:      &avatar,
+		DisabledAt:  &disabledAt,
+	}).CreateUserResp()
+
+	assert.Equal(t, "Anna", resp.DisplayName)
+	assert.Equal(t, &avatar, resp.Avatar)
+	assert.True(t, resp.Disabled)
+	assert.Equal(t, model.RoleViewer, resp.Role)
+}
@@ -0,0 +1,267 @@
+package email_transport_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"edjr-trk/internal/repository"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+var message = &repository.EmailMessage{To: "owner@example.com", Subject: "Hello", HTML: "<p>Hi</p>"}
+
+func TestFileEmailRepository(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := repository.NewFileEmailRepository(dir, "Сайт <site@example.com>", zap.NewNop())
+	assert.NoError(t, err)
+
+	assert.NoError(t, repo.SendEmail(context.Background(), &repository.EmailMessage{
+		To:      "owner@example.com",
+		ReplyTo: "Анна <anna@example.com>",
+		Subject: "Сообщение с сайта",
+		HTML:    "<p>Привет</p>",
+		Text:    "Привет",
+	}))
+
+	files, _ := os.ReadDir(filepath.Join(dir, "new"))
+	assert.Len(t, files, 1)
+	tmp, _ := os.ReadDir(filepath.Join(dir, "tmp"))
+	assert.Empty(t, tmp)
+
+	raw, _ := os.ReadFile(filepath.Join(dir, "new", files[0].Name()))
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	assert.NoError(t, err)
+
+	t.Run("Headers are RFC 2047 encoded", func(t *testing.T) {
+		assert.NotContains(t, msg.Header.Get("Subject"), "Сообщение")
+		subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+		assert.NoError(t, err)
+		assert.Equal(t, "Сообщение с сайта", subject)
+
+		from, err := mail.ParseAddress(msg.Header.Get("From"))
+		assert.NoError(t, err)
+		assert.Equal(t, "Сайт", from.Name)
+
+		replyTo, err := mail.ParseAddress(msg.Header.Get("Reply-To"))
+		assert.NoError(t, err)
+		assert.Equal(t, "anna@example.com", replyTo.Address)
+
+		assert.Equal(t, "owner@example.com", strings.Trim(msg.Header.Get("To"), "<>"))
+		assert.Regexp(t, `^<.+@example\.com>$`, msg.Header.Get("Message-ID"))
+		_, err = msg.Header.Date()
+		assert.NoError(t, err)
+	})
+
+	t.Run("Text and HTML alternatives", func(t *testing.T) {
+		mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+		assert.NoError(t, err)
+		assert.Equal(t, "multipart/alternative", mediaType)
+
+		reader := multipart.NewReader(msg.Body, params["boundary"])
+		var types, bodies []string
+		for {
+			part, err := reader.NextPart()
+			if err != nil {
+				break
+			}
+			body, _ := io.ReadAll(part) // quoted-printable декодируется автоматически
+			types = append(types, strings.Split(part.Header.Get("Content-Type"), ";")[0])
+			bodies = append(bodies, string(body))
+		}
+		assert.Equal(t, []string{"text/plain", "text/html"}, types)
+		assert.Equal(t, []string{"Привет", "<p>Привет</p>"}, bodies)
+	})
+}
+
+func TestHeaderInjection(t *testing.T) {
+	dir := t.TempDir()
+	repo, _ := repository.NewFileEmailRepository(dir, "site@example.com", zap.NewNop())
+
+	assert.Error(t, repo.SendEmail(context.Background(), &repository.EmailMessage{To: "a@example.com\r\nBcc: b@example.com", Subject: "x", HTML: "x"}))
+
+	assert.NoError(t, repo.SendEmail(context.Background(), &repository.EmailMessage{To: "a@example.com", Subject: "x\r\nBcc: b@example.com", HTML: "x"}))
+	files, _ := os.ReadDir(filepath.Join(dir, "new"))
+	raw, _ := os.ReadFile(filepath.Join(dir, "new", files[0].Name()))
+	msg, _ := mail.ReadMessage(bytes.NewReader(raw))
+	assert.Empty(t, msg.Header.Get("Bcc"))
+}
+
+func TestHTTPEmailRepository(t *testing.T) {
+	t.Run("Posts JSON with bearer token", func(t *testing.T) {
+		var payload map[string]string
+		var auth string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			auth = r.Header.Get("Authorization")
+			json.NewDecoder(r.Body).Decode(&payload)
+			w.WriteHeader(http.StatusAccepted)
+		}))
+		defer server.Close()
+
+		repo, err := repository.NewHTTPEmailRepository(server.URL, "secret", time.Second, "site@example.com", zap.NewNop())
+		assert.NoError(t, err)
+		assert.NoError(t, repo.SendEmail(context.Background(), message))
+
+		assert.Equal(t, "Bearer secret", auth)
+		assert.Equal(t, "site@example.com", payload["from"])
+		assert.Equal(t, "owner@example.com", payload["to"])
+		assert.Equal(t, "Hello", payload["subject"])
+		assert.Equal(t, "<p>Hi</p>", payload["html"])
+	})
+
+	t.Run("Error status is an error", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "quota exceeded", http.StatusTooManyRequests)
+		}))
+		defer server.Close()
+
+		repo, _ := repository.NewHTTPEmailRepository(server.URL, "", time.Second, "site@example.com", zap.NewNop())
+		err := repo.SendEmail(context.Background(), message)
+		assert.ErrorContains(t, err, "429")
+		assert.ErrorContains(t, err, "quota exceeded")
+	})
+
+	t.Run("Missing sender", func(t *testing.T) {
+		repo, err := repository.NewHTTPEmailRepository("http://localhost/send", "", time.Second, "", zap.NewNop())
+		assert.NoError(t, err)
+		assert.ErrorIs(t, repo.SendEmail(context.Background(), message), repository.ErrMailFromNotSet)
+	})
+}
+
+func TestNewEmailRepository(t *testing.T) {
+	t.Run("Unknown transport", func(t *testing.T) {
+		t.Setenv("MAIL_TRANSPORT", "pigeon")
+		_, err := repository.NewEmailRepository(zap.NewNop())
+		assert.Error(t, err)
+	})
+
+	t.Run("Unknown TLS mode", func(t *testing.T) {
+		t.Setenv("MAIL_TRANSPORT", repository.EmailTransportSMTP)
+		t.Setenv("SMTP_TLS", "ssl3")
+		_, err := repository.NewEmailRepository(zap.NewNop())
+		assert.Error(t, err)
+	})
+
+	t.Run("Invalid sender", func(t *testing.T) {
+		t.Setenv("MAIL_TRANSPORT", repository.EmailTransportSMTP)
+		t.Setenv("MAIL_FROM", "not an address")
+		_, err := repository.NewEmailRepository(zap.NewNop())
+		assert.Error(t, err)
+	})
+}
+
+func TestSMTPEmailRepository(t *testing.T) {
+	server := newFakeSMTPServer(t)
+
+	repo, err := repository.NewSMTPEmailRepository(repository.SMTPConfig{
+		Host:        "127.0.0.1",
+		Port:        server.port,
+		TLSMode:     repository.SMTPNoTLS,
+		Timeout:     time.Second,
+		IdleTimeout: time.Minute,
+		MaxIdle:     1,
+	}, "Site <site@example.com>", zap.NewNop())
+	assert.NoError(t, err)
+
+	assert.NoError(t, repo.SendEmail(context.Background(), message))
+	assert.NoError(t, repo.SendEmail(context.Background(), message))
+
+	assert.Equal(t, 1, server.connections(), "the connection is reused")
+	assert.Equal(t, 2, len(server.messages()))
+	assert.Contains(t, server.messages()[0], "Subject: Hello")
+}
+
+// fakeSMTPServer - минимальный SMTP сервер без TLS и авторизации.
+type fakeSMTPServer struct {
+	port  string
+	mu    sync.Mutex
+	conns int
+	mails []string
+}
+
+func newFakeSMTPServer(t *testing.T) *fakeSMTPServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	_, port, _ := net.SplitHostPort(listener.Addr().String())
+	s := &fakeSMTPServer{port: port}
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			s.mu.Lock()
+			s.conns++
+			s.mu.Unlock()
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *fakeSMTPServer) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+
+	reply("220 fake ESMTP")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		cmd := strings.ToUpper(strings.TrimSpace(line))
+		switch {
+		case strings.HasPrefix(cmd, "EHLO"):
+			reply("250 fake")
+		case strings.HasPrefix(cmd, "DATA"):
+			reply("354 go ahead")
+			var data strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil || l == ".\r\n" {
+					break
+				}
+				data.WriteString(l)
+			}
+			s.mu.Lock()
+			s.mails = append(s.mails, data.String())
+			s.mu.Unlock()
+			reply("250 queued")
+		case strings.HasPrefix(cmd, "QUIT"):
+			reply("221 bye")
+			return
+		default:
+			reply("250 ok")
+		}
+	}
+}
+
+func (s *fakeSMTPServer) connections() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.conns
+}
+
+func (s *fakeSMTPServer) messages() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.mails...)
+}
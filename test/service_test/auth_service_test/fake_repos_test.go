@@ -0,0 +1,236 @@
+package auth_service_test
+
+import (
+	"context"
+	"edjr-trk/internal/model"
+	"edjr-trk/internal/repository"
+	"edjr-trk/internal/service"
+	"edjr-trk/pkg/jwtkeys"
+	"edjr-trk/pkg/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.uber.org/zap"
+	"sync"
+	"testing"
+	"time"
+)
+
+const testPassword = "correct-password"
+
+// fakeUserRepo - пользователи в памяти; методы, которые authService и passwordService не вызывают, не реализованы.
+type fakeUserRepo struct {
+	repository.UserRepositoryInterface
+	mu    sync.Mutex
+	users map[primitive.ObjectID]*model.RowUser
+}
+
+func (r *fakeUserRepo) find(match func(*model.RowUser) bool) (*model.RowUser, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, user := range r.users {
+		if match(user) {
+			copied := *user
+			return &copied, nil
+		}
+	}
+	return nil, mongo.ErrNoDocuments
+}
+
+func (r *fakeUserRepo) GetUserByEmail(_ context.Context, email string) (*model.RowUser, error) {
+	return r.find(func(user *model.RowUser) bool { return user.Email == email })
+}
+
+func (r *fakeUserRepo) GetUserById(_ context.Context, id string) (*model.RowUser, error) {
+	return r.find(func(user *model.RowUser) bool { return user.ID.Hex() == id })
+}
+
+func (r *fakeUserRepo) SetTokensRevokedAt(_ context.Context, id primitive.ObjectID, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	user, ok := r.users[id]
+	if !ok {
+		return mongo.ErrNoDocuments
+	}
+	user.TokensRevokedAt = &at
+	return nil
+}
+
+func (r *fakeUserRepo) UpdatePassword(_ context.Context, id primitive.ObjectID, passwordHash string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	user, ok := r.users[id]
+	if !ok {
+		return mongo.ErrNoDocuments
+	}
+	user.Password = passwordHash
+	return nil
+}
+
+func (r *fakeUserRepo) UseTwoFactorStep(_ context.Context, id primitive.ObjectID, step int64) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	user, ok := r.users[id]
+	if !ok || !user.TwoFactor.IsEnabled() || user.TwoFactor.LastUsedStep >= step {
+		return false, nil
+	}
+	user.TwoFactor.LastUsedStep = step
+	return true, nil
+}
+
+func (r *fakeUserRepo) UseRecoveryCode(_ context.Context, id primitive.ObjectID, codeHash string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	user, ok := r.users[id]
+	if !ok || !user.TwoFactor.IsEnabled() {
+		return false, nil
+	}
+	codes := user.TwoFactor.RecoveryCodes
+	for i, hash := range codes {
+		if hash == codeHash {
+			user.TwoFactor.RecoveryCodes = append(codes[:i:i], codes[i+1:]...)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// fakeRefreshTokenRepo - refresh токены в памяти. MarkUsed атомарен, как и в MongoDB.
+type fakeRefreshTokenRepo struct {
+	mu     sync.Mutex
+	tokens map[string]*model.RowRefreshToken
+	// staleReads - GetByHash отдаёт токен в том виде, в каком он был создан,
+	// как будто параллельный запрос прочитал его до обмена
+	staleReads bool
+	created    map[string]model.RowRefreshToken
+}
+
+func (r *fakeRefreshTokenRepo) Create(_ context.Context, token *model.RowRefreshToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tokens[token.TokenHash] = token
+	r.created[token.TokenHash] = *token
+	return nil
+}
+
+func (r *fakeRefreshTokenRepo) GetByHash(_ context.Context, tokenHash string) (*model.RowRefreshToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	token, ok := r.tokens[tokenHash]
+	if !ok {
+		return nil, mongo.ErrNoDocuments
+	}
+	copied := *token
+	if r.staleReads {
+		copied = r.created[tokenHash]
+	}
+	return &copied, nil
+}
+
+func (r *fakeRefreshTokenRepo) MarkUsed(_ context.Context, id primitive.ObjectID, at time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, token := range r.tokens {
+		if token.ID == id && token.UsedAt == nil && token.RevokedAt == nil {
+			token.UsedAt = &at
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *fakeRefreshTokenRepo) RevokeFamily(_ context.Context, familyID primitive.ObjectID, at time.Time) error {
+	r.revoke(func(token *model.RowRefreshToken) bool { return token.FamilyID == familyID }, at)
+	return nil
+}
+
+func (r *fakeRefreshTokenRepo) RevokeUserTokens(_ context.Context, userID primitive.ObjectID, at time.Time) error {
+	r.revoke(func(token *model.RowRefreshToken) bool { return token.UserID == userID }, at)
+	return nil
+}
+
+func (r *fakeRefreshTokenRepo) revoke(match func(*model.RowRefreshToken) bool, at time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, token := range r.tokens {
+		if match(token) && token.RevokedAt == nil {
+			token.RevokedAt = &at
+		}
+	}
+}
+
+// testEnv - authService и passwordService на фейковых репозиториях с настоящими JWT и 2FA.
+type testEnv struct {
+	auth          service.AuthServiceInterface
+	passwords     service.PasswordServiceInterface
+	jwt           service.JWTServiceInterface
+	users         *fakeUserRepo
+	refreshTokens *fakeRefreshTokenRepo
+	user          *model.RowUser
+}
+
+func newTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+
+	// Минимальная сложность bcrypt, чтобы тесты не тратили время на хэширование
+	passwordHash, err := utils.HashData(testPassword, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := &model.RowUser{
+		ID:       primitive.NewObjectID(),
+		Email:    "user@example.com",
+		Password: passwordHash,
+		Role:     model.RoleEditor,
+	}
+
+	keys, err := jwtkeys.Load(jwtkeys.Config{HMACSecret: "test-secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logger := zap.NewNop()
+	users := &fakeUserRepo{users: map[primitive.ObjectID]*model.RowUser{user.ID: user}}
+	refreshTokens := &fakeRefreshTokenRepo{
+		tokens:  map[string]*model.RowRefreshToken{},
+		created: map[string]model.RowRefreshToken{},
+	}
+	jwtService := service.NewJWTService(keys, "edjr-trk", "edjr-trk", logger)
+	guard := service.NewLoginGuard(
+		service.NewLoginThrottle(5, time.Minute, time.Hour, 15*time.Minute, 1000),
+		service.NewLoginThrottle(20, time.Minute, time.Hour, 15*time.Minute, 1000),
+		nil, nil, logger,
+	)
+
+	auth := service.NewAuthService(users, refreshTokens, jwtService,
+		service.NewTwoFactorService(users, "edjr-trk", logger), guard,
+		15*time.Minute, 24*time.Hour, logger)
+
+	return &testEnv{
+		auth:          auth,
+		passwords:     service.NewPasswordService(users, nil, auth, guard, nil, nil, time.Hour, "", logger),
+		jwt:           jwtService,
+		users:         users,
+		refreshTokens: refreshTokens,
+		user:          user,
+	}
+}
+
+// login - вход без 2FA, возвращает пару токенов.
+func (e *testEnv) login(t *testing.T) *model.LoginResponse {
+	t.Helper()
+	resp, err := e.auth.Login(context.Background(), e.user.Email, testPassword, "192.0.2.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp
+}
+
+// issuedAt - iat access токена в том виде, в каком его получает middleware.
+func (e *testEnv) issuedAt(t *testing.T, accessToken string) time.Time {
+	t.Helper()
+	token, err := e.jwt.ValidateToken(accessToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token.Claims.(*service.Claims).IssuedAt.Time
+}
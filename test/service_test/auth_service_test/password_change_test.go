@@ -0,0 +1,45 @@
+package auth_service_test
+
+import (
+	"context"
+	"edjr-trk/internal/service"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestChangePassword(t *testing.T) {
+	ctx := context.Background()
+	const ip = "192.0.2.1"
+
+	t.Run("Correct current password changes the password", func(t *testing.T) {
+		env := newTestEnv(t)
+
+		assert.NoError(t, env.passwords.Change(ctx, env.user.ID.Hex(), testPassword, "new-password", ip))
+
+		_, err := env.auth.Login(ctx, env.user.Email, "new-password", ip)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Wrong current password is throttled like a failed login", func(t *testing.T) {
+		env := newTestEnv(t)
+
+		err := env.passwords.Change(ctx, env.user.ID.Hex(), "guess", "new-password", ip)
+		assert.ErrorIs(t, err, service.ErrWrongPassword)
+
+		// Следующая попытка ждёт, даже с верным паролем
+		var throttled *service.LoginThrottledError
+		err = env.passwords.Change(ctx, env.user.ID.Hex(), testPassword, "new-password", ip)
+		assert.ErrorAs(t, err, &throttled)
+	})
+
+	t.Run("Password change and login share the counters", func(t *testing.T) {
+		env := newTestEnv(t)
+
+		assert.ErrorIs(t, env.passwords.Change(ctx, env.user.ID.Hex(), "guess", "new-password", "198.51.100.1"), service.ErrWrongPassword)
+
+		// Вход в тот же аккаунт с другого IP тоже ждёт
+		var throttled *service.LoginThrottledError
+		_, err := env.auth.Login(ctx, env.user.Email, testPassword, ip)
+		assert.ErrorAs(t, err, &throttled)
+	})
+}
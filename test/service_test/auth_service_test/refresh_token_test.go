@@ -0,0 +1,191 @@
+package auth_service_test
+
+import (
+	"context"
+	"edjr-trk/internal/service"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRefresh(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Refresh token is rotated", func(t *testing.T) {
+		env := newTestEnv(t)
+		first := env.login(t)
+
+		second, err := env.auth.Refresh(ctx, first.RefreshToken)
+
+		assert.NoError(t, err)
+		assert.NotEmpty(t, second.AccessToken)
+		assert.NotEqual(t, first.RefreshToken, second.RefreshToken)
+
+		_, err = env.auth.Refresh(ctx, second.RefreshToken)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Reused token revokes the whole family", func(t *testing.T) {
+		env := newTestEnv(t)
+		first := env.login(t)
+		second, err := env.auth.Refresh(ctx, first.RefreshToken)
+		assert.NoError(t, err)
+
+		_, err = env.auth.Refresh(ctx, first.RefreshToken)
+		assert.ErrorIs(t, err, service.ErrRefreshTokenReused)
+
+		// Токен, выданный при честном обмене, тоже больше не работает
+		_, err = env.auth.Refresh(ctx, second.RefreshToken)
+		assert.ErrorIs(t, err, service.ErrInvalidRefreshToken)
+
+		revoked, err := env.auth.IsTokenRevoked(ctx, env.user.ID.Hex(), env.issuedAt(t, second.AccessToken).Add(-time.Second))
+		assert.NoError(t, err)
+		assert.True(t, revoked)
+	})
+
+	t.Run("Reuse is detected when the token was read before a concurrent exchange", func(t *testing.T) {
+		env := newTestEnv(t)
+		first := env.login(t)
+		env.refreshTokens.staleReads = true
+
+		second, err := env.auth.Refresh(ctx, first.RefreshToken)
+		assert.NoError(t, err)
+
+		// Второй запрос видит токен ещё не обменянным, но MarkUsed уже не проходит
+		_, err = env.auth.Refresh(ctx, first.RefreshToken)
+		assert.ErrorIs(t, err, service.ErrRefreshTokenReused)
+
+		env.refreshTokens.staleReads = false
+		_, err = env.auth.Refresh(ctx, second.RefreshToken)
+		assert.ErrorIs(t, err, service.ErrInvalidRefreshToken)
+	})
+
+	t.Run("Only one of concurrent refreshes succeeds", func(t *testing.T) {
+		env := newTestEnv(t)
+		first := env.login(t)
+
+		const requests = 10
+		errs := make([]error, requests)
+		var wg sync.WaitGroup
+		for i := range errs {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, errs[i] = env.auth.Refresh(ctx, first.RefreshToken)
+			}()
+		}
+		wg.Wait()
+
+		// Проигравшие обнаруживают повтор, а после отзыва цепочки токен уже просто недействителен
+		succeeded, reused := 0, 0
+		for _, err := range errs {
+			switch {
+			case err == nil:
+				succeeded++
+			case errors.Is(err, service.ErrRefreshTokenReused):
+				reused++
+			default:
+				assert.ErrorIs(t, err, service.ErrInvalidRefreshToken)
+			}
+		}
+		assert.Equal(t, 1, succeeded)
+		assert.Positive(t, reused)
+	})
+
+	t.Run("Unknown token is rejected", func(t *testing.T) {
+		env := newTestEnv(t)
+
+		_, err := env.auth.Refresh(ctx, "unknown")
+
+		assert.ErrorIs(t, err, service.ErrInvalidRefreshToken)
+	})
+}
+
+func TestLogout(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Logout revokes the current session only", func(t *testing.T) {
+		env := newTestEnv(t)
+		current := env.login(t)
+		other := env.login(t)
+
+		assert.NoError(t, env.auth.Logout(ctx, current.RefreshToken, false))
+
+		_, err := env.auth.Refresh(ctx, current.RefreshToken)
+		assert.ErrorIs(t, err, service.ErrInvalidRefreshToken)
+		_, err = env.auth.Refresh(ctx, other.RefreshToken)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Logout from all sessions revokes every refresh token", func(t *testing.T) {
+		env := newTestEnv(t)
+		current := env.login(t)
+		other := env.login(t)
+
+		assert.NoError(t, env.auth.Logout(ctx, current.RefreshToken, true))
+
+		_, err := env.auth.Refresh(ctx, current.RefreshToken)
+		assert.ErrorIs(t, err, service.ErrInvalidRefreshToken)
+		_, err = env.auth.Refresh(ctx, other.RefreshToken)
+		assert.ErrorIs(t, err, service.ErrInvalidRefreshToken)
+	})
+
+	t.Run("Unknown token is rejected", func(t *testing.T) {
+		env := newTestEnv(t)
+
+		assert.ErrorIs(t, env.auth.Logout(ctx, "unknown", false), service.ErrInvalidRefreshToken)
+	})
+}
+
+func TestIsTokenRevoked(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Revocation time is truncated to whole seconds", func(t *testing.T) {
+		env := newTestEnv(t)
+		session := env.login(t)
+
+		assert.NoError(t, env.auth.Logout(ctx, session.RefreshToken, false))
+
+		revokedAt := *env.users.users[env.user.ID].TokensRevokedAt
+		assert.Equal(t, revokedAt.Truncate(time.Second), revokedAt)
+	})
+
+	t.Run("Token issued in the same second after logout stays valid", func(t *testing.T) {
+		env := newTestEnv(t)
+		session := env.login(t)
+		assert.NoError(t, env.auth.Logout(ctx, session.RefreshToken, false))
+
+		// iat хранится в секундах, поэтому новый вход может получить тот же iat, что и момент выхода
+		next := env.login(t)
+		revoked, err := env.auth.IsTokenRevoked(ctx, env.user.ID.Hex(), env.issuedAt(t, next.AccessToken))
+
+		assert.NoError(t, err)
+		assert.False(t, revoked)
+	})
+
+	t.Run("Token issued a second before logout is revoked", func(t *testing.T) {
+		env := newTestEnv(t)
+		session := env.login(t)
+		assert.NoError(t, env.auth.Logout(ctx, session.RefreshToken, false))
+
+		revokedAt := *env.users.users[env.user.ID].TokensRevokedAt
+		revoked, err := env.auth.IsTokenRevoked(ctx, env.user.ID.Hex(), revokedAt.Add(-time.Second))
+
+		assert.NoError(t, err)
+		assert.True(t, revoked)
+	})
+
+	t.Run("Tokens of a disabled user are revoked", func(t *testing.T) {
+		env := newTestEnv(t)
+		session := env.login(t)
+		now := time.Now()
+		env.users.users[env.user.ID].DisabledAt = &now
+
+		revoked, err := env.auth.IsTokenRevoked(ctx, env.user.ID.Hex(), env.issuedAt(t, session.AccessToken))
+
+		assert.NoError(t, err)
+		assert.True(t, revoked)
+	})
+}
@@ -0,0 +1,133 @@
+package auth_service_test
+
+import (
+	"context"
+	"edjr-trk/internal/model"
+	"edjr-trk/internal/service"
+	"edjr-trk/pkg/totp"
+	"edjr-trk/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+const recoveryCode = "abcde-fghjk"
+
+// enableTwoFactor - включает 2FA с одним кодом восстановления и возвращает секрет.
+func enableTwoFactor(t *testing.T, env *testEnv) string {
+	t.Helper()
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	recoveryHash, err := utils.HashData("abcdefghjk", 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	env.users.users[env.user.ID].TwoFactor = &model.TwoFactor{
+		Enabled:       true,
+		Secret:        secret,
+		RecoveryCodes: []string{recoveryHash},
+	}
+	return secret
+}
+
+// challenge - первый шаг входа с включённой 2FA.
+func challenge(t *testing.T, env *testEnv) string {
+	t.Helper()
+	resp, err := env.auth.Login(context.Background(), env.user.Email, testPassword, "192.0.2.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp.ChallengeToken
+}
+
+func TestLoginTwoFactor(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Password alone returns a challenge instead of tokens", func(t *testing.T) {
+		env := newTestEnv(t)
+		enableTwoFactor(t, env)
+
+		resp, err := env.auth.Login(ctx, env.user.Email, testPassword, "192.0.2.1")
+
+		assert.NoError(t, err)
+		assert.True(t, resp.TwoFactorRequired)
+		assert.NotEmpty(t, resp.ChallengeToken)
+		assert.Empty(t, resp.AccessToken)
+		assert.Empty(t, resp.RefreshToken)
+	})
+
+	t.Run("Valid TOTP code completes the login once", func(t *testing.T) {
+		env := newTestEnv(t)
+		secret := enableTwoFactor(t, env)
+		code, err := totp.Code(secret, totp.Step(time.Now()))
+		assert.NoError(t, err)
+
+		resp, err := env.auth.LoginTwoFactor(ctx, challenge(t, env), code, "192.0.2.1")
+		assert.NoError(t, err)
+		assert.NotEmpty(t, resp.AccessToken)
+		assert.NotEmpty(t, resp.RefreshToken)
+
+		// Перехваченный код нельзя использовать повторно
+		_, err = env.auth.LoginTwoFactor(ctx, challenge(t, env), code, "192.0.2.1")
+		assert.ErrorIs(t, err, service.ErrInvalidTwoFactorCode)
+	})
+
+	t.Run("Wrong code is rejected", func(t *testing.T) {
+		env := newTestEnv(t)
+		enableTwoFactor(t, env)
+
+		_, err := env.auth.LoginTwoFactor(ctx, challenge(t, env), "000000", "192.0.2.1")
+
+		assert.ErrorIs(t, err, service.ErrInvalidTwoFactorCode)
+	})
+
+	t.Run("Recovery code works only once", func(t *testing.T) {
+		env := newTestEnv(t)
+		enableTwoFactor(t, env)
+
+		_, err := env.auth.LoginTwoFactor(ctx, challenge(t, env), recoveryCode, "192.0.2.1")
+		assert.NoError(t, err)
+
+		_, err = env.auth.LoginTwoFactor(ctx, challenge(t, env), recoveryCode, "192.0.2.1")
+		assert.ErrorIs(t, err, service.ErrInvalidTwoFactorCode)
+	})
+
+	t.Run("Access token is not accepted as a challenge", func(t *testing.T) {
+		env := newTestEnv(t)
+		session := env.login(t)
+		secret := enableTwoFactor(t, env)
+		code, err := totp.Code(secret, totp.Step(time.Now()))
+		assert.NoError(t, err)
+
+		_, err = env.auth.LoginTwoFactor(ctx, session.AccessToken, code, "192.0.2.1")
+
+		assert.ErrorIs(t, err, service.ErrInvalidChallengeToken)
+	})
+
+	t.Run("Challenge of a user without 2FA is rejected", func(t *testing.T) {
+		env := newTestEnv(t)
+		enableTwoFactor(t, env)
+		token := challenge(t, env)
+		env.users.users[env.user.ID].TwoFactor = nil
+
+		_, err := env.auth.LoginTwoFactor(ctx, token, "000000", "192.0.2.1")
+
+		assert.ErrorIs(t, err, service.ErrInvalidChallengeToken)
+	})
+
+	t.Run("Disabled user cannot finish the login", func(t *testing.T) {
+		env := newTestEnv(t)
+		secret := enableTwoFactor(t, env)
+		token := challenge(t, env)
+		now := time.Now()
+		env.users.users[env.user.ID].DisabledAt = &now
+		code, err := totp.Code(secret, totp.Step(time.Now()))
+		assert.NoError(t, err)
+
+		_, err = env.auth.LoginTwoFactor(ctx, token, code, "192.0.2.1")
+
+		assert.ErrorIs(t, err, service.ErrAccountDisabled)
+	})
+}
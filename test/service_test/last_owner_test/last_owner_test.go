@@ -0,0 +1,234 @@
+package last_owner_test
+
+import (
+	"context"
+	"edjr-trk/internal/api/dto"
+	"edjr-trk/internal/model"
+	"edjr-trk/internal/repository"
+	"edjr-trk/internal/service"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.uber.org/zap"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeUserRepo - пользователи в памяти; методы, которые проверка владельцев не вызывает, не реализованы.
+type fakeUserRepo struct {
+	repository.UserRepositoryInterface
+	mu    sync.Mutex
+	users map[string]*model.RowUser
+	// afterCount - вызывается один раз после первого подсчёта владельцев,
+	// то есть между проверкой и изменением
+	afterCount func()
+}
+
+func newFakeUserRepo(users ...*model.RowUser) *fakeUserRepo {
+	repo := &fakeUserRepo{users: map[string]*model.RowUser{}}
+	for _, user := range users {
+		repo.users[user.ID.Hex()] = user
+	}
+	return repo
+}
+
+// update - изменяет пользователя вне корзины.
+func (r *fakeUserRepo) update(id string, change func(*model.RowUser)) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	user, ok := r.users[id]
+	if !ok || user.DeletedAt != nil {
+		return mongo.ErrNoDocuments
+	}
+	change(user)
+	return nil
+}
+
+func (r *fakeUserRepo) GetUserById(_ context.Context, id string) (*model.RowUser, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	user, ok := r.users[id]
+	if !ok || user.DeletedAt != nil {
+		return nil, mongo.ErrNoDocuments
+	}
+	copied := *user
+	return &copied, nil
+}
+
+func (r *fakeUserRepo) RemoveUserById(_ context.Context, id string) error {
+	now := time.Now()
+	return r.update(id, func(user *model.RowUser) { user.DeletedAt = &now })
+}
+
+func (r *fakeUserRepo) RestoreUserById(_ context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	user, ok := r.users[id]
+	if !ok || user.DeletedAt == nil {
+		return mongo.ErrNoDocuments
+	}
+	user.DeletedAt = nil
+	return nil
+}
+
+func (r *fakeUserRepo) SetDisabled(_ context.Context, id primitive.ObjectID, disabledAt *time.Time) error {
+	return r.update(id.Hex(), func(user *model.RowUser) { user.DisabledAt = disabledAt })
+}
+
+func (r *fakeUserRepo) SetRole(_ context.Context, id primitive.ObjectID, role string) error {
+	return r.update(id.Hex(), func(user *model.RowUser) { user.Role = role })
+}
+
+func (r *fakeUserRepo) PatchUserById(_ context.Context, patch *dto.PatchUserRequest, id string) (*model.RowUser, error) {
+	err := r.update(id, func(user *model.RowUser) {
+		if patch.Role != nil {
+			user.Role = *patch.Role
+		}
+		if patch.DisplayName != nil {
+			user.DisplayName = *patch.DisplayName
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.GetUserById(context.Background(), id)
+}
+
+func (r *fakeUserRepo) CountOwners(context.Context) (int64, error) {
+	r.mu.Lock()
+	var owners int64
+	for _, user := range r.users {
+		if user.DeletedAt == nil && user.IsActiveOwner() {
+			owners++
+		}
+	}
+	hook := r.afterCount
+	r.afterCount = nil
+	r.mu.Unlock()
+
+	if hook != nil {
+		hook()
+	}
+	return owners, nil
+}
+
+func (r *fakeUserRepo) activeOwners() int {
+	owners, _ := r.CountOwners(context.Background())
+	return int(owners)
+}
+
+type fakeMedia struct {
+	service.MediaServiceInterface
+}
+
+func (fakeMedia) StoreInlineImage(_ context.Context, img *string) (*string, error) {
+	return img, nil
+}
+
+type fakeAuth struct {
+	service.AuthServiceInterface
+}
+
+func (fakeAuth) RevokeSessions(context.Context, primitive.ObjectID) error {
+	return nil
+}
+
+func newOwner() *model.RowUser {
+	return &model.RowUser{ID: primitive.NewObjectID(), Role: model.RoleOwner}
+}
+
+func TestLastOwner(t *testing.T) {
+	ctx := context.Background()
+	viewer := model.RoleViewer
+
+	// Каждое изменение, которое лишает пользователя прав владельца
+	changes := map[string]func(users service.UserServiceInterface, id string) error{
+		"remove": func(users service.UserServiceInterface, id string) error {
+			_, err := users.RemoveUserById(ctx, id)
+			return err
+		},
+		"disable": func(users service.UserServiceInterface, id string) error {
+			_, err := users.DisableUserById(ctx, id)
+			return err
+		},
+		"demote": func(users service.UserServiceInterface, id string) error {
+			_, err := users.PatchUserById(ctx, dto.PatchUserRequest{Role: &viewer}, id)
+			return err
+		},
+	}
+
+	for name, change := range changes {
+		t.Run("Single owner is protected: "+name, func(t *testing.T) {
+			owner := newOwner()
+			repo := newFakeUserRepo(owner)
+			users := service.NewUserService(repo, fakeMedia{}, fakeAuth{}, zap.NewNop())
+
+			assert.ErrorIs(t, change(users, owner.ID.Hex()), service.ErrLastOwner)
+			assert.Equal(t, 1, repo.activeOwners())
+		})
+
+		t.Run("One of two owners can lose the role: "+name, func(t *testing.T) {
+			first, second := newOwner(), newOwner()
+			repo := newFakeUserRepo(first, second)
+			users := service.NewUserService(repo, fakeMedia{}, fakeAuth{}, zap.NewNop())
+
+			assert.NoError(t, change(users, first.ID.Hex()))
+			assert.Equal(t, 1, repo.activeOwners())
+		})
+
+		t.Run("Change racing with another owner's removal is rolled back: "+name, func(t *testing.T) {
+			first, second := newOwner(), newOwner()
+			repo := newFakeUserRepo(first, second)
+			users := service.NewUserService(repo, fakeMedia{}, fakeAuth{}, zap.NewNop())
+			// Параллельный запрос уже прошёл свою проверку и удалил второго владельца
+			repo.afterCount = func() {
+				assert.NoError(t, repo.RemoveUserById(ctx, second.ID.Hex()))
+			}
+
+			assert.ErrorIs(t, change(users, first.ID.Hex()), service.ErrLastOwner)
+
+			restored, err := repo.GetUserById(ctx, first.ID.Hex())
+			assert.NoError(t, err)
+			assert.True(t, restored.IsActiveOwner())
+		})
+	}
+
+	t.Run("Concurrent changes never leave the system without an owner", func(t *testing.T) {
+		for i := 0; i < 50; i++ {
+			first, second := newOwner(), newOwner()
+			repo := newFakeUserRepo(first, second)
+			users := service.NewUserService(repo, fakeMedia{}, fakeAuth{}, zap.NewNop())
+
+			var wg sync.WaitGroup
+			for _, owner := range []*model.RowUser{first, second} {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					_, _ = users.DisableUserById(ctx, owner.ID.Hex())
+				}()
+			}
+			wg.Wait()
+
+			assert.GreaterOrEqual(t, repo.activeOwners(), 1)
+		}
+	})
+
+	t.Run("Legacy administrator keeps the owner role after a refused demotion", func(t *testing.T) {
+		legacy := &model.RowUser{ID: primitive.NewObjectID(), IsAdmin: true}
+		other := newOwner()
+		repo := newFakeUserRepo(legacy, other)
+		users := service.NewUserService(repo, fakeMedia{}, fakeAuth{}, zap.NewNop())
+		repo.afterCount = func() {
+			assert.NoError(t, repo.RemoveUserById(ctx, other.ID.Hex()))
+		}
+
+		_, err := users.PatchUserById(ctx, dto.PatchUserRequest{Role: &viewer}, legacy.ID.Hex())
+
+		assert.ErrorIs(t, err, service.ErrLastOwner)
+		restored, err := repo.GetUserById(ctx, legacy.ID.Hex())
+		assert.NoError(t, err)
+		assert.Empty(t, restored.Role)
+		assert.Equal(t, model.RoleOwner, restored.EffectiveRole())
+	})
+}
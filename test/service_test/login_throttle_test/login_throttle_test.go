@@ -0,0 +1,83 @@
+package login_throttle_test
+
+import (
+	"edjr-trk/internal/service"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestLoginThrottle(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+
+	t.Run("Delay doubles after each failure", func(t *testing.T) {
+		throttle := service.NewLoginThrottle(10, time.Second, time.Minute, 15*time.Minute, 1000)
+
+		assert.Equal(t, time.Duration(0), throttle.Blocked("a@example.com", now))
+
+		for _, expected := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second} {
+			assert.False(t, throttle.Fail("a@example.com", now))
+			assert.Equal(t, expected, throttle.Blocked("a@example.com", now))
+		}
+		assert.Equal(t, time.Duration(0), throttle.Blocked("a@example.com", now.Add(8*time.Second)))
+	})
+
+	t.Run("Delay is capped", func(t *testing.T) {
+		throttle := service.NewLoginThrottle(20, time.Second, 5*time.Second, 15*time.Minute, 1000)
+		for i := 0; i < 10; i++ {
+			throttle.Fail("ip", now)
+		}
+		assert.Equal(t, 5*time.Second, throttle.Blocked("ip", now))
+	})
+
+	t.Run("Lockout after max failures", func(t *testing.T) {
+		throttle := service.NewLoginThrottle(3, time.Second, time.Minute, 15*time.Minute, 1000)
+
+		assert.False(t, throttle.Fail("a@example.com", now))
+		assert.False(t, throttle.Fail("a@example.com", now))
+		assert.True(t, throttle.Fail("a@example.com", now))
+		assert.Equal(t, 15*time.Minute, throttle.Blocked("a@example.com", now))
+
+		// После блокировки счёт начинается заново
+		later := now.Add(15 * time.Minute)
+		assert.Equal(t, time.Duration(0), throttle.Blocked("a@example.com", later))
+		assert.False(t, throttle.Fail("a@example.com", later))
+	})
+
+	t.Run("Failures expire", func(t *testing.T) {
+		throttle := service.NewLoginThrottle(2, time.Second, time.Minute, time.Minute, 1000)
+
+		assert.False(t, throttle.Fail("a@example.com", now))
+		assert.False(t, throttle.Fail("a@example.com", now.Add(2*time.Minute)))
+	})
+
+	t.Run("Keys are independent and reset", func(t *testing.T) {
+		throttle := service.NewLoginThrottle(5, time.Second, time.Minute, 15*time.Minute, 1000)
+
+		throttle.Fail("a@example.com", now)
+		assert.Equal(t, time.Duration(0), throttle.Blocked("b@example.com", now))
+
+		throttle.Reset("a@example.com")
+		assert.Equal(t, time.Duration(0), throttle.Blocked("a@example.com", now))
+	})
+
+	t.Run("Oldest key is evicted when full", func(t *testing.T) {
+		throttle := service.NewLoginThrottle(5, time.Minute, time.Hour, 15*time.Minute, 2)
+
+		throttle.Fail("1.1.1.1", now)
+		throttle.Fail("2.2.2.2", now.Add(time.Second))
+		throttle.Fail("3.3.3.3", now.Add(2*time.Second))
+
+		assert.Equal(t, time.Duration(0), throttle.Blocked("1.1.1.1", now.Add(2*time.Second)))
+		assert.Greater(t, throttle.Blocked("2.2.2.2", now.Add(2*time.Second)), time.Duration(0))
+		assert.Greater(t, throttle.Blocked("3.3.3.3", now.Add(2*time.Second)), time.Duration(0))
+	})
+}
+
+func TestLoginThrottledError(t *testing.T) {
+	var err error = &service.LoginThrottledError{RetryAfter: time.Minute}
+
+	assert.True(t, errors.Is(err, service.ErrTooManyLoginAttempts))
+	assert.False(t, errors.Is(err, service.ErrInvalidCredentials))
+}
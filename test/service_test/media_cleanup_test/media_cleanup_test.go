@@ -0,0 +1,136 @@
+package media_cleanup_test
+
+import (
+	"context"
+	"edjr-trk/internal/api/dto"
+	"edjr-trk/internal/model"
+	"edjr-trk/internal/repository"
+	"edjr-trk/internal/service"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+	"testing"
+)
+
+const (
+	oldImg = "/api/media/000000000000000000000001"
+	newImg = "/api/media/000000000000000000000002"
+	inline = "data:image/png;base64,AAAA"
+)
+
+// fakeProductRepo - один проект в памяти; patchErr имитирует сбой сохранения.
+type fakeProductRepo struct {
+	repository.ProductRepositoryInterface
+	product  model.RowProduct
+	patchErr error
+}
+
+func (r *fakeProductRepo) GetProductById(context.Context, string) (*model.RowProduct, error) {
+	copied := r.product
+	return &copied, nil
+}
+
+func (r *fakeProductRepo) PatchProductById(_ context.Context, patch *dto.PatchProductRequest, _ string) (*model.RowProduct, error) {
+	if r.patchErr != nil {
+		return nil, r.patchErr
+	}
+	if patch.Img != nil {
+		r.product.Img = patch.Img
+	}
+	if patch.Title != nil {
+		r.product.Title = *patch.Title
+	}
+	copied := r.product
+	return &copied, nil
+}
+
+// fakeMedia - сохраняет каждое inline изображение как newImg и запоминает удалённые.
+type fakeMedia struct {
+	service.MediaServiceInterface
+	removed []string
+}
+
+func (m *fakeMedia) StoreInlineImage(_ context.Context, img *string) (*string, error) {
+	if img == nil || *img != inline {
+		return img, nil
+	}
+	url := newImg
+	return &url, nil
+}
+
+func (m *fakeMedia) RemoveImage(_ context.Context, img *string) {
+	if img != nil {
+		m.removed = append(m.removed, *img)
+	}
+}
+
+func (m *fakeMedia) VariantURLs(*string) map[string]string {
+	return nil
+}
+
+type fakeRevisions struct {
+	service.RevisionServiceInterface
+}
+
+func (fakeRevisions) EnsureBaseline(context.Context, string, primitive.ObjectID, func() (interface{}, error)) error {
+	return nil
+}
+
+func (fakeRevisions) Record(context.Context, string, primitive.ObjectID, interface{}, string, string) error {
+	return nil
+}
+
+func TestProductImageCleanup(t *testing.T) {
+	ctx := context.Background()
+
+	setup := func() (*fakeProductRepo, *fakeMedia, service.ProductServiceInterface) {
+		img := oldImg
+		repo := &fakeProductRepo{product: model.RowProduct{ID: primitive.NewObjectID(), Img: &img}}
+		media := &fakeMedia{}
+		return repo, media, service.NewProductService(repo, media, fakeRevisions{}, nil, zap.NewNop())
+	}
+
+	t.Run("Replaced image is removed after a successful patch", func(t *testing.T) {
+		repo, media, products := setup()
+		img := inline
+
+		resp, err := products.PatchProductById(ctx, dto.PatchProductRequest{Img: &img}, repo.product.ID.Hex(), "")
+
+		assert.NoError(t, err)
+		assert.Equal(t, newImg, *resp.Img)
+		assert.Equal(t, []string{oldImg}, media.removed)
+	})
+
+	t.Run("Image stored for a failed patch is removed, the current one is kept", func(t *testing.T) {
+		repo, media, products := setup()
+		repo.patchErr = errors.New("write failed")
+		img := inline
+
+		_, err := products.PatchProductById(ctx, dto.PatchProductRequest{Img: &img}, repo.product.ID.Hex(), "")
+
+		assert.Error(t, err)
+		assert.Equal(t, []string{newImg}, media.removed)
+		assert.Equal(t, oldImg, *repo.product.Img)
+	})
+
+	t.Run("Patch without a new image keeps the current one", func(t *testing.T) {
+		repo, media, products := setup()
+		title := "Renamed"
+
+		_, err := products.PatchProductById(ctx, dto.PatchProductRequest{Title: &title}, repo.product.ID.Hex(), "")
+
+		assert.NoError(t, err)
+		assert.Empty(t, media.removed)
+	})
+
+	t.Run("Same image URL sent again is not removed", func(t *testing.T) {
+		repo, media, products := setup()
+		img := oldImg
+
+		_, err := products.PatchProductById(ctx, dto.PatchProductRequest{Img: &img}, repo.product.ID.Hex(), "")
+
+		assert.NoError(t, err)
+		assert.Empty(t, media.removed)
+	})
+}
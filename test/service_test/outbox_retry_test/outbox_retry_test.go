@@ -0,0 +1,28 @@
+package outbox_retry_test
+
+import (
+	"edjr-trk/internal/service"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestOutboxRetryDelay(t *testing.T) {
+	base, maxDelay := 30*time.Second, 10*time.Minute
+
+	t.Run("Delay doubles after each failure", func(t *testing.T) {
+		expected := []time.Duration{30 * time.Second, time.Minute, 2 * time.Minute, 4 * time.Minute, 8 * time.Minute}
+		for i, delay := range expected {
+			assert.Equal(t, delay, service.OutboxRetryDelay(i+1, base, maxDelay), "attempt %d", i+1)
+		}
+	})
+
+	t.Run("Delay is capped", func(t *testing.T) {
+		assert.Equal(t, maxDelay, service.OutboxRetryDelay(6, base, maxDelay))
+		assert.Equal(t, maxDelay, service.OutboxRetryDelay(1000, base, maxDelay))
+	})
+
+	t.Run("First attempt waits the base delay", func(t *testing.T) {
+		assert.Equal(t, base, service.OutboxRetryDelay(0, base, maxDelay))
+	})
+}
@@ -0,0 +1,259 @@
+package outbox_retry_test
+
+import (
+	"context"
+	"edjr-trk/internal/model"
+	"edjr-trk/internal/repository"
+	"edjr-trk/internal/service"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.uber.org/zap"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeOutboxRepo - очередь в памяти с той же логикой захвата, что и в MongoDB.
+type fakeOutboxRepo struct {
+	repository.OutboxRepositoryInterface
+	mu       sync.Mutex
+	messages []*model.RowOutboxMessage
+}
+
+func (r *fakeOutboxRepo) Enqueue(_ context.Context, msg *model.RowOutboxMessage) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.messages = append(r.messages, msg)
+	return nil
+}
+
+func (r *fakeOutboxRepo) ClaimNext(_ context.Context, now time.Time, lease time.Duration) (*model.RowOutboxMessage, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, msg := range r.messages {
+		ready := msg.Status == model.OutboxPending && !msg.NextAttemptAt.After(now)
+		expired := msg.Status == model.OutboxSending && msg.LockedUntil != nil && !msg.LockedUntil.After(now)
+		if ready || expired {
+			lockedUntil := now.Add(lease)
+			msg.Status = model.OutboxSending
+			msg.LockedUntil = &lockedUntil
+			msg.LockToken = primitive.NewObjectID().Hex()
+			msg.Attempts++
+			copied := *msg
+			return &copied, nil
+		}
+	}
+	return nil, mongo.ErrNoDocuments
+}
+
+func (r *fakeOutboxRepo) MarkSent(_ context.Context, id primitive.ObjectID, lockToken string, sentAt, expiresAt time.Time) (*model.RowOutboxMessage, error) {
+	return r.update(id, lockToken, func(msg *model.RowOutboxMessage) {
+		msg.Status = model.OutboxSent
+		msg.SentAt = &sentAt
+		msg.ExpiresAt = &expiresAt
+		msg.LockedUntil = nil
+		msg.LockToken = ""
+		msg.LastError = ""
+		msg.Body = ""
+		msg.Text = ""
+	})
+}
+
+func (r *fakeOutboxRepo) MarkFailed(_ context.Context, id primitive.ObjectID, lockToken string, lastError string, nextAttemptAt time.Time, dead bool) (*model.RowOutboxMessage, error) {
+	return r.update(id, lockToken, func(msg *model.RowOutboxMessage) {
+		msg.Status = model.OutboxPending
+		if dead {
+			msg.Status = model.OutboxDead
+		}
+		msg.LastError = lastError
+		msg.NextAttemptAt = nextAttemptAt
+		msg.LockedUntil = nil
+		msg.LockToken = ""
+	})
+}
+
+// update - изменяет письмо, только пока его держит захват с lockToken.
+func (r *fakeOutboxRepo) update(id primitive.ObjectID, lockToken string, change func(*model.RowOutboxMessage)) (*model.RowOutboxMessage, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, msg := range r.messages {
+		if msg.ID == id && msg.Status == model.OutboxSending && msg.LockToken == lockToken {
+			change(msg)
+			copied := *msg
+			return &copied, nil
+		}
+	}
+	return nil, mongo.ErrNoDocuments
+}
+
+// message - копия письма, чтобы читать его, пока воркер работает.
+func (r *fakeOutboxRepo) message(i int) model.RowOutboxMessage {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return *r.messages[i]
+}
+
+// fakeLeadRepo - запоминает последнее состояние доставки каждого обращения.
+type fakeLeadRepo struct {
+	repository.LeadRepositoryInterface
+	mu         sync.Mutex
+	deliveries map[primitive.ObjectID]model.LeadDelivery
+}
+
+func (r *fakeLeadRepo) SetDelivery(_ context.Context, id primitive.ObjectID, delivery model.LeadDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.deliveries[id] = delivery
+	return nil
+}
+
+func (r *fakeLeadRepo) delivery(id primitive.ObjectID) model.LeadDelivery {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.deliveries[id]
+}
+
+// fakeSender - первые failures отправок завершаются ошибкой.
+type fakeSender struct {
+	mu        sync.Mutex
+	failures  int
+	calls     int
+	deadlines []time.Duration // сколько времени оставалось на каждую отправку
+	// during - вызывается во время отправки, например чтобы истекла аренда
+	during func()
+}
+
+func (s *fakeSender) SendEmail(ctx context.Context, _ *repository.EmailMessage) error {
+	s.mu.Lock()
+	s.calls++
+	calls := s.calls
+	if deadline, ok := ctx.Deadline(); ok {
+		s.deadlines = append(s.deadlines, time.Until(deadline))
+	}
+	during := s.during
+	s.mu.Unlock()
+
+	if during != nil {
+		during()
+	}
+	if calls <= s.failures {
+		return errors.New("smtp unavailable")
+	}
+	return nil
+}
+
+func (s *fakeSender) sent() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls
+}
+
+func TestOutboxWorker(t *testing.T) {
+	ctx := context.Background()
+	config := service.OutboxConfig{
+		MaxAttempts:  3,
+		BaseDelay:    time.Millisecond,
+		MaxDelay:     time.Millisecond,
+		PollInterval: 5 * time.Millisecond,
+		Lease:        time.Minute,
+	}
+
+	run := func(t *testing.T, sender *fakeSender, check func(repo *fakeOutboxRepo) bool) (*fakeOutboxRepo, *fakeLeadRepo, primitive.ObjectID) {
+		t.Helper()
+		repo := &fakeOutboxRepo{}
+		leads := &fakeLeadRepo{deliveries: map[primitive.ObjectID]model.LeadDelivery{}}
+		outbox := service.NewEmailOutbox(repo, leads, sender, config, zap.NewNop())
+		leadID := primitive.NewObjectID()
+
+		outbox.Start()
+		assert.NoError(t, outbox.Enqueue(ctx, &model.RowOutboxMessage{
+			To:      "visitor@example.com",
+			Subject: "Thanks",
+			Body:    "<p>Thanks</p>",
+			LeadID:  &leadID,
+		}))
+		assert.Eventually(t, func() bool { return check(repo) }, 2*time.Second, 5*time.Millisecond)
+
+		shutdownCtx, cancel := context.WithTimeout(ctx, time.Second)
+		defer cancel()
+		assert.NoError(t, outbox.Shutdown(shutdownCtx))
+		return repo, leads, leadID
+	}
+
+	t.Run("Failed delivery is retried until it succeeds", func(t *testing.T) {
+		sender := &fakeSender{failures: 2}
+
+		repo, leads, leadID := run(t, sender, func(repo *fakeOutboxRepo) bool {
+			return repo.message(0).Status == model.OutboxSent
+		})
+
+		msg := repo.message(0)
+		assert.Equal(t, 3, msg.Attempts)
+		assert.Empty(t, msg.LastError)
+		assert.Empty(t, msg.Body)
+		assert.NotNil(t, msg.ExpiresAt)
+		assert.Equal(t, 3, sender.sent())
+		assert.Equal(t, model.DeliverySent, leads.delivery(leadID).Status)
+	})
+
+	t.Run("Message becomes dead after the last attempt", func(t *testing.T) {
+		sender := &fakeSender{failures: 100}
+
+		repo, leads, leadID := run(t, sender, func(repo *fakeOutboxRepo) bool {
+			return repo.message(0).Status == model.OutboxDead
+		})
+
+		msg := repo.message(0)
+		assert.Equal(t, config.MaxAttempts, msg.Attempts)
+		assert.Equal(t, "smtp unavailable", msg.LastError)
+		assert.Equal(t, "<p>Thanks</p>", msg.Body)
+		// Dead письмо больше не отправляется
+		assert.Equal(t, config.MaxAttempts, sender.sent())
+		assert.Equal(t, model.DeliveryFailed, leads.delivery(leadID).Status)
+	})
+
+	t.Run("Delivery must finish within the lease", func(t *testing.T) {
+		sender := &fakeSender{}
+
+		run(t, sender, func(repo *fakeOutboxRepo) bool {
+			return repo.message(0).Status == model.OutboxSent
+		})
+
+		sender.mu.Lock()
+		defer sender.mu.Unlock()
+		assert.Len(t, sender.deadlines, 1)
+		assert.Positive(t, sender.deadlines[0])
+		assert.Less(t, sender.deadlines[0], config.Lease)
+	})
+
+	t.Run("Result of a worker that lost its lease is discarded", func(t *testing.T) {
+		repo := &fakeOutboxRepo{}
+		// Пока письмо отправлялось, аренда истекла и его захватил другой воркер
+		sender := &fakeSender{during: func() {
+			repo.mu.Lock()
+			repo.messages[0].LockToken = "other-worker"
+			repo.mu.Unlock()
+		}}
+
+		leads := &fakeLeadRepo{deliveries: map[primitive.ObjectID]model.LeadDelivery{}}
+		outbox := service.NewEmailOutbox(repo, leads, sender, config, zap.NewNop())
+		leadID := primitive.NewObjectID()
+
+		outbox.Start()
+		assert.NoError(t, outbox.Enqueue(ctx, &model.RowOutboxMessage{To: "visitor@example.com", Subject: "Thanks", LeadID: &leadID}))
+		assert.Eventually(t, func() bool { return sender.sent() == 1 }, 2*time.Second, 5*time.Millisecond)
+
+		shutdownCtx, cancel := context.WithTimeout(ctx, time.Second)
+		defer cancel()
+		assert.NoError(t, outbox.Shutdown(shutdownCtx))
+
+		// Итог записывает только тот, кто держит письмо сейчас
+		msg := repo.message(0)
+		assert.Equal(t, model.OutboxSending, msg.Status)
+		assert.Equal(t, "other-worker", msg.LockToken)
+		assert.Nil(t, msg.SentAt)
+		assert.Empty(t, leads.delivery(leadID).Status)
+	})
+}
@@ -0,0 +1,35 @@
+package rate_limiter_test
+
+import (
+	"edjr-trk/internal/service"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterAllow(t *testing.T) {
+	t.Run("Blocks after the limit", func(t *testing.T) {
+		limiter := service.NewRateLimiter(2, time.Hour, time.Hour)
+
+		assert.NoError(t, limiter.Allow("anna@example.com"))
+		assert.NoError(t, limiter.Allow("anna@example.com"))
+		assert.Error(t, limiter.Allow("anna@example.com"))
+		assert.Error(t, limiter.Allow("anna@example.com"), "stays blocked")
+	})
+
+	t.Run("Keys are counted separately", func(t *testing.T) {
+		limiter := service.NewRateLimiter(1, time.Hour, time.Hour)
+
+		assert.NoError(t, limiter.Allow("anna@example.com"))
+		assert.NoError(t, limiter.Allow("boris@example.com"))
+		assert.Error(t, limiter.Allow("anna@example.com"))
+	})
+
+	t.Run("Old requests leave the window", func(t *testing.T) {
+		limiter := service.NewRateLimiter(1, 10*time.Millisecond, time.Hour)
+
+		assert.NoError(t, limiter.Allow("anna@example.com"))
+		time.Sleep(20 * time.Millisecond)
+		assert.NoError(t, limiter.Allow("anna@example.com"))
+	})
+}
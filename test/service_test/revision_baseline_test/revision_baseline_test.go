@@ -0,0 +1,88 @@
+package revision_baseline_test
+
+import (
+	"context"
+	"edjr-trk/internal/model"
+	"edjr-trk/internal/service"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+	"testing"
+)
+
+// fakeRevisionRepo - ревизии в памяти
+type fakeRevisionRepo struct {
+	revisions []*model.RowRevision
+}
+
+func (r *fakeRevisionRepo) Create(_ context.Context, revision *model.RowRevision) (*model.RowRevision, error) {
+	r.revisions = append(r.revisions, revision)
+	return revision, nil
+}
+
+func (r *fakeRevisionRepo) GetLatestVersion(_ context.Context, entityType string, entityID primitive.ObjectID) (int, error) {
+	latest := 0
+	for _, revision := range r.revisions {
+		if revision.EntityType == entityType && revision.EntityID == entityID && revision.Version > latest {
+			latest = revision.Version
+		}
+	}
+	return latest, nil
+}
+
+func (r *fakeRevisionRepo) GetRevisions(context.Context, string, primitive.ObjectID, int, int) ([]model.RowRevision, int, error) {
+	return nil, 0, nil
+}
+
+func (r *fakeRevisionRepo) GetRevisionById(context.Context, string, primitive.ObjectID, string) (*model.RowRevision, error) {
+	return nil, nil
+}
+
+func (r *fakeRevisionRepo) DeleteRevisions(context.Context, string, []primitive.ObjectID) (int64, error) {
+	return 0, nil
+}
+
+func TestEnsureBaseline(t *testing.T) {
+	ctx := context.Background()
+	id := primitive.NewObjectID()
+	legacy := &model.RowArticle{ID: id, Title: "Original"}
+
+	t.Run("Document without history gets a baseline revision", func(t *testing.T) {
+		repo := &fakeRevisionRepo{}
+		revisions := service.NewRevisionService(repo, zap.NewNop())
+
+		err := revisions.EnsureBaseline(ctx, model.RevisionEntityArticle, id, func() (interface{}, error) { return legacy, nil })
+
+		assert.NoError(t, err)
+		assert.Len(t, repo.revisions, 1)
+		assert.Equal(t, 1, repo.revisions[0].Version)
+		assert.Equal(t, model.RevisionActionBaseline, repo.revisions[0].Action)
+		assert.Equal(t, "Original", repo.revisions[0].Snapshot["title"])
+	})
+
+	t.Run("Document with history is not loaded again", func(t *testing.T) {
+		repo := &fakeRevisionRepo{}
+		revisions := service.NewRevisionService(repo, zap.NewNop())
+		assert.NoError(t, revisions.Record(ctx, model.RevisionEntityArticle, id, legacy, model.RevisionActionCreate, "author"))
+
+		err := revisions.EnsureBaseline(ctx, model.RevisionEntityArticle, id, func() (interface{}, error) {
+			t.Fatal("load must not be called")
+			return nil, nil
+		})
+
+		assert.NoError(t, err)
+		assert.Len(t, repo.revisions, 1)
+	})
+
+	t.Run("Load error is returned", func(t *testing.T) {
+		repo := &fakeRevisionRepo{}
+		revisions := service.NewRevisionService(repo, zap.NewNop())
+		loadErr := errors.New("not found")
+
+		err := revisions.EnsureBaseline(ctx, model.RevisionEntityArticle, id, func() (interface{}, error) { return nil, loadErr })
+
+		assert.ErrorIs(t, err, loadErr)
+		assert.Empty(t, repo.revisions)
+	})
+}
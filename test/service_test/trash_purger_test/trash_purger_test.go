@@ -0,0 +1,119 @@
+package trash_purger_test
+
+import (
+	"context"
+	"edjr-trk/internal/model"
+	"edjr-trk/internal/repository"
+	"edjr-trk/internal/service"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+	"testing"
+	"time"
+)
+
+type fakeArticleRepo struct {
+	repository.ArticleRepositoryInterface
+	purged []model.RowArticle
+	err    error
+}
+
+func (r *fakeArticleRepo) PurgeDeletedArticles(context.Context, time.Time) ([]model.RowArticle, error) {
+	return r.purged, r.err
+}
+
+type fakeProductRepo struct {
+	repository.ProductRepositoryInterface
+	purged []model.RowProduct
+}
+
+func (r *fakeProductRepo) PurgeDeletedProducts(context.Context, time.Time) ([]model.RowProduct, error) {
+	return r.purged, nil
+}
+
+type fakeUserRepo struct {
+	repository.UserRepositoryInterface
+	purged []model.RowUser
+}
+
+func (r *fakeUserRepo) PurgeDeletedUsers(context.Context, time.Time) ([]model.RowUser, error) {
+	return r.purged, nil
+}
+
+// fakeRevisionRepo - запоминает, чью историю удалили.
+type fakeRevisionRepo struct {
+	repository.RevisionRepositoryInterface
+	deleted map[string][]primitive.ObjectID
+}
+
+func (r *fakeRevisionRepo) DeleteRevisions(_ context.Context, entityType string, entityIDs []primitive.ObjectID) (int64, error) {
+	r.deleted[entityType] = append(r.deleted[entityType], entityIDs...)
+	return int64(len(entityIDs)), nil
+}
+
+// fakeMedia - запоминает удалённые изображения.
+type fakeMedia struct {
+	service.MediaServiceInterface
+	removed []string
+}
+
+func (m *fakeMedia) RemoveImage(_ context.Context, img *string) {
+	if img != nil {
+		m.removed = append(m.removed, *img)
+	}
+}
+
+func newFakeRevisionRepo() *fakeRevisionRepo {
+	return &fakeRevisionRepo{deleted: map[string][]primitive.ObjectID{}}
+}
+
+func image(s string) *string {
+	return &s
+}
+
+func TestTrashPurger(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Images of purged documents are removed", func(t *testing.T) {
+		articles := &fakeArticleRepo{purged: []model.RowArticle{
+			{ID: primitive.NewObjectID(), Img: image("/api/media/a1")},
+			{ID: primitive.NewObjectID()},
+		}}
+		products := &fakeProductRepo{purged: []model.RowProduct{{ID: primitive.NewObjectID(), Img: image("/api/media/p1")}}}
+		users := &fakeUserRepo{purged: []model.RowUser{{ID: primitive.NewObjectID(), Avatar: image("/api/media/u1")}}}
+		media := &fakeMedia{}
+
+		service.NewTrashPurger(articles, products, users, newFakeRevisionRepo(), media, time.Hour, time.Hour, zap.NewNop()).Purge(ctx)
+
+		assert.ElementsMatch(t, []string{"/api/media/a1", "/api/media/p1", "/api/media/u1"}, media.removed)
+	})
+
+	t.Run("Documents purged before an error are cleaned up too", func(t *testing.T) {
+		articles := &fakeArticleRepo{
+			purged: []model.RowArticle{{ID: primitive.NewObjectID(), Img: image("/api/media/a1")}},
+			err:    errors.New("connection reset"),
+		}
+		revisions := newFakeRevisionRepo()
+		media := &fakeMedia{}
+
+		service.NewTrashPurger(articles, &fakeProductRepo{}, &fakeUserRepo{}, revisions, media, time.Hour, time.Hour, zap.NewNop()).Purge(ctx)
+
+		assert.Equal(t, []string{"/api/media/a1"}, media.removed)
+		assert.Equal(t, []primitive.ObjectID{articles.purged[0].ID}, revisions.deleted[model.RevisionEntityArticle])
+	})
+
+	t.Run("Revisions of purged articles and projects are removed", func(t *testing.T) {
+		articles := &fakeArticleRepo{purged: []model.RowArticle{{ID: primitive.NewObjectID()}, {ID: primitive.NewObjectID()}}}
+		products := &fakeProductRepo{purged: []model.RowProduct{{ID: primitive.NewObjectID()}}}
+		users := &fakeUserRepo{purged: []model.RowUser{{ID: primitive.NewObjectID()}}}
+		revisions := newFakeRevisionRepo()
+
+		service.NewTrashPurger(articles, products, users, revisions, &fakeMedia{}, time.Hour, time.Hour, zap.NewNop()).Purge(ctx)
+
+		assert.Equal(t, []primitive.ObjectID{articles.purged[0].ID, articles.purged[1].ID}, revisions.deleted[model.RevisionEntityArticle])
+		assert.Equal(t, []primitive.ObjectID{products.purged[0].ID}, revisions.deleted[model.RevisionEntityProduct])
+		// У пользователей истории изменений нет
+		assert.Len(t, revisions.deleted, 2)
+	})
+}
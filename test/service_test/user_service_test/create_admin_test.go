@@ -44,7 +44,7 @@ func setup() (context.Context, service.UserServiceInterface) {
 	ctx := context.Background()
 
 	repo := repository.NewUserRepository(clientDB, logger)
-	serv := service.NewUserService(repo, logger)
+	serv := service.NewUserService(repo, nil, nil, logger)
 	return ctx, serv
 }
 
@@ -59,7 +59,7 @@ func TestCreateAdmin(t *testing.T) {
 
 	// Запуск теста
 	t.Run("Success", func(t *testing.T) {
-		createdUser, err := serv.CreateNewAdmin(ctx, &correctDto)
+		createdUser, err := serv.CreateUser(ctx, &correctDto)
 		// Вывод структуры с ключами
 		fmt.Printf("CreatedUser: %+v\n", createdUser)
 
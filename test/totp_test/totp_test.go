@@ -0,0 +1,69 @@
+package totp_test
+
+import (
+	"edjr-trk/pkg/totp"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfcSecret - "12345678901234567890" из тестовых векторов RFC 6238 в base32.
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestCode(t *testing.T) {
+	t.Run("RFC 6238 vectors", func(t *testing.T) {
+		vectors := map[int64]string{
+			59:         "287082",
+			1111111109: "081804",
+			1234567890: "005924",
+			2000000000: "279037",
+		}
+		for unix, expected := range vectors {
+			code, err := totp.Code(rfcSecret, totp.Step(time.Unix(unix, 0)))
+			assert.NoError(t, err)
+			assert.Equal(t, expected, code, "time %d", unix)
+		}
+	})
+
+	t.Run("Invalid secret", func(t *testing.T) {
+		_, err := totp.Code("not base32!", 1)
+		assert.Error(t, err)
+	})
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+
+	t.Run("Current code", func(t *testing.T) {
+		step, ok := totp.Validate(rfcSecret, "005924", now)
+		assert.True(t, ok)
+		assert.Equal(t, totp.Step(now), step)
+	})
+
+	t.Run("Previous period is accepted for clock drift", func(t *testing.T) {
+		_, ok := totp.Validate(rfcSecret, "005924", now.Add(totp.Period))
+		assert.True(t, ok)
+	})
+
+	t.Run("Old code is rejected", func(t *testing.T) {
+		_, ok := totp.Validate(rfcSecret, "005924", now.Add(3*totp.Period))
+		assert.False(t, ok)
+	})
+
+	t.Run("Malformed code", func(t *testing.T) {
+		_, ok := totp.Validate(rfcSecret, "5924", now)
+		assert.False(t, ok)
+	})
+}
+
+func TestURI(t *testing.T) {
+	secret, err := totp.GenerateSecret()
+	assert.NoError(t, err)
+	assert.Len(t, secret, 32)
+
+	uri := totp.URI("edjr-trk", "owner@example.com", secret)
+	assert.True(t, strings.HasPrefix(uri, "otpauth://totp/edjr-trk:owner@example.com?"))
+	assert.Contains(t, uri, "secret="+secret)
+	assert.Contains(t, uri, "issuer=edjr-trk")
+}
@@ -0,0 +1,21 @@
+package utils_test
+
+import (
+	"edjr-trk/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParseAcceptLanguage(t *testing.T) {
+	t.Run("Orders by quality and strips regions", func(t *testing.T) {
+		assert.Equal(t, []string{"en", "ru"}, utils.ParseAcceptLanguage("ru;q=0.8, en-US, en;q=0.9"))
+	})
+
+	t.Run("Skips wildcard and zero quality", func(t *testing.T) {
+		assert.Equal(t, []string{"de"}, utils.ParseAcceptLanguage("*, fr;q=0, de;q=0.5"))
+	})
+
+	t.Run("Empty header", func(t *testing.T) {
+		assert.Empty(t, utils.ParseAcceptLanguage(""))
+	})
+}
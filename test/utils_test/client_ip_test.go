@@ -0,0 +1,95 @@
+package utils_test
+
+import (
+	"edjr-trk/pkg/utils"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientIPResolver(t *testing.T) {
+	proxy := net.ParseIP("10.0.0.5")
+	client := net.ParseIP("203.0.113.7")
+
+	t.Run("Proxy mode without trusted proxies refuses to start", func(t *testing.T) {
+		_, err := utils.NewClientIPResolver(nil, "X-Forwarded-For", true)
+		assert.ErrorIs(t, err, utils.ErrNoTrustedProxies)
+	})
+
+	t.Run("Invalid trusted proxy is rejected", func(t *testing.T) {
+		_, err := utils.NewClientIPResolver([]string{"not-an-ip"}, "X-Forwarded-For", true)
+		assert.Error(t, err)
+	})
+
+	t.Run("Header from an untrusted connection is ignored", func(t *testing.T) {
+		resolver, err := utils.NewClientIPResolver(nil, "X-Forwarded-For", false)
+		assert.NoError(t, err)
+
+		assert.Equal(t, client.String(), resolver.Resolve(client, "198.51.100.1"))
+	})
+
+	t.Run("Rightmost untrusted hop is the client", func(t *testing.T) {
+		resolver, err := utils.NewClientIPResolver([]string{"10.0.0.0/8"}, "X-Forwarded-For", true)
+		assert.NoError(t, err)
+
+		assert.Equal(t, "203.0.113.7", resolver.Resolve(proxy, "203.0.113.7"))
+		// Two proxies in a row: the inner one is skipped as well
+		assert.Equal(t, "203.0.113.7", resolver.Resolve(proxy, "203.0.113.7, 10.0.0.9"))
+	})
+
+	t.Run("Spoofed leftmost X-Forwarded-For entries are ignored", func(t *testing.T) {
+		resolver, err := utils.NewClientIPResolver([]string{"10.0.0.5"}, "X-Forwarded-For", true)
+		assert.NoError(t, err)
+
+		// Клиент прислал свой X-Forwarded-For, прокси дописал к нему настоящий адрес
+		assert.Equal(t, "203.0.113.7", resolver.Resolve(proxy, "198.51.100.1, 203.0.113.7"))
+		assert.Equal(t, "203.0.113.7", resolver.Resolve(proxy, "garbage, 203.0.113.7"))
+	})
+
+	t.Run("Header with only trusted or invalid hops falls back to the connection", func(t *testing.T) {
+		resolver, err := utils.NewClientIPResolver([]string{"10.0.0.0/8"}, "X-Forwarded-For", true)
+		assert.NoError(t, err)
+
+		assert.Equal(t, proxy.String(), resolver.Resolve(proxy, "10.0.0.9"))
+		assert.Equal(t, proxy.String(), resolver.Resolve(proxy, "unknown"))
+		assert.Equal(t, proxy.String(), resolver.Resolve(proxy, ""))
+	})
+
+	t.Run("X-Real-IP set by the proxy is used as is", func(t *testing.T) {
+		resolver, err := utils.NewClientIPResolver([]string{"10.0.0.5"}, "X-Real-IP", true)
+		assert.NoError(t, err)
+
+		assert.Equal(t, "203.0.113.7", resolver.Resolve(proxy, "203.0.113.7"))
+		assert.Equal(t, proxy.String(), resolver.Resolve(proxy, "203.0.113.7, 198.51.100.1"))
+		assert.Equal(t, client.String(), resolver.Resolve(client, "198.51.100.1"))
+	})
+
+	t.Run("IPv6 proxies are supported", func(t *testing.T) {
+		resolver, err := utils.NewClientIPResolver([]string{"fd00::1"}, "X-Forwarded-For", true)
+		assert.NoError(t, err)
+
+		assert.Equal(t, "2001:db8::7", resolver.Resolve(net.ParseIP("fd00::1"), "2001:db8::7"))
+	})
+
+	t.Run("Middleware passes the resolved IP to GetClientIP", func(t *testing.T) {
+		// app.Test подключается с адреса 0.0.0.0
+		resolver, err := utils.NewClientIPResolver([]string{"0.0.0.0"}, "X-Forwarded-For", true)
+		assert.NoError(t, err)
+
+		app := fiber.New()
+		app.Use(resolver.Middleware())
+		app.Get("/", func(c *fiber.Ctx) error { return c.SendString(utils.GetClientIP(c)) })
+
+		req := httptest.NewRequest(fiber.MethodGet, "/", nil)
+		req.Header.Set(fiber.HeaderXForwardedFor, "198.51.100.1, 203.0.113.7")
+		resp, err := app.Test(req)
+		assert.NoError(t, err)
+
+		body, err := io.ReadAll(resp.Body)
+		assert.NoError(t, err)
+		assert.Equal(t, "203.0.113.7", string(body))
+	})
+}
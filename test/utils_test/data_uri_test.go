@@ -0,0 +1,32 @@
+package utils_test
+
+import (
+	"edjr-trk/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParseDataURI(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mimeType, data, err := utils.ParseDataURI("data:image/png;base64,aGVsbG8=")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "image/png", mimeType)
+		assert.Equal(t, []byte("hello"), data)
+	})
+
+	t.Run("Not a data URI", func(t *testing.T) {
+		_, _, err := utils.ParseDataURI("/api/media/674b0981fd898a8a128c5ffb")
+		assert.ErrorIs(t, err, utils.ErrInvalidDataURI)
+	})
+
+	t.Run("Not base64", func(t *testing.T) {
+		_, _, err := utils.ParseDataURI("data:image/png,hello")
+		assert.ErrorIs(t, err, utils.ErrInvalidDataURI)
+	})
+
+	t.Run("Broken payload", func(t *testing.T) {
+		_, _, err := utils.ParseDataURI("data:image/png;base64,@@@")
+		assert.ErrorIs(t, err, utils.ErrInvalidDataURI)
+	})
+}
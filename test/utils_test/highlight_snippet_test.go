@@ -0,0 +1,39 @@
+package utils_test
+
+import (
+	"edjr-trk/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestHighlightSnippet(t *testing.T) {
+	t.Run("Cyrillic match", func(t *testing.T) {
+		snippet := utils.HighlightSnippet("Новый Проект запущен", "проект", 50)
+		assert.Equal(t, "Новый <mark>Проект</mark> запущен", snippet)
+	})
+
+	t.Run("Window around match", func(t *testing.T) {
+		snippet := utils.HighlightSnippet("aaaaaaaaaa golang bbbbbbbbbb", "golang", 3)
+		assert.Equal(t, "…aa <mark>gol</mark>…", snippet)
+	})
+
+	t.Run("Escapes HTML", func(t *testing.T) {
+		snippet := utils.HighlightSnippet("<b>go</b>", "go", 50)
+		assert.Equal(t, "&lt;b&gt;<mark>go</mark>&lt;/b&gt;", snippet)
+	})
+
+	t.Run("Inflected forms match the stem", func(t *testing.T) {
+		snippet := utils.HighlightSnippet("Запуск проекта и новые проекты", "проекты", 50)
+		assert.Equal(t, "Запуск <mark>проекта</mark> и новые <mark>проекты</mark>", snippet)
+	})
+
+	t.Run("Short terms match word prefixes only", func(t *testing.T) {
+		snippet := utils.HighlightSnippet("cargo golang", "go", 50)
+		assert.Equal(t, "cargo <mark>golang</mark>", snippet)
+	})
+
+	t.Run("No match", func(t *testing.T) {
+		snippet := utils.HighlightSnippet("hello world", "xyz", 3)
+		assert.Equal(t, "hello …", snippet)
+	})
+}
@@ -0,0 +1,31 @@
+package utils_test
+
+import (
+	"edjr-trk/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"testing"
+	"time"
+)
+
+func TestPageCursor(t *testing.T) {
+	t.Run("Round trip", func(t *testing.T) {
+		cursor := utils.PageCursor{
+			Date:     time.UnixMilli(1732970881274),
+			ID:       primitive.NewObjectID(),
+			Backward: true,
+		}
+
+		decoded, err := utils.DecodePageCursor(utils.EncodePageCursor(cursor))
+
+		assert.NoError(t, err)
+		assert.True(t, cursor.Date.Equal(decoded.Date))
+		assert.Equal(t, cursor.ID, decoded.ID)
+		assert.True(t, decoded.Backward)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		_, err := utils.DecodePageCursor("not-a-cursor")
+		assert.ErrorIs(t, err, utils.ErrInvalidPageCursor)
+	})
+}
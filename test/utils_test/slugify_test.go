@@ -0,0 +1,41 @@
+package utils_test
+
+import (
+	"edjr-trk/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestSlugify(t *testing.T) {
+	t.Run("Cyrillic title", func(t *testing.T) {
+		assert.Equal(t, "privet-mir-2024", utils.Slugify("Привет, мир! 2024"))
+		assert.Equal(t, "shchuka-i-yozh", utils.Slugify("Щука и ёж"))
+	})
+
+	t.Run("Latin with diacritics", func(t *testing.T) {
+		assert.Equal(t, "cafe-creme", utils.Slugify("  Café   Crème -- "))
+	})
+
+	t.Run("Soft and hard signs", func(t *testing.T) {
+		assert.Equal(t, "obekt-semya", utils.Slugify("Объект Семья"))
+	})
+
+	t.Run("Nothing to keep", func(t *testing.T) {
+		assert.Equal(t, "", utils.Slugify("!!! ★ ???"))
+	})
+
+	t.Run("Length limit", func(t *testing.T) {
+		slug := utils.Slugify(strings.Repeat("слово ", 40))
+		assert.LessOrEqual(t, len(slug), utils.MaxSlugLength)
+		assert.True(t, utils.IsValidSlug(slug))
+	})
+}
+
+func TestIsValidSlug(t *testing.T) {
+	assert.True(t, utils.IsValidSlug("my-first-post-2"))
+	assert.False(t, utils.IsValidSlug("My-Post"))
+	assert.False(t, utils.IsValidSlug("double--dash"))
+	assert.False(t, utils.IsValidSlug("-leading"))
+	assert.False(t, utils.IsValidSlug(""))
+}
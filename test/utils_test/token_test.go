@@ -0,0 +1,30 @@
+package utils_test
+
+import (
+	"edjr-trk/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRandomToken(t *testing.T) {
+	t.Run("url-safe and unique", func(t *testing.T) {
+		first, err := utils.RandomToken(32)
+		assert.NoError(t, err)
+		second, err := utils.RandomToken(32)
+		assert.NoError(t, err)
+
+		assert.Len(t, first, 43)
+		assert.NotEqual(t, first, second)
+		assert.NotContains(t, first, "=")
+		assert.NotContains(t, first, "+")
+		assert.NotContains(t, first, "/")
+	})
+}
+
+func TestHashToken(t *testing.T) {
+	t.Run("deterministic sha256 hex", func(t *testing.T) {
+		assert.Equal(t, "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855", utils.HashToken(""))
+		assert.Equal(t, utils.HashToken("token"), utils.HashToken("token"))
+		assert.NotEqual(t, utils.HashToken("token"), utils.HashToken("token2"))
+	})
+}